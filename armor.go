@@ -0,0 +1,141 @@
+package serial
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Armor is the text encoding of binary payloads sent one per line.
+type Armor int
+
+const (
+	ArmorHex    Armor = iota // hex digits in either case; spaces are ignored
+	ArmorBase64              // standard alphabet, padding optional
+)
+
+// Dearmor returns a Framer for devices that ASCII-armor binary payloads: it
+// splits the input into lines like the "lines" framer, strips prefix (such
+// as "DATA:") from lines that start with it and decodes the rest with armor.
+// With next nil, every line is a frame; otherwise the decoded bytes are
+// passed on to next, for a binary protocol tunnelled through text lines,
+// and its frames are returned. Lines that do not decode are reported as
+// ErrBadFrame. Config.MaxLineLength also bounds the decoded bytes held for
+// next.
+//
+// The framer is also an Encoder: it encodes payloads with next, if that is
+// an Encoder, then armors them in upper-case hex or padded base64 behind
+// prefix and ends them with the delimiter.
+//
+// The "hex" and "base64" framers are Dearmor without prefix or next framer.
+func Dearmor(cfg Config, armor Armor, prefix string, next Framer) Framer {
+	return &dearmor{
+		lineEnd: cfg.lineEnd(),
+		delim:   cfg.delimiter(),
+		armor:   armor,
+		prefix:  []byte(prefix),
+		next:    next,
+		max:     cfg.MaxLineLength,
+	}
+}
+
+type dearmor struct {
+	lineEnd func(b []byte, from int) (end, next int)
+	delim   string
+	armor   Armor
+	prefix  []byte
+	next    Framer
+	max     int
+	out     []byte // decoded line
+	buf     []byte // decoded bytes not yet framed by next
+	skip    int    // length of the line decoded into buf, still in data
+}
+
+// Frame implements Framer. A decoded line is consumed once next has no more
+// frames in it; those frames are returned with advance 0.
+func (d *dearmor) Frame(data []byte) (int, []byte, error) {
+	if d.skip == 0 {
+		end, next := d.lineEnd(data, 0)
+		if end < 0 {
+			return 0, nil, nil
+		}
+		payload, err := d.decode(data[:end])
+		if err != nil {
+			return next, nil, fmt.Errorf("%w: %w", ErrBadFrame, err)
+		}
+		if d.next == nil {
+			return next, payload, nil
+		}
+		d.buf = append(d.buf, payload...)
+		if d.max > 0 && len(d.buf) > d.max {
+			d.buf = d.buf[:0]
+			return next, nil, ErrLineTooLong
+		}
+		d.skip = next
+	}
+	// An error from next is returned with the frame after it or, if there
+	// is none, with the rest of the line, so every call makes progress.
+	var bad error
+	for len(d.buf) > 0 {
+		advance, frame, err := d.next.Frame(d.buf)
+		if advance < 0 || advance > len(d.buf) {
+			advance = len(d.buf)
+		}
+		if err != nil && frame == nil && advance == 0 {
+			advance = 1 // resynchronise one byte on
+		}
+		d.buf = d.buf[advance:]
+		if err != nil && bad == nil {
+			bad = err
+		}
+		if frame != nil {
+			return 0, frame, bad
+		}
+		if advance == 0 {
+			break
+		}
+	}
+	skip := d.skip
+	d.skip = 0
+	return skip, nil, bad
+}
+
+// Encode implements Encoder.
+func (d *dearmor) Encode(payload []byte) []byte {
+	if e, ok := d.next.(Encoder); ok {
+		payload = e.Encode(payload)
+	}
+	out := append([]byte(nil), d.prefix...)
+	switch d.armor {
+	case ArmorHex:
+		out = append(out, strings.ToUpper(hex.EncodeToString(payload))...)
+	case ArmorBase64:
+		out = base64.StdEncoding.AppendEncode(out, payload)
+	}
+	return append(out, d.delim...)
+}
+
+// decode strips the prefix from line and decodes the rest into d.out.
+func (d *dearmor) decode(line []byte) ([]byte, error) {
+	line = bytes.TrimSpace(bytes.TrimPrefix(line, d.prefix))
+	switch d.armor {
+	case ArmorHex:
+		if bytes.IndexByte(line, ' ') >= 0 {
+			line = bytes.ReplaceAll(line, []byte(" "), nil)
+		}
+		n := hex.DecodedLen(len(line))
+		d.out = slices.Grow(d.out[:0], n)[:n]
+		n, err := hex.Decode(d.out, line)
+		return d.out[:n], err
+	case ArmorBase64:
+		line = bytes.TrimRight(line, "=")
+		n := base64.RawStdEncoding.DecodedLen(len(line))
+		d.out = slices.Grow(d.out[:0], n)[:n]
+		n, err := base64.RawStdEncoding.Decode(d.out, line)
+		return d.out[:n], err
+	}
+	return nil, fmt.Errorf("invalid armor %d", d.armor)
+}
@@ -0,0 +1,62 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDearmor(t *testing.T) {
+	f := Dearmor(Config{}, ArmorHex, "DATA:", nil)
+	data := []byte("DATA:de ad BE EF\r\n0102\r\nxyz\r\n")
+	advance, frame, err := f.Frame(data)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, frame)
+	data = data[advance:]
+	advance, frame, err = f.Frame(data)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, frame)
+	data = data[advance:]
+	advance, frame, err = f.Frame(data)
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.Nil(t, frame)
+	require.Equal(t, 5, advance)
+
+	f, err = NewFramer("base64", Config{Delimiter: "\n"})
+	require.NoError(t, err)
+	for _, line := range []string{"AAEC/w==\n", "AAEC/w\n"} {
+		_, frame, err = f.Frame([]byte(line))
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 1, 2, 0xff}, frame)
+	}
+
+	// A next framer failing without progress is moved on a byte at a time.
+	next := FramerFunc(func(data []byte) (int, []byte, error) {
+		if data[0] == '!' {
+			return 0, nil, ErrBadFrame
+		}
+		return 1, data[:1], nil
+	})
+	f = Dearmor(Config{Delimiter: "\n"}, ArmorHex, "", next)
+	data = []byte("2141\n")
+	advance, frame, err = f.Frame(data)
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.Equal(t, "A", string(frame))
+	require.Zero(t, advance)
+	advance, frame, err = f.Frame(data)
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	require.Equal(t, 5, advance)
+}
+
+func TestSerialReader_DearmorNext(t *testing.T) {
+	reader, master := newTestReader(t, Config{Delimiter: "\n"})
+	frames := make(chan string, 4)
+	go reader.ReadFramesLoop(Dearmor(reader.config, ArmorHex, "", COBS{}), func(f []byte) { frames <- string(f) }, func(error) {})
+
+	// Two COBS frames split across lines, the second line ending both.
+	_, err := master.Write([]byte("03414202\n4300024400\n"))
+	require.NoError(t, err)
+	require.Equal(t, "AB\x00C", <-frames)
+	require.Equal(t, "D", <-frames)
+}
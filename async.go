@@ -0,0 +1,110 @@
+package serial
+
+import "sync"
+
+// AsyncDelivery decouples framing from delivery in ReadLinesLoop: the read
+// loop only appends each line to a queue, and one delivery goroutine runs
+// checksum verification, middleware, subscriptions and onLine in order. See
+// Config.Async.
+//
+// Compared with a WorkerPool, order is always kept and nothing runs in
+// parallel, so a callback that is slow on average still falls behind; the
+// queue only absorbs bursts. Each line's latency grows by its wait in the
+// queue, which is zero while delivery keeps up.
+type AsyncDelivery struct {
+	// Queue bounds the lines waiting for delivery; 0 means unbounded, so
+	// the read loop never waits but memory grows while delivery lags.
+	Queue int
+
+	// Policy decides what happens to a line arriving at a full queue: Block
+	// makes the read loop wait (the kernel buffer then fills, and overruns
+	// if delivery stays behind), the others drop lines, counted in
+	// Stats().Dropped.
+	Policy Backpressure
+}
+
+// lineQueue is the queue behind AsyncDelivery.
+type lineQueue struct {
+	mu       sync.Mutex
+	notEmpty sync.Cond
+	notFull  sync.Cond
+	lines    []string
+	limit    int
+	policy   Backpressure
+	closed   bool
+	dropped  func(n int)
+}
+
+func (q *lineQueue) put(line string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.limit > 0 && len(q.lines) >= q.limit {
+		switch q.policy {
+		case DropNewest:
+			q.dropped(1)
+			return
+		case DropOldest:
+			q.lines = q.lines[1:]
+			q.dropped(1)
+		case Coalesce:
+			q.dropped(len(q.lines))
+			q.lines = q.lines[:0]
+		default:
+			q.notFull.Wait()
+		}
+	}
+	q.lines = append(q.lines, line)
+	q.notEmpty.Signal()
+}
+
+// take returns the queued lines, waiting for at least one, or nil once the
+// queue is closed and empty.
+func (q *lineQueue) take(buf []string) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.lines) == 0 && !q.closed {
+		q.notEmpty.Wait()
+	}
+	buf = append(buf[:0], q.lines...)
+	clear(q.lines)
+	q.lines = q.lines[:0]
+	q.notFull.Broadcast()
+	return buf
+}
+
+func (q *lineQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Signal()
+	q.mu.Unlock()
+}
+
+// asyncFunc returns a handler that queues lines for deliver on a goroutine
+// of its own, per Config.Async, and a function that waits until every
+// queued line has been delivered. Without Config.Async it returns deliver.
+func (s *SerialReader) asyncFunc(deliver func(string)) (func(string), func()) {
+	a := s.config.Async
+	if a == nil {
+		return deliver, func() {}
+	}
+	q := &lineQueue{limit: a.Queue, policy: a.Policy, dropped: func(n int) { s.dropped.Add(uint64(n)) }}
+	q.notEmpty.L, q.notFull.L = &q.mu, &q.mu
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var batch []string
+		for {
+			batch = q.take(batch)
+			if len(batch) == 0 {
+				return
+			}
+			for _, line := range batch {
+				deliver(line)
+			}
+		}
+	}()
+	return q.put, func() {
+		q.close()
+		<-done
+	}
+}
@@ -0,0 +1,73 @@
+package serial
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_Async(t *testing.T) {
+	reader, master := newTestReader(t, Config{Async: &AsyncDelivery{}})
+	gate := make(chan struct{})
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader.ReadLinesLoop(func(l string) {
+			<-gate // a callback that cannot keep up
+			mu.Lock()
+			got = append(got, l)
+			mu.Unlock()
+		}, func(error) {})
+	}()
+
+	var want []string
+	for i := range 20 {
+		want = append(want, fmt.Sprint(i))
+	}
+	_, err := master.Write([]byte(strings.Join(want, "\n") + "\n"))
+	require.NoError(t, err)
+
+	// The read loop frames every line while the callback is still stuck on
+	// the first one.
+	require.Eventually(t, func() bool { return reader.Stats().Lines == 20 }, time.Second, time.Millisecond)
+	close(gate)
+	reader.Close()
+	<-done
+	require.Equal(t, want, got)
+	require.Zero(t, reader.Stats().Dropped)
+}
+
+func TestSerialReader_AsyncDropNewest(t *testing.T) {
+	reader, master := newTestReader(t, Config{Async: &AsyncDelivery{Queue: 2, Policy: DropNewest}})
+	gate := make(chan struct{})
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader.ReadLinesLoop(func(l string) {
+			<-gate
+			got = append(got, l)
+		}, func(error) {})
+	}()
+
+	_, err := master.Write([]byte("a\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return reader.Stats().Lines == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond) // let delivery take "a" and block on it
+	_, err = master.Write([]byte("b\nc\nd\ne\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return reader.Stats().Lines == 5 }, time.Second, time.Millisecond)
+	close(gate)
+	reader.Close()
+	<-done
+	require.Equal(t, []string{"a", "b", "c"}, got)
+	require.EqualValues(t, 2, reader.Stats().Dropped)
+}
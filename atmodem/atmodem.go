@@ -0,0 +1,303 @@
+// Package atmodem drives Hayes-compatible (AT command) modems, such as the
+// cellular modems remote stations use for backhaul, over a serial byte stream.
+//
+// A Modem reads the stream in the background: command responses go to the
+// pending Command call, and unsolicited result codes (URCs) such as RING or
+// +CREG go to OnURC. Dial switches the modem to data mode and returns the
+// data stream; closing it escapes back to command mode with +++ and hangs up.
+package atmodem
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+var (
+	// ErrError is returned when the modem answers ERROR.
+	ErrError = errors.New("atmodem: ERROR")
+	// ErrTimeout is returned when no final result code arrives in time.
+	ErrTimeout = errors.New("atmodem: command timed out")
+	// ErrDataMode is returned by Command while a data connection is open.
+	ErrDataMode = errors.New("atmodem: modem is in data mode")
+)
+
+// CMEError is a +CME ERROR (equipment) or +CMS ERROR (SMS) result. With
+// verbose errors enabled (AT+CMEE=2) Text holds the message and Code is -1.
+type CMEError struct {
+	SMS  bool
+	Code int
+	Text string
+}
+
+func (e *CMEError) Error() string {
+	kind := "CME"
+	if e.SMS {
+		kind = "CMS"
+	}
+	if e.Text != "" {
+		return fmt.Sprintf("atmodem: +%s ERROR: %s", kind, e.Text)
+	}
+	return fmt.Sprintf("atmodem: +%s ERROR: %d", kind, e.Code)
+}
+
+// ResultError is a final result code other than OK or ERROR that ends a
+// command or dial attempt, e.g. BUSY or NO CARRIER.
+type ResultError struct {
+	Result string
+}
+
+func (e *ResultError) Error() string { return "atmodem: " + e.Result }
+
+// failureResults are the final result codes reported as *ResultError.
+var failureResults = []string{"NO CARRIER", "BUSY", "NO DIALTONE", "NO DIAL TONE", "NO ANSWER"}
+
+// Modem sends AT commands over a byte stream such as a *serial.SerialReader.
+// Commands are serialised; it is safe for concurrent use.
+type Modem struct {
+	// Timeout bounds Command. Zero means five seconds.
+	Timeout time.Duration
+	// GuardTime is the silence kept before and after the +++ escape. Zero
+	// means one second.
+	GuardTime time.Duration
+	// OnURC receives unsolicited result codes. Outside of a command every
+	// line is one; during a command only lines starting with one of
+	// URCPrefixes are, unless they answer the command itself.
+	OnURC       func(line string)
+	URCPrefixes []string
+
+	rw io.ReadWriter
+
+	cmdMu sync.Mutex // serialises commands
+
+	mu      sync.Mutex
+	pending *command
+	data    *io.PipeWriter // non-nil in data mode
+
+	done chan struct{}
+	err  error
+}
+
+// command is an AT command awaiting its final result code.
+type command struct {
+	text   string
+	prefix string // response prefix, e.g. "+CSQ" for AT+CSQ
+	lines  []string
+	final  chan error
+	sink   *io.PipeWriter // for Dial: receives the data stream after CONNECT
+}
+
+// New returns a Modem on rw and starts reading from it. The Modem stops when
+// a read fails, typically because the port was closed; see Done and Err.
+func New(rw io.ReadWriter) *Modem {
+	m := &Modem{rw: rw, done: make(chan struct{})}
+	go m.readLoop()
+	return m
+}
+
+// Done is closed when the Modem has stopped reading.
+func (m *Modem) Done() <-chan struct{} { return m.done }
+
+// Err returns the read error that stopped the Modem, once Done is closed.
+func (m *Modem) Err() error {
+	<-m.done
+	return m.err
+}
+
+// Command sends cmd (e.g. "AT+CSQ") followed by CR and returns the
+// information lines of the response, excluding echo and the final OK.
+func (m *Modem) Command(cmd string) ([]string, error) {
+	return m.CommandTimeout(cmd, m.Timeout)
+}
+
+// CommandTimeout is Command with an explicit timeout, for slow commands
+// such as network registration or AT+COPS=?.
+func (m *Modem) CommandTimeout(cmd string, timeout time.Duration) ([]string, error) {
+	m.cmdMu.Lock()
+	defer m.cmdMu.Unlock()
+	return m.run(&command{text: cmd}, cmd+"\r", timeout)
+}
+
+// run registers c as pending, writes out and waits for the final result.
+func (m *Modem) run(c *command, out string, timeout time.Duration) ([]string, error) {
+	if timeout <= 0 {
+		timeout = m.timeout()
+	}
+	c.final = make(chan error, 1)
+	c.prefix = responsePrefix(c.text)
+	m.mu.Lock()
+	if m.data != nil {
+		m.mu.Unlock()
+		return nil, ErrDataMode
+	}
+	m.pending = c
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		if m.pending == c {
+			m.pending = nil
+		}
+		m.mu.Unlock()
+	}()
+
+	if _, err := io.WriteString(m.rw, out); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-c.final:
+		m.mu.Lock()
+		lines := c.lines
+		m.mu.Unlock()
+		return lines, err
+	case <-timer.C:
+		return nil, ErrTimeout
+	case <-m.done:
+		return nil, m.err
+	}
+}
+
+func (m *Modem) timeout() time.Duration {
+	if m.Timeout > 0 {
+		return m.Timeout
+	}
+	return 5 * time.Second
+}
+
+// responsePrefix returns the prefix of information lines answering cmd:
+// "+CSQ" for "AT+CSQ" and "AT+CSQ=?", "+CREG" for "AT+CREG?".
+func responsePrefix(cmd string) string {
+	s := strings.TrimPrefix(strings.ToUpper(cmd), "AT")
+	if !strings.HasPrefix(s, "+") && !strings.HasPrefix(s, "^") && !strings.HasPrefix(s, "#") {
+		return ""
+	}
+	if i := strings.IndexAny(s, "=?;"); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// readLoop reads the stream, splitting it into lines in command mode and
+// passing it through in data mode.
+func (m *Modem) readLoop() {
+	defer close(m.done)
+	buf := make([]byte, 1024)
+	var pending []byte
+	for {
+		n, err := m.rw.Read(buf)
+		if err != nil && n == 0 {
+			if isTimeout(err) {
+				continue
+			}
+			m.err = err
+			m.mu.Lock()
+			if m.data != nil {
+				m.data.CloseWithError(err)
+			}
+			m.mu.Unlock()
+			return
+		}
+		pending = append(pending, buf[:n]...)
+		for len(pending) > 0 {
+			m.mu.Lock()
+			data := m.data
+			m.mu.Unlock()
+			if data != nil {
+				data.Write(pending) // fails harmlessly once the reader is closed
+				pending = nil
+				break
+			}
+			i := bytes.IndexAny(pending, "\r\n")
+			if i < 0 {
+				break
+			}
+			line := string(pending[:i])
+			if pending[i] == '\r' && i+1 < len(pending) && pending[i+1] == '\n' {
+				i++ // CRLF, so nothing of it leaks into data after CONNECT
+			}
+			pending = pending[i+1:]
+			if line != "" {
+				m.handleLine(line)
+			}
+		}
+	}
+}
+
+// isTimeout reports whether err is a read timeout, e.g. from a port opened
+// with Config.ReadTimeout; the loop simply keeps reading.
+func isTimeout(err error) bool {
+	return errors.Is(err, serial.ErrTimeout) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// handleLine dispatches one line in command mode.
+func (m *Modem) handleLine(line string) {
+	m.mu.Lock()
+	c := m.pending
+	if c == nil || (m.isURC(line) && (c.prefix == "" || !strings.HasPrefix(line, c.prefix))) {
+		m.mu.Unlock()
+		if m.OnURC != nil {
+			m.OnURC(line)
+		}
+		return
+	}
+	if line == c.text {
+		m.mu.Unlock()
+		return // echo
+	}
+	err, final := finalResult(line)
+	if !final {
+		c.lines = append(c.lines, line)
+		m.mu.Unlock()
+		return
+	}
+	if err == nil && c.sink != nil && strings.HasPrefix(line, "CONNECT") {
+		c.lines = append(c.lines, line)
+		m.data = c.sink // what follows CONNECT is data
+	}
+	m.pending = nil
+	m.mu.Unlock()
+	c.final <- err
+}
+
+func (m *Modem) isURC(line string) bool {
+	for _, p := range m.URCPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// finalResult classifies line as a final result code.
+func finalResult(line string) (err error, final bool) {
+	switch {
+	case line == "OK", strings.HasPrefix(line, "CONNECT"):
+		return nil, true
+	case line == "ERROR":
+		return ErrError, true
+	case strings.HasPrefix(line, "+CME ERROR:"), strings.HasPrefix(line, "+CMS ERROR:"):
+		e := &CMEError{SMS: line[3] == 'S', Code: -1}
+		detail := strings.TrimSpace(line[len("+CME ERROR:"):])
+		if code, err := strconv.Atoi(detail); err == nil {
+			e.Code = code
+		} else {
+			e.Text = detail
+		}
+		return e, true
+	}
+	for _, r := range failureResults {
+		if line == r {
+			return &ResultError{Result: line}, true
+		}
+	}
+	return nil, false
+}
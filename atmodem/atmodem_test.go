@@ -0,0 +1,159 @@
+package atmodem
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeModem answers commands read from conn with reply(cmd), echoing them
+// first like a modem with ATE1. In data mode (after a reply containing
+// CONNECT) received bytes go to data until "+++" arrives.
+func fakeModem(t *testing.T, reply func(cmd string) string, data chan<- []byte) *Modem {
+	t.Helper()
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	go func() {
+		buf := make([]byte, 256)
+		var pending []byte
+		online := false
+		for {
+			n, err := b.Read(buf)
+			if err != nil {
+				return
+			}
+			pending = append(pending, buf[:n]...)
+			if online {
+				if i := bytes.Index(pending, []byte("+++")); i >= 0 {
+					online = false
+					pending = pending[i+3:]
+					b.Write([]byte("\r\nOK\r\n"))
+				} else {
+					data <- pending
+					pending = nil
+				}
+				continue
+			}
+			for {
+				i := bytes.IndexByte(pending, '\r')
+				if i < 0 {
+					break
+				}
+				cmd := string(pending[:i])
+				pending = pending[i+1:]
+				resp := reply(cmd)
+				b.Write([]byte(cmd + "\r" + resp))
+				if bytes.Contains([]byte(resp), []byte("CONNECT")) {
+					online = true
+				}
+			}
+		}
+	}()
+	return New(a)
+}
+
+func TestModem_Command(t *testing.T) {
+	urcs := make(chan string, 4)
+	m := fakeModem(t, func(cmd string) string {
+		switch cmd {
+		case "AT":
+			return "\r\nOK\r\n"
+		case "AT+CSQ":
+			// A URC interleaved with the response.
+			return "\r\n+CREG: 5\r\n\r\n+CSQ: 17,99\r\n\r\nOK\r\n"
+		case "AT+CREG?":
+			return "\r\n+CREG: 0,1\r\n\r\nOK\r\n"
+		case "AT+CPIN?":
+			return "\r\n+CME ERROR: 10\r\n"
+		case "AT+CMEE=2;+CPIN?":
+			return "\r\n+CME ERROR: SIM not inserted\r\n"
+		case "ATX":
+			return "\r\nERROR\r\n"
+		}
+		return "" // no answer
+	}, nil)
+	m.Timeout = 200 * time.Millisecond
+	m.URCPrefixes = []string{"+CREG:", "RING"}
+	m.OnURC = func(line string) { urcs <- line }
+
+	lines, err := m.Command("AT")
+	require.NoError(t, err)
+	require.Empty(t, lines)
+
+	lines, err = m.Command("AT+CSQ")
+	require.NoError(t, err)
+	require.Equal(t, []string{"+CSQ: 17,99"}, lines)
+	require.Equal(t, "+CREG: 5", <-urcs)
+
+	// A URC prefix that answers the command itself is part of the response.
+	lines, err = m.Command("AT+CREG?")
+	require.NoError(t, err)
+	require.Equal(t, []string{"+CREG: 0,1"}, lines)
+
+	_, err = m.Command("AT+CPIN?")
+	var cme *CMEError
+	require.ErrorAs(t, err, &cme)
+	require.Equal(t, 10, cme.Code)
+	_, err = m.Command("AT+CMEE=2;+CPIN?")
+	require.ErrorAs(t, err, &cme)
+	require.Equal(t, "SIM not inserted", cme.Text)
+
+	_, err = m.Command("ATX")
+	require.ErrorIs(t, err, ErrError)
+	_, err = m.Command("AT+SLOW")
+	require.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestModem_DataMode(t *testing.T) {
+	received := make(chan []byte, 4)
+	var hungUp bool
+	m := fakeModem(t, func(cmd string) string {
+		switch cmd {
+		case "ATD*99#":
+			return "\r\nCONNECT 150000000\r\nhello"
+		case "ATDT555":
+			return "\r\nBUSY\r\n"
+		case "ATH":
+			hungUp = true
+			return "\r\nOK\r\n"
+		}
+		return "\r\nOK\r\n"
+	}, received)
+	m.Timeout = time.Second
+	m.GuardTime = 10 * time.Millisecond
+
+	_, err := m.Dial("ATDT555", time.Second)
+	var rerr *ResultError
+	require.ErrorAs(t, err, &rerr)
+	require.Equal(t, "BUSY", rerr.Result)
+
+	conn, err := m.Dial("ATD*99#", time.Second)
+	require.NoError(t, err)
+	got := make([]byte, 5)
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(got))
+
+	_, err = m.Command("AT")
+	require.ErrorIs(t, err, ErrDataMode)
+
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(<-received))
+
+	require.NoError(t, conn.Close())
+	require.True(t, hungUp)
+	_, err = m.Command("AT")
+	require.NoError(t, err)
+}
+
+func TestResponsePrefix(t *testing.T) {
+	require.Equal(t, "+CSQ", responsePrefix("AT+CSQ"))
+	require.Equal(t, "+CREG", responsePrefix("at+creg?"))
+	require.Equal(t, "+COPS", responsePrefix("AT+COPS=?"))
+	require.Equal(t, "", responsePrefix("ATI"))
+}
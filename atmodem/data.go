@@ -0,0 +1,61 @@
+package atmodem
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// DataConn is the data stream of a connection established by Dial.
+type DataConn struct {
+	m    *Modem
+	r    *io.PipeReader
+	once sync.Once
+	err  error
+}
+
+// Dial sends a dial command (e.g. "ATD*99#" for packet data) and waits up to
+// timeout for CONNECT. The modem then stays in data mode until the returned
+// DataConn is closed; Command fails with ErrDataMode meanwhile.
+func (m *Modem) Dial(cmd string, timeout time.Duration) (*DataConn, error) {
+	m.cmdMu.Lock()
+	defer m.cmdMu.Unlock()
+	r, w := io.Pipe()
+	if _, err := m.run(&command{text: cmd, sink: w}, cmd+"\r", timeout); err != nil {
+		return nil, err
+	}
+	return &DataConn{m: m, r: r}, nil
+}
+
+// Read reads data received from the remote end.
+func (d *DataConn) Read(p []byte) (int, error) { return d.r.Read(p) }
+
+// Write sends data to the remote end.
+func (d *DataConn) Write(p []byte) (int, error) { return d.m.rw.Write(p) }
+
+// Close leaves data mode with the +++ escape sequence, preceded by the guard
+// time, and hangs up with ATH. The Modem is back in command mode afterwards
+// even if the modem does not answer.
+func (d *DataConn) Close() error {
+	d.once.Do(func() {
+		m := d.m
+		m.cmdMu.Lock()
+		defer m.cmdMu.Unlock()
+		guard := m.GuardTime
+		if guard <= 0 {
+			guard = time.Second
+		}
+		time.Sleep(guard)
+		m.mu.Lock()
+		m.data = nil
+		m.mu.Unlock()
+		d.r.Close()
+		// The modem answers OK only after the trailing guard time.
+		if _, err := m.run(&command{text: "+++"}, "+++", guard+m.timeout()); err != nil {
+			d.err = err
+			return
+		}
+		_, d.err = m.run(&command{text: "ATH"}, "ATH\r", 0)
+	})
+	return d.err
+}
@@ -0,0 +1,249 @@
+// Package bench is a soak and latency harness for the serial stack. It feeds
+// generated lines through a PTY pair (or a loopback port) into a SerialReader
+// and measures how long each takes to travel from the sender through the
+// caller's callback. Downstream projects run it as a Go benchmark against
+// their own line handlers to detect latency regressions:
+//
+//	func BenchmarkParser(b *testing.B) {
+//	    bench.Benchmark(b, bench.Options{Rate: 200}, func(line string) {
+//	        parse(line)
+//	    })
+//	}
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+)
+
+// Options configures a run.
+type Options struct {
+	// Config holds the port settings; Device is set by Run. Delimiter
+	// defaults to "\n". MeasureLatency is always enabled.
+	Config serial.Config
+	// Device is a port whose TX is wired to its RX; empty uses a PTY pair.
+	Device string
+	// Rate is the number of lines per second; zero sends as fast as possible.
+	Rate   float64
+	Jitter time.Duration
+	// Lines is the number of lines to send; zero sends until Duration or
+	// ctx ends.
+	Lines    int
+	Duration time.Duration
+	// Size pads lines to at least this many bytes, delimiter included.
+	Size int
+	// Settle is how long Run waits for outstanding lines after the last one
+	// was sent; default 1s.
+	Settle time.Duration
+}
+
+// Result holds the measurements of a run.
+type Result struct {
+	Sent, Received int
+	Bytes          int // received, delimiters included
+	Elapsed        time.Duration
+
+	// Latencies are the sorted end-to-end latencies: from generating a line
+	// until the callback handling it returned.
+	Latencies []time.Duration
+	// Read is the port's own histogram: from the poll wakeup until the
+	// callback returned.
+	Read *serial.Histogram
+
+	Mallocs    uint64 // heap allocations during the run, whole process
+	AllocBytes uint64
+	GCCycles   uint32
+	GCPause    time.Duration
+}
+
+// Run sends lines through the port and passes each received line to onLine
+// (which may be nil) until the configured number of lines or duration is
+// reached, or ctx is done.
+func Run(ctx context.Context, o Options, onLine func(string)) (*Result, error) {
+	cfg := o.Config
+	if cfg.Delimiter == "" {
+		cfg.Delimiter = "\n"
+	}
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 115200
+	}
+	cfg.MeasureLatency = true
+	var send func(string) error
+	if o.Device == "" {
+		sim, err := serialtest.NewSimulator(cfg.Delimiter)
+		if err != nil {
+			return nil, err
+		}
+		defer sim.Close()
+		cfg.Device = sim.Path()
+		send = sim.Send
+	} else {
+		cfg.Device = o.Device
+	}
+	port, err := serial.Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer port.Close()
+	if send == nil {
+		send = func(line string) error { return port.WriteLine(line, cfg.Delimiter) }
+	}
+
+	r := &Result{}
+	var mu sync.Mutex
+	all := make(chan struct{})
+	var target int // lines sent, once known; 0 while sending
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		port.ReadLinesLoop(func(line string) {
+			if onLine != nil {
+				onLine(line)
+			}
+			sample, _, _ := strings.Cut(line, "|")
+			d, err := serialtest.Latency(sample)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				return
+			}
+			r.Latencies = append(r.Latencies, d)
+			r.Bytes += len(line) + len(cfg.Delimiter)
+			if target > 0 && len(r.Latencies) == target {
+				close(all)
+			}
+		}, func(error) {})
+	}()
+
+	g := serialtest.Generator{Rate: o.Rate, Jitter: o.Jitter, Count: o.Lines}
+	if o.Size > 0 {
+		g.Line = func(seq uint64, t time.Time) string {
+			s := serialtest.FormatSample(seq, t)
+			if pad := o.Size - len(s) - len(cfg.Delimiter); pad > 0 {
+				s += "|" + strings.Repeat("x", pad-1)
+			}
+			return s
+		}
+	}
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	if o.Rate > 0 {
+		r.Sent, err = g.Run(ctx, send)
+	} else {
+		r.Sent, err = flood(ctx, &g, send)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return nil, err
+	}
+
+	mu.Lock()
+	target = r.Sent
+	if len(r.Latencies) == target {
+		close(all)
+	}
+	mu.Unlock()
+	settle := o.Settle
+	if settle == 0 {
+		settle = time.Second
+	}
+	select {
+	case <-all:
+	case <-time.After(settle):
+	}
+	r.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	port.Close()
+	<-loopDone
+
+	r.Received = len(r.Latencies)
+	slices.Sort(r.Latencies)
+	r.Read = port.Stats().Latency
+	r.Mallocs = after.Mallocs - before.Mallocs
+	r.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	r.GCCycles = after.NumGC - before.NumGC
+	r.GCPause = time.Duration(after.PauseTotalNs - before.PauseTotalNs)
+	return r, nil
+}
+
+// flood sends lines back to back in the generator's format.
+func flood(ctx context.Context, g *serialtest.Generator, send func(string) error) (int, error) {
+	format := g.Line
+	if format == nil {
+		format = serialtest.FormatSample
+	}
+	for seq := 0; g.Count == 0 || seq < g.Count; seq++ {
+		if err := ctx.Err(); err != nil {
+			return seq, err
+		}
+		if err := send(format(uint64(seq), time.Now())); err != nil {
+			return seq, err
+		}
+	}
+	return g.Count, nil
+}
+
+// Percentile returns the end-to-end latency at or below which the fraction q
+// (0 to 1) of the received lines fell.
+func (r *Result) Percentile(q float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	return r.Latencies[int(q*float64(len(r.Latencies)-1))]
+}
+
+// Report writes a human-readable summary to w.
+func (r *Result) Report(w io.Writer) {
+	secs := r.Elapsed.Seconds()
+	fmt.Fprintf(w, "lines       sent %d, received %d, lost %d\n", r.Sent, r.Received, r.Sent-r.Received)
+	fmt.Fprintf(w, "throughput  %.1f lines/s, %.0f bytes/s\n", float64(r.Received)/secs, float64(r.Bytes)/secs)
+	if r.Received == 0 {
+		return
+	}
+	fmt.Fprintf(w, "latency     min %v  p50 %v  p90 %v  p99 %v  p99.9 %v  max %v\n",
+		r.Latencies[0], r.Percentile(0.50), r.Percentile(0.90), r.Percentile(0.99),
+		r.Percentile(0.999), r.Latencies[len(r.Latencies)-1])
+	if r.Read != nil {
+		fmt.Fprintf(w, "read        p50 %v  p99 %v  max %v (poll wakeup to callback return)\n",
+			r.Read.Percentile(0.50), r.Read.Percentile(0.99), r.Read.Max())
+	}
+	fmt.Fprintf(w, "allocs      %.1f per line, %.0f bytes per line\n",
+		float64(r.Mallocs)/float64(r.Received), float64(r.AllocBytes)/float64(r.Received))
+	fmt.Fprintf(w, "gc          %d cycles, %v total pause\n", r.GCCycles, r.GCPause)
+}
+
+// Benchmark runs b.N lines through onLine and reports the end-to-end latency
+// percentiles (p50-ns, p99-ns, max-ns) and lost lines as benchmark metrics.
+func Benchmark(b *testing.B, o Options, onLine func(string)) {
+	b.Helper()
+	o.Lines, o.Duration = b.N, 0
+	b.ReportAllocs()
+	b.ResetTimer()
+	r, err := Run(context.Background(), o, onLine)
+	b.StopTimer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportMetric(float64(r.Percentile(0.50)), "p50-ns")
+	b.ReportMetric(float64(r.Percentile(0.99)), "p99-ns")
+	if r.Received > 0 {
+		b.ReportMetric(float64(r.Latencies[len(r.Latencies)-1]), "max-ns")
+	}
+	b.ReportMetric(float64(r.Sent-r.Received), "lost")
+}
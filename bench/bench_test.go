@@ -0,0 +1,47 @@
+package bench
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int32
+	r, err := Run(context.Background(), Options{Rate: 1000, Lines: 200, Size: 48}, func(line string) {
+		require.Len(t, line, 47)
+		calls.Add(1)
+	})
+	require.NoError(t, err)
+	require.Equal(t, 200, r.Sent)
+	require.Equal(t, 200, r.Received)
+	require.EqualValues(t, 200, calls.Load())
+	require.Equal(t, 200*48, r.Bytes)
+	require.EqualValues(t, 200, r.Read.Count())
+	require.LessOrEqual(t, r.Percentile(0.5), r.Percentile(0.99))
+
+	var b strings.Builder
+	r.Report(&b)
+	require.Contains(t, b.String(), "lost 0\n")
+}
+
+func TestRunDuration(t *testing.T) {
+	r, err := Run(context.Background(), Options{Rate: 500, Duration: 100 * time.Millisecond, Settle: 100 * time.Millisecond}, nil)
+	require.NoError(t, err)
+	require.InDelta(t, 50, r.Sent, 10)
+	require.Equal(t, r.Sent, r.Received)
+}
+
+func BenchmarkLines(b *testing.B) {
+	Benchmark(b, Options{}, func(line string) { strings.Split(line, ",") })
+}
+
+// BenchmarkLongLines sends lines longer than one read, which the loop
+// accumulates over several chunks before finding the delimiter.
+func BenchmarkLongLines(b *testing.B) {
+	Benchmark(b, Options{Size: 16384}, nil)
+}
@@ -0,0 +1,77 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestVtime(t *testing.T) {
+	require.EqualValues(t, 1, vtime(time.Millisecond))
+	require.EqualValues(t, 1, vtime(100*time.Millisecond))
+	require.EqualValues(t, 2, vtime(101*time.Millisecond))
+	require.EqualValues(t, 255, vtime(time.Minute))
+}
+
+func TestSerialReader_BlockingRead(t *testing.T) {
+	reader, master := newTestReader(t, Config{BlockingRead: 100 * time.Millisecond})
+	tio, err := unix.IoctlGetTermios(reader.port().fd, ioctlGetTermios)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, tio.Cc[unix.VMIN])
+	require.EqualValues(t, 1, tio.Cc[unix.VTIME])
+
+	lines := make(chan string, 2)
+	done := make(chan struct{})
+	go func() {
+		reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+		close(done)
+	}()
+	_, err = master.Write([]byte("a\nb\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a", <-lines)
+	require.Equal(t, "b", <-lines)
+
+	// Close takes effect once the pending read times out.
+	start := time.Now()
+	require.NoError(t, reader.Close())
+	select {
+	case <-done:
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("loop did not exit after Close")
+	}
+}
+
+func TestSerialReader_BlockingReadHangup(t *testing.T) {
+	reader, master := newTestReader(t, Config{BlockingRead: 100 * time.Millisecond})
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	time.Sleep(20 * time.Millisecond)
+	master.Close()
+	select {
+	case err := <-errs:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("hangup not reported")
+	}
+}
+
+func TestSerialReader_ReadSettle(t *testing.T) {
+	reader, master := newTestReader(t, Config{ReadSettle: 50 * time.Millisecond})
+
+	// Three bursts within the settle time arrive in a single read.
+	go func() {
+		for _, b := range []string{"ab", "cd", "ef"} {
+			master.Write([]byte(b))
+			time.Sleep(2 * time.Millisecond)
+		}
+	}()
+	buf := make([]byte, 64)
+	n, err := reader.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "abcdef", string(buf[:n]))
+}
@@ -0,0 +1,65 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// adoptFD returns a port on a duplicate of the descriptor behind sc, which
+// is left open; op names the operation in errors.
+func adoptFD(sc syscall.Conn, op string, cfg Config) (*port, error) {
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: op, Err: err}
+	}
+	fd := -1
+	var dupErr error
+	if err := raw.Control(func(s uintptr) {
+		fd, dupErr = unix.FcntlInt(s, unix.F_DUPFD_CLOEXEC, 0)
+	}); err != nil {
+		dupErr = err
+	}
+	if dupErr != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: op, Err: dupErr}
+	}
+	// The engine polls before every read, so the fd is used in blocking mode.
+	syscall.SetNonblock(fd, false)
+	return newPort(fd, cfg.Device)
+}
+
+// bridgePort returns a port on one end of a new socketpair, of datagramPair
+// type if datagram is set and a stream otherwise, and the other end for the
+// goroutines that relay to it. That end is non-blocking, so the runtime
+// poller serves it and closing it interrupts a pending read; name names it.
+func bridgePort(datagram bool, name string, cfg Config) (*port, *os.File, error) {
+	typ := unix.SOCK_STREAM
+	if datagram {
+		typ = datagramPair
+	}
+	fds, err := socketpair(typ)
+	if err != nil {
+		return nil, nil, &SerialError{Device: cfg.Device, Op: "socketpair", Err: err}
+	}
+	unix.SetNonblock(fds[1], true)
+	local := os.NewFile(uintptr(fds[1]), name)
+	p, err := newPort(fds[0], cfg.Device)
+	if err != nil {
+		local.Close()
+		return nil, nil, err
+	}
+	return p, local, nil
+}
+
+// reusePort sets SO_REUSEPORT on the socket c, so Reopen can bind the
+// replacement while the old socket is still open.
+func reusePort(c syscall.RawConn) error {
+	var err error
+	c.Control(func(fd uintptr) {
+		err = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	return err
+}
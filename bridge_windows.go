@@ -0,0 +1,22 @@
+package serial
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Network ports relay through a socketpair to the poll engine, which the
+// Windows backend does not have.
+
+func bridgePort(datagram bool, name string, cfg Config) (*port, *os.File, error) {
+	return nil, nil, &SerialError{Device: cfg.Device, Op: "socketpair", Err: errors.ErrUnsupported}
+}
+
+func adoptFD(sc syscall.Conn, op string, cfg Config) (*port, error) {
+	return nil, &SerialError{Device: cfg.Device, Op: op, Err: errors.ErrUnsupported}
+}
+
+func reusePort(c syscall.RawConn) error {
+	return nil
+}
@@ -0,0 +1,95 @@
+package serial
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// BufferedWriter coalesces small writes to a SerialReader, for applications
+// that emit many tiny command fragments: buffered bytes go out in one write
+// syscall on Flush, when the buffer fills, or Delay after the first byte was
+// buffered, whichever comes first. Create one with NewBufferedWriter; it is
+// safe for concurrent use.
+//
+// As with bufio.Writer, a failed write is sticky: the error is returned by
+// every later Write and Flush, and the bytes involved are lost.
+type BufferedWriter struct {
+	s     *SerialReader
+	size  int
+	delay time.Duration
+
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer // pending delayed flush
+	err   error
+}
+
+var _ io.StringWriter = (*BufferedWriter)(nil)
+
+// NewBufferedWriter returns a BufferedWriter that flushes at size bytes
+// (default 4096) and delay after the first buffered byte; a zero delay
+// leaves everything to Flush and the size limit.
+func (s *SerialReader) NewBufferedWriter(size int, delay time.Duration) *BufferedWriter {
+	if size <= 0 {
+		size = 4096
+	}
+	return &BufferedWriter{s: s, size: size, delay: delay, buf: make([]byte, 0, size)}
+}
+
+// Write buffers b. It writes to the port itself only when the buffer
+// fills.
+func (w *BufferedWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := 0
+	for len(b) > 0 {
+		c := copy(w.buf[len(w.buf):w.size], b)
+		w.buf, b, n = w.buf[:len(w.buf)+c], b[c:], n+c
+		if len(w.buf) == w.size {
+			if err := w.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if len(w.buf) > 0 && w.delay > 0 && w.timer == nil {
+		w.timer = time.AfterFunc(w.delay, func() { w.Flush() })
+	}
+	return n, nil
+}
+
+// WriteString buffers str, like Write.
+func (w *BufferedWriter) WriteString(str string) (int, error) {
+	return w.Write([]byte(str))
+}
+
+// Flush writes the buffered bytes to the port.
+func (w *BufferedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Buffered returns the number of bytes waiting for a flush.
+func (w *BufferedWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.buf)
+}
+
+func (w *BufferedWriter) flush() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.err != nil || len(w.buf) == 0 {
+		return w.err
+	}
+	_, err := w.s.Write(w.buf)
+	w.buf = w.buf[:0]
+	w.err = err
+	return err
+}
@@ -0,0 +1,45 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	w := reader.NewBufferedWriter(8, 20*time.Millisecond)
+	read := func() string {
+		buf := make([]byte, 64)
+		n, err := master.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	// Fragments go out together on Flush, in one write.
+	for _, f := range []string{"A", "T", "+", "X"} {
+		_, err := w.WriteString(f)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, w.Buffered())
+	require.Zero(t, reader.Stats().BytesWritten)
+	require.NoError(t, w.Flush())
+	require.Equal(t, "AT+X", read())
+
+	// A full buffer is written at once, the rest after the delay.
+	_, err := w.WriteString("0123456789")
+	require.NoError(t, err)
+	require.Equal(t, "01234567", read())
+	require.Equal(t, 2, w.Buffered())
+	require.Equal(t, "89", read())
+	require.Zero(t, w.Buffered())
+
+	// Write errors are sticky.
+	reader.Close()
+	_, err = w.WriteString("x")
+	require.NoError(t, err)
+	require.ErrorIs(t, w.Flush(), ErrClosed)
+	_, err = w.WriteString("y")
+	require.ErrorIs(t, err, ErrClosed)
+}
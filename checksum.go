@@ -0,0 +1,152 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// ErrChecksum reports a line whose checksum is missing or does not match.
+// It is recoverable.
+var ErrChecksum = errors.New("checksum mismatch")
+
+// ChecksumFunc computes a checksum over data; only the low Width*4 bits
+// are used by LineChecksum.
+type ChecksumFunc func(data []byte) uint32
+
+// CRC8 computes CRC-8 (polynomial 0x07, initial value 0).
+func CRC8(data []byte) uint32 {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return uint32(crc)
+}
+
+// CRC16CCITT computes CRC-16/CCITT-FALSE (polynomial 0x1021, initial value 0xFFFF).
+func CRC16CCITT(data []byte) uint32 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return uint32(crc)
+}
+
+// CRC16Modbus computes CRC-16/MODBUS (reflected polynomial 0xA001, initial value 0xFFFF).
+func CRC16Modbus(data []byte) uint32 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return uint32(crc)
+}
+
+// CRC32 computes the IEEE CRC-32 used by Ethernet and zlib.
+func CRC32(data []byte) uint32 {
+	return crc32.ChecksumIEEE(data)
+}
+
+// XOR8 XORs all bytes together, as NMEA 0183 does.
+func XOR8(data []byte) uint32 {
+	var sum byte
+	for _, b := range data {
+		sum ^= b
+	}
+	return uint32(sum)
+}
+
+// LineChecksum describes how a checksum is carried in a text line: the
+// payload, Sep, then the checksum as Width hexadecimal digits, e.g.
+// "$GPGGA,...*4F". Skip is a prefix excluded from the sum (NMEA's "$").
+type LineChecksum struct {
+	Func  ChecksumFunc
+	Width int    // hex digits; 0 means 2
+	Sep   string // separates payload and checksum; may be empty
+	Skip  string
+}
+
+// NMEAChecksum is the NMEA 0183 scheme: XOR of the bytes between "$" and
+// "*", as two hex digits.
+var NMEAChecksum = &LineChecksum{Func: XOR8, Width: 2, Sep: "*", Skip: "$"}
+
+func (c *LineChecksum) width() int {
+	if c.Width > 0 {
+		return c.Width
+	}
+	return 2
+}
+
+func (c *LineChecksum) sum(payload string) uint32 {
+	v := c.Func([]byte(strings.TrimPrefix(payload, c.Skip)))
+	if w := c.width(); w < 8 {
+		v &= 1<<(4*w) - 1
+	}
+	return v
+}
+
+// Verify checks line and returns its payload, without separator and
+// checksum. Hex digits are accepted in either case.
+func (c *LineChecksum) Verify(line string) (string, error) {
+	w := c.width()
+	if len(line) < w+len(c.Sep) || !strings.HasSuffix(line[:len(line)-w], c.Sep) {
+		return "", fmt.Errorf("%w: no checksum in %q", ErrChecksum, line)
+	}
+	payload := line[:len(line)-w-len(c.Sep)]
+	got, err := strconv.ParseUint(line[len(line)-w:], 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed checksum in %q", ErrChecksum, line)
+	}
+	if want := c.sum(payload); uint32(got) != want {
+		return "", fmt.Errorf("%w: got %0*X, want %0*X", ErrChecksum, w, got, w, want)
+	}
+	return payload, nil
+}
+
+// Append returns payload with its separator and checksum appended, using
+// upper-case hex digits.
+func (c *LineChecksum) Append(payload string) string {
+	return fmt.Sprintf("%s%s%0*X", payload, c.Sep, c.width(), c.sum(payload))
+}
+
+// validateFunc wraps next so that, with Config.Checksum set, only verified
+// payloads reach it; other lines go to Config.OnBadLine and are counted.
+func (s *SerialReader) validateFunc(next func(string)) func(string) {
+	c := s.config.Checksum
+	if c == nil {
+		return next
+	}
+	return func(line string) {
+		payload, err := c.Verify(line)
+		if err != nil {
+			s.badLines.Add(1)
+			if s.config.OnBadLine != nil {
+				s.config.OnBadLine(line, err)
+			}
+			return
+		}
+		next(payload)
+	}
+}
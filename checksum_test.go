@@ -0,0 +1,73 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumFuncs(t *testing.T) {
+	check := []byte("123456789")
+	require.Equal(t, uint32(0xF4), CRC8(check))
+	require.Equal(t, uint32(0x29B1), CRC16CCITT(check))
+	require.Equal(t, uint32(0x4B37), CRC16Modbus(check))
+	require.Equal(t, uint32(0xCBF43926), CRC32(check))
+	require.Equal(t, uint32(0x31), XOR8(check))
+}
+
+func TestLineChecksum(t *testing.T) {
+	sentence := "$GPGLL,4916.45,N,12311.12,W,225444,A*31"
+	payload, err := NMEAChecksum.Verify(sentence)
+	require.NoError(t, err)
+	require.Equal(t, "$GPGLL,4916.45,N,12311.12,W,225444,A", payload)
+	require.Equal(t, sentence, NMEAChecksum.Append(payload))
+
+	_, err = NMEAChecksum.Verify("$GPGLL,4916.45,N,12311.12,W,225444,A*32")
+	require.ErrorIs(t, err, ErrChecksum)
+	_, err = NMEAChecksum.Verify("$GPGLL,4916.45")
+	require.ErrorIs(t, err, ErrChecksum)
+
+	crc := &LineChecksum{Func: CRC16CCITT, Width: 4, Sep: ","}
+	line := crc.Append("123456789")
+	require.Equal(t, "123456789,29B1", line)
+	payload, err = crc.Verify("123456789,29b1")
+	require.NoError(t, err)
+	require.Equal(t, "123456789", payload)
+}
+
+func TestSerialReader_Checksum(t *testing.T) {
+	var bad []string
+	reader, master := newTestReader(t, Config{
+		Checksum:  NMEAChecksum,
+		OnBadLine: func(line string, err error) { bad = append(bad, line) },
+	})
+	lines := make(chan string, 4)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+
+	good := NMEAChecksum.Append("$GPZDA,201530.00,04,07,2024,00,00")
+	_, err := master.Write([]byte("$GPZDA,201530.00,04,07,2024,00,00*00\n" + good + "\n"))
+	require.NoError(t, err)
+	select {
+	case l := <-lines:
+		require.Equal(t, "$GPZDA,201530.00,04,07,2024,00,00", l)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for line")
+	}
+	require.Equal(t, []string{"$GPZDA,201530.00,04,07,2024,00,00*00"}, bad)
+	st := reader.Stats()
+	require.EqualValues(t, 2, st.Lines)
+	require.EqualValues(t, 1, st.BadLines)
+	require.EqualValues(t, len(good)*2+2, st.BytesRead)
+}
+
+func TestSerialReader_WriteChecksum(t *testing.T) {
+	reader, master := newTestReader(t, Config{
+		WriteChecksum: &LineChecksum{Func: CRC16Modbus, Width: 4, Sep: ";"},
+	})
+	require.NoError(t, reader.WriteLine("123456789", "\r\n"))
+	buf := make([]byte, 64)
+	n, err := master.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "123456789;4B37\r\n", string(buf[:n]))
+}
@@ -0,0 +1,140 @@
+// Command serialcat is a small interactive terminal for serial ports, a
+// minicom replacement built on the serial package.
+//
+// Usage:
+//
+//	serialcat [flags] <device or URL>
+//
+// Received lines are printed to stdout; every line typed on stdin is sent to
+// the port. Lines starting with "~" are commands:
+//
+//	~dtr on|off   set the DTR line
+//	~rts on|off   set the RTS line
+//	~.            quit
+//
+// Start a line with "~~" to send a line beginning with "~".
+//
+// serialcat also exits on end of input, SIGINT and SIGTERM.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+func main() {
+	baud := flag.Int("baud", 115200, "baud rate")
+	dataBits := flag.Int("databits", 8, "data bits (5-8)")
+	parity := flag.String("parity", "none", "parity: none, odd, even, mark or space")
+	stopBits := flag.Int("stopbits", 1, "stop bits (1 or 2)")
+	delim := flag.String("delim", `\r\n`, "line delimiter of received data (Go escapes allowed)")
+	eol := flag.String("eol", `\r\n`, "line ending appended to sent lines (Go escapes allowed)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <device or URL>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := serial.Config{
+		Device:   flag.Arg(0),
+		BaudRate: *baud,
+		DataBits: *dataBits,
+		StopBits: *stopBits,
+	}
+	var err error
+	if cfg.Parity, err = serial.ParseParity(*parity); err != nil {
+		fatal(err)
+	}
+	if cfg.Delimiter, err = unescape(*delim); err != nil {
+		fatal(fmt.Errorf("-delim: %w", err))
+	}
+	newline, err := unescape(*eol)
+	if err != nil {
+		fatal(fmt.Errorf("-eol: %w", err))
+	}
+
+	port, err := serial.Open(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	defer port.Close()
+
+	quit := make(chan struct{})
+	go func() {
+		port.ReadLinesLoop(
+			func(line string) { fmt.Println(line) },
+			func(err error) { fmt.Fprintln(os.Stderr, "serialcat:", err) },
+		)
+		close(quit) // the port went away
+	}()
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		port.Close()
+	}()
+
+	input := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			input <- sc.Text()
+		}
+		close(input)
+	}()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case line, ok := <-input:
+			if !ok || line == "~." {
+				return
+			}
+			if err := handle(port, line, newline); err != nil {
+				fmt.Fprintln(os.Stderr, "serialcat:", err)
+			}
+		}
+	}
+}
+
+// handle sends line to the port or runs it as a ~ command.
+func handle(port *serial.SerialReader, line, newline string) error {
+	if !strings.HasPrefix(line, "~") || strings.HasPrefix(line, "~~") {
+		return port.WriteLine(strings.TrimPrefix(line, "~"), newline)
+	}
+	f := strings.Fields(line[1:])
+	if len(f) != 2 || (f[1] != "on" && f[1] != "off") {
+		return fmt.Errorf("unknown command %q (try ~dtr on|off, ~rts on|off, ~.)", line)
+	}
+	on := f[1] == "on"
+	switch f[0] {
+	case "dtr":
+		return port.SetDTR(on)
+	case "rts":
+		return port.SetRTS(on)
+	}
+	return fmt.Errorf("unknown command %q", line)
+}
+
+// unescape interprets Go escape sequences such as \r and \n in s.
+func unescape(s string) (string, error) {
+	return strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "serialcat:", err)
+	os.Exit(1)
+}
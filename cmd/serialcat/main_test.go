@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/creack/pty"
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandle(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+	port, err := serial.Open(serial.Config{Device: slave.Name(), BaudRate: 115200, Delimiter: "\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+
+	require.NoError(t, handle(port, "C,INFO", "\r\n"))
+	require.NoError(t, handle(port, "~~home", "\r\n"))
+	want := "C,INFO\r\n~home\r\n"
+	buf := make([]byte, len(want))
+	_, err = io.ReadFull(master, buf)
+	require.NoError(t, err)
+	require.Equal(t, want, string(buf))
+
+	require.Error(t, handle(port, "~dtr maybe", "\r\n"))
+	require.Error(t, handle(port, "~cts on", "\r\n"))
+}
+
+func TestUnescape(t *testing.T) {
+	s, err := unescape(`\r\n`)
+	require.NoError(t, err)
+	require.Equal(t, "\r\n", s)
+	s, err = unescape(`"x"`)
+	require.NoError(t, err)
+	require.Equal(t, `"x"`, s)
+}
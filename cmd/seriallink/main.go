@@ -0,0 +1,78 @@
+// Command seriallink qualifies a serial link, such as a long RS-485 or
+// RS-232 cable run, before installation by sending a known pseudo-random
+// pattern over it and checking it bit by bit.
+//
+// Usage:
+//
+//	seriallink [flags] /dev/ttyUSB0               # TX looped back to RX
+//	seriallink [flags] /dev/ttyUSB0 /dev/ttyUSB1  # both ends on this host
+//	seriallink -mode tx [flags] /dev/ttyUSB0      # paired instances on
+//	seriallink -mode rx [flags] /dev/ttyUSB0      # two hosts
+//
+// The report lists the bit error rate, lost and corrupt frames, throughput
+// and, with both ends on one host, latency percentiles. The measurements
+// come from the linktest package.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/linktest"
+)
+
+func main() {
+	var o linktest.Options
+	mode := flag.String("mode", "loop", "loop, tx or rx")
+	baud := flag.Int("baud", 115200, "baud rate")
+	parity := flag.String("parity", "none", "parity: none, odd, even, mark or space")
+	flag.Float64Var(&o.Rate, "rate", 0, "frames per second; 0 sends as fast as the link allows")
+	flag.DurationVar(&o.Duration, "duration", 10*time.Second, "how long to send, or to receive with -mode rx")
+	flag.IntVar(&o.Frames, "frames", 0, "number of frames; 0 sends until -duration")
+	flag.IntVar(&o.Size, "size", 64, "pattern bytes per frame")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] device [receiving device]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 || flag.NArg() > 2 || flag.NArg() == 2 && *mode != "loop" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	p, err := serial.ParseParity(*parity)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "seriallink:", err)
+		os.Exit(2)
+	}
+	o.Config = serial.Config{BaudRate: *baud, Parity: p}
+	o.Device, o.RxDevice = flag.Arg(0), flag.Arg(1)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var r *linktest.Result
+	switch *mode {
+	case "loop":
+		r, err = linktest.Run(ctx, o)
+	case "rx":
+		r, err = linktest.Receive(ctx, o)
+	case "tx":
+		var sent int
+		if sent, err = linktest.Transmit(ctx, o); err == nil {
+			fmt.Printf("frames      sent %d\n", sent)
+			return
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "seriallink:", err)
+		os.Exit(1)
+	}
+	r.Report(os.Stdout)
+}
@@ -0,0 +1,68 @@
+// Command seriallist prints the serial ports present on the system with their
+// driver, USB identity, stable /dev/serial/by-id path and lock status, so
+// operators can find the right device quickly.
+//
+// Usage:
+//
+//	seriallist [-json]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print JSON instead of a table")
+	flag.Parse()
+
+	ports, err := serial.ListPorts()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "seriallist:", err)
+		os.Exit(1)
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(ports)
+		return
+	}
+	printTable(os.Stdout, ports)
+}
+
+// printTable writes one row per port; missing values are shown as "-".
+func printTable(w io.Writer, ports []serial.PortInfo) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DEVICE\tDRIVER\tUSB ID\tSERIAL\tPRODUCT\tLOCK\tBY-ID")
+	for _, p := range ports {
+		usb := ""
+		if p.VID != "" {
+			usb = p.VID + ":" + p.PID
+		}
+		product := p.Product
+		if p.Manufacturer != "" {
+			product = p.Manufacturer + " " + product
+		}
+		lock := ""
+		if p.LockPID != 0 {
+			lock = "pid " + strconv.Itoa(p.LockPID)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.Device, dash(p.Driver), dash(usb), dash(p.SerialNumber), dash(product), dash(lock), dash(p.ByID))
+	}
+	tw.Flush()
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
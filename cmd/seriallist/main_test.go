@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintTable(t *testing.T) {
+	var b strings.Builder
+	printTable(&b, []serial.PortInfo{
+		{Device: "/dev/ttyS1", Driver: "serial8250"},
+		{Device: "/dev/ttyUSB0", Driver: "ftdi_sio", VID: "0403", PID: "6001", SerialNumber: "A50285BI",
+			Manufacturer: "FTDI", Product: "FT232R", LockPID: 42, ByID: "/dev/serial/by-id/usb-FTDI"},
+	})
+	require.Equal(t, ""+
+		"DEVICE        DRIVER      USB ID     SERIAL    PRODUCT      LOCK    BY-ID\n"+
+		"/dev/ttyS1    serial8250  -          -         -            -       -\n"+
+		"/dev/ttyUSB0  ftdi_sio    0403:6001  A50285BI  FTDI FT232R  pid 42  /dev/serial/by-id/usb-FTDI\n",
+		b.String())
+}
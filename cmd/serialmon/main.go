@@ -0,0 +1,160 @@
+// Command serialmon monitors a serial port read-only for field diagnostics.
+//
+// Usage:
+//
+//	serialmon [flags] <device or URL>
+//
+// By default every received line is printed with a timestamp; -hex prints a
+// timestamped hexdump of each chunk as it arrives instead. -capture saves the
+// received bytes, unmodified, to a file for later analysis.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+const timeFormat = "15:04:05.000000"
+
+func main() {
+	baud := flag.Int("baud", 115200, "baud rate")
+	dataBits := flag.Int("databits", 8, "data bits (5-8)")
+	parity := flag.String("parity", "none", "parity: none, odd, even, mark or space")
+	stopBits := flag.Int("stopbits", 1, "stop bits (1 or 2)")
+	delim := flag.String("delim", `\r\n`, "line delimiter (Go escapes allowed)")
+	hex := flag.Bool("hex", false, "print a hexdump of the raw bytes instead of lines")
+	capture := flag.String("capture", "", "also write the received bytes to this file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <device or URL>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := serial.Config{
+		Device:   flag.Arg(0),
+		BaudRate: *baud,
+		DataBits: *dataBits,
+		StopBits: *stopBits,
+		Access:   serial.ReadOnly,
+	}
+	var err error
+	if cfg.Parity, err = serial.ParseParity(*parity); err != nil {
+		fatal(err)
+	}
+	if cfg.Delimiter, err = strconv.Unquote(`"` + strings.ReplaceAll(*delim, `"`, `\"`) + `"`); err != nil {
+		fatal(fmt.Errorf("-delim: %w", err))
+	}
+
+	var sink io.Writer = io.Discard
+	if *capture != "" {
+		f, err := os.OpenFile(*capture, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		sink = f
+	}
+
+	port, err := serial.Open(cfg)
+	if err != nil {
+		fatal(err)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		port.Close()
+	}()
+
+	if err := monitor(port, cfg.Delimiter, os.Stdout, sink, *hex); err != nil {
+		fmt.Fprintln(os.Stderr, "serialmon:", err)
+	}
+}
+
+// monitor reads chunks from port until it is closed, writing them to capture
+// and printing them to out as lines split at delim or, with hex, as a
+// hexdump.
+func monitor(port *serial.SerialReader, delim string, out, capture io.Writer, hex bool) error {
+	var partial []byte // line view: bytes after the last delimiter
+	buf := make([]byte, 4096)
+	for {
+		n, err := port.Read(buf)
+		if err != nil {
+			if errors.Is(err, serial.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		chunk := buf[:n]
+		if _, err := capture.Write(chunk); err != nil {
+			return err
+		}
+		now := time.Now().Format(timeFormat)
+		if hex {
+			fmt.Fprint(out, hexdump(now, chunk))
+			continue
+		}
+		partial = append(partial, chunk...)
+		for {
+			i := bytes.Index(partial, []byte(delim))
+			if i < 0 {
+				break
+			}
+			fmt.Fprintf(out, "%s  %q\n", now, partial[:i])
+			partial = partial[i+len(delim):]
+		}
+	}
+}
+
+// hexdump formats chunk as rows of 16 bytes, the first stamped with ts.
+func hexdump(ts string, chunk []byte) string {
+	var b strings.Builder
+	for off := 0; off < len(chunk); off += 16 {
+		row := chunk[off:min(off+16, len(chunk))]
+		if off == 0 {
+			b.WriteString(ts)
+		} else {
+			b.WriteString(strings.Repeat(" ", len(ts)))
+		}
+		fmt.Fprintf(&b, "  %04x ", off)
+		for i := 0; i < 16; i++ {
+			if i == 8 {
+				b.WriteByte(' ')
+			}
+			if i < len(row) {
+				fmt.Fprintf(&b, " %02x", row[i])
+			} else {
+				b.WriteString("   ")
+			}
+		}
+		b.WriteString("  |")
+		for _, c := range row {
+			if c < 0x20 || c > 0x7e {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		b.WriteString("|\n")
+	}
+	return b.String()
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "serialmon:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexdump(t *testing.T) {
+	got := hexdump("12:00:00.000000", []byte("Hello, serial port\r\n"))
+	require.Equal(t,
+		"12:00:00.000000  0000  48 65 6c 6c 6f 2c 20 73  65 72 69 61 6c 20 70 6f  |Hello, serial po|\n"+
+			"                 0010  72 74 0d 0a                                       |rt..|\n", got)
+}
+
+func TestMonitor(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+	port, err := serial.Open(serial.Config{Device: slave.Name(), BaudRate: 115200, Access: serial.ReadOnly})
+	require.NoError(t, err)
+
+	var out, capture bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- monitor(port, "\r\n", &out, &capture, false) }()
+	_, err = master.Write([]byte("T=21.5\r\nP=10"))
+	require.NoError(t, err)
+	_, err = master.Write([]byte("13\r\n"))
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	port.Close()
+	require.NoError(t, <-done)
+
+	require.Equal(t, "T=21.5\r\nP=1013\r\n", capture.String())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasSuffix(lines[0], `  "T=21.5"`), lines[0])
+	require.True(t, strings.HasSuffix(lines[1], `  "P=1013"`), lines[1])
+}
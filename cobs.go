@@ -0,0 +1,60 @@
+package serial
+
+// COBS is a Framer for Consistent Overhead Byte Stuffing: frames end with a
+// zero byte and the encoded payload contains none, at a cost of one byte per
+// 254. Common on microcontroller links. Empty frames are skipped; a frame
+// whose code bytes run past its end is reported as ErrBadFrame.
+type COBS struct{}
+
+// Frame implements Framer.
+func (COBS) Frame(data []byte) (int, []byte, error) {
+	end := -1
+	for i, b := range data {
+		if b == 0 {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, nil, nil
+	}
+	if end == 0 {
+		return 1, nil, nil
+	}
+	frame := make([]byte, 0, end)
+	for i := 0; i < end; {
+		code := int(data[i])
+		if i+code > end {
+			return end + 1, nil, ErrBadFrame
+		}
+		frame = append(frame, data[i+1:i+code]...)
+		i += code
+		if code < 0xff && i < end {
+			frame = append(frame, 0)
+		}
+	}
+	return end + 1, frame, nil
+}
+
+// Encode returns payload as a COBS frame, terminating zero included.
+func (COBS) Encode(payload []byte) []byte {
+	out := make([]byte, 1, len(payload)+len(payload)/254+2)
+	code := 0     // index of the current code byte
+	full := false // the previous block was 254 data bytes, with no zero after it
+	for _, b := range payload {
+		if b != 0 {
+			out = append(out, b)
+		}
+		if b == 0 || len(out)-code == 0xff {
+			out[code] = byte(len(out) - code)
+			full = b != 0
+			code = len(out)
+			out = append(out, 0)
+		}
+	}
+	if full && len(out)-code == 1 {
+		return append(out[:code], 0) // a final full block needs no empty one after it
+	}
+	out[code] = byte(len(out) - code)
+	return append(out, 0)
+}
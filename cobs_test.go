@@ -0,0 +1,35 @@
+package serial
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOBS_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		payload, enc []byte
+	}{
+		{[]byte{0x00}, []byte{0x01, 0x01, 0x00}},
+		{[]byte{0x11, 0x22, 0x00, 0x33}, []byte{0x03, 0x11, 0x22, 0x02, 0x33, 0x00}},
+		{[]byte{0x11, 0x00, 0x00}, []byte{0x02, 0x11, 0x01, 0x01, 0x00}},
+		{bytes.Repeat([]byte{0x01}, 254), append(append([]byte{0xff}, bytes.Repeat([]byte{0x01}, 254)...), 0x00)},
+		{bytes.Repeat([]byte{0x01}, 255), append(append([]byte{0xff}, bytes.Repeat([]byte{0x01}, 254)...), 0x02, 0x01, 0x00)},
+	} {
+		enc := COBS{}.Encode(tc.payload)
+		require.Equal(t, tc.enc, enc)
+		advance, frame, err := COBS{}.Frame(enc)
+		require.NoError(t, err)
+		require.Equal(t, len(enc), advance)
+		require.Equal(t, tc.payload, frame)
+	}
+
+	advance, frame, err := COBS{}.Frame([]byte{0x05, 0x11, 0x00})
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.Equal(t, 3, advance)
+	require.Nil(t, frame)
+
+	advance, _, _ = COBS{}.Frame([]byte{0x00, 0x01})
+	require.Equal(t, 1, advance)
+}
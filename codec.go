@@ -0,0 +1,100 @@
+package serial
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	framersMu sync.RWMutex
+	framers   = map[string]func(cfg Config) Framer{
+		"lines":  func(cfg Config) Framer { return lineFramer{cfg.lineEnd(), cfg.delimiter(), nil} },
+		"nmea":   func(cfg Config) Framer { return lineFramer{cfg.lineEnd(), cfg.delimiter(), NMEAChecksum} },
+		"slip":   func(Config) Framer { return SLIP{} },
+		"hdlc":   func(Config) Framer { return HDLC{} },
+		"dle":    func(Config) Framer { return DLE{} },
+		"cobs":   func(Config) Framer { return COBS{} },
+		"hex":    func(cfg Config) Framer { return Dearmor(cfg, ArmorHex, "", nil) },
+		"base64": func(cfg Config) Framer { return Dearmor(cfg, ArmorBase64, "", nil) },
+	}
+)
+
+// RegisterFramer makes a framer available by name, for Config.Framer, the
+// framer parameter of endpoint URLs and NewFramer, so generic acquisition
+// daemons can pick the protocol from a configuration file. newFramer is
+// called with the port's Config for every ReadFramesLoop. Packages
+// implementing protocols register them in an init function; the modbus
+// package registers "modbus-rtu". Built in are "lines" (Config.Delimiter or
+// Terminator), "nmea" (lines with a verified NMEA checksum, which is
+// stripped), "slip", "hdlc", "dle", "cobs", and "hex" and "base64" (lines
+// decoded by Dearmor).
+//
+// RegisterFramer panics if name is empty or already registered.
+func RegisterFramer(name string, newFramer func(cfg Config) Framer) {
+	framersMu.Lock()
+	defer framersMu.Unlock()
+	if name == "" || newFramer == nil {
+		panic("serial: RegisterFramer with empty name or nil function")
+	}
+	if _, dup := framers[name]; dup {
+		panic("serial: RegisterFramer called twice for " + name)
+	}
+	framers[name] = newFramer
+}
+
+// NewFramer returns the framer registered as name, set up for cfg.
+func NewFramer(name string, cfg Config) (Framer, error) {
+	framersMu.RLock()
+	newFramer, ok := framers[name]
+	framersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown framer %q", name)
+	}
+	return newFramer(cfg), nil
+}
+
+// Framers returns the names of the registered framers, sorted.
+func Framers() []string {
+	framersMu.RLock()
+	defer framersMu.RUnlock()
+	names := make([]string, 0, len(framers))
+	for name := range framers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// lineFramer frames lines like ReadLinesLoop, optionally verifying a
+// checksum.
+type lineFramer struct {
+	lineEnd  func(b []byte, from int) (end, next int)
+	delim    string
+	checksum *LineChecksum
+}
+
+// Frame implements Framer.
+func (f lineFramer) Frame(data []byte) (int, []byte, error) {
+	end, next := f.lineEnd(data, 0)
+	if end < 0 {
+		return 0, nil, nil
+	}
+	if f.checksum == nil {
+		return next, data[:end], nil
+	}
+	payload, err := f.checksum.Verify(string(data[:end]))
+	if err != nil {
+		return next, nil, fmt.Errorf("%w: %w", ErrBadFrame, err)
+	}
+	return next, []byte(payload), nil
+}
+
+// Encode implements Encoder: it appends the checksum, if any, and the
+// delimiter, "\r\n" when the config only has a Terminator.
+func (f lineFramer) Encode(payload []byte) []byte {
+	if f.checksum != nil {
+		return []byte(f.checksum.Append(string(payload)) + f.delim)
+	}
+	return append(payload[:len(payload):len(payload)], f.delim...)
+}
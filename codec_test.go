@@ -0,0 +1,79 @@
+package serial
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramerRegistry(t *testing.T) {
+	require.Subset(t, Framers(), []string{"cobs", "dle", "hdlc", "lines", "nmea", "slip"})
+	_, err := NewFramer("nope", Config{})
+	require.Error(t, err)
+	require.Panics(t, func() { RegisterFramer("slip", func(Config) Framer { return SLIP{} }) })
+
+	RegisterFramer("test-fixed2", func(Config) Framer {
+		return FramerFunc(func(data []byte) (int, []byte, error) {
+			if len(data) < 2 {
+				return 0, nil, nil
+			}
+			return 2, data[:2], nil
+		})
+	})
+	require.Contains(t, Framers(), "test-fixed2")
+	require.NoError(t, Config{Device: "/dev/ttyS0", Framer: "test-fixed2"}.Validate())
+	require.ErrorIs(t, Config{Device: "/dev/ttyS0", Framer: "nope"}.Validate(), ErrInvalidConfig)
+
+	cfg, err := ParseConfig("/dev/ttyS0?framer=nmea")
+	require.NoError(t, err)
+	require.Equal(t, "nmea", cfg.Framer)
+	require.Equal(t, `/dev/ttyS0 115200 8N1 delim="\r\n" framer=nmea`, cfg.String())
+}
+
+func TestNMEAFramer(t *testing.T) {
+	f, err := NewFramer("nmea", Config{})
+	require.NoError(t, err)
+	advance, frame, err := f.Frame([]byte("$PUBX,00*33\r\n$GP"))
+	require.NoError(t, err)
+	require.Equal(t, 13, advance)
+	require.Equal(t, "$PUBX,00", string(frame))
+
+	advance, frame, err = f.Frame([]byte("$PUBX,00*34\r\n"))
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.ErrorIs(t, err, ErrChecksum)
+	require.Equal(t, 13, advance)
+	require.Nil(t, frame)
+}
+
+func TestSerialReader_ConfigFramer(t *testing.T) {
+	reader, master := newTestReader(t, Config{Framer: "cobs"})
+	frames := make(chan []byte, 1)
+	go reader.ReadFramesLoop(nil, func(f []byte) { frames <- append([]byte(nil), f...) }, func(error) {})
+	_, err := master.Write(COBS{}.Encode([]byte{1, 0, 2}))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 0, 2}, <-frames)
+}
+
+func TestReadFramesLoop_ErrorWithoutProgress(t *testing.T) {
+	reader, master := newTestReader(t, Config{ContinueOnError: true})
+	// The framer complains about a short frame without consuming it.
+	f := FramerFunc(func(data []byte) (int, []byte, error) {
+		if len(data) < 4 {
+			return 0, nil, ErrBadFrame
+		}
+		return 4, data[:4], nil
+	})
+	var errs atomic.Int32
+	frames := make(chan string, 1)
+	go reader.ReadFramesLoop(f, func(b []byte) { frames <- string(b) }, func(error) { errs.Add(1) })
+
+	_, err := master.Write([]byte("ab"))
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	require.LessOrEqual(t, errs.Load(), int32(2)) // once per read, not in a spin
+	_, err = master.Write([]byte("cd"))
+	require.NoError(t, err)
+	require.Equal(t, "abcd", <-frames)
+}
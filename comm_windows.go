@@ -0,0 +1,135 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// Queue selectors for queueLen, named after the ioctls of the termios
+	// backends.
+	ioctlInQueue  = 0
+	ioctlOutQueue = 1
+
+	// cmspar is nonzero where mark/space parity is available; the DCB has
+	// it natively.
+	cmspar = 1
+
+	maxDWORD = 0xffffffff
+)
+
+// DCB flag bits (the C bit fields of struct _DCB).
+const (
+	dcbBinary       = 0x0001
+	dcbParity       = 0x0002
+	dcbOutxCtsFlow  = 0x0004
+	dcbOutxDsrFlow  = 0x0008
+	dcbDtrControl   = 0x0030
+	dcbDsrSensitive = 0x0040
+	dcbOutX         = 0x0100
+	dcbInX          = 0x0200
+	dcbErrorChar    = 0x0400
+	dcbNull         = 0x0800
+	dcbRtsControl   = 0x3000
+	dcbAbortOnError = 0x4000
+)
+
+// setLineSettings applies the speed, the character format (data bits,
+// parity, stop bits) and the flow control from cfg to d.
+func setLineSettings(d *windows.DCB, cfg Config) error {
+	d.BaudRate = 115200
+	if cfg.BaudRate != 0 {
+		d.BaudRate = uint32(cfg.BaudRate)
+	}
+	switch cfg.DataBits {
+	case 5, 6, 7, 8:
+		d.ByteSize = uint8(cfg.DataBits)
+	case 0:
+		d.ByteSize = 8
+	default:
+		return fmt.Errorf("invalid data bits %d", cfg.DataBits)
+	}
+	switch cfg.Parity {
+	case ParityNone:
+		d.Parity = windows.NOPARITY
+	case ParityOdd:
+		d.Parity = windows.ODDPARITY
+	case ParityEven:
+		d.Parity = windows.EVENPARITY
+	case ParityMark:
+		d.Parity = windows.MARKPARITY
+	case ParitySpace:
+		d.Parity = windows.SPACEPARITY
+	default:
+		return fmt.Errorf("invalid parity %d", cfg.Parity)
+	}
+	switch cfg.StopBits {
+	case 0, 1:
+		d.StopBits = windows.ONESTOPBIT
+	case 2:
+		d.StopBits = windows.TWOSTOPBITS
+	default:
+		return fmt.Errorf("invalid stop bits %d", cfg.StopBits)
+	}
+	// Raw binary transfer: no substitutions, no aborts on line errors.
+	d.Flags |= dcbBinary
+	d.Flags &^= dcbParity | dcbOutxCtsFlow | dcbOutxDsrFlow | dcbDsrSensitive |
+		dcbOutX | dcbInX | dcbErrorChar | dcbNull | dcbAbortOnError
+	if cfg.Parity != ParityNone {
+		d.Flags |= dcbParity
+	}
+	if d.Flags&dcbRtsControl == windows.RTS_CONTROL_HANDSHAKE {
+		d.Flags = d.Flags&^dcbRtsControl | windows.RTS_CONTROL_ENABLE
+	}
+	if cfg.RTSCTS {
+		d.Flags |= dcbOutxCtsFlow
+		d.Flags = d.Flags&^dcbRtsControl | windows.RTS_CONTROL_HANDSHAKE
+	}
+	if cfg.XONXOFF {
+		d.Flags |= dcbOutX | dcbInX
+		d.XonChar, d.XoffChar = 0x11, 0x13
+	}
+	return nil
+}
+
+// setInitialLines applies cfg.InitialDTR and cfg.InitialRTS (or the RS-485
+// receive state) to d, so both lines change with the rest of the DCB.
+func setInitialLines(d *windows.DCB, cfg Config) {
+	rts := cfg.InitialRTS
+	if cfg.RS485 != nil {
+		rts = cfg.RS485.rtsState(false) // start out receiving
+	}
+	switch cfg.InitialDTR {
+	case LineOn:
+		d.Flags = d.Flags&^dcbDtrControl | windows.DTR_CONTROL_ENABLE
+	case LineOff:
+		d.Flags = d.Flags&^dcbDtrControl | windows.DTR_CONTROL_DISABLE
+	}
+	if cfg.RTSCTS {
+		return // RTS belongs to the flow control
+	}
+	switch rts {
+	case LineOn:
+		d.Flags = d.Flags&^dcbRtsControl | windows.RTS_CONTROL_ENABLE
+	case LineOff:
+		d.Flags = d.Flags&^dcbRtsControl | windows.RTS_CONTROL_DISABLE
+	}
+}
+
+// drainOutput waits until everything written has been transmitted.
+func drainOutput(h windows.Handle) error {
+	return windows.FlushFileBuffers(h)
+}
+
+// flushInput discards data received but not yet read.
+func flushInput(h windows.Handle) error {
+	return windows.PurgeComm(h, windows.PURGE_RXCLEAR)
+}
+
+// readOverruns fails: Windows reports overruns only as a flag that
+// ClearCommError resets, not as counters.
+func readOverruns(h windows.Handle) (overrun, bufOverrun uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
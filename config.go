@@ -0,0 +1,497 @@
+package serial
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBlockingRead is the longest VTIME, 255 tenths of a second.
+const maxBlockingRead = 25500 * time.Millisecond
+
+// ConfigError describes one invalid Config field. It matches
+// ErrInvalidConfig with errors.Is.
+type ConfigError struct {
+	Field  string // Config field name, e.g. "BaudRate"
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return "invalid config: " + e.Field + ": " + e.Reason
+}
+
+func (e *ConfigError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Validate checks c for settings that cannot work or contradict each other
+// and returns one ConfigError per problem, joined with errors.Join, or nil.
+// Open calls it, so most applications only need it to check a configuration
+// before the device is present.
+//
+// Zero values keep their documented defaults and are always valid. Baud
+// rates are only checked for local devices; network endpoints pass them on
+// or ignore them.
+func (c Config) Validate() error {
+	var errs []error
+	bad := func(field, format string, args ...any) {
+		errs = append(errs, &ConfigError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+	local := !strings.Contains(c.Device, "://")
+
+	if c.Device == "" {
+		bad("Device", "empty")
+	}
+	if _, ok := baudRates[c.BaudRate]; c.BaudRate < 0 || local && c.BaudRate != 0 && !ok {
+		rates := make([]int, 0, len(baudRates))
+		for r := range baudRates {
+			rates = append(rates, r)
+		}
+		slices.Sort(rates)
+		bad("BaudRate", "%d not supported (supported: %v)", c.BaudRate, rates)
+	}
+	if c.DataBits != 0 && (c.DataBits < 5 || c.DataBits > 8) {
+		bad("DataBits", "%d not in 5-8", c.DataBits)
+	}
+	switch {
+	case c.Parity < ParityNone || c.Parity > ParitySpace:
+		bad("Parity", "unknown parity %d", c.Parity)
+	case local && cmspar == 0 && (c.Parity == ParityMark || c.Parity == ParitySpace):
+		bad("Parity", "mark/space parity not supported on this system")
+	}
+	if c.StopBits != 0 && c.StopBits != 1 && c.StopBits != 2 {
+		bad("StopBits", "%d not 1 or 2", c.StopBits)
+	}
+	if c.RTSCTS && c.XONXOFF {
+		bad("XONXOFF", "conflicts with RTSCTS; choose one flow control")
+	}
+	for _, l := range []struct {
+		field string
+		v     LineState
+	}{{"InitialDTR", c.InitialDTR}, {"InitialRTS", c.InitialRTS}} {
+		if l.v < LineDefault || l.v > LineOff {
+			bad(l.field, "unknown line state %d", l.v)
+		}
+	}
+	if c.HoldModemLines && (c.InitialDTR != LineDefault || c.InitialRTS != LineDefault) {
+		bad("HoldModemLines", "conflicts with InitialDTR and InitialRTS, which change the lines")
+	}
+	if r := c.RS485; r != nil {
+		switch {
+		case c.RTSCTS:
+			bad("RS485", "conflicts with RTSCTS; both drive RTS")
+		case c.InitialRTS != LineDefault:
+			bad("RS485", "conflicts with InitialRTS; RS485 keeps RTS in the receive state")
+		case c.HoldModemLines:
+			bad("RS485", "conflicts with HoldModemLines; RS485 toggles RTS")
+		}
+		if r.DelayBeforeSend < 0 || r.DelayAfterSend < 0 {
+			bad("RS485", "negative delay")
+		}
+	}
+	if h := c.Health; h != nil && (h.Timeout < 0 || h.MaxIdle < 0) {
+		bad("Health", "negative duration")
+	}
+	if c.RTSCTS && c.InitialRTS != LineDefault {
+		bad("InitialRTS", "RTS is driven by the kernel with RTSCTS")
+	}
+	if c.Access < ReadWrite || c.Access > WriteOnly {
+		bad("Access", "unknown access mode %d", c.Access)
+	}
+	for _, d := range []struct {
+		field string
+		v     time.Duration
+	}{
+		{"ReadTimeout", c.ReadTimeout},
+		{"DrainTimeout", c.DrainTimeout},
+		{"BlockingRead", c.BlockingRead},
+		{"ReadSettle", c.ReadSettle},
+		{"BacklogInterval", c.BacklogInterval},
+	} {
+		if d.v < 0 {
+			bad(d.field, "negative duration %v", d.v)
+		}
+	}
+	if c.BlockingRead > maxBlockingRead {
+		bad("BlockingRead", "%v exceeds the VTIME limit of %v", c.BlockingRead, maxBlockingRead)
+	}
+	if c.MaxLineLength < 0 {
+		bad("MaxLineLength", "negative")
+	}
+	if c.RingSize < 0 {
+		bad("RingSize", "negative")
+	}
+	if c.ReadChunkSize < 0 {
+		bad("ReadChunkSize", "negative")
+	}
+	if c.SampleRate < 0 || math.IsNaN(c.SampleRate) || math.IsInf(c.SampleRate, 0) {
+		bad("SampleRate", "%v is not a rate", c.SampleRate)
+	}
+	if c.RealtimePriority < 0 || c.RealtimePriority > 99 {
+		bad("RealtimePriority", "%d not in 1-99", c.RealtimePriority)
+	}
+	if c.OnBadChar != nil && !c.MarkErrors {
+		bad("OnBadChar", "set without MarkErrors, so it is never called")
+	}
+	if w := c.Workers; w != nil && (w.Workers < 0 || w.Queue < 0) {
+		bad("Workers", "negative worker or queue count")
+	}
+	if a := c.Async; a != nil && a.Queue < 0 {
+		bad("Async", "negative queue length")
+	}
+	if c.Terminator != nil && c.Terminator.Match(nil) {
+		bad("Terminator", "matches the empty string")
+	}
+	if c.Framer != "" {
+		framersMu.RLock()
+		_, ok := framers[c.Framer]
+		framersMu.RUnlock()
+		if !ok {
+			bad("Framer", "%q is not registered; is its package imported?", c.Framer)
+		}
+	}
+	if c.DetectDelimiter && (c.Delimiter != "" || c.Terminator != nil) {
+		bad("DetectDelimiter", "set with a Delimiter or Terminator")
+	}
+	if c.OnDelimiterDetected != nil && !c.DetectDelimiter {
+		bad("OnDelimiterDetected", "set without DetectDelimiter, so it is never called")
+	}
+	if c.OnBreak != nil && !c.DetectBreaks {
+		bad("OnBreak", "set without DetectBreaks, so it is never called")
+	}
+	if c.OnBadLine != nil && c.Checksum == nil {
+		bad("OnBadLine", "set without Checksum, so it is never called")
+	}
+	return errors.Join(errs...)
+}
+
+// delimiter returns the line delimiter, applying the "\r\n" default.
+func (c *Config) delimiter() string {
+	if c.Delimiter == "" {
+		return "\r\n"
+	}
+	return c.Delimiter
+}
+
+// readChunkSize returns the read buffer size, applying the 4096 default.
+func (c *Config) readChunkSize() int {
+	if c.ReadChunkSize == 0 {
+		return 4096
+	}
+	return c.ReadChunkSize
+}
+
+// lineEnd returns a function that finds the first line end in b: where the
+// line stops and where the next one starts, or -1, -1 if there is none yet.
+// b[:from] is known to hold no line end, so a fixed delimiter is only looked
+// for where it could overlap the bytes after it; a Terminator can match
+// differently as b grows and always scans all of b.
+func (c *Config) lineEnd() func(b []byte, from int) (end, next int) {
+	if c.DetectDelimiter {
+		return detectingLineEnd(c.OnDelimiterDetected)
+	}
+	if re := c.Terminator; re != nil {
+		return func(b []byte, _ int) (int, int) {
+			loc := re.FindIndex(b)
+			if loc == nil {
+				return -1, -1
+			}
+			return loc[0], loc[1]
+		}
+	}
+	return delimiterEnd(c.delimiter())
+}
+
+// delimiterEnd is lineEnd for the fixed delimiter delim.
+func delimiterEnd(s string) func(b []byte, from int) (end, next int) {
+	delim := []byte(s)
+	return func(b []byte, from int) (int, int) {
+		from = max(from-len(delim)+1, 0)
+		i := bytes.Index(b[from:], delim)
+		if i < 0 {
+			return -1, -1
+		}
+		return from + i, from + i + len(delim)
+	}
+}
+
+// configFile is the configuration-file form of Config: durations are strings
+// such as "500ms", parity and access mode are names and the delimiter is
+// Go-escaped. Middleware, checksums and callbacks have no file form.
+type configFile struct {
+	Device           string      `json:"device" yaml:"device"`
+	BaudRate         int         `json:"baud_rate,omitempty" yaml:"baud_rate,omitempty"`
+	DataBits         int         `json:"data_bits,omitempty" yaml:"data_bits,omitempty"`
+	Parity           Parity      `json:"parity,omitempty" yaml:"parity,omitempty"`
+	StopBits         int         `json:"stop_bits,omitempty" yaml:"stop_bits,omitempty"`
+	Access           AccessMode  `json:"access,omitempty" yaml:"access,omitempty"`
+	RTSCTS           bool        `json:"rtscts,omitempty" yaml:"rtscts,omitempty"`
+	XONXOFF          bool        `json:"xonxoff,omitempty" yaml:"xonxoff,omitempty"`
+	InitialDTR       LineState   `json:"initial_dtr,omitempty" yaml:"initial_dtr,omitempty"`
+	InitialRTS       LineState   `json:"initial_rts,omitempty" yaml:"initial_rts,omitempty"`
+	HoldModemLines   bool        `json:"hold_modem_lines,omitempty" yaml:"hold_modem_lines,omitempty"`
+	RS485            *rs485File  `json:"rs485,omitempty" yaml:"rs485,omitempty"`
+	Delimiter        string      `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	Terminator       string      `json:"terminator,omitempty" yaml:"terminator,omitempty"`
+	DetectDelimiter  bool        `json:"detect_delimiter,omitempty" yaml:"detect_delimiter,omitempty"`
+	Framer           string      `json:"framer,omitempty" yaml:"framer,omitempty"`
+	ReadTimeout      string      `json:"read_timeout,omitempty" yaml:"read_timeout,omitempty"`
+	MarkErrors       bool        `json:"mark_errors,omitempty" yaml:"mark_errors,omitempty"`
+	DetectBreaks     bool        `json:"detect_breaks,omitempty" yaml:"detect_breaks,omitempty"`
+	KeepStaleInput   bool        `json:"keep_stale_input,omitempty" yaml:"keep_stale_input,omitempty"`
+	DiscardPartial   bool        `json:"discard_partial,omitempty" yaml:"discard_partial,omitempty"`
+	MaxLineLength    int         `json:"max_line_length,omitempty" yaml:"max_line_length,omitempty"`
+	ContinueOnError  bool        `json:"continue_on_error,omitempty" yaml:"continue_on_error,omitempty"`
+	DrainTimeout     string      `json:"drain_timeout,omitempty" yaml:"drain_timeout,omitempty"`
+	RingSize         int         `json:"ring_size,omitempty" yaml:"ring_size,omitempty"`
+	RealtimePriority int         `json:"realtime_priority,omitempty" yaml:"realtime_priority,omitempty"`
+	BlockingRead     string      `json:"blocking_read,omitempty" yaml:"blocking_read,omitempty"`
+	ReadSettle       string      `json:"read_settle,omitempty" yaml:"read_settle,omitempty"`
+	ReadChunkSize    int         `json:"read_chunk_size,omitempty" yaml:"read_chunk_size,omitempty"`
+	BacklogInterval  string      `json:"backlog_interval,omitempty" yaml:"backlog_interval,omitempty"`
+	MeasureLatency   bool        `json:"measure_latency,omitempty" yaml:"measure_latency,omitempty"`
+	Health           *healthFile `json:"health,omitempty" yaml:"health,omitempty"`
+	SampleRate       float64     `json:"sample_rate,omitempty" yaml:"sample_rate,omitempty"`
+	NotifyReady      bool        `json:"notify_ready,omitempty" yaml:"notify_ready,omitempty"`
+}
+
+type rs485File struct {
+	RTSActiveLow    bool   `json:"rts_active_low,omitempty" yaml:"rts_active_low,omitempty"`
+	DelayBeforeSend string `json:"delay_before_send,omitempty" yaml:"delay_before_send,omitempty"`
+	DelayAfterSend  string `json:"delay_after_send,omitempty" yaml:"delay_after_send,omitempty"`
+}
+
+type healthFile struct {
+	Command string `json:"command,omitempty" yaml:"command,omitempty"`
+	Expect  string `json:"expect,omitempty" yaml:"expect,omitempty"`
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MaxIdle string `json:"max_idle,omitempty" yaml:"max_idle,omitempty"`
+}
+
+func (c *Config) file() configFile {
+	dur := func(d time.Duration) string {
+		if d == 0 {
+			return ""
+		}
+		return d.String()
+	}
+	var rs485 *rs485File
+	if r := c.RS485; r != nil {
+		rs485 = &rs485File{r.RTSActiveLow, dur(r.DelayBeforeSend), dur(r.DelayAfterSend)}
+	}
+	var health *healthFile
+	if h := c.Health; h != nil {
+		health = &healthFile{Command: h.Command, Timeout: dur(h.Timeout), MaxIdle: dur(h.MaxIdle)}
+		if h.Expect != nil {
+			health.Expect = h.Expect.String()
+		}
+	}
+	delim := strconv.Quote(c.Delimiter)
+	var term string
+	if c.Terminator != nil {
+		term = c.Terminator.String()
+	}
+	return configFile{
+		Device:           c.Device,
+		BaudRate:         c.BaudRate,
+		DataBits:         c.DataBits,
+		Parity:           c.Parity,
+		StopBits:         c.StopBits,
+		Access:           c.Access,
+		RTSCTS:           c.RTSCTS,
+		XONXOFF:          c.XONXOFF,
+		InitialDTR:       c.InitialDTR,
+		InitialRTS:       c.InitialRTS,
+		HoldModemLines:   c.HoldModemLines,
+		RS485:            rs485,
+		Delimiter:        delim[1 : len(delim)-1],
+		Terminator:       term,
+		DetectDelimiter:  c.DetectDelimiter,
+		Framer:           c.Framer,
+		ReadTimeout:      dur(c.ReadTimeout),
+		MarkErrors:       c.MarkErrors,
+		DetectBreaks:     c.DetectBreaks,
+		KeepStaleInput:   c.KeepStaleInput,
+		DiscardPartial:   c.DiscardPartial,
+		MaxLineLength:    c.MaxLineLength,
+		ContinueOnError:  c.ContinueOnError,
+		DrainTimeout:     dur(c.DrainTimeout),
+		RingSize:         c.RingSize,
+		RealtimePriority: c.RealtimePriority,
+		BlockingRead:     dur(c.BlockingRead),
+		ReadSettle:       dur(c.ReadSettle),
+		ReadChunkSize:    c.ReadChunkSize,
+		BacklogInterval:  dur(c.BacklogInterval),
+		MeasureLatency:   c.MeasureLatency,
+		Health:           health,
+		SampleRate:       c.SampleRate,
+		NotifyReady:      c.NotifyReady,
+	}
+}
+
+// apply stores f in c, leaving the fields without a file form alone.
+func (f *configFile) apply(c *Config) error {
+	type duration struct {
+		name string
+		s    string
+		d    *time.Duration
+	}
+	durs := []duration{
+		{"read_timeout", f.ReadTimeout, &c.ReadTimeout},
+		{"drain_timeout", f.DrainTimeout, &c.DrainTimeout},
+		{"blocking_read", f.BlockingRead, &c.BlockingRead},
+		{"read_settle", f.ReadSettle, &c.ReadSettle},
+		{"backlog_interval", f.BacklogInterval, &c.BacklogInterval},
+	}
+	c.RS485 = nil
+	if r := f.RS485; r != nil {
+		c.RS485 = &RS485{RTSActiveLow: r.RTSActiveLow}
+		durs = append(durs,
+			duration{"rs485.delay_before_send", r.DelayBeforeSend, &c.RS485.DelayBeforeSend},
+			duration{"rs485.delay_after_send", r.DelayAfterSend, &c.RS485.DelayAfterSend})
+	}
+	c.Health = nil
+	if h := f.Health; h != nil {
+		c.Health = &HealthProbe{Command: h.Command}
+		if h.Expect != "" {
+			re, err := regexp.Compile(h.Expect)
+			if err != nil {
+				return fmt.Errorf("serial: config health.expect: %w", err)
+			}
+			c.Health.Expect = re
+		}
+		durs = append(durs,
+			duration{"health.timeout", h.Timeout, &c.Health.Timeout},
+			duration{"health.max_idle", h.MaxIdle, &c.Health.MaxIdle})
+	}
+	for _, d := range durs {
+		*d.d = 0
+		if d.s == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.s)
+		if err != nil {
+			return fmt.Errorf("serial: config %s: %w", d.name, err)
+		}
+		*d.d = v
+	}
+	c.Terminator = nil
+	if f.Terminator != "" {
+		re, err := regexp.Compile(f.Terminator)
+		if err != nil {
+			return fmt.Errorf("serial: config terminator: %w", err)
+		}
+		c.Terminator = re
+	}
+	c.Delimiter = ""
+	if f.Delimiter != "" {
+		delim, err := ParseDelimiter(f.Delimiter)
+		if err != nil {
+			return fmt.Errorf("serial: config delimiter: %w", err)
+		}
+		c.Delimiter = delim
+	}
+	c.Device, c.BaudRate, c.DataBits, c.Parity, c.StopBits = f.Device, f.BaudRate, f.DataBits, f.Parity, f.StopBits
+	c.Access, c.RTSCTS, c.XONXOFF = f.Access, f.RTSCTS, f.XONXOFF
+	c.InitialDTR, c.InitialRTS, c.HoldModemLines = f.InitialDTR, f.InitialRTS, f.HoldModemLines
+	c.MarkErrors, c.DetectBreaks, c.KeepStaleInput = f.MarkErrors, f.DetectBreaks, f.KeepStaleInput
+	c.DiscardPartial, c.Framer, c.DetectDelimiter = f.DiscardPartial, f.Framer, f.DetectDelimiter
+	c.MaxLineLength, c.ContinueOnError, c.RingSize = f.MaxLineLength, f.ContinueOnError, f.RingSize
+	c.RealtimePriority, c.MeasureLatency = f.RealtimePriority, f.MeasureLatency
+	c.ReadChunkSize, c.SampleRate, c.NotifyReady = f.ReadChunkSize, f.SampleRate, f.NotifyReady
+	return nil
+}
+
+// MarshalJSON encodes c for a configuration file: durations as strings
+// ("2s"), parity and access mode by name ("even", "read-only") and the
+// delimiter Go-escaped ("\\r\\n"). Middleware, checksums and callbacks are
+// left out.
+func (c Config) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.file())
+}
+
+// UnmarshalJSON decodes the form written by MarshalJSON. The delimiter may
+// also be a name or a literal string; see ParseDelimiter. Fields missing
+// from data keep their current values, so defaults can be set beforehand;
+// middleware, checksums and callbacks are never touched.
+func (c *Config) UnmarshalJSON(data []byte) error {
+	f := c.file()
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	return f.apply(c)
+}
+
+// MarshalYAML is MarshalJSON for YAML encoders such as gopkg.in/yaml.v3.
+func (c Config) MarshalYAML() (any, error) {
+	return c.file(), nil
+}
+
+// UnmarshalYAML is UnmarshalJSON for YAML decoders such as gopkg.in/yaml.v3,
+// using the decoder-callback form so the package does not depend on one.
+func (c *Config) UnmarshalYAML(unmarshal func(any) error) error {
+	f := c.file()
+	if err := unmarshal(&f); err != nil {
+		return err
+	}
+	return f.apply(c)
+}
+
+// String summarises c for logs, e.g. `/dev/ttyUSB0 115200 8E1 rtscts
+// delim="\r\n"`, with defaults filled in. Passwords in endpoint URLs are
+// redacted; callbacks, checksums and middleware are not shown.
+func (c Config) String() string {
+	var b strings.Builder
+	device := c.Device
+	if strings.Contains(device, "://") {
+		if u, err := url.Parse(device); err == nil {
+			device = u.Redacted()
+		}
+	}
+	if device == "" {
+		device = "<no device>"
+	}
+	baud, dataBits, stopBits := c.BaudRate, c.DataBits, c.StopBits
+	if baud == 0 {
+		baud = 115200
+	}
+	if dataBits == 0 {
+		dataBits = 8
+	}
+	if stopBits == 0 {
+		stopBits = 1
+	}
+	parity := "?"
+	if c.Parity >= ParityNone && c.Parity <= ParitySpace {
+		parity = strings.ToUpper(c.Parity.String()[:1])
+	}
+	fmt.Fprintf(&b, "%s %d %d%s%d", device, baud, dataBits, parity, stopBits)
+	if c.RTSCTS {
+		b.WriteString(" rtscts")
+	}
+	if c.XONXOFF {
+		b.WriteString(" xonxoff")
+	}
+	if c.Access != ReadWrite {
+		b.WriteString(" " + c.Access.String())
+	}
+	if c.Terminator != nil {
+		fmt.Fprintf(&b, " term=%q", c.Terminator.String())
+	} else if c.DetectDelimiter {
+		b.WriteString(" delim=auto")
+	} else {
+		fmt.Fprintf(&b, " delim=%q", c.delimiter())
+	}
+	if c.Framer != "" {
+		b.WriteString(" framer=" + c.Framer)
+	}
+	return b.String()
+}
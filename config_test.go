@@ -0,0 +1,218 @@
+package serial
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfigValidate(t *testing.T) {
+	require.NoError(t, Config{Device: "/dev/ttyUSB0"}.Validate())
+	require.NoError(t, Config{Device: "/dev/ttyUSB0", BaudRate: 9600, DataBits: 7, Parity: ParityEven, StopBits: 2}.Validate())
+	require.NoError(t, Config{Device: "rfc2217://host:4001", BaudRate: 250000}.Validate())
+
+	for _, tc := range []struct {
+		cfg   Config
+		field string
+	}{
+		{Config{}, "Device"},
+		{Config{Device: "/dev/ttyS0", BaudRate: 250000}, "BaudRate"},
+		{Config{Device: "/dev/ttyS0", BaudRate: -1}, "BaudRate"},
+		{Config{Device: "/dev/ttyS0", DataBits: 9}, "DataBits"},
+		{Config{Device: "/dev/ttyS0", Parity: Parity(7)}, "Parity"},
+		{Config{Device: "/dev/ttyS0", StopBits: 3}, "StopBits"},
+		{Config{Device: "/dev/ttyS0", Access: AccessMode(5)}, "Access"},
+		{Config{Device: "/dev/ttyS0", ReadTimeout: -time.Second}, "ReadTimeout"},
+		{Config{Device: "/dev/ttyS0", BlockingRead: 30 * time.Second}, "BlockingRead"},
+		{Config{Device: "/dev/ttyS0", RealtimePriority: 100}, "RealtimePriority"},
+		{Config{Device: "/dev/ttyS0", ReadChunkSize: -1}, "ReadChunkSize"},
+		{Config{Device: "/dev/ttyS0", BacklogInterval: -time.Second}, "BacklogInterval"},
+		{Config{Device: "/dev/ttyS0", OnBadLine: func(string, error) {}}, "OnBadLine"},
+		{Config{Device: "/dev/ttyS0", OnBadChar: func(byte) {}}, "OnBadChar"},
+		{Config{Device: "/dev/ttyS0", OnBreak: func(BreakReceived) {}}, "OnBreak"},
+		{Config{Device: "/dev/ttyS0", Workers: &WorkerPool{Queue: -1}}, "Workers"},
+		{Config{Device: "/dev/ttyS0", Terminator: regexp.MustCompile(`\n?`)}, "Terminator"},
+		{Config{Device: "/dev/ttyS0", RS485: &RS485{}, RTSCTS: true}, "RS485"},
+		{Config{Device: "/dev/ttyS0", RS485: &RS485{DelayAfterSend: -1}}, "RS485"},
+		{Config{Device: "/dev/ttyS0", Health: &HealthProbe{MaxIdle: -time.Second}}, "Health"},
+		{Config{Device: "/dev/ttyS0", SampleRate: math.Inf(1)}, "SampleRate"},
+		{Config{Device: "/dev/ttyS0", DetectDelimiter: true, Delimiter: "\n"}, "DetectDelimiter"},
+		{Config{Device: "/dev/ttyS0", OnDelimiterDetected: func(string) {}}, "OnDelimiterDetected"},
+	} {
+		err := tc.cfg.Validate()
+		require.ErrorIs(t, err, ErrInvalidConfig, tc.field)
+		var cerr *ConfigError
+		require.ErrorAs(t, err, &cerr, tc.field)
+		require.Equal(t, tc.field, cerr.Field)
+	}
+
+	// Every problem is reported, not just the first.
+	err := Config{DataBits: 4, StopBits: 3}.Validate()
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var cerr *ConfigError
+		require.True(t, errors.As(e, &cerr))
+		fields = append(fields, cerr.Field)
+	}
+	require.Equal(t, []string{"Device", "DataBits", "StopBits"}, fields)
+}
+
+func TestOpen_InvalidConfig(t *testing.T) {
+	_, err := Open(Config{Device: "/dev/does-not-exist", BaudRate: 12345})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	var serr *SerialError
+	require.ErrorAs(t, err, &serr)
+	require.True(t, serr.IsConfig())
+
+	_, err = Open(Config{Device: "serial:///dev/does-not-exist?baud=12345"})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestSerialReader_DefaultDelimiter(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	reader.config.Delimiter = ""
+	reader.lineEnd = reader.config.lineEnd()
+	_, err := master.Write([]byte("a\nb\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "a\nb", line)
+}
+
+func TestConfig_JSON(t *testing.T) {
+	cfg := Config{
+		Device: "/dev/ttyUSB0", BaudRate: 9600, Parity: ParityEven, Access: ReadOnly,
+		Delimiter: "\r\n", ReadTimeout: 1500 * time.Millisecond, RingSize: 64,
+		Middleware: []Middleware{Tap(func(string) {})},
+	}
+	b, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"device":"/dev/ttyUSB0","baud_rate":9600,"parity":"even","access":"read-only",
+		"delimiter":"\\r\\n","read_timeout":"1.5s","ring_size":64}`, string(b))
+
+	var got Config
+	require.NoError(t, json.Unmarshal(b, &got))
+	cfg.Middleware = nil
+	require.Equal(t, cfg, got)
+
+	// Missing fields keep preset defaults; names and literals work for the delimiter.
+	got = Config{BaudRate: 115200, Delimiter: "\n"}
+	require.NoError(t, json.Unmarshal([]byte(`{"device":"/dev/ttyS0","delimiter":"crlf"}`), &got))
+	require.Equal(t, Config{Device: "/dev/ttyS0", BaudRate: 115200, Delimiter: "\r\n"}, got)
+
+	require.Error(t, json.Unmarshal([]byte(`{"parity":"sometimes"}`), &got))
+	require.Error(t, json.Unmarshal([]byte(`{"read_timeout":"soon"}`), &got))
+}
+
+func TestConfig_YAML(t *testing.T) {
+	var cfg Config
+	require.NoError(t, yaml.Unmarshal([]byte(`
+device: /dev/ttyACM0
+baud_rate: 57600
+parity: odd
+stop_bits: 2
+delimiter: '\r\n'
+read_timeout: 250ms
+blocking_read: 1s
+rs485:
+  delay_after_send: 500us
+health:
+  command: "*IDN?"
+  expect: ^ACME
+  max_idle: 30s
+sample_rate: 100
+`), &cfg))
+	require.Equal(t, Config{
+		Device: "/dev/ttyACM0", BaudRate: 57600, Parity: ParityOdd, StopBits: 2,
+		Delimiter: "\r\n", ReadTimeout: 250 * time.Millisecond, BlockingRead: time.Second,
+		RS485:      &RS485{DelayAfterSend: 500 * time.Microsecond},
+		Health:     &HealthProbe{Command: "*IDN?", Expect: regexp.MustCompile("^ACME"), MaxIdle: 30 * time.Second},
+		SampleRate: 100,
+	}, cfg)
+
+	b, err := yaml.Marshal(cfg)
+	require.NoError(t, err)
+	var back Config
+	require.NoError(t, yaml.Unmarshal(b, &back))
+	require.Equal(t, cfg, back)
+}
+
+func TestConfig_LineEnd(t *testing.T) {
+	lineEnd := (&Config{Delimiter: "\r\n"}).lineEnd()
+	end, next := lineEnd([]byte("ab\r\ncd"), 0)
+	require.Equal(t, []int{2, 4}, []int{end, next})
+	// A delimiter split across reads is found when its first byte was
+	// already scanned.
+	end, next = lineEnd([]byte("ab\r\n"), 3)
+	require.Equal(t, []int{2, 4}, []int{end, next})
+	end, _ = lineEnd([]byte("\r\nab\r"), 4)
+	require.Equal(t, -1, end)
+
+	lineEnd = (&Config{Terminator: regexp.MustCompile(`>\s$`)}).lineEnd()
+	end, next = lineEnd([]byte("a> "), 3)
+	require.Equal(t, []int{1, 3}, []int{end, next})
+}
+
+// BenchmarkLineEnd feeds a long line in small reads, scanning only the new
+// bytes of each read (indexed) or the whole accumulated line every time
+// (rescan), as ReadLinesLoop did when it rebuilt the line as a string.
+func BenchmarkLineEnd(b *testing.B) {
+	for _, size := range []int{64, 1024, 16384} {
+		for _, rescan := range []bool{false, true} {
+			name := fmt.Sprintf("%d/indexed", size)
+			if rescan {
+				name = fmt.Sprintf("%d/rescan", size)
+			}
+			b.Run(name, func(b *testing.B) {
+				benchmarkLineEnd(b, size, 32, rescan)
+			})
+		}
+	}
+}
+
+func benchmarkLineEnd(b *testing.B, size, chunk int, rescan bool) {
+	lineEnd := (&Config{Delimiter: "\r\n"}).lineEnd()
+	data := []byte(strings.Repeat("x", size-2) + "\r\n")
+	line := make([]byte, 0, size)
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	for b.Loop() {
+		scanned := 0
+		for off := 0; off < len(data); off += chunk {
+			line = append(line, data[off:min(off+chunk, len(data))]...)
+			if rescan {
+				scanned = 0
+			}
+			end, next := lineEnd(line, scanned)
+			if end >= 0 {
+				line = line[:copy(line, line[next:])]
+				scanned = 0
+				continue
+			}
+			scanned = len(line)
+		}
+	}
+}
+
+func TestConfig_Terminator(t *testing.T) {
+	b, err := json.Marshal(Config{Device: "/dev/ttyS0", Terminator: regexp.MustCompile(`\r?\n`)})
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"terminator":"\\r?\\n"`)
+	var cfg Config
+	require.NoError(t, json.Unmarshal(b, &cfg))
+	require.Equal(t, `\r?\n`, cfg.Terminator.String())
+	require.Error(t, json.Unmarshal([]byte(`{"terminator":"("}`), &cfg))
+
+	cfg, err = ParseConfig(`/dev/ttyS0?term=%3E\s$`)
+	require.NoError(t, err)
+	require.Equal(t, `>\s$`, cfg.Terminator.String())
+	require.Equal(t, `/dev/ttyS0 115200 8N1 term=">\\s$"`, cfg.String())
+}
@@ -0,0 +1,187 @@
+// Package console drives the command-line interfaces of network gear,
+// dataloggers and instruments over a serial console.
+//
+// A Console sends a command, answers pagination prompts such as --More--
+// on the way, and returns the output up to the next command prompt, with
+// the echoed command, the pagination prompts and the prompt itself
+// removed.
+package console
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// ErrTimeout is returned when the console falls silent before a prompt.
+var ErrTimeout = errors.New("console: timed out waiting for prompt")
+
+var (
+	// DefaultPrompt matches common shell and device prompts such as
+	// "Router>", "switch(config)#" and "user@host:~$ ".
+	DefaultPrompt = regexp.MustCompile(`^[^\s>#$%]*[>#$%] ?$`)
+	// DefaultMore matches common pagination prompts such as "--More--",
+	// " --More-- (42%)" and "Press any key to continue".
+	DefaultMore = regexp.MustCompile(`(?i)--+ ?more ?.*--+|press any key to continue`)
+)
+
+// Console runs commands on a command-line interface reached through rw,
+// typically a *serial.SerialReader. Prompts are matched against the last,
+// unterminated line of output. Commands are serialised; a Console is safe
+// for concurrent use.
+type Console struct {
+	// Prompt matches the command prompt. Nil means DefaultPrompt.
+	Prompt *regexp.Regexp
+	// More matches a pagination prompt, answered with MoreReply. Nil means
+	// DefaultMore.
+	More *regexp.Regexp
+	// MoreReply is sent at a pagination prompt. Empty means a space.
+	MoreReply string
+	// Newline ends commands. Empty means "\r".
+	Newline string
+	// Timeout is how long Run waits for output before giving up. Zero means
+	// ten seconds. It needs rw to have a SetReadDeadline method, as
+	// SerialReader and net.Conn do; otherwise Run waits for as long as it
+	// takes.
+	Timeout time.Duration
+
+	rw  io.ReadWriter
+	mu  sync.Mutex
+	buf []byte
+}
+
+// New returns a Console on rw.
+func New(rw io.ReadWriter) *Console {
+	return &Console{rw: rw}
+}
+
+// Run sends cmd and returns the output it produced up to the next prompt,
+// lines separated by "\n". Erased pagination prompts, backspaces and
+// terminal escape sequences are removed.
+func (c *Console) Run(cmd string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.buf = c.buf[:0] // output that arrived between commands
+	if _, err := io.WriteString(c.rw, cmd+c.newline()); err != nil {
+		return "", err
+	}
+	out, err := c.readToPrompt()
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(out, "\n")
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) == strings.TrimSpace(cmd) {
+		lines = lines[1:] // echo
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Sync sends an empty command and waits for the prompt, to get in step
+// with a console that may be in the middle of something, e.g. after
+// opening the port.
+func (c *Console) Sync() error {
+	_, err := c.Run("")
+	return err
+}
+
+// readToPrompt reads until the last line of output matches the prompt,
+// answering pagination prompts, and returns the cleaned output before the
+// prompt line.
+func (c *Console) readToPrompt() (string, error) {
+	prompt, more := c.Prompt, c.More
+	if prompt == nil {
+		prompt = DefaultPrompt
+	}
+	if more == nil {
+		more = DefaultMore
+	}
+	d, _ := c.rw.(interface{ SetReadDeadline(time.Time) error })
+	if d != nil {
+		defer d.SetReadDeadline(time.Time{})
+	}
+	chunk := make([]byte, 1024)
+	for {
+		if d != nil {
+			if err := d.SetReadDeadline(time.Now().Add(c.timeout())); err != nil {
+				return "", err
+			}
+		}
+		n, err := c.rw.Read(chunk)
+		c.buf = append(c.buf, chunk[:n]...)
+		if err != nil && n == 0 {
+			if errors.Is(err, serial.ErrTimeout) || errors.Is(err, os.ErrDeadlineExceeded) {
+				return "", ErrTimeout
+			}
+			return "", err
+		}
+		start := bytes.LastIndexByte(c.buf, '\n') + 1
+		last := []byte(clean(string(c.buf[start:])))
+		if more.Match(last) {
+			c.buf = c.buf[:start]
+			if _, err := io.WriteString(c.rw, c.moreReply()); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if prompt.Match(last) {
+			return clean(string(c.buf[:max(start-1, 0)])), nil
+		}
+	}
+}
+
+var escape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// clean normalises terminal output: escape sequences are removed,
+// backspaces delete the character before them, and a carriage return
+// within a line lets the text after it replace the line.
+func clean(s string) string {
+	s = escape.ReplaceAllString(s, "")
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		if strings.IndexByte(line, '\b') >= 0 {
+			b := make([]byte, 0, len(line))
+			for k := 0; k < len(line); k++ {
+				if line[k] == '\b' {
+					b = b[:max(len(b)-1, 0)]
+					continue
+				}
+				b = append(b, line[k])
+			}
+			line = string(b)
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Console) newline() string {
+	if c.Newline == "" {
+		return "\r"
+	}
+	return c.Newline
+}
+
+func (c *Console) moreReply() string {
+	if c.MoreReply == "" {
+		return " "
+	}
+	return c.MoreReply
+}
+
+func (c *Console) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return 10 * time.Second
+}
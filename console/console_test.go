@@ -0,0 +1,108 @@
+package console
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCLI echoes commands read from conn and answers them with
+// output(cmd), paging it twenty lines at a time like a switch, then
+// prints the prompt.
+func fakeCLI(t *testing.T, output func(cmd string) string) *Console {
+	t.Helper()
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	go func() {
+		buf := make([]byte, 256)
+		read := func() (byte, bool) {
+			n, err := b.Read(buf[:1])
+			return buf[0], err == nil && n == 1
+		}
+		var cmd []byte
+		for {
+			c, ok := read()
+			if !ok {
+				return
+			}
+			if c != '\r' {
+				cmd = append(cmd, c)
+				continue
+			}
+			b.Write(append(cmd, "\r\n"...))
+			lines := strings.SplitAfter(output(string(cmd)), "\n")
+			cmd = cmd[:0]
+			for i, line := range lines {
+				if i > 0 && i%20 == 0 {
+					b.Write([]byte(" --More-- "))
+					if c, ok := read(); !ok || c != ' ' {
+						return
+					}
+					b.Write([]byte("\r          \r"))
+				}
+				b.Write([]byte(strings.ReplaceAll(line, "\n", "\r\n")))
+			}
+			b.Write([]byte("switch# "))
+		}
+	}()
+	return New(a)
+}
+
+func TestConsole_Run(t *testing.T) {
+	var long bytes.Buffer
+	for i := range 45 {
+		long.WriteString(strings.Repeat("x", i) + "\n")
+	}
+	c := fakeCLI(t, func(cmd string) string {
+		switch cmd {
+		case "show version":
+			return "Version 1.2\nUptime 3 days\n"
+		case "show log":
+			return long.String()
+		}
+		return ""
+	})
+	require.NoError(t, c.Sync())
+
+	out, err := c.Run("show version")
+	require.NoError(t, err)
+	require.Equal(t, "Version 1.2\nUptime 3 days", out)
+
+	out, err = c.Run("show log")
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimSuffix(long.String(), "\n"), out)
+
+	out, err = c.Run("")
+	require.NoError(t, err)
+	require.Empty(t, out)
+}
+
+func TestConsole_Timeout(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			if _, err := b.Read(buf); err != nil {
+				return
+			}
+			b.Write([]byte("working...\r\n"))
+		}
+	}()
+	c := New(a)
+	c.Timeout = 50 * time.Millisecond
+	_, err := c.Run("reboot")
+	require.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestClean(t *testing.T) {
+	require.Equal(t, "abd", clean("abc\bd"))
+	require.Equal(t, "red text", clean("\x1b[31mred\x1b[0m text"))
+	require.Equal(t, "after\nnext", clean("before\rafter\r\nnext"))
+	require.Equal(t, "one\ntwo", clean("one\r\ntwo"))
+}
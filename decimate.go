@@ -0,0 +1,67 @@
+package serial
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Decimator thins out lines for consumers that do not need the full rate,
+// such as a UI or a logger fed from a 200 Hz sensor. Install it with
+//
+//	d := &serial.Decimator{MaxRate: 10}
+//	cfg.Middleware = append(cfg.Middleware, d.Middleware())
+//
+// With both fields set, lines are counted off first and the survivors rate
+// limited. Dropped lines are counted; see Dropped.
+type Decimator struct {
+	Every   int     // pass only every Nth line (the 1st, N+1th, ...); 0 or 1 passes all
+	MaxRate float64 // pass at most this many lines per second; 0 means no limit
+
+	mu      sync.Mutex
+	n       int       // position of the next line in the Every cycle
+	next    time.Time // earliest time the next line may pass MaxRate
+	dropped atomic.Uint64
+}
+
+// Middleware returns a Middleware that passes lines according to d. To keep
+// to MaxRate the lines in between are dropped, so the first line after a
+// quiet spell always passes. A Decimator may serve several loops; they then
+// share its budget.
+func (d *Decimator) Middleware() Middleware {
+	return func(next func(string)) func(string) {
+		return func(line string) {
+			if !d.pass() {
+				return
+			}
+			next(line)
+		}
+	}
+}
+
+func (d *Decimator) pass() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Every > 1 {
+		i := d.n
+		d.n = (d.n + 1) % d.Every
+		if i != 0 {
+			d.dropped.Add(1)
+			return false
+		}
+	}
+	if d.MaxRate > 0 {
+		now := time.Now()
+		if now.Before(d.next) {
+			d.dropped.Add(1)
+			return false
+		}
+		d.next = now.Add(time.Duration(float64(time.Second) / d.MaxRate))
+	}
+	return true
+}
+
+// Dropped returns the number of lines dropped so far.
+func (d *Decimator) Dropped() uint64 {
+	return d.dropped.Load()
+}
@@ -0,0 +1,52 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ParseError is a line rejected by the parse function given to Decode, or
+// by the JSON decoding of DecodeJSON and JSONLines.
+type ParseError struct {
+	Line string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("serial: parse %q: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Decode runs ReadLinesLoop on r in a new goroutine and turns the lines into
+// a typed stream: every line is passed to parse, and the values it returns
+// are sent on values in order; lines for which it returns ErrSkipLine are
+// dropped. Lines parse rejects are sent on errs as *ParseError and counted
+// in Stats().ParseErrors, apart from read errors, which are sent on errs as
+// they are and end the stream as they end ReadLinesLoop. Both channels are
+// closed when the loop returns, after Close for instance.
+//
+// The read loop waits for the consumer, so drain both channels; values has
+// a small buffer to absorb bursts, and Config.Async decouples a slow
+// consumer further.
+func Decode[T any](r *SerialReader, parse func(line string) (T, error)) (values <-chan T, errs <-chan error) {
+	vals := make(chan T, 64)
+	errc := make(chan error, 8)
+	go func() {
+		defer close(errc)
+		defer close(vals)
+		r.ReadLinesLoop(func(line string) {
+			v, err := parse(line)
+			if errors.Is(err, ErrSkipLine) {
+				return
+			}
+			if err != nil {
+				r.parseErrors.Add(1)
+				errc <- &ParseError{Line: line, Err: err}
+				return
+			}
+			vals <- v
+		}, func(err error) { errc <- err })
+	}()
+	return vals, errc
+}
@@ -0,0 +1,34 @@
+package serial
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	values, errs := Decode(reader, func(line string) (float64, error) {
+		return strconv.ParseFloat(line, 64)
+	})
+	_, err := master.Write([]byte("1.5\nabc\n-2\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, 1.5, <-values)
+	err = <-errs
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "abc", perr.Line)
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+	require.Equal(t, -2.0, <-values)
+	require.EqualValues(t, 1, reader.Stats().ParseErrors)
+	require.EqualValues(t, 3, reader.Stats().Lines)
+
+	reader.Close()
+	for range values {
+	}
+	for err := range errs {
+		require.NotErrorAs(t, err, &perr)
+	}
+}
@@ -0,0 +1,65 @@
+package serial
+
+import (
+	"bytes"
+	"sync/atomic"
+)
+
+// DetectDelimiter returns the line ending used by data, whose first byte is
+// the first received from the device: "\n", "\r\n" or "\r", whichever ends
+// the first line. ok is false if data does not tell yet, because it holds no
+// line end or ends in a "\r" that may be followed by a "\n". A "\n" at the
+// start of data may be the tail of a "\r\n" sent before the port was opened
+// and is skipped.
+func DetectDelimiter(data []byte) (delim string, ok bool) {
+	delim = detectDelimiter(data, 0)
+	return delim, delim != ""
+}
+
+// detectDelimiter implements DetectDelimiter, knowing that data[:from] holds
+// no line end except possibly a leading "\n" or a trailing "\r".
+func detectDelimiter(data []byte, from int) string {
+	start := max(from-1, 0) // a trailing "\r" may have been followed since
+	for {
+		j := bytes.IndexAny(data[start:], "\r\n")
+		if j < 0 {
+			return ""
+		}
+		i := start + j
+		switch {
+		case data[i] == '\n' && i == 0:
+			start = 1
+			continue
+		case data[i] == '\n':
+			return "\n"
+		case i+1 == len(data):
+			return ""
+		case data[i+1] == '\n':
+			return "\r\n"
+		}
+		return "\r"
+	}
+}
+
+// detectingLineEnd returns a Config.lineEnd for DetectDelimiter: until the
+// delimiter is known it finds no line end, so the data stays pending, and
+// from then on it is the lineEnd of the delimiter found. onDetected, if set,
+// is called once with the delimiter.
+func detectingLineEnd(onDetected func(delim string)) func(b []byte, from int) (end, next int) {
+	var found atomic.Pointer[func(b []byte, from int) (int, int)]
+	return func(b []byte, from int) (int, int) {
+		if f := found.Load(); f != nil {
+			return (*f)(b, from)
+		}
+		delim := detectDelimiter(b, from)
+		if delim == "" {
+			return -1, -1
+		}
+		f := delimiterEnd(delim)
+		found.Store(&f)
+		if onDetected != nil {
+			onDetected(delim)
+		}
+		return f(b, 0) // b[:from] was only free of the delimiter's start
+	}
+}
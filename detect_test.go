@@ -0,0 +1,63 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	for _, tc := range []struct {
+		data, want string
+	}{
+		{"abc\n", "\n"},
+		{"abc\r\ndef", "\r\n"},
+		{"abc\rdef", "\r"},
+		{"abc\r", ""}, // \r or \r\n
+		{"abc", ""},
+		{"\nabc\r\n", "\r\n"}, // tail of a \r\n sent before the open
+		{"\n", ""},
+		{"\r\r", "\r"},
+	} {
+		got, ok := DetectDelimiter([]byte(tc.data))
+		require.Equal(t, tc.want, got, "%q", tc.data)
+		require.Equal(t, tc.want != "", ok, "%q", tc.data)
+	}
+
+	// Data arriving a byte at a time is decided by the byte after a \r.
+	end := detectingLineEnd(nil)
+	data := []byte("ab\r")
+	e, _ := end(data, 2)
+	require.Equal(t, -1, e)
+	data = append(data, 'c', 'd', '\r')
+	e, n := end(data, 3)
+	require.Equal(t, 2, e)
+	require.Equal(t, 3, n)
+}
+
+func TestSerialReader_DetectDelimiter(t *testing.T) {
+	detected := make(chan string, 2)
+	reader, master := newTestReader(t, Config{
+		DetectDelimiter:     true,
+		OnDelimiterDetected: func(d string) { detected <- d },
+	})
+	_, err := master.Write([]byte("first\r"))
+	require.NoError(t, err)
+	_, err = master.Write([]byte("\nsecond\r\n"))
+	require.NoError(t, err)
+	for _, want := range []string{"first", "second"} {
+		line, err := reader.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, want, line)
+	}
+	require.Equal(t, "\r\n", <-detected)
+
+	// The choice survives a reopen and is reported once.
+	require.NoError(t, reader.Reopen())
+	_, err = master.Write([]byte("third\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "third", line)
+	require.Empty(t, detected)
+}
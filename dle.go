@@ -0,0 +1,93 @@
+package serial
+
+// ASCII control characters used by DLE framing.
+const (
+	asciiSTX = 0x02
+	asciiETX = 0x03
+	asciiDLE = 0x10
+)
+
+// DLE is a Framer for DLE byte stuffing, used by many binary instrument
+// protocols: frames are DLE STX payload DLE ETX, and every DLE inside the
+// payload is doubled, so the payload may contain any byte, including the
+// framing ones. Bytes outside a frame are dropped as noise. A DLE followed
+// by anything else inside a frame is reported as ErrBadFrame.
+//
+// The zero value uses DLE (0x10), STX (0x02) and ETX (0x03); set the fields
+// for protocols that escape with ESC (0x1B) or use other markers.
+type DLE struct {
+	Escape     byte // default DLE
+	Start, End byte // bytes following Escape that open and close a frame; default STX and ETX
+}
+
+func (d DLE) bytes() (esc, start, end byte) {
+	esc, start, end = d.Escape, d.Start, d.End
+	if esc == 0 {
+		esc = asciiDLE
+	}
+	if start == 0 {
+		start = asciiSTX
+	}
+	if end == 0 {
+		end = asciiETX
+	}
+	return esc, start, end
+}
+
+// Frame implements Framer.
+func (d DLE) Frame(data []byte) (int, []byte, error) {
+	esc, start, end := d.bytes()
+	// Find the opening escape-start pair, dropping the noise before it.
+	i := 0
+	for ; i+1 < len(data); i++ {
+		if data[i] == esc && data[i+1] == start {
+			break
+		}
+	}
+	if i+1 >= len(data) {
+		if len(data) > 0 && data[len(data)-1] == esc {
+			return len(data) - 1, nil, nil // may be the start of a pair
+		}
+		return len(data), nil, nil
+	}
+	if i > 0 {
+		return i, nil, nil
+	}
+	var frame []byte
+	for j := 2; j+1 < len(data); j++ {
+		b := data[j]
+		if b != esc {
+			frame = append(frame, b)
+			continue
+		}
+		j++
+		switch data[j] {
+		case esc:
+			frame = append(frame, esc)
+		case end:
+			if frame == nil {
+				frame = []byte{}
+			}
+			return j + 1, frame, nil
+		case start:
+			return j - 1, nil, nil // the frame was cut short; start over
+		default:
+			return j + 1, nil, ErrBadFrame
+		}
+	}
+	return 0, nil, nil
+}
+
+// Encode returns payload as a DLE frame, doubling the escape bytes in it.
+func (d DLE) Encode(payload []byte) []byte {
+	esc, start, end := d.bytes()
+	out := make([]byte, 0, len(payload)+4)
+	out = append(out, esc, start)
+	for _, b := range payload {
+		if b == esc {
+			out = append(out, esc)
+		}
+		out = append(out, b)
+	}
+	return append(out, esc, end)
+}
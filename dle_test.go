@@ -0,0 +1,67 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDLE_RoundTrip(t *testing.T) {
+	payload := []byte{0x01, asciiDLE, asciiETX, 0x02}
+	enc := DLE{}.Encode(payload)
+	require.Equal(t, []byte{asciiDLE, asciiSTX, 0x01, asciiDLE, asciiDLE, asciiETX, 0x02, asciiDLE, asciiETX}, enc)
+
+	// Noise before the frame is dropped first.
+	data := append([]byte{0xAA, 0x55}, enc...)
+	advance, frame, err := DLE{}.Frame(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, advance)
+	require.Nil(t, frame)
+
+	advance, frame, err = DLE{}.Frame(data[2:])
+	require.NoError(t, err)
+	require.Equal(t, len(enc), advance)
+	require.Equal(t, payload, frame)
+
+	// Incomplete, including a frame ending in a lone escape.
+	for n := 2; n < len(enc); n++ {
+		advance, frame, _ = DLE{}.Frame(enc[:n])
+		require.Zero(t, advance, n)
+		require.Nil(t, frame)
+	}
+}
+
+func TestDLE_Errors(t *testing.T) {
+	// An escape followed by an unexpected byte.
+	advance, frame, err := DLE{}.Frame([]byte{asciiDLE, asciiSTX, 0x01, asciiDLE, 0x7F, 0x02})
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.Equal(t, 5, advance)
+	require.Nil(t, frame)
+
+	// A new frame starting inside an unfinished one restarts framing.
+	advance, frame, err = DLE{}.Frame([]byte{asciiDLE, asciiSTX, 0x01, asciiDLE, asciiSTX, 0x02})
+	require.NoError(t, err)
+	require.Equal(t, 3, advance)
+	require.Nil(t, frame)
+
+	// Custom escape and markers.
+	esc := DLE{Escape: 0x1B, Start: 'S', End: 'E'}
+	_, frame, err = esc.Frame(esc.Encode([]byte{0x1B, 'E'}))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x1B, 'E'}, frame)
+}
+
+func TestSerialReader_ReadFramesLoopDLE(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	frames := make(chan []byte, 2)
+	go reader.ReadFramesLoop(DLE{}, func(f []byte) {
+		frames <- append([]byte(nil), f...)
+	}, func(error) {})
+
+	enc := DLE{}.Encode([]byte{'\n', asciiDLE, 0x00})
+	_, err := master.Write(enc[:4])
+	require.NoError(t, err)
+	_, err = master.Write(enc[4:])
+	require.NoError(t, err)
+	require.Equal(t, []byte{'\n', asciiDLE, 0x00}, <-frames)
+}
@@ -1,4 +1,4 @@
-// Package serial provides a minimal, Linux-only serial port reader
+// Package serial provides a minimal, Linux-first serial port reader
 // designed for high-frequency unbuffered communication with embedded devices.
 //
 // This package is optimized for real-time use cases such as scientific
@@ -12,7 +12,11 @@
 //   - Self-pipe mechanism for killability
 //   - PTY-based tests for reliability
 //
-// This package does **not** support Windows.
+// macOS and FreeBSD are supported for development, without the Linux-only
+// extras (mark/space parity, LockRealtime, sysfs port details). Windows has
+// a reduced backend for COM ports: lines, raw reads and writes, and modem
+// control work, while network ports and the tty-specific extras return
+// errors.ErrUnsupported.
 //
 // Example usage:
 //
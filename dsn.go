@@ -0,0 +1,104 @@
+package serial
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ParseConfig parses a whole port configuration from one string, for
+// deployments that carry it in a flag or environment variable:
+//
+//	/dev/ttyUSB0:115200/8N1?delim=crlf&rtscts=1
+//	/dev/ttyS0:9600/7E2
+//	/dev/ttyACM0?timeout=2s
+//	rfc2217://host:4001?baud=9600&parity=even
+//
+// A local device may be followed by ":<baud>" and "/<framing>", where framing
+// is data bits, parity letter (N, O, E, M or S) and stop bits. The query takes
+// the parameters of endpoint URLs (see Open): baud, databits, parity,
+// stopbits, rtscts, xonxoff, dtr, rts, delim, term, framer and timeout.
+// Endpoint URLs are kept whole in Device, query included, with the
+// parameters also applied to the result. The result is checked with
+// Validate.
+func ParseConfig(dsn string) (Config, error) {
+	var cfg Config
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return cfg, fmt.Errorf("serial: parse config %q: %w", dsn, err)
+		}
+		cfg.Device = dsn
+		if err := applyQuery(&cfg, u.Query()); err != nil {
+			return cfg, fmt.Errorf("serial: parse config %q: %w", dsn, err)
+		}
+		return cfg, cfg.Validate()
+	}
+
+	path, query, _ := strings.Cut(dsn, "?")
+	if i := strings.LastIndexByte(path, '/'); i >= 0 && len(path[i+1:]) == 3 {
+		if err := parseFraming(&cfg, path[i+1:]); err == nil {
+			path = path[:i]
+		}
+	}
+	if i := strings.LastIndexByte(path, ':'); i >= 0 {
+		if baud, err := strconv.Atoi(path[i+1:]); err == nil {
+			cfg.BaudRate = baud
+			path = path[:i]
+		}
+	}
+	cfg.Device = path
+	q, err := url.ParseQuery(query)
+	if err == nil {
+		err = applyQuery(&cfg, q)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("serial: parse config %q: %w", dsn, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// parseFraming parses a framing such as "8N1" into cfg.
+func parseFraming(cfg *Config, s string) error {
+	if s[0] < '5' || s[0] > '8' || (s[2] != '1' && s[2] != '2') {
+		return fmt.Errorf("invalid framing %q", s)
+	}
+	parity, err := ParseParity(s[1:2])
+	if err != nil {
+		return fmt.Errorf("invalid framing %q", s)
+	}
+	cfg.DataBits, cfg.Parity, cfg.StopBits = int(s[0]-'0'), parity, int(s[2]-'0')
+	return nil
+}
+
+// ParseDelimiter parses a delimiter given by name (crlf, lf, cr, nul), as
+// hex bytes prefixed with 0x such as 0x1003, as a Go-escaped string such as
+// `\r\n` or `\xff\x00`, or literally if s contains no backslash. The
+// result is a byte sequence, not necessarily valid text.
+func ParseDelimiter(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "crlf":
+		return "\r\n", nil
+	case "lf":
+		return "\n", nil
+	case "cr":
+		return "\r", nil
+	case "nul":
+		return "\x00", nil
+	}
+	if h, ok := strings.CutPrefix(s, "0x"); ok && h != "" {
+		if b, err := hex.DecodeString(h); err == nil {
+			return string(b), nil
+		}
+	}
+	if s != "" && !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	d, err := strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`)
+	if err != nil || d == "" {
+		return "", fmt.Errorf("invalid delimiter %q", s)
+	}
+	return d, nil
+}
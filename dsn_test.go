@@ -0,0 +1,54 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	for dsn, want := range map[string]Config{
+		"/dev/ttyUSB0:115200/8N1?delim=crlf&rtscts=1": {
+			Device: "/dev/ttyUSB0", BaudRate: 115200, DataBits: 8, StopBits: 1, Delimiter: "\r\n", RTSCTS: true,
+		},
+		"/dev/ttyS0:9600/7E2": {Device: "/dev/ttyS0", BaudRate: 9600, DataBits: 7, Parity: ParityEven, StopBits: 2},
+		"/dev/ttyS0/8o1":      {Device: "/dev/ttyS0", DataBits: 8, Parity: ParityOdd, StopBits: 1},
+		"/dev/ttyACM0?timeout=2s&delim=%5Cx03": {
+			Device: "/dev/ttyACM0", ReadTimeout: 2 * time.Second, Delimiter: "\x03",
+		},
+		"/dev/ttyUSB1?delim=auto": {Device: "/dev/ttyUSB1", DetectDelimiter: true},
+		"/dev/serial/by-path/pci-0000:00:14.0-usb-0:1:1.0-port0:57600": {
+			Device: "/dev/serial/by-path/pci-0000:00:14.0-usb-0:1:1.0-port0", BaudRate: 57600,
+		},
+		"rfc2217://host:4001?baud=250000&xonxoff=true": {
+			Device: "rfc2217://host:4001?baud=250000&xonxoff=true", BaudRate: 250000, XONXOFF: true,
+		},
+	} {
+		cfg, err := ParseConfig(dsn)
+		require.NoError(t, err, dsn)
+		require.Equal(t, want, cfg, dsn)
+	}
+
+	for _, dsn := range []string{
+		"/dev/ttyS0:12345",              // unsupported baud
+		"/dev/ttyS0?rtscts=maybe",       // bad bool
+		"/dev/ttyS0?speed=9600",         // unknown parameter
+		"/dev/ttyS0?rtscts=1&xonxoff=1", // conflicting flow control
+		":9600",                         // no device
+	} {
+		_, err := ParseConfig(dsn)
+		require.Error(t, err, dsn)
+	}
+}
+
+func TestParseDelimiter(t *testing.T) {
+	for in, want := range map[string]string{"crlf": "\r\n", "LF": "\n", "cr": "\r", `\r\n`: "\r\n", ";": ";",
+		"0x1003": "\x10\x03", `\xff\x00`: "\xff\x00", "nul": "\x00"} {
+		d, err := ParseDelimiter(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, d, in)
+	}
+	_, err := ParseDelimiter("")
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package serial
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// An Encoder frames payloads for writing, the counterpart of a Framer, so
+// that request/response protocols use the same framing both ways. SLIP,
+// HDLC, DLE and COBS are Encoders, as are the built-in "lines", "nmea",
+// "hex" and "base64" framers returned by NewFramer.
+type Encoder interface {
+	Encode(payload []byte) []byte
+}
+
+// EncoderFunc adapts an ordinary function to the Encoder interface.
+type EncoderFunc func(payload []byte) []byte
+
+// Encode calls f(payload).
+func (f EncoderFunc) Encode(payload []byte) []byte { return f(payload) }
+
+// ChainEncoders returns an Encoder that applies encs in order, the first
+// innermost: ChainEncoders(AppendCRC(CRC16CCITT, 2, binary.BigEndian),
+// COBS{}) appends a CRC and COBS-encodes payload and CRC together.
+func ChainEncoders(encs ...Encoder) Encoder {
+	return EncoderFunc(func(payload []byte) []byte {
+		for _, e := range encs {
+			payload = e.Encode(payload)
+		}
+		return payload
+	})
+}
+
+// AppendCRC returns an Encoder that appends the checksum of the payload as
+// size bytes (1, 2 or 4) in the given byte order, e.g.
+// AppendCRC(CRC16Modbus, 2, binary.LittleEndian). It panics on other sizes.
+func AppendCRC(sum ChecksumFunc, size int, order binary.AppendByteOrder) Encoder {
+	if size != 1 && size != 2 && size != 4 {
+		panic(fmt.Sprintf("serial: AppendCRC with size %d", size))
+	}
+	return EncoderFunc(func(payload []byte) []byte {
+		v := sum(payload)
+		out := append(make([]byte, 0, len(payload)+size), payload...)
+		switch size {
+		case 1:
+			return append(out, byte(v))
+		case 2:
+			return order.AppendUint16(out, uint16(v))
+		}
+		return order.AppendUint32(out, v)
+	})
+}
+
+// Delimit returns an Encoder that appends delim to the payload.
+func Delimit(delim string) Encoder {
+	return EncoderFunc(func(payload []byte) []byte {
+		return append(payload[:len(payload):len(payload)], delim...)
+	})
+}
+
+// FrameWriter writes every payload given to Write as one frame, encoded by
+// its Encoder, in a single write to the underlying writer. It is as safe
+// for concurrent use as that writer.
+type FrameWriter struct {
+	w   io.Writer
+	enc Encoder
+}
+
+// NewFrameWriter returns a FrameWriter encoding with enc and writing to w,
+// typically a SerialReader or BufferedWriter.
+func NewFrameWriter(w io.Writer, enc Encoder) *FrameWriter {
+	return &FrameWriter{w: w, enc: enc}
+}
+
+// Write encodes payload and writes the frame. It returns len(payload) once
+// the whole frame is written, and 0 otherwise.
+func (w *FrameWriter) Write(payload []byte) (int, error) {
+	frame := w.enc.Encode(payload)
+	n, err := w.w.Write(frame)
+	if err == nil && n < len(frame) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(payload), nil
+}
+
+// FrameWriter returns a FrameWriter for the port that mirrors the framer
+// named by Config.Framer, which must implement Encoder.
+func (s *SerialReader) FrameWriter() (*FrameWriter, error) {
+	f, err := NewFramer(s.config.Framer, s.config)
+	if err != nil {
+		return nil, s.opErr("write", err)
+	}
+	enc, ok := f.(Encoder)
+	if !ok {
+		return nil, s.opErr("write", fmt.Errorf("framer %q cannot encode", s.config.Framer))
+	}
+	return NewFrameWriter(s, enc), nil
+}
@@ -0,0 +1,73 @@
+package serial
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainEncoders(t *testing.T) {
+	enc := ChainEncoders(AppendCRC(CRC16CCITT, 2, binary.BigEndian), COBS{})
+	payload := []byte{1, 0, 2}
+	frame := enc.Encode(payload)
+	require.Equal(t, []byte{1, 0, 2}, payload)
+
+	_, decoded, err := COBS{}.Frame(frame)
+	require.NoError(t, err)
+	sum := CRC16CCITT(payload)
+	require.Equal(t, append([]byte{1, 0, 2}, byte(sum>>8), byte(sum)), decoded)
+
+	require.Equal(t, []byte("AT\r"), Delimit("\r").Encode([]byte("AT")))
+	require.Equal(t, []byte{7, 0xAB}, AppendCRC(func([]byte) uint32 { return 0x12AB }, 1, binary.LittleEndian).Encode([]byte{7}))
+	require.Panics(t, func() { AppendCRC(CRC8, 3, binary.LittleEndian) })
+}
+
+func TestFramerEncode(t *testing.T) {
+	for _, name := range []string{"lines", "nmea", "slip", "hdlc", "dle", "cobs", "hex", "base64"} {
+		f, err := NewFramer(name, Config{})
+		require.NoError(t, err)
+		enc, ok := f.(Encoder)
+		require.True(t, ok, name)
+		payload := []byte("$GPTXT,hello")
+		data := enc.Encode(payload)
+		var frame []byte
+		for frame == nil && len(data) > 0 { // SLIP leads with an END
+			var advance int
+			advance, frame, err = f.Frame(data)
+			require.NoError(t, err, name)
+			data = data[advance:]
+		}
+		require.Equal(t, payload, frame, name)
+		require.Empty(t, data, name)
+	}
+
+	f := Dearmor(Config{Delimiter: "\n"}, ArmorHex, "TX:", SLIP{})
+	frame := f.(Encoder).Encode([]byte{0xC0, 1})
+	require.Equal(t, "TX:C0DBDC01C0\n", string(frame))
+}
+
+func TestFrameWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFrameWriter(&buf, SLIP{})
+	n, err := w.Write([]byte{1, slipEnd})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []byte{slipEnd, 1, slipEsc, slipEscEnd, slipEnd}, buf.Bytes())
+
+	reader, master := newTestReader(t, Config{Framer: "nmea", Delimiter: "\r\n"})
+	fw, err := reader.FrameWriter()
+	require.NoError(t, err)
+	_, err = fw.Write([]byte("$PUBX,00"))
+	require.NoError(t, err)
+	got := make([]byte, 32)
+	n, err = master.Read(got)
+	require.NoError(t, err)
+	require.Equal(t, "$PUBX,00*33\r\n", string(got[:n]))
+
+	RegisterFramer("test-decode-only", func(Config) Framer { return FramerFunc(COBS{}.Frame) })
+	reader, _ = newTestReader(t, Config{Framer: "test-decode-only"})
+	_, err = reader.FrameWriter()
+	require.Error(t, err)
+}
@@ -0,0 +1,144 @@
+package serial
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// openURL opens an endpoint given as a URL in cfg.Device:
+//
+//	serial:///dev/ttyUSB0?baud=115200&parity=even
+//	tcp://host:4001
+//	rfc2217://host:4001?baud=9600
+//	udp://host:5000
+//	unix:///run/sim.sock
+//	stdin://
+//
+// Query parameters baud, databits, parity (none, odd, even, mark, space),
+// stopbits, rtscts, xonxoff, dtr and rts (on, off or default), delim (see
+// ParseDelimiter, or auto for DetectDelimiter), term (a Terminator regexp), framer (a registered framer
+// name) and timeout (a ReadTimeout duration) override the corresponding
+// Config fields.
+func openURL(cfg Config) (*SerialReader, error) {
+	u, err := url.Parse(cfg.Device)
+	if err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "parse url", Err: err}
+	}
+	if err := applyQuery(&cfg, u.Query()); err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "parse url", Err: err}
+	}
+	switch u.Scheme {
+	case "serial":
+		cfg.Device = u.Path
+		if err := cfg.Validate(); err != nil {
+			return nil, &SerialError{Device: cfg.Device, Op: "open", Err: err}
+		}
+		return openWith(cfg, openPort)
+	case "tcp":
+		return DialTCP(u.Host, cfg)
+	case "rfc2217":
+		return DialRFC2217(u.Host, cfg)
+	case "udp":
+		return DialUDP(u.Host, cfg, UDPOptions{})
+	case "unix":
+		return DialUnix(u.Path, cfg)
+	case "stdin":
+		return OpenStream(os.Stdin, cfg)
+	}
+	return nil, &SerialError{Device: cfg.Device, Op: "open", Err: fmt.Errorf("unsupported scheme %q", u.Scheme)}
+}
+
+// applyQuery sets line settings from URL query parameters.
+func applyQuery(cfg *Config, q url.Values) error {
+	for key, vals := range q {
+		v := vals[len(vals)-1]
+		var err error
+		switch key {
+		case "baud":
+			cfg.BaudRate, err = strconv.Atoi(v)
+		case "databits":
+			cfg.DataBits, err = strconv.Atoi(v)
+		case "stopbits":
+			cfg.StopBits, err = strconv.Atoi(v)
+		case "parity":
+			cfg.Parity, err = ParseParity(v)
+		case "rtscts":
+			cfg.RTSCTS, err = strconv.ParseBool(v)
+		case "xonxoff":
+			cfg.XONXOFF, err = strconv.ParseBool(v)
+		case "dtr":
+			err = cfg.InitialDTR.UnmarshalText([]byte(v))
+		case "rts":
+			err = cfg.InitialRTS.UnmarshalText([]byte(v))
+		case "delim":
+			if v == "auto" {
+				cfg.Delimiter, cfg.DetectDelimiter = "", true
+				break
+			}
+			cfg.Delimiter, err = ParseDelimiter(v)
+		case "term":
+			cfg.Terminator, err = regexp.Compile(v)
+		case "framer":
+			cfg.Framer = v
+		case "timeout":
+			cfg.ReadTimeout, err = time.ParseDuration(v)
+		default:
+			err = fmt.Errorf("unknown parameter")
+		}
+		if err != nil {
+			return fmt.Errorf("%s=%s: %w", key, v, err)
+		}
+	}
+	return nil
+}
+
+// ParseParity parses a parity name: none, odd, even, mark or space, or its
+// first letter, in any case.
+func ParseParity(s string) (Parity, error) {
+	switch strings.ToLower(s) {
+	case "none", "n":
+		return ParityNone, nil
+	case "odd", "o":
+		return ParityOdd, nil
+	case "even", "e":
+		return ParityEven, nil
+	case "mark", "m":
+		return ParityMark, nil
+	case "space", "s":
+		return ParitySpace, nil
+	}
+	return 0, fmt.Errorf("invalid parity %q", s)
+}
+
+var parityNames = [...]string{"none", "odd", "even", "mark", "space"}
+
+// String returns the parity name accepted by ParseParity.
+func (p Parity) String() string {
+	if p < 0 || int(p) >= len(parityNames) {
+		return "Parity(" + strconv.Itoa(int(p)) + ")"
+	}
+	return parityNames[p]
+}
+
+// MarshalText encodes p by name, for configuration files.
+func (p Parity) MarshalText() ([]byte, error) {
+	if p < 0 || int(p) >= len(parityNames) {
+		return nil, fmt.Errorf("invalid parity %d", int(p))
+	}
+	return []byte(parityNames[p]), nil
+}
+
+// UnmarshalText decodes a name accepted by ParseParity.
+func (p *Parity) UnmarshalText(b []byte) error {
+	v, err := ParseParity(string(b))
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
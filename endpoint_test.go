@@ -0,0 +1,62 @@
+package serial
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenURL(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+
+	reader, err := Open(Config{Device: "serial://" + slave.Name() + "?baud=9600&stopbits=2", Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, slave.Name(), reader.config.Device)
+	require.Equal(t, 9600, reader.config.BaudRate)
+	require.Equal(t, 2, reader.config.StopBits)
+	_, err = master.Write([]byte("tty\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "tty", line)
+
+	for _, network := range []string{"tcp", "unix"} {
+		addr := "127.0.0.1:0"
+		if network == "unix" {
+			addr = filepath.Join(t.TempDir(), "sim.sock")
+		}
+		l, err := net.Listen(network, addr)
+		require.NoError(t, err)
+		defer l.Close()
+		go func() {
+			if c, err := l.Accept(); err == nil {
+				c.Write([]byte(network + "\n"))
+				defer c.Close()
+				c.Read(make([]byte, 1))
+			}
+		}()
+		url := "tcp://" + l.Addr().String()
+		if network == "unix" {
+			url = "unix://" + addr
+		}
+		r, err := Open(Config{Device: url, Delimiter: "\n"})
+		require.NoError(t, err, url)
+		line, err := r.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, network, line)
+		r.Close()
+	}
+
+	_, err = Open(Config{Device: "gopher://host"})
+	require.ErrorContains(t, err, "unsupported scheme")
+	_, err = Open(Config{Device: "serial:///dev/null?parity=sometimes"})
+	require.ErrorContains(t, err, "invalid parity")
+	_, err = Open(Config{Device: "serial:///dev/null?speed=9600"})
+	require.ErrorContains(t, err, "unknown parameter")
+}
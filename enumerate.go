@@ -0,0 +1,58 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PortInfo describes a serial port found by ListPorts.
+type PortInfo struct {
+	Name   string // kernel name, e.g. "ttyUSB0"
+	Device string // device node, e.g. "/dev/ttyUSB0"
+	Driver string // kernel driver, e.g. "ftdi_sio", "cdc_acm", "serial8250"
+	ByID   string // stable /dev/serial/by-id link, if udev created one
+
+	// USB identity; empty for other buses.
+	VID, PID     string // hex vendor and product IDs, e.g. "0403", "6001"
+	SerialNumber string
+	Manufacturer string
+	Product      string
+
+	// LockPID is the process holding a UUCP lock file on the port
+	// (/var/lock/LCK..ttyUSB0 or /run/lock/LCK..ttyUSB0), or 0 if none.
+	// Stale locks of processes that no longer exist are ignored.
+	LockPID int
+}
+
+// Paths scanned by ListPorts; variables so tests can use a fake tree.
+var (
+	devDir   = "/dev"
+	lockDirs = []string{"/var/lock", "/run/lock"}
+)
+
+// ListPorts returns the serial ports present on the system, sorted by name.
+// Virtual terminals and pseudo terminals are not included, and neither are
+// 8250 UART slots without hardware behind them.
+//
+// On macOS and FreeBSD, where there is no sysfs, only Name, Device and
+// LockPID are filled in, from the callout devices (/dev/cu.*, /dev/cuau*).
+// On Windows Name and Device are both the COM port name, e.g. "COM3".
+func ListPorts() ([]PortInfo, error) {
+	ports, err := listPorts()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Name < ports[j].Name })
+	return ports, nil
+}
+
+// readAttr returns the trimmed content of a sysfs attribute, or "".
+func readAttr(dir, name string) string {
+	b, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
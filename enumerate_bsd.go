@@ -0,0 +1,31 @@
+//go:build darwin || freebsd
+
+package serial
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// calloutPatterns match the callout devices of USB and on-board UARTs: the
+// nodes that open without waiting for carrier.
+var calloutPatterns = []string{"cu.*", "cuaU*", "cuau*"}
+
+// listPorts describes the callout devices in devDir.
+func listPorts() ([]PortInfo, error) {
+	var ports []PortInfo
+	for _, pat := range calloutPatterns {
+		matches, err := filepath.Glob(filepath.Join(devDir, pat))
+		if err != nil {
+			return nil, err
+		}
+		for _, dev := range matches {
+			name := filepath.Base(dev)
+			if strings.HasSuffix(name, ".init") || strings.HasSuffix(name, ".lock") {
+				continue // FreeBSD termios init/lock state nodes
+			}
+			ports = append(ports, PortInfo{Name: name, Device: dev, LockPID: lockOwner(name)})
+		}
+	}
+	return ports, nil
+}
@@ -0,0 +1,70 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// sysClassTTY is the sysfs tty class scanned by listPorts.
+var sysClassTTY = "/sys/class/tty"
+
+// listPorts describes the ttys in sysClassTTY that have hardware behind them.
+func listPorts() ([]PortInfo, error) {
+	entries, err := os.ReadDir(sysClassTTY)
+	if err != nil {
+		return nil, err
+	}
+	byID := byIDLinks(filepath.Join(devDir, "serial", "by-id"))
+	var ports []PortInfo
+	for _, e := range entries {
+		dir := filepath.Join(sysClassTTY, e.Name())
+		dev, err := filepath.EvalSymlinks(filepath.Join(dir, "device"))
+		if err != nil {
+			continue // no hardware device: a virtual terminal
+		}
+		if readAttr(dir, "type") == "0" {
+			continue // PORT_UNKNOWN: a UART slot with nothing behind it
+		}
+		p := PortInfo{
+			Name:   e.Name(),
+			Device: filepath.Join(devDir, e.Name()),
+		}
+		if drv, err := os.Readlink(filepath.Join(dev, "driver")); err == nil {
+			p.Driver = filepath.Base(drv)
+		}
+		p.ByID = byID[p.Device]
+		// USB attributes live on the usb_device, a few levels above the
+		// interface (cdc_acm) or usb-serial port (ftdi_sio, cp210x, ...).
+		for d, i := dev, 0; i < 4 && d != "/"; d, i = filepath.Dir(d), i+1 {
+			if vid := readAttr(d, "idVendor"); vid != "" {
+				p.VID = vid
+				p.PID = readAttr(d, "idProduct")
+				p.SerialNumber = readAttr(d, "serial")
+				p.Manufacturer = readAttr(d, "manufacturer")
+				p.Product = readAttr(d, "product")
+				break
+			}
+		}
+		p.LockPID = lockOwner(e.Name())
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// byIDLinks maps device nodes to the by-id links pointing at them.
+func byIDLinks(dir string) map[string]string {
+	links := make(map[string]string)
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		link := filepath.Join(dir, e.Name())
+		target, err := os.Readlink(link)
+		if err != nil {
+			continue
+		}
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(dir, target)
+		}
+		links[filepath.Clean(target)] = link
+	}
+	return links
+}
@@ -0,0 +1,71 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSysfs builds a /sys, /dev and lock directory tree with a USB FTDI
+// adapter, an unpopulated 8250 slot and a virtual terminal.
+func fakeSysfs(t *testing.T) {
+	t.Helper()
+	root := t.TempDir()
+	mkdir := func(p string) string {
+		p = filepath.Join(root, p)
+		require.NoError(t, os.MkdirAll(p, 0755))
+		return p
+	}
+	write := func(dir, name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content+"\n"), 0644))
+	}
+
+	usb := mkdir("sys/devices/usb1/1-1")
+	write(usb, "idVendor", "0403")
+	write(usb, "idProduct", "6001")
+	write(usb, "serial", "A50285BI")
+	write(usb, "manufacturer", "FTDI")
+	write(usb, "product", "FT232R USB UART")
+	port := mkdir("sys/devices/usb1/1-1/1-1:1.0/ttyUSB0")
+	drv := mkdir("sys/bus/usb-serial/drivers/ftdi_sio")
+	require.NoError(t, os.Symlink(drv, filepath.Join(port, "driver")))
+	uart := mkdir("sys/devices/platform/serial8250")
+
+	class := mkdir("sys/class/tty")
+	for name, dev := range map[string]string{"ttyUSB0": port, "ttyS0": uart} {
+		dir := mkdir("sys/class/tty/" + name)
+		require.NoError(t, os.Symlink(dev, filepath.Join(dir, "device")))
+	}
+	write(filepath.Join(class, "ttyS0"), "type", "0")
+	mkdir("sys/class/tty/tty1")
+
+	byID := mkdir("dev/serial/by-id")
+	require.NoError(t, os.Symlink("../../ttyUSB0", filepath.Join(byID, "usb-FTDI_FT232R_USB_UART_A50285BI-if00-port0")))
+	lock := mkdir("lock")
+	write(lock, "LCK..ttyUSB0", "    "+strconv.Itoa(os.Getpid()))
+
+	oldSys, oldDev, oldLock := sysClassTTY, devDir, lockDirs
+	t.Cleanup(func() { sysClassTTY, devDir, lockDirs = oldSys, oldDev, oldLock })
+	sysClassTTY, devDir, lockDirs = class, filepath.Join(root, "dev"), []string{lock}
+}
+
+func TestListPorts(t *testing.T) {
+	fakeSysfs(t)
+	ports, err := ListPorts()
+	require.NoError(t, err)
+	require.Len(t, ports, 1)
+	p := ports[0]
+	require.Equal(t, "ttyUSB0", p.Name)
+	require.Equal(t, filepath.Join(devDir, "ttyUSB0"), p.Device)
+	require.Equal(t, "ftdi_sio", p.Driver)
+	require.Equal(t, filepath.Join(devDir, "serial/by-id/usb-FTDI_FT232R_USB_UART_A50285BI-if00-port0"), p.ByID)
+	require.Equal(t, "0403", p.VID)
+	require.Equal(t, "6001", p.PID)
+	require.Equal(t, "A50285BI", p.SerialNumber)
+	require.Equal(t, "FTDI", p.Manufacturer)
+	require.Equal(t, "FT232R USB UART", p.Product)
+	require.Equal(t, os.Getpid(), p.LockPID)
+}
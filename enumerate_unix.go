@@ -0,0 +1,23 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// lockOwner returns the live process holding the UUCP lock file of the tty
+// name, or 0. Lock files hold the PID in ASCII.
+func lockOwner(name string) int {
+	for _, dir := range lockDirs {
+		pid, err := strconv.Atoi(readAttr(dir, "LCK.."+name))
+		if err != nil || pid <= 0 {
+			continue
+		}
+		if err := syscall.Kill(pid, 0); err == nil || err == syscall.EPERM {
+			return pid
+		}
+	}
+	return 0
+}
@@ -0,0 +1,33 @@
+package serial
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// listPorts describes the COM ports the serial drivers registered under
+// HARDWARE\DEVICEMAP\SERIALCOMM. Only Name and Device are filled in.
+func listPorts() ([]PortInfo, error) {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `HARDWARE\DEVICEMAP\SERIALCOMM`, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil, nil // created with the first port
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer k.Close()
+	names, err := k.ReadValueNames(0)
+	if err != nil {
+		return nil, err
+	}
+	var ports []PortInfo
+	for _, n := range names {
+		com, _, err := k.GetStringValue(n)
+		if err != nil {
+			continue
+		}
+		ports = append(ports, PortInfo{Name: com, Device: com})
+	}
+	return ports, nil
+}
@@ -0,0 +1,156 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Sentinel errors returned (possibly wrapped) by SerialReader. Use errors.Is to test for them.
+var (
+	// ErrClosed is returned when the reader has been closed.
+	ErrClosed = errors.New("serialreader closed")
+	// ErrTimeout is returned when Config.ReadTimeout elapses before a full line arrives.
+	ErrTimeout = errors.New("read timeout")
+	// ErrDeviceRemoved is returned when the device disappears, e.g. a USB adapter is unplugged.
+	ErrDeviceRemoved = errors.New("device removed")
+	// ErrPortBusy is returned by Open when the device is in use elsewhere.
+	ErrPortBusy = errors.New("port busy")
+	// ErrLineTooLong is returned when a line exceeds Config.MaxLineLength.
+	ErrLineTooLong = errors.New("line too long")
+	// ErrAccessMode is returned when reading a WriteOnly port or writing a ReadOnly one.
+	ErrAccessMode = errors.New("not permitted by access mode")
+	// ErrInvalidConfig is matched by every error from Config.Validate.
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+// SerialError records a failed operation on a specific device, so multi-port
+// applications can tell which port failed and why. It unwraps to the
+// underlying cause, so errors.Is works with the sentinel errors above,
+// syscall errnos and fs.ErrPermission.
+type SerialError struct {
+	Device string // device path from Config
+	Op     string // operation, e.g. "open", "set termios", "read", "write"
+	Err    error  // underlying cause
+}
+
+func (e *SerialError) Error() string {
+	return e.Op + " " + e.Device + ": " + e.Err.Error()
+}
+
+func (e *SerialError) Unwrap() error {
+	return e.Err
+}
+
+// Errno returns the system error number behind the failure, or 0 if there is none.
+func (e *SerialError) Errno() syscall.Errno {
+	var errno syscall.Errno
+	if errors.As(e.Err, &errno) {
+		return errno
+	}
+	return 0
+}
+
+// IsPermission reports whether the failure was caused by insufficient permissions.
+func (e *SerialError) IsPermission() bool {
+	return errors.Is(e.Err, fs.ErrPermission)
+}
+
+// IsDisconnect reports whether the device went away.
+func (e *SerialError) IsDisconnect() bool {
+	return errors.Is(e.Err, ErrDeviceRemoved)
+}
+
+// IsConfig reports whether the configuration was invalid or rejected by the
+// device.
+func (e *SerialError) IsConfig() bool {
+	return strings.HasSuffix(e.Op, "termios") || errors.Is(e.Err, syscall.EINVAL) ||
+		errors.Is(e.Err, ErrInvalidConfig)
+}
+
+// DisconnectedError reports that the device went away (EIO, ENODEV, ENXIO or a
+// hang-up, typically a USB adapter being unplugged), as opposed to a transient
+// condition. It matches ErrDeviceRemoved with errors.Is, so supervisors can
+// choose between reconnecting and aborting.
+type DisconnectedError struct {
+	Time         time.Time // when the disconnect was detected
+	BytesRead    uint64    // bytes read since Open
+	BytesWritten uint64    // bytes written since Open
+	Err          error     // underlying cause, e.g. syscall.EIO
+}
+
+func (e *DisconnectedError) Error() string {
+	if e.Err == nil || e.Err == ErrDeviceRemoved {
+		return ErrDeviceRemoved.Error()
+	}
+	return ErrDeviceRemoved.Error() + ": " + e.Err.Error()
+}
+
+func (e *DisconnectedError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrDeviceRemoved.
+func (e *DisconnectedError) Is(target error) bool {
+	return target == ErrDeviceRemoved
+}
+
+// opErr wraps err in a SerialError for this reader's device after mapping it
+// onto the sentinel errors. nil and ErrClosed are returned unchanged.
+func (s *SerialReader) opErr(op string, err error) error {
+	if err == nil || err == ErrClosed {
+		return err
+	}
+	if errors.Is(err, fs.ErrClosed) {
+		return ErrClosed
+	}
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		err = pe.Err // SerialError already names the device
+	}
+	if isDisconnect(err) {
+		err = &DisconnectedError{
+			Time:         time.Now(),
+			BytesRead:    s.bytesRead.Load(),
+			BytesWritten: s.bytesWritten.Load(),
+			Err:          err,
+		}
+	} else if errors.Is(err, syscall.EBADF) {
+		err = fmt.Errorf("%w: %w", ErrClosed, err)
+	}
+	return &SerialError{Device: s.config.Device, Op: op, Err: err}
+}
+
+// isDisconnect reports whether err means the device is gone for good.
+// With VMIN=1 a zero-byte read (io.EOF) means the tty was hung up. A
+// Bluetooth RFCOMM tty whose link drops fails reads with ECONNRESET,
+// ENOTCONN or EHOSTDOWN instead.
+func isDisconnect(err error) bool {
+	return err == io.EOF ||
+		errors.Is(err, ErrDeviceRemoved) ||
+		errors.Is(err, syscall.EIO) ||
+		errors.Is(err, syscall.ENODEV) ||
+		errors.Is(err, syscall.ENXIO) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ENOTCONN) ||
+		errors.Is(err, syscall.EHOSTDOWN)
+}
+
+// isRecoverable reports whether err leaves the port usable, so a read loop
+// may carry on after reporting it. EINTR never gets this far: poll and read
+// are retried internally when interrupted by a signal.
+func isRecoverable(err error) bool {
+	return errors.Is(err, syscall.EAGAIN) ||
+		errors.Is(err, ErrLineTooLong) ||
+		errors.Is(err, ErrBadFrame) ||
+		errors.Is(err, ErrChecksum)
+}
+
+// keepGoing reports whether the read loop should continue after err.
+func (s *SerialReader) keepGoing(err error) bool {
+	return s.config.ContinueOnError && isRecoverable(err)
+}
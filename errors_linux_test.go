@@ -0,0 +1,45 @@
+package serial
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSerialReader_SignalsDoNotInterrupt(t *testing.T) {
+	reader, master := newTestReader(t, Config{ReadTimeout: time.Second})
+
+	sigs := make(chan os.Signal, 64)
+	signal.Notify(sigs, syscall.SIGUSR1)
+	defer signal.Stop(sigs)
+
+	// Pin the reading goroutine to a thread so signals hit the blocked poll.
+	tid := make(chan int, 1)
+	result := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		tid <- unix.Gettid()
+		line, err := reader.ReadLine()
+		if err == nil && line != "after signals" {
+			err = errors.New("unexpected line " + line)
+		}
+		result <- err
+	}()
+
+	target := <-tid
+	for i := 0; i < 20; i++ {
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, unix.Tgkill(os.Getpid(), target, unix.SIGUSR1))
+	}
+	_, err := master.Write([]byte("after signals\n"))
+	require.NoError(t, err)
+	require.NoError(t, <-result)
+}
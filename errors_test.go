@@ -0,0 +1,171 @@
+package serial
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_ReadLineTimeout(t *testing.T) {
+	reader, master := newTestReader(t, Config{ReadTimeout: 30 * time.Millisecond})
+
+	start := time.Now()
+	_, err := reader.ReadLine()
+	require.ErrorIs(t, err, ErrTimeout)
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	_, err = master.Write([]byte("in time\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "in time", line)
+}
+
+func TestSerialReader_ErrClosed(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := reader.ReadLine()
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, reader.Close())
+
+	select {
+	case err := <-result:
+		require.ErrorIs(t, err, ErrClosed)
+		require.Equal(t, "serialreader closed", err.Error())
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("ReadLine did not return after Close")
+	}
+
+	_, err := reader.ReadLine()
+	require.ErrorIs(t, err, ErrClosed)
+	require.ErrorIs(t, reader.WriteLine("x", "\n"), ErrClosed)
+}
+
+func TestSerialReader_ErrDeviceRemoved(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	require.NoError(t, master.Close())
+
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, ErrDeviceRemoved)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for error after device disconnect")
+	}
+}
+
+func TestSerialReader_ErrLineTooLong(t *testing.T) {
+	reader, master := newTestReader(t, Config{MaxLineLength: 16})
+
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	_, err := master.Write([]byte(strings.Repeat("x", 32)))
+	require.NoError(t, err)
+
+	select {
+	case err := <-errs:
+		require.True(t, errors.Is(err, ErrLineTooLong))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for ErrLineTooLong")
+	}
+}
+
+func TestSerialReader_ContinueOnError(t *testing.T) {
+	reader, master := newTestReader(t, Config{MaxLineLength: 8, ContinueOnError: true})
+
+	lines := make(chan string, 1)
+	errs := make(chan error, 2)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) { errs <- err })
+
+	_, err := master.Write([]byte(strings.Repeat("x", 16)))
+	require.NoError(t, err)
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, ErrLineTooLong)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for ErrLineTooLong")
+	}
+
+	// The loop survived and resynchronises on the next delimiter.
+	_, err = master.Write([]byte("\nok\n"))
+	require.NoError(t, err)
+	for {
+		select {
+		case l := <-lines:
+			if l == "" {
+				continue // tail of the discarded line
+			}
+			require.Equal(t, "ok", l)
+			return
+		case err := <-errs:
+			t.Fatalf("unexpected error: %v", err)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("loop stopped after recoverable error")
+		}
+	}
+}
+
+func TestSerialError(t *testing.T) {
+	_, err := Open(Config{Device: "/dev/does-not-exist", BaudRate: 115200, Delimiter: "\n"})
+	var serr *SerialError
+	require.ErrorAs(t, err, &serr)
+	require.Equal(t, "/dev/does-not-exist", serr.Device)
+	require.Equal(t, "open", serr.Op)
+	require.Equal(t, syscall.ENOENT, serr.Errno())
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	require.False(t, serr.IsPermission())
+	require.Equal(t, "open /dev/does-not-exist: no such file or directory", err.Error())
+
+	reader, master := newTestReader(t, Config{})
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	require.NoError(t, master.Close())
+	select {
+	case err := <-errs:
+		require.ErrorAs(t, err, &serr)
+		require.Equal(t, reader.config.Device, serr.Device)
+		require.True(t, serr.IsDisconnect())
+		require.False(t, serr.IsConfig())
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for disconnect error")
+	}
+}
+
+func TestSerialReader_DisconnectedError(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	lines := make(chan string, 1)
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) { errs <- err })
+
+	require.NoError(t, reader.WriteLine("cmd", "\n"))
+	_, err := master.Write([]byte("sample\n"))
+	require.NoError(t, err)
+	<-lines
+
+	before := time.Now()
+	require.NoError(t, master.Close())
+	select {
+	case err := <-errs:
+		var de *DisconnectedError
+		require.ErrorAs(t, err, &de)
+		require.ErrorIs(t, err, ErrDeviceRemoved)
+		require.EqualValues(t, len("sample\n"), de.BytesRead)
+		require.EqualValues(t, len("cmd\n"), de.BytesWritten)
+		require.False(t, de.Time.Before(before))
+		require.False(t, isRecoverable(err))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for disconnect error")
+	}
+}
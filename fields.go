@@ -0,0 +1,373 @@
+package serial
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrMissingField reports a line with fewer fields than the record needs.
+	ErrMissingField = errors.New("missing field")
+	// ErrFieldCount reports a line whose field count does not match the
+	// header or record in CSVStrict mode.
+	ErrFieldCount = errors.New("wrong number of fields")
+	// ErrSkipLine is returned for lines that carry no record, such as CSV
+	// headers. Decode drops lines whose parse function returns it.
+	ErrSkipLine = errors.New("skip line")
+)
+
+// CSVMode selects how a FieldDecoder treats lines that do not fit the record
+// exactly.
+type CSVMode int
+
+const (
+	// CSVDefault fails on missing fields and ignores extra ones.
+	CSVDefault CSVMode = iota
+	// CSVStrict also fails when the field count differs from the header, or
+	// from the highest tagged index without a header.
+	CSVStrict
+	// CSVLenient leaves missing and empty fields, and columns absent from
+	// the header, at their zero value.
+	CSVLenient
+)
+
+// FieldError reports a field that could not be decoded.
+type FieldError struct {
+	Field string // struct field name
+	Index int    // position in the line
+	Value string // raw field text; empty if missing
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %d (%s) %q: %v", e.Index, e.Field, e.Value, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// FieldDecoder maps the delimited fields of a line onto a struct, e.g.
+//
+//	type Sample struct {
+//	    Seq  uint32  `serial:"0"`
+//	    Z    int32   `serial:"1"`
+//	    N    int32   `serial:"2"`
+//	    E    int32   `serial:"3"`
+//	    Temp float64 `serial:"5"`
+//	}
+//
+// The serial tag gives the zero-based field index, or a column name to look
+// up in a header line (`serial:"temp"`); fields tagged "-" are skipped. If no
+// field is tagged, exported fields map to consecutive line fields in
+// declaration order. Supported field types are strings, bools, integers,
+// floats, time.Duration, time.Time (RFC 3339) and any
+// encoding.TextUnmarshaler.
+//
+// With column names, the first line that has all of them is taken as the
+// header, and so is any later line equal to it, as devices print it again
+// after a reset; Decode returns ErrSkipLine for those. SetHeader sets the
+// header up front.
+//
+// A FieldDecoder is safe for concurrent use and counts decoded lines and
+// failures.
+type FieldDecoder struct {
+	typ    reflect.Type
+	sep    string
+	csv    bool // quoted fields
+	mode   CSVMode
+	fields []fieldMapping
+	named  bool // some fields map by column name
+
+	layout  atomic.Pointer[fieldLayout]
+	decoded atomic.Uint64
+	errors  atomic.Uint64
+}
+
+type fieldMapping struct {
+	name   string
+	column string // header name, or "" to map by index
+	index  int    // position in the line, -1 if absent from the header
+	path   []int  // reflect field index
+}
+
+// fieldLayout is the mapping in effect, resolved against the header if any.
+type fieldLayout struct {
+	header []string
+	fields []fieldMapping
+	width  int // fields expected in a line
+}
+
+// NewFieldDecoder returns a decoder for records of the type of v, a struct
+// or pointer to struct. sep separates fields; "" splits on runs of
+// whitespace. Fields are trimmed of surrounding spaces.
+func NewFieldDecoder(v any, sep string) (*FieldDecoder, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("serial: FieldDecoder needs a struct, got %T", v)
+	}
+	d := &FieldDecoder{typ: t, sep: sep}
+	tagged := false
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("serial"); ok {
+			tagged = true
+		}
+	}
+	next := 0
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("serial")
+		if tag == "-" || (tagged && !ok) {
+			continue
+		}
+		m := fieldMapping{name: f.Name, index: next, path: f.Index}
+		if tagged {
+			n, err := strconv.Atoi(tag)
+			switch {
+			case err != nil && tag != "":
+				m.column, m.index = tag, -1
+				d.named = true
+			case err != nil || n < 0:
+				return nil, fmt.Errorf("serial: field %s: bad index tag %q", f.Name, tag)
+			default:
+				m.index = n
+			}
+		}
+		next++
+		if !decodable(f.Type) {
+			return nil, fmt.Errorf("serial: field %s: unsupported type %s", f.Name, f.Type)
+		}
+		d.fields = append(d.fields, m)
+	}
+	if !d.named {
+		l := &fieldLayout{fields: d.fields}
+		for _, f := range d.fields {
+			l.width = max(l.width, f.index+1)
+		}
+		d.layout.Store(l)
+	}
+	return d, nil
+}
+
+// NewCSVDecoder returns a FieldDecoder for comma-separated lines, in which
+// fields may be double-quoted with "" standing for a quote, as in RFC 4180.
+// mode sets how lines that do not fit the record are handled.
+func NewCSVDecoder(v any, mode CSVMode) (*FieldDecoder, error) {
+	if mode < CSVDefault || mode > CSVLenient {
+		return nil, fmt.Errorf("serial: invalid CSV mode %d", mode)
+	}
+	d, err := NewFieldDecoder(v, ",")
+	if err != nil {
+		return nil, err
+	}
+	d.csv, d.mode = true, mode
+	return d, nil
+}
+
+// SetHeader resolves the column names of the serial tags against a header
+// line, ignoring case. Unless the decoder is in CSVLenient mode, every column
+// must be present; in that mode at least one must.
+func (d *FieldDecoder) SetHeader(line string) error {
+	header := d.Split(line)
+	l := &fieldLayout{header: header, width: len(header)}
+	found := false
+	for _, f := range d.fields {
+		if f.column != "" {
+			f.index = slices.IndexFunc(header, func(h string) bool { return strings.EqualFold(h, f.column) })
+			if f.index < 0 && d.mode != CSVLenient {
+				return fmt.Errorf("serial: header %q has no column %q", line, f.column)
+			}
+			found = found || f.index >= 0
+		}
+		l.fields = append(l.fields, f)
+	}
+	if !found && d.named {
+		return fmt.Errorf("serial: header %q has none of the tagged columns", line)
+	}
+	d.layout.Store(l)
+	return nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func decodable(t reflect.Type) bool {
+	if t == timeType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// Split returns the trimmed fields of line.
+func (d *FieldDecoder) Split(line string) []string {
+	if d.sep == "" {
+		return strings.Fields(line)
+	}
+	if d.csv {
+		return splitQuoted(line, d.sep[0])
+	}
+	parts := strings.Split(line, d.sep)
+	for i, p := range parts {
+		parts[i] = strings.TrimSpace(p)
+	}
+	return parts
+}
+
+// splitQuoted splits a CSV line, removing the quotes around fields.
+func splitQuoted(line string, sep byte) []string {
+	var parts []string
+	var field strings.Builder
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quoted && c == '"' && i+1 < len(line) && line[i+1] == '"':
+			field.WriteByte('"')
+			i++
+		case c == '"' && (quoted || strings.TrimSpace(field.String()) == ""):
+			if !quoted {
+				field.Reset()
+			}
+			quoted = !quoted
+		case c == sep && !quoted:
+			parts = append(parts, strings.TrimSpace(field.String()))
+			field.Reset()
+		default:
+			field.WriteByte(c)
+		}
+	}
+	return append(parts, strings.TrimSpace(field.String()))
+}
+
+// Decode parses line into dst, which must be a pointer to the decoder's
+// record type. On failure it returns a *FieldError for the first bad field.
+// Header lines leave dst alone and return ErrSkipLine.
+func (d *FieldDecoder) Decode(line string, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Type() != d.typ {
+		return fmt.Errorf("serial: Decode needs *%s, got %T", d.typ, dst)
+	}
+	parts := d.Split(line)
+	l := d.layout.Load()
+	if d.named && (l == nil || slices.Equal(parts, l.header)) {
+		if err := d.SetHeader(line); err != nil {
+			d.errors.Add(1)
+			return err
+		}
+		return ErrSkipLine
+	}
+	if err := d.decode(l, parts, rv.Elem()); err != nil {
+		d.errors.Add(1)
+		return err
+	}
+	d.decoded.Add(1)
+	return nil
+}
+
+// FieldParser adapts d to Decode, for a stream of records of type T.
+func FieldParser[T any](d *FieldDecoder) func(line string) (T, error) {
+	return func(line string) (T, error) {
+		var v T
+		err := d.Decode(line, &v)
+		return v, err
+	}
+}
+
+func (d *FieldDecoder) decode(l *fieldLayout, parts []string, rv reflect.Value) error {
+	if d.mode == CSVStrict && len(parts) != l.width {
+		return fmt.Errorf("%w: got %d, want %d", ErrFieldCount, len(parts), l.width)
+	}
+	for _, f := range l.fields {
+		v := rv.FieldByIndex(f.path)
+		if d.mode == CSVLenient && (f.index < 0 || f.index >= len(parts) || parts[f.index] == "") {
+			v.SetZero()
+			continue
+		}
+		if f.index >= len(parts) {
+			return &FieldError{Field: f.name, Index: f.index, Err: ErrMissingField}
+		}
+		if err := setField(v, parts[f.index]); err != nil {
+			return &FieldError{Field: f.name, Index: f.index, Value: parts[f.index], Err: err}
+		}
+	}
+	return nil
+}
+
+// Decoded returns the number of lines decoded successfully.
+func (d *FieldDecoder) Decoded() uint64 { return d.decoded.Load() }
+
+// Errors returns the number of lines that failed to decode.
+func (d *FieldDecoder) Errors() uint64 { return d.errors.Load() }
+
+// setField parses s into v according to v's type.
+func setField(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case v.Type() == timeType:
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
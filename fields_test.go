@@ -0,0 +1,129 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldDecoder_Tags(t *testing.T) {
+	type sample struct {
+		Seq     uint32        `serial:"0"`
+		Z       int32         `serial:"1"`
+		E       int32         `serial:"3"`
+		Temp    float64       `serial:"4"`
+		Latency time.Duration `serial:"5"`
+		Note    string        `serial:"-"`
+	}
+	d, err := NewFieldDecoder(sample{}, ",")
+	require.NoError(t, err)
+
+	var s sample
+	require.NoError(t, d.Decode("17, -1200, 5, 0x10, 23.5, 3ms", &s))
+	require.Equal(t, sample{Seq: 17, Z: -1200, E: 16, Temp: 23.5, Latency: 3 * time.Millisecond}, s)
+
+	err = d.Decode("18,abc,5,1,2,1s", &s)
+	var fe *FieldError
+	require.ErrorAs(t, err, &fe)
+	require.Equal(t, "Z", fe.Field)
+	require.Equal(t, 1, fe.Index)
+	require.Equal(t, "abc", fe.Value)
+
+	err = d.Decode("19,1,2", &s)
+	require.ErrorIs(t, err, ErrMissingField)
+	require.EqualValues(t, 1, d.Decoded())
+	require.EqualValues(t, 2, d.Errors())
+}
+
+func TestFieldDecoder_DeclarationOrder(t *testing.T) {
+	type reading struct {
+		Station string
+		OK      bool
+		Count   int
+		hidden  int
+		At      time.Time
+	}
+	d, err := NewFieldDecoder(&reading{}, "")
+	require.NoError(t, err)
+	var r reading
+	require.NoError(t, d.Decode("STA1  true 42\t2024-07-04T20:15:30Z", &r))
+	require.Equal(t, "STA1", r.Station)
+	require.True(t, r.OK)
+	require.Equal(t, 42, r.Count)
+	require.Equal(t, time.Date(2024, 7, 4, 20, 15, 30, 0, time.UTC), r.At)
+
+	_, err = NewFieldDecoder(struct{ C chan int }{}, ",")
+	require.Error(t, err)
+	require.Error(t, d.Decode("x", &struct{}{}))
+}
+
+func TestCSVDecoder_Header(t *testing.T) {
+	type reading struct {
+		Station string  `serial:"station"`
+		Temp    float64 `serial:"temp"`
+		Seq     int     `serial:"0"`
+	}
+	d, err := NewCSVDecoder(reading{}, CSVDefault)
+	require.NoError(t, err)
+
+	var r reading
+	require.Error(t, d.Decode("boot v1.2", &r))
+	require.ErrorIs(t, d.Decode("seq,TEMP,station", &r), ErrSkipLine)
+	require.NoError(t, d.Decode(`7, 21.5, "North, upper"`, &r))
+	require.Equal(t, reading{Station: "North, upper", Temp: 21.5, Seq: 7}, r)
+
+	// The device prints its header again after a reset.
+	require.ErrorIs(t, d.Decode("seq, TEMP, station", &r), ErrSkipLine)
+	require.NoError(t, d.Decode(`8,-1,"say ""hi"""`, &r))
+	require.Equal(t, reading{Station: `say "hi"`, Temp: -1, Seq: 8}, r)
+	require.EqualValues(t, 2, d.Decoded())
+	require.EqualValues(t, 1, d.Errors())
+}
+
+func TestCSVDecoder_Modes(t *testing.T) {
+	type sample struct {
+		A int `serial:"0"`
+		B int `serial:"2"`
+	}
+	strict, err := NewCSVDecoder(sample{}, CSVStrict)
+	require.NoError(t, err)
+	var s sample
+	require.NoError(t, strict.Decode("1,2,3", &s))
+	require.ErrorIs(t, strict.Decode("1,2,3,4", &s), ErrFieldCount)
+
+	def, err := NewCSVDecoder(sample{}, CSVDefault)
+	require.NoError(t, err)
+	require.NoError(t, def.Decode("1,2,3,4", &s))
+	require.ErrorIs(t, def.Decode("1,2", &s), ErrMissingField)
+
+	lenient, err := NewCSVDecoder(sample{}, CSVLenient)
+	require.NoError(t, err)
+	require.NoError(t, lenient.Decode("5", &s))
+	require.Equal(t, sample{A: 5}, s)
+	require.NoError(t, lenient.Decode(",,6", &s))
+	require.Equal(t, sample{B: 6}, s)
+	require.Error(t, lenient.Decode("x,,6", &s))
+
+	_, err = NewCSVDecoder(sample{}, CSVMode(7))
+	require.Error(t, err)
+}
+
+func TestFieldParser(t *testing.T) {
+	type reading struct {
+		ID   string  `serial:"id"`
+		Volt float64 `serial:"volt"`
+	}
+	d, err := NewCSVDecoder(reading{}, CSVDefault)
+	require.NoError(t, err)
+	reader, master := newTestReader(t, Config{})
+	values, errs := Decode(reader, FieldParser[reading](d))
+	_, err = master.Write([]byte("id,volt\nA,3.3\nB,high\n"))
+	require.NoError(t, err)
+
+	require.Equal(t, reading{"A", 3.3}, <-values)
+	var fe *FieldError
+	require.ErrorAs(t, <-errs, &fe)
+	require.Equal(t, "Volt", fe.Field)
+	require.EqualValues(t, 1, reader.Stats().ParseErrors)
+}
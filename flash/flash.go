@@ -0,0 +1,190 @@
+// Package flash pushes firmware images to microcontrollers through their
+// serial bootloaders, so field updates need no external tool competing for
+// the port.
+//
+// A Flasher resets the target into its bootloader by sequencing DTR, RTS
+// and break, switches to the bootloader's baud rate, and transfers the
+// image with XMODEM or STK500v1 (the Arduino/Optiboot protocol). Images in
+// Intel HEX format can be loaded with ReadIntelHex.
+package flash
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/xmodem"
+)
+
+// ErrVerify reports flash contents that differ from the image after an
+// upload.
+var ErrVerify = errors.New("flash: verification failed")
+
+// Port is the connection to the target. *serial.SerialReader implements it.
+type Port interface {
+	xmodem.Transport
+	SetDTR(on bool) error
+	SetRTS(on bool) error
+	SendBreak(d time.Duration) error
+	Config() serial.Config
+	SetLineSettings(baud, dataBits int, parity serial.Parity, stopBits int) error
+}
+
+// Step is one step of a reset sequence: the modem lines are set, then a
+// break is sent if Break is positive, then the sequence waits for Wait.
+type Step struct {
+	DTR, RTS serial.LineState // LineDefault leaves the line alone
+	Break    time.Duration
+	Wait     time.Duration
+}
+
+var (
+	// ArduinoReset pulses DTR and RTS, which the auto-reset circuit of
+	// Arduino boards couples to the reset pin, starting the bootloader.
+	ArduinoReset = []Step{
+		{DTR: serial.LineOff, RTS: serial.LineOff, Wait: 250 * time.Millisecond},
+		{DTR: serial.LineOn, RTS: serial.LineOn, Wait: 50 * time.Millisecond},
+	}
+	// ESP32Reset holds IO0 low while releasing EN, through the two-transistor
+	// circuit of ESP32 development boards, which starts the ROM loader.
+	ESP32Reset = []Step{
+		{DTR: serial.LineOff, RTS: serial.LineOn, Wait: 100 * time.Millisecond},
+		{DTR: serial.LineOn, RTS: serial.LineOff, Wait: 50 * time.Millisecond},
+		{DTR: serial.LineOff},
+	}
+)
+
+// Protocol is the transfer protocol spoken by the bootloader.
+type Protocol int
+
+const (
+	XMODEM   Protocol = iota // XMODEM-CRC, 128-byte blocks
+	XMODEM1K                 // XMODEM-1K
+	STK500v1                 // Atmel STK500 version 1, as spoken by Optiboot
+)
+
+// Flasher uploads firmware over a Port. A Flasher runs one upload at a
+// time.
+type Flasher struct {
+	// Reset is applied to enter the bootloader. Nil skips the reset, for
+	// targets already waiting in it.
+	Reset []Step
+	// Start is applied after a successful upload to run the new firmware,
+	// if the bootloader does not do so by itself.
+	Start []Step
+	// BaudRate is the speed of the bootloader, switched to after Reset and
+	// restored when Flash returns. Zero keeps the current rate.
+	BaudRate int
+	// Protocol transfers the image.
+	Protocol Protocol
+	// PageSize is the flash page size for STK500v1. Zero means 128 bytes,
+	// as on the ATmega328P.
+	PageSize int
+	// Verify reads an STK500v1 upload back and compares it with the image.
+	Verify bool
+	// Timeout bounds each wait for the bootloader. Zero means one second.
+	Timeout time.Duration
+	// Progress, if set, is called as the upload advances with the bytes
+	// sent so far and the size of the image.
+	Progress func(sent, total int)
+
+	p Port
+}
+
+// New returns a Flasher using p.
+func New(p Port) *Flasher {
+	return &Flasher{p: p}
+}
+
+// Flash resets the target into its bootloader and uploads image, a raw
+// binary starting at address 0.
+func (f *Flasher) Flash(image []byte) error {
+	if err := f.sequence(f.Reset); err != nil {
+		return fmt.Errorf("flash: reset: %w", err)
+	}
+	if f.BaudRate > 0 {
+		cfg := f.p.Config()
+		if err := f.p.SetLineSettings(f.BaudRate, 0, cfg.Parity, 0); err != nil {
+			return fmt.Errorf("flash: set baud rate: %w", err)
+		}
+		defer f.p.SetLineSettings(cfg.BaudRate, 0, cfg.Parity, 0)
+	}
+	var err error
+	switch f.Protocol {
+	case XMODEM, XMODEM1K:
+		c := xmodem.New(f.p)
+		c.Block1K = f.Protocol == XMODEM1K
+		if f.Timeout > 0 {
+			c.Timeout = f.Timeout
+		}
+		err = c.Send(&progressReader{data: image, progress: f.Progress})
+	case STK500v1:
+		err = f.stk500(image)
+	default:
+		err = fmt.Errorf("flash: unknown protocol %d", f.Protocol)
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.sequence(f.Start); err != nil {
+		return fmt.Errorf("flash: start: %w", err)
+	}
+	return nil
+}
+
+// sequence applies the steps of a reset sequence.
+func (f *Flasher) sequence(steps []Step) error {
+	for _, s := range steps {
+		if s.DTR != serial.LineDefault {
+			if err := f.p.SetDTR(s.DTR == serial.LineOn); err != nil {
+				return err
+			}
+		}
+		if s.RTS != serial.LineDefault {
+			if err := f.p.SetRTS(s.RTS == serial.LineOn); err != nil {
+				return err
+			}
+		}
+		if s.Break > 0 {
+			if err := f.p.SendBreak(s.Break); err != nil {
+				return err
+			}
+		}
+		time.Sleep(s.Wait)
+	}
+	return nil
+}
+
+func (f *Flasher) timeout() time.Duration {
+	if f.Timeout > 0 {
+		return f.Timeout
+	}
+	return time.Second
+}
+
+func (f *Flasher) progress(sent, total int) {
+	if f.Progress != nil {
+		f.Progress(sent, total)
+	}
+}
+
+// progressReader reads data, reporting its position.
+type progressReader struct {
+	data     []byte
+	off      int
+	progress func(sent, total int)
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	if r.off == len(r.data) {
+		return 0, io.EOF
+	}
+	n := copy(b, r.data[r.off:])
+	r.off += n
+	if r.progress != nil {
+		r.progress(r.off, len(r.data))
+	}
+	return n, nil
+}
@@ -0,0 +1,193 @@
+package flash
+
+import (
+	"bytes"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/xmodem"
+)
+
+// fakePort is one end of a pipe that records the line changes made to it.
+type fakePort struct {
+	net.Conn
+	mu     sync.Mutex
+	events []string
+	baud   int
+}
+
+func (p *fakePort) record(event string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *fakePort) SetDTR(on bool) error {
+	p.record(map[bool]string{true: "DTR on", false: "DTR off"}[on])
+	return nil
+}
+
+func (p *fakePort) SetRTS(on bool) error {
+	p.record(map[bool]string{true: "RTS on", false: "RTS off"}[on])
+	return nil
+}
+
+func (p *fakePort) SendBreak(time.Duration) error {
+	p.record("break")
+	return nil
+}
+
+func (p *fakePort) Config() serial.Config {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return serial.Config{BaudRate: p.baud}
+}
+
+func (p *fakePort) SetLineSettings(baud, _ int, _ serial.Parity, _ int) error {
+	p.mu.Lock()
+	p.baud = baud
+	p.mu.Unlock()
+	p.record("baud")
+	return nil
+}
+
+func newFakePort(t *testing.T) (*fakePort, net.Conn) {
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	return &fakePort{Conn: a, baud: 9600}, b
+}
+
+// optiboot emulates the STK500v1 side of Optiboot, programming flash,
+// until it leaves programming mode.
+func optiboot(conn net.Conn, flash []byte) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		read := func(n int) []byte {
+			b := make([]byte, n)
+			for got := 0; got < n; {
+				m, err := conn.Read(b[got:])
+				if err != nil {
+					return nil
+				}
+				got += m
+			}
+			return b
+		}
+		reply := func(data ...byte) {
+			conn.Write(append(append([]byte{stkInSync}, data...), stkOK))
+		}
+		addr := 0
+		for {
+			cmd := read(1)
+			if cmd == nil {
+				return
+			}
+			switch cmd[0] {
+			case stkGetSync, stkEnterProg:
+				read(1)
+				reply()
+			case stkLoadAddress:
+				a := read(3)
+				addr = 2 * (int(a[0]) | int(a[1])<<8)
+				reply()
+			case stkProgPage:
+				h := read(3)
+				n := int(h[0])<<8 | int(h[1])
+				copy(flash[addr:], read(n))
+				read(1)
+				reply()
+			case stkReadPage:
+				h := read(4)
+				n := int(h[0])<<8 | int(h[1])
+				reply(flash[addr : addr+n]...)
+			case stkLeaveProg:
+				read(1)
+				reply()
+				return
+			}
+		}
+	}()
+	return done
+}
+
+func TestFlasher_STK500(t *testing.T) {
+	port, conn := newFakePort(t)
+	flash := make([]byte, 1024)
+	done := optiboot(conn, flash)
+
+	image := bytes.Repeat([]byte("firmware"), 40) // 320 bytes, a partial last page
+	var progress []int
+	f := New(port)
+	f.Reset = []Step{{DTR: serial.LineOff, Break: time.Millisecond}, {DTR: serial.LineOn}}
+	f.BaudRate = 115200
+	f.Protocol = STK500v1
+	f.Verify = true
+	f.Progress = func(sent, total int) {
+		require.Equal(t, len(image), total)
+		progress = append(progress, sent)
+	}
+	require.NoError(t, f.Flash(image))
+	<-done
+	require.Equal(t, image, flash[:len(image)])
+	require.Equal(t, []int{128, 256, 320}, progress)
+	require.Equal(t, []string{"DTR off", "break", "DTR on", "baud", "baud"}, port.events)
+	require.Equal(t, 9600, port.baud)
+}
+
+func TestFlasher_XMODEM(t *testing.T) {
+	port, conn := newFakePort(t)
+	received := make(chan []byte, 1)
+	go func() {
+		var buf bytes.Buffer
+		xmodem.New(conn).Receive(&buf)
+		received <- buf.Bytes()
+	}()
+
+	image := bytes.Repeat([]byte{0x55, 0xAA}, 700)
+	f := New(port)
+	f.Protocol = XMODEM1K
+	require.NoError(t, f.Flash(image))
+	got := <-received
+	require.Equal(t, image, got[:len(image)])
+	require.Empty(t, port.events)
+}
+
+func TestFlasher_NoBootloader(t *testing.T) {
+	port, conn := newFakePort(t)
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return
+			}
+		}
+	}()
+	f := New(port)
+	f.Protocol = STK500v1
+	f.Timeout = 20 * time.Millisecond
+	require.ErrorContains(t, f.Flash([]byte{1, 2}), "no sync")
+}
+
+func TestReadIntelHex(t *testing.T) {
+	const file = `:020000040001F9
+:0400100001020304E2
+:02001800AABB81
+:00000001FF
+`
+	image, start, err := ReadIntelHex(strings.NewReader(file))
+	require.NoError(t, err)
+	require.Equal(t, 0x10010, start)
+	require.Equal(t, []byte{1, 2, 3, 4, 0xFF, 0xFF, 0xFF, 0xFF, 0xAA, 0xBB}, image)
+
+	_, _, err = ReadIntelHex(strings.NewReader(":0400100001020304E3\n:00000001FF\n"))
+	require.ErrorContains(t, err, "checksum")
+	_, _, err = ReadIntelHex(strings.NewReader(":0400100001020304E2\n"))
+	require.ErrorContains(t, err, "end-of-file")
+}
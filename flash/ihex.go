@@ -0,0 +1,78 @@
+package flash
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadIntelHex reads an image in Intel HEX format, as produced by avr-gcc
+// and most MCU toolchains, and returns it as a binary together with the
+// address of its first byte, the lowest address in the file. Gaps between
+// records are filled with 0xFF, the erased flash value. Data, end-of-file
+// and extended segment and linear address records are supported.
+func ReadIntelHex(r io.Reader) (image []byte, start int, err error) {
+	type record struct {
+		addr int
+		data []byte
+	}
+	var recs []record
+	base, start, end := 0, -1, 0
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		rec, err := hex.DecodeString(strings.TrimPrefix(line, ":"))
+		if err != nil || !strings.HasPrefix(line, ":") || len(rec) < 5 || len(rec) != int(rec[0])+5 {
+			return nil, 0, fmt.Errorf("flash: intel hex line %d: malformed record", n)
+		}
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0 {
+			return nil, 0, fmt.Errorf("flash: intel hex line %d: checksum mismatch", n)
+		}
+		data := rec[4 : len(rec)-1]
+		switch rec[3] {
+		case 0x00: // data
+			addr := base + (int(rec[1])<<8 | int(rec[2]))
+			recs = append(recs, record{addr, data})
+			if start < 0 || addr < start {
+				start = addr
+			}
+			end = max(end, addr+len(data))
+		case 0x01: // end of file
+			if start < 0 {
+				return nil, 0, nil
+			}
+			image = bytes.Repeat([]byte{0xFF}, end-start)
+			for _, r := range recs {
+				copy(image[r.addr-start:], r.data)
+			}
+			return image, start, nil
+		case 0x02: // extended segment address
+			if len(data) != 2 {
+				return nil, 0, fmt.Errorf("flash: intel hex line %d: malformed record", n)
+			}
+			base = (int(data[0])<<8 | int(data[1])) << 4
+		case 0x04: // extended linear address
+			if len(data) != 2 {
+				return nil, 0, fmt.Errorf("flash: intel hex line %d: malformed record", n)
+			}
+			base = (int(data[0])<<8 | int(data[1])) << 16
+		case 0x03, 0x05: // start address, irrelevant to the image
+		default:
+			return nil, 0, fmt.Errorf("flash: intel hex line %d: unknown record type %#02x", n, rec[3])
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, err
+	}
+	return nil, 0, fmt.Errorf("flash: intel hex: no end-of-file record")
+}
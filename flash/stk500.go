@@ -0,0 +1,135 @@
+package flash
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// STK500 version 1 commands and responses.
+const (
+	stkOK           = 0x10
+	stkInSync       = 0x14
+	stkCRCEOP       = 0x20
+	stkGetSync      = 0x30
+	stkEnterProg    = 0x50
+	stkLeaveProg    = 0x51
+	stkLoadAddress  = 0x55
+	stkProgPage     = 0x64
+	stkReadPage     = 0x74
+	stkMemTypeFlash = 'F'
+)
+
+// stk500 uploads image page by page: sync, enter programming mode, load the
+// address and program each page, optionally read the pages back, and leave
+// programming mode, which starts the firmware.
+func (f *Flasher) stk500(image []byte) error {
+	if err := f.stkSync(); err != nil {
+		return err
+	}
+	if _, err := f.stkCommand([]byte{stkEnterProg}, 0); err != nil {
+		return fmt.Errorf("flash: enter programming mode: %w", err)
+	}
+	page := f.PageSize
+	if page <= 0 {
+		page = 128
+	}
+	for addr := 0; addr < len(image); addr += page {
+		data := image[addr:min(addr+page, len(image))]
+		if err := f.stkLoadAddress(addr); err != nil {
+			return err
+		}
+		cmd := append([]byte{stkProgPage, byte(len(data) >> 8), byte(len(data)), stkMemTypeFlash}, data...)
+		if _, err := f.stkCommand(cmd, 0); err != nil {
+			return fmt.Errorf("flash: program page at %#x: %w", addr, err)
+		}
+		f.progress(addr+len(data), len(image))
+	}
+	if f.Verify {
+		for addr := 0; addr < len(image); addr += page {
+			want := image[addr:min(addr+page, len(image))]
+			if err := f.stkLoadAddress(addr); err != nil {
+				return err
+			}
+			got, err := f.stkCommand([]byte{stkReadPage, byte(len(want) >> 8), byte(len(want)), stkMemTypeFlash}, len(want))
+			if err != nil {
+				return fmt.Errorf("flash: read page at %#x: %w", addr, err)
+			}
+			if !bytes.Equal(got, want) {
+				return fmt.Errorf("%w: page at %#x", ErrVerify, addr)
+			}
+		}
+	}
+	if _, err := f.stkCommand([]byte{stkLeaveProg}, 0); err != nil {
+		return fmt.Errorf("flash: leave programming mode: %w", err)
+	}
+	return nil
+}
+
+// stkSync gets in sync with the bootloader, which may still be starting or
+// have noise from the reset to discard.
+func (f *Flasher) stkSync() error {
+	var err error
+	for range 5 {
+		f.discard()
+		if _, err = f.stkCommand([]byte{stkGetSync}, 0); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("flash: no sync with bootloader: %w", err)
+}
+
+// stkLoadAddress sets the address of the next page command. STK500 flash
+// addresses count 16-bit words.
+func (f *Flasher) stkLoadAddress(addr int) error {
+	word := addr / 2
+	if _, err := f.stkCommand([]byte{stkLoadAddress, byte(word), byte(word >> 8)}, 0); err != nil {
+		return fmt.Errorf("flash: load address %#x: %w", addr, err)
+	}
+	return nil
+}
+
+// stkCommand sends cmd with its end marker and reads the response: INSYNC,
+// n bytes of data, OK.
+func (f *Flasher) stkCommand(cmd []byte, n int) ([]byte, error) {
+	if _, err := f.p.Write(append(cmd, stkCRCEOP)); err != nil {
+		return nil, err
+	}
+	resp, err := f.readFull(n + 2)
+	if err != nil {
+		return nil, err
+	}
+	if resp[0] != stkInSync || resp[n+1] != stkOK {
+		return nil, fmt.Errorf("unexpected response % x", resp)
+	}
+	return resp[1 : n+1], nil
+}
+
+// readFull reads n bytes, waiting at most Timeout.
+func (f *Flasher) readFull(n int) ([]byte, error) {
+	if err := f.p.SetReadDeadline(time.Now().Add(f.timeout())); err != nil {
+		return nil, err
+	}
+	defer f.p.SetReadDeadline(time.Time{})
+	buf := make([]byte, n)
+	for got := 0; got < n; {
+		m, err := f.p.Read(buf[got:])
+		got += m
+		if err != nil && got < n {
+			return nil, err
+		}
+	}
+	return buf, nil
+}
+
+// discard drops input that is already waiting.
+func (f *Flasher) discard() {
+	f.p.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	defer f.p.SetReadDeadline(time.Time{})
+	buf := make([]byte, 256)
+	for {
+		if _, err := f.p.Read(buf); err != nil {
+			return
+		}
+	}
+}
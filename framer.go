@@ -0,0 +1,83 @@
+package serial
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrBadFrame reports a frame that was delimited correctly but failed
+// validation (e.g. a checksum mismatch). It is recoverable: with
+// Config.ContinueOnError, ReadFramesLoop carries on with the next frame.
+var ErrBadFrame = errors.New("malformed frame")
+
+// A Framer splits a byte stream into frames for ReadFramesLoop.
+//
+// Frame is called with all bytes buffered so far. It returns how many of them
+// were consumed and, if a complete frame was found, its decoded payload.
+// A nil frame with a positive advance drops bytes (e.g. noise between frames);
+// advance 0 asks for more data, unless a frame is returned: framers that
+// hold decoded bytes of their own report frames from those with advance 0
+// and are called again. A non-nil error is reported through onError after
+// advance bytes have been dropped. The payload may alias data.
+type Framer interface {
+	Frame(data []byte) (advance int, frame []byte, err error)
+}
+
+// FramerFunc adapts an ordinary function to the Framer interface.
+type FramerFunc func(data []byte) (advance int, frame []byte, err error)
+
+// Frame calls f(data).
+func (f FramerFunc) Frame(data []byte) (int, []byte, error) { return f(data) }
+
+// ReadFramesLoop is ReadLinesLoop for binary protocols: it splits the input
+// with f instead of Config.Delimiter and invokes onFrame for each frame.
+// The frame is only valid during the call; copy it to retain it.
+// Config.MaxLineLength bounds the bytes buffered for an incomplete frame.
+// Line middleware and subscriptions are not involved. Like a partial line,
+// a partial frame is kept for the next ReadFramesLoop. A nil f selects the
+// framer named by Config.Framer.
+func (s *SerialReader) ReadFramesLoop(f Framer, onFrame func([]byte), onError func(error)) {
+	if f == nil {
+		var err error
+		if f, err = NewFramer(s.config.Framer, s.config); err != nil {
+			onError(s.opErr("read", err))
+			return
+		}
+	}
+	p := s.port()
+	pending := s.partial.takeFrame(p)
+	defer func() { s.partial.keepFrame(p, pending) }()
+	s.readChunks(nil, func(chunk []byte, wake time.Time) bool {
+		pending = append(pending, chunk...)
+		for len(pending) > 0 {
+			advance, frame, err := f.Frame(pending)
+			if advance < 0 || advance > len(pending) {
+				advance = len(pending)
+			}
+			if frame != nil {
+				onFrame(frame)
+				if s.latency != nil {
+					s.latency.record(time.Since(wake))
+				}
+			}
+			pending = pending[advance:]
+			if err != nil {
+				err = s.opErr("read", err)
+				onError(err)
+				if !s.keepGoing(err) {
+					return false
+				}
+			}
+			if advance == 0 && frame == nil {
+				break // more data is needed, even after an error
+			}
+		}
+		if s.config.MaxLineLength > 0 && len(pending) > s.config.MaxLineLength {
+			pending = nil
+			err := s.opErr("read", ErrLineTooLong)
+			onError(err)
+			return s.keepGoing(err)
+		}
+		return true
+	}, onError)
+}
@@ -0,0 +1,182 @@
+// Package gcf decodes Güralp Compressed Format (GCF) blocks, the native
+// output of Güralp seismic digitizers, from a serial byte stream. It is also
+// the reference for plugging a vendor's binary format into the serial
+// package: Framer finds blocks with ReadFramesLoop and Decode turns them
+// into samples.
+//
+//	cfg.Framer = "gcf"
+//	reader.ReadFramesLoop(nil, func(b []byte) {
+//		blk, err := gcf.Decode(b)
+//		...
+//	}, onError)
+//
+// A block is a 16-byte header, then for data blocks the first sample as a
+// 32-bit integer, the first differences packed in 4-byte records of one,
+// two or four differences, and the last sample again as an integrity check,
+// all big-endian. Status blocks carry text instead. Framer expects the
+// blocks back to back, as a digitizer sends them with its serial output set
+// to plain GCF, without the block recovery protocol.
+package gcf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+func init() {
+	serial.RegisterFramer("gcf", func(serial.Config) serial.Framer { return Framer{} })
+}
+
+const (
+	headerLen = 16
+	// MaxBlockLen is the size of a full GCF block.
+	MaxBlockLen = 1024
+)
+
+var (
+	// ErrIntegrity reports a data block whose last sample does not match
+	// the sum of its differences.
+	ErrIntegrity = errors.New("gcf: integrity check failed")
+	// ErrFormat reports bytes that are not a GCF block.
+	ErrFormat = errors.New("gcf: invalid block header")
+)
+
+// epoch is day 0 of GCF timestamps.
+var epoch = time.Date(1989, 11, 17, 0, 0, 0, 0, time.UTC)
+
+// Block is a decoded GCF block.
+type Block struct {
+	SystemID   string    // digitizer, e.g. "DM24"
+	StreamID   string    // stream, e.g. "1234Z2"
+	Start      time.Time // time of the first sample
+	SampleRate int       // samples per second; 0 for status blocks
+	Samples    []int32   // nil for status blocks
+	Status     string    // text of a status block
+}
+
+// Framer is a serial.Framer that picks GCF blocks out of a byte stream,
+// registered as "gcf". Blocks are sized from their header; an implausible
+// header, or a status block holding binary data, skips one byte to
+// resynchronise, as does a data block failing its integrity check,
+// reported as serial.ErrBadFrame. The frame passed on is the whole block,
+// for Decode.
+type Framer struct{}
+
+// Frame implements serial.Framer.
+func (Framer) Frame(data []byte) (int, []byte, error) {
+	if len(data) < headerLen {
+		return 0, nil, nil
+	}
+	l, err := blockLen(data)
+	if err != nil {
+		return 1, nil, nil
+	}
+	if len(data) < l {
+		return 0, nil, nil
+	}
+	if _, err := Decode(data[:l]); errors.Is(err, ErrFormat) {
+		return 1, nil, nil
+	} else if err != nil {
+		return 1, nil, fmt.Errorf("%w: %w", serial.ErrBadFrame, err)
+	}
+	return l, data[:l], nil
+}
+
+// blockLen returns the length of the block whose header starts b.
+func blockLen(b []byte) (int, error) {
+	if binary.BigEndian.Uint32(b[8:])&0x1FFFF >= 86400 {
+		return 0, ErrFormat // not a second of the day
+	}
+	rate, format, records := b[13], b[14], int(b[15])
+	if rate == 0 {
+		// Status blocks hold text, stored as 8-bit values.
+		if format != 4 || records == 0 {
+			return 0, ErrFormat
+		}
+		return headerLen + 4*records, nil
+	}
+	switch format {
+	case 1, 2, 4:
+	default:
+		return 0, ErrFormat
+	}
+	l := headerLen + 4 + 4*records + 4
+	if records == 0 || l > MaxBlockLen {
+		return 0, ErrFormat
+	}
+	return l, nil
+}
+
+// Decode decodes one GCF block.
+func Decode(b []byte) (Block, error) {
+	if len(b) < headerLen {
+		return Block{}, ErrFormat
+	}
+	l, err := blockLen(b)
+	if err != nil {
+		return Block{}, err
+	}
+	if len(b) != l {
+		return Block{}, fmt.Errorf("%w: %d bytes, header says %d", ErrFormat, len(b), l)
+	}
+	t := binary.BigEndian.Uint32(b[8:])
+	blk := Block{
+		SystemID:   base36(binary.BigEndian.Uint32(b[0:])),
+		StreamID:   base36(binary.BigEndian.Uint32(b[4:])),
+		Start:      epoch.AddDate(0, 0, int(t>>17)).Add(time.Duration(t&0x1FFFF) * time.Second),
+		SampleRate: int(b[13]),
+	}
+	if blk.SampleRate == 0 {
+		text := b[headerLen:]
+		for _, c := range text {
+			if (c < ' ' || c > '~') && c != '\r' && c != '\n' && c != 0 {
+				return Block{}, fmt.Errorf("%w: status block holds binary data", ErrFormat)
+			}
+		}
+		blk.Status = strings.TrimRight(string(text), "\x00 ")
+		return blk, nil
+	}
+	format, records := int(b[14]), int(b[15])
+	data := b[headerLen+4 : l-4]
+	x := int32(binary.BigEndian.Uint32(b[headerLen:]))
+	blk.Samples = make([]int32, records*format)
+	for i := range blk.Samples {
+		var d int32
+		switch format {
+		case 1:
+			d = int32(binary.BigEndian.Uint32(data[4*i:]))
+		case 2:
+			d = int32(int16(binary.BigEndian.Uint16(data[2*i:])))
+		case 4:
+			d = int32(int8(data[i]))
+		}
+		// The first difference is from the last sample of the previous
+		// block; the first sample is given outright.
+		if i > 0 {
+			x += d
+		}
+		blk.Samples[i] = x
+	}
+	if last := int32(binary.BigEndian.Uint32(b[l-4:])); last != x {
+		return blk, fmt.Errorf("%w: last sample %d, differences give %d", ErrIntegrity, last, x)
+	}
+	return blk, nil
+}
+
+// base36 decodes a GCF identifier.
+func base36(v uint32) string {
+	const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	var buf [7]byte
+	i := len(buf)
+	for v > 0 {
+		i--
+		buf[i] = digits[v%36]
+		v /= 36
+	}
+	return string(buf[i:])
+}
@@ -0,0 +1,97 @@
+package gcf
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+// id encodes a GCF identifier.
+func id(s string) uint32 {
+	var v uint32
+	for _, c := range s {
+		v = v*36 + uint32(strings.IndexRune("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ", c))
+	}
+	return v
+}
+
+// block encodes a data block of samples with 16-bit differences.
+func block(start time.Time, rate byte, samples []int32) []byte {
+	days := int(start.Sub(epoch).Hours() / 24)
+	secs := start.Sub(epoch.AddDate(0, 0, days)) / time.Second
+	b := binary.BigEndian.AppendUint32(nil, id("DM24"))
+	b = binary.BigEndian.AppendUint32(b, id("1234Z2"))
+	b = binary.BigEndian.AppendUint32(b, uint32(days)<<17|uint32(secs))
+	b = append(b, 0, rate, 2, byte(len(samples)/2))
+	b = binary.BigEndian.AppendUint32(b, uint32(samples[0]))
+	prev := int32(-7) // last sample of the previous block
+	for _, x := range samples {
+		b = binary.BigEndian.AppendUint16(b, uint16(int16(x-prev)))
+		prev = x
+	}
+	return binary.BigEndian.AppendUint32(b, uint32(prev))
+}
+
+func TestDecode(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 5, 0, time.UTC)
+	samples := []int32{100, 120, 90, -300}
+	blk, err := Decode(block(start, 100, samples))
+	require.NoError(t, err)
+	require.Equal(t, Block{SystemID: "DM24", StreamID: "1234Z2", Start: start, SampleRate: 100, Samples: samples}, blk)
+
+	b := block(start, 100, samples)
+	b[len(b)-1]++
+	_, err = Decode(b)
+	require.ErrorIs(t, err, ErrIntegrity)
+
+	status := binary.BigEndian.AppendUint32(nil, id("DM24"))
+	status = binary.BigEndian.AppendUint32(status, id("1234Z0"))
+	status = append(status, 0, 0, 0, 0, 0, 0, 4, 2)
+	status = append(status, "GPS OK\x00\x00"...)
+	blk, err = Decode(status)
+	require.NoError(t, err)
+	require.Equal(t, "GPS OK", blk.Status)
+	require.Nil(t, blk.Samples)
+
+	_, err = Decode(b[:10])
+	require.ErrorIs(t, err, ErrFormat)
+}
+
+func TestFramer(t *testing.T) {
+	f, err := serial.NewFramer("gcf", serial.Config{})
+	require.NoError(t, err)
+	start := time.Date(2024, 3, 1, 12, 0, 6, 0, time.UTC)
+	good := block(start, 100, []int32{1, 2, 3, 4, 5, 6})
+	bad := block(start, 100, []int32{1, 2})
+	bad[len(bad)-1]++
+
+	stream := append(append([]byte{0xFF, 0xFF}, bad...), good...)
+	advance, frame, err := f.Frame(stream[:10])
+	require.Zero(t, advance)
+	require.Nil(t, frame)
+	require.NoError(t, err)
+
+	var frames [][]byte
+	var errs []error
+	for len(stream) > 0 {
+		advance, frame, err := f.Frame(stream)
+		if frame != nil {
+			frames = append(frames, frame)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if advance == 0 {
+			break
+		}
+		stream = stream[advance:]
+	}
+	require.Equal(t, [][]byte{good}, frames)
+	require.NotEmpty(t, errs)
+	require.ErrorIs(t, errs[0], serial.ErrBadFrame)
+	require.ErrorIs(t, errs[0], ErrIntegrity)
+}
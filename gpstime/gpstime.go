@@ -0,0 +1,126 @@
+// Package gpstime builds a disciplined clock from a GPS receiver attached to
+// a serial port, optionally combined with the receiver's pulse-per-second
+// output captured by the kernel PPS subsystem (see package pps).
+//
+// Feed every received line to Clock.HandleLine, e.g. as the onLine callback
+// or through a serial.Router. Each valid RMC or ZDA sentence labels the
+// preceding PPS edge with its UTC second, which yields the offset between the
+// system clock and GPS time at the edge's kernel timestamp. Without a PPS
+// source the sentence arrival time is used instead, which is only as precise
+// as the receiver's output latency (typically tens of milliseconds).
+//
+// The pairing assumes the receiver emits each time sentence shortly after the
+// pulse it describes, which is the default for common u-blox and MediaTek
+// modules.
+package gpstime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/luhtfiimanal/go-linux-serial/pps"
+)
+
+// EdgeSource provides the most recent PPS edge; *pps.Device implements it.
+type EdgeSource interface {
+	Fetch() (pps.Edge, error)
+}
+
+// Status describes the state of a Clock.
+type Status struct {
+	Locked   bool          // an offset has been established
+	PPS      bool          // the offset came from a PPS edge rather than sentence arrival
+	Offset   time.Duration // GPS time minus system time
+	Drift    float64       // estimated system clock rate error (s/s), from successive fixes
+	LastFix  time.Time     // system time of the edge or sentence behind the offset
+	Sentence string        // last sentence used
+}
+
+// Clock maps system clock readings onto GPS time. It is safe for concurrent use.
+type Clock struct {
+	// MaxAge bounds how long after a PPS edge a time sentence may arrive and
+	// still be paired with it. Zero means one second.
+	MaxAge time.Duration
+
+	src EdgeSource
+	now func() time.Time
+
+	mu      sync.Mutex
+	status  Status
+	lastSeq uint32
+}
+
+// New returns a Clock. src may be nil to discipline from sentences alone.
+func New(src EdgeSource) *Clock {
+	return &Clock{src: src, now: time.Now}
+}
+
+// HandleLine feeds one received line. Lines that are not valid RMC or ZDA
+// sentences are ignored, so HandleLine can see the whole stream.
+func (c *Clock) HandleLine(line string) {
+	arrival := c.now()
+	gps, err := ParseNMEATime(line)
+	if err != nil {
+		return
+	}
+	ref, fromPPS := arrival, false
+	var edge pps.Edge
+	if c.src != nil && gps.Nanosecond() == 0 {
+		if e, err := c.src.Fetch(); err == nil {
+			maxAge := c.MaxAge
+			if maxAge <= 0 {
+				maxAge = time.Second
+			}
+			if age := arrival.Sub(e.Time); age >= 0 && age < maxAge {
+				ref, fromPPS, edge = e.Time, true, e
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if fromPPS && c.status.PPS && edge.Sequence == c.lastSeq {
+		return // this edge was already labelled
+	}
+	if !fromPPS && c.status.PPS && arrival.Sub(c.status.LastFix) < 3*time.Second {
+		return // keep a recent PPS fix rather than degrade to arrival time
+	}
+	offset := gps.Sub(ref)
+	if c.status.Locked && c.status.PPS == fromPPS {
+		if dt := ref.Sub(c.status.LastFix).Seconds(); dt >= 1 {
+			c.status.Drift = (offset - c.status.Offset).Seconds() / dt
+		}
+	}
+	c.lastSeq = edge.Sequence
+	c.status.Locked = true
+	c.status.PPS = fromPPS
+	c.status.Offset = offset
+	c.status.LastFix = ref
+	c.status.Sentence = line
+}
+
+// Timestamp maps a system clock reading, such as a line's arrival time, onto
+// GPS time. ok is false until the clock is locked.
+func (c *Clock) Timestamp(local time.Time) (t time.Time, ok bool) {
+	c.mu.Lock()
+	st := c.status
+	c.mu.Unlock()
+	if !st.Locked {
+		return local, false
+	}
+	// Extrapolate the offset with the drift estimate since the last fix.
+	corr := time.Duration(st.Drift * float64(local.Sub(st.LastFix)))
+	return local.Add(st.Offset + corr).UTC(), true
+}
+
+// Now returns the current GPS time; ok is false until the clock is locked.
+func (c *Clock) Now() (time.Time, bool) {
+	return c.Timestamp(c.now())
+}
+
+// Status returns a snapshot of the clock state.
+func (c *Clock) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
@@ -0,0 +1,90 @@
+package gpstime
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/luhtfiimanal/go-linux-serial/pps"
+)
+
+func nmea(body string) string {
+	var sum byte
+	for i := 0; i < len(body); i++ {
+		sum ^= body[i]
+	}
+	return fmt.Sprintf("$%s*%02X", body, sum)
+}
+
+type fakeEdges struct {
+	edge pps.Edge
+	err  error
+}
+
+func (f *fakeEdges) Fetch() (pps.Edge, error) { return f.edge, f.err }
+
+func TestParseNMEATime(t *testing.T) {
+	got, err := ParseNMEATime(nmea("GPRMC,123519.00,A,4807.038,N,01131.000,E,022.4,084.4,230394,003.1,W"))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2094, 3, 23, 12, 35, 19, 0, time.UTC), got)
+
+	got, err = ParseNMEATime(nmea("GNZDA,201530.50,04,07,2024,00,00"))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 7, 4, 20, 15, 30, 500000000, time.UTC), got)
+
+	_, err = ParseNMEATime(nmea("GPRMC,123519,V,,,,,,,230394,,"))
+	require.ErrorIs(t, err, ErrNoTime)
+	_, err = ParseNMEATime(nmea("GPGGA,123519,4807.038,N"))
+	require.ErrorIs(t, err, ErrNoTime)
+	_, err = ParseNMEATime("$GNZDA,201530.00,04,07,2024,00,00*00")
+	require.Error(t, err)
+}
+
+func TestClockWithPPS(t *testing.T) {
+	base := time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)
+	edgeAt := base.Add(1500 * time.Millisecond) // system clock is 1.5s behind GPS at 20:15:31
+	src := &fakeEdges{edge: pps.Edge{Sequence: 1, Time: edgeAt}}
+	c := New(src)
+	c.now = func() time.Time { return edgeAt.Add(120 * time.Millisecond) }
+
+	_, ok := c.Now()
+	require.False(t, ok)
+
+	gps := time.Date(2024, 7, 4, 20, 15, 31, 0, time.UTC)
+	c.HandleLine("$GPGGA,ignored")
+	c.HandleLine(nmea("GNZDA,201531.00,04,07,2024,00,00"))
+	st := c.Status()
+	require.True(t, st.Locked)
+	require.True(t, st.PPS)
+	require.Equal(t, gps.Sub(edgeAt), st.Offset)
+
+	ts, ok := c.Timestamp(edgeAt.Add(250 * time.Millisecond))
+	require.True(t, ok)
+	require.Equal(t, gps.Add(250*time.Millisecond), ts)
+
+	// Next second: the system clock ran 1ms fast over one second.
+	src.edge = pps.Edge{Sequence: 2, Time: edgeAt.Add(1001 * time.Millisecond)}
+	c.now = func() time.Time { return src.edge.Time.Add(100 * time.Millisecond) }
+	c.HandleLine(nmea("GNZDA,201532.00,04,07,2024,00,00"))
+	st = c.Status()
+	require.InDelta(t, -0.001, st.Drift, 1e-6)
+}
+
+func TestClockStaleEdgeFallsBack(t *testing.T) {
+	arrival := time.Date(2024, 7, 4, 0, 0, 10, 0, time.UTC)
+	src := &fakeEdges{edge: pps.Edge{Sequence: 1, Time: arrival.Add(-5 * time.Second)}}
+	c := New(src)
+	c.now = func() time.Time { return arrival }
+	c.HandleLine(nmea("GNZDA,000011.00,04,07,2024,00,00"))
+	st := c.Status()
+	require.True(t, st.Locked)
+	require.False(t, st.PPS)
+	require.Equal(t, time.Second, st.Offset)
+
+	src.err = errors.New("no pps")
+	c.HandleLine(nmea("GNZDA,000011.00,04,07,2024,00,00"))
+	require.False(t, c.Status().PPS)
+}
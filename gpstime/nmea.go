@@ -0,0 +1,91 @@
+package gpstime
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoTime is returned by ParseNMEATime for sentences that carry no usable UTC date and time.
+var ErrNoTime = errors.New("gpstime: sentence carries no valid date and time")
+
+// ParseNMEATime extracts the UTC date and time from an RMC or ZDA sentence
+// from any talker ($GPRMC, $GNZDA, ...). The checksum, if present, is verified.
+// RMC sentences whose status is not "A" (valid) are rejected.
+func ParseNMEATime(sentence string) (time.Time, error) {
+	fields, err := splitNMEA(sentence)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(fields[0]) < 5 {
+		return time.Time{}, ErrNoTime
+	}
+	switch fields[0][len(fields[0])-3:] {
+	case "RMC":
+		// $xxRMC,hhmmss.ss,A,lat,N,lon,E,spd,cog,ddmmyy,...
+		if len(fields) < 10 || fields[2] != "A" || len(fields[9]) != 6 {
+			return time.Time{}, ErrNoTime
+		}
+		day, err1 := strconv.Atoi(fields[9][0:2])
+		month, err2 := strconv.Atoi(fields[9][2:4])
+		year, err3 := strconv.Atoi(fields[9][4:6])
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return time.Time{}, ErrNoTime
+		}
+		return combine(2000+year, month, day, fields[1])
+	case "ZDA":
+		// $xxZDA,hhmmss.ss,dd,mm,yyyy,zh,zm
+		if len(fields) < 5 {
+			return time.Time{}, ErrNoTime
+		}
+		day, err1 := strconv.Atoi(fields[2])
+		month, err2 := strconv.Atoi(fields[3])
+		year, err3 := strconv.Atoi(fields[4])
+		if err := errors.Join(err1, err2, err3); err != nil {
+			return time.Time{}, ErrNoTime
+		}
+		return combine(year, month, day, fields[1])
+	}
+	return time.Time{}, ErrNoTime
+}
+
+// splitNMEA verifies the framing and optional checksum of sentence and
+// returns its comma-separated fields, the first being the address ("GPRMC").
+func splitNMEA(sentence string) ([]string, error) {
+	sentence = strings.TrimRight(sentence, "\r\n")
+	if !strings.HasPrefix(sentence, "$") {
+		return nil, ErrNoTime
+	}
+	body := sentence[1:]
+	if star := strings.LastIndexByte(body, '*'); star >= 0 {
+		want, err := strconv.ParseUint(body[star+1:], 16, 8)
+		if err != nil {
+			return nil, errors.New("gpstime: malformed checksum")
+		}
+		body = body[:star]
+		var sum byte
+		for i := 0; i < len(body); i++ {
+			sum ^= body[i]
+		}
+		if sum != byte(want) {
+			return nil, errors.New("gpstime: checksum mismatch")
+		}
+	}
+	return strings.Split(body, ","), nil
+}
+
+func combine(year, month, day int, hms string) (time.Time, error) {
+	if len(hms) < 6 {
+		return time.Time{}, ErrNoTime
+	}
+	h, err1 := strconv.Atoi(hms[0:2])
+	m, err2 := strconv.Atoi(hms[2:4])
+	sec, err3 := strconv.ParseFloat(hms[4:], 64)
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return time.Time{}, ErrNoTime
+	}
+	whole := int(sec)
+	nsec := int((sec - float64(whole)) * 1e9)
+	return time.Date(year, time.Month(month), day, h, m, whole, nsec, time.UTC), nil
+}
@@ -0,0 +1,96 @@
+package serial
+
+import "fmt"
+
+// HDLC-like framing characters (RFC 1662).
+const (
+	hdlcFlag   = 0x7E
+	hdlcEscape = 0x7D
+	hdlcXor    = 0x20
+)
+
+// HDLC is a Framer for HDLC-like framing as used by PPP (RFC 1662) and many
+// instrument protocols: frames are delimited by 0x7E flags, 0x7E and 0x7D
+// inside them are escaped as 0x7D followed by the byte XOR 0x20, and each
+// frame ends with a 16-bit CRC-CCITT frame check sequence (FCS-16), sent low
+// byte first. Frame returns the payload without the FCS; a frame whose FCS
+// does not verify is reported as ErrBadFrame. Consecutive flags are skipped,
+// and a frame ending in 0x7D 0x7E (abort) is discarded.
+type HDLC struct {
+	// EscapeControl makes Encode also escape bytes below 0x20, as PPP does
+	// with its default async control character map.
+	EscapeControl bool
+}
+
+// Frame implements Framer.
+func (HDLC) Frame(data []byte) (int, []byte, error) {
+	end := -1
+	for i, b := range data {
+		if b == hdlcFlag {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, nil, nil
+	}
+	if end == 0 {
+		return 1, nil, nil
+	}
+	if data[end-1] == hdlcEscape {
+		return end + 1, nil, nil // aborted frame
+	}
+	frame := make([]byte, 0, end)
+	for i := 0; i < end; i++ {
+		b := data[i]
+		if b == hdlcEscape && i+1 < end {
+			i++
+			b = data[i] ^ hdlcXor
+		}
+		frame = append(frame, b)
+	}
+	if len(frame) < 3 {
+		return end + 1, nil, fmt.Errorf("%w: HDLC frame of %d bytes", ErrBadFrame, len(frame))
+	}
+	if fcs16(frame) != fcs16Good {
+		return end + 1, nil, fmt.Errorf("%w: HDLC FCS mismatch", ErrBadFrame)
+	}
+	return end + 1, frame[:len(frame)-2], nil
+}
+
+// Encode returns payload as an HDLC frame: opening flag, escaped payload and
+// FCS, closing flag.
+func (h HDLC) Encode(payload []byte) []byte {
+	fcs := ^fcs16(payload)
+	out := make([]byte, 0, len(payload)+6)
+	out = append(out, hdlcFlag)
+	for _, b := range append(payload[:len(payload):len(payload)], byte(fcs), byte(fcs>>8)) {
+		if b == hdlcFlag || b == hdlcEscape || (h.EscapeControl && b < 0x20) {
+			out = append(out, hdlcEscape, b^hdlcXor)
+			continue
+		}
+		out = append(out, b)
+	}
+	return append(out, hdlcFlag)
+}
+
+// fcs16Good is the FCS-16 residue of a frame that includes its own FCS.
+const fcs16Good = 0xF0B8
+
+// fcs16 computes the RFC 1662 FCS-16 register (CRC-CCITT, reflected
+// polynomial 0x8408, initial value 0xFFFF) over b, without the final
+// complement.
+func fcs16(b []byte) uint16 {
+	fcs := uint16(0xFFFF)
+	for _, c := range b {
+		fcs ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if fcs&1 != 0 {
+				fcs = fcs>>1 ^ 0x8408
+			} else {
+				fcs >>= 1
+			}
+		}
+	}
+	return fcs
+}
@@ -0,0 +1,58 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHDLC_RoundTrip(t *testing.T) {
+	// CRC-16/X.25 check value for "123456789" is 0x906E.
+	require.Equal(t, uint16(0x906E), ^fcs16([]byte("123456789")))
+
+	payload := []byte{0xFF, 0x03, hdlcFlag, 0x01, hdlcEscape, 0x02}
+	enc := HDLC{}.Encode(payload)
+	require.Equal(t, byte(hdlcFlag), enc[0])
+	require.Equal(t, byte(hdlcFlag), enc[len(enc)-1])
+	require.NotContains(t, enc[1:len(enc)-1], byte(hdlcFlag))
+
+	advance, frame, err := HDLC{}.Frame(enc[1:])
+	require.NoError(t, err)
+	require.Equal(t, len(enc)-1, advance)
+	require.Equal(t, payload, frame)
+
+	enc = HDLC{EscapeControl: true}.Encode([]byte{0x01, 0x41})
+	require.Equal(t, []byte{hdlcEscape, 0x21, 0x41}, enc[1:4])
+	_, frame, err = HDLC{}.Frame(enc[1:])
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x41}, frame)
+}
+
+func TestHDLC_BadAndAbortedFrames(t *testing.T) {
+	enc := HDLC{}.Encode([]byte("data"))
+	enc[2] ^= 0x01
+	advance, frame, err := HDLC{}.Frame(enc[1:])
+	require.ErrorIs(t, err, ErrBadFrame)
+	require.Nil(t, frame)
+	require.Equal(t, len(enc)-1, advance)
+
+	advance, frame, err = HDLC{}.Frame([]byte{0x01, 0x02, hdlcEscape, hdlcFlag, 0x05})
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	require.Equal(t, 4, advance)
+
+	// Flags shared between frames and runs of flags are both accepted.
+	stream := append(HDLC{}.Encode([]byte("a")), HDLC{}.Encode([]byte("b"))[1:]...)
+	stream = append([]byte{hdlcFlag, hdlcFlag}, stream...)
+	var got []string
+	for len(stream) > 0 {
+		advance, frame, err := HDLC{}.Frame(stream)
+		require.NoError(t, err)
+		require.Positive(t, advance)
+		if frame != nil {
+			got = append(got, string(frame))
+		}
+		stream = stream[advance:]
+	}
+	require.Equal(t, []string{"a", "b"}, got)
+}
@@ -0,0 +1,111 @@
+package serial
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrIdle is reported by HealthCheck when nothing has been received for
+// longer than HealthProbe.MaxIdle.
+var ErrIdle = errors.New("no data received")
+
+// HealthProbe configures HealthCheck.
+type HealthProbe struct {
+	// Command, if set, is written with the delimiter as a probe, e.g.
+	// "AT" or "*IDN?". A line matching Expect must then arrive within
+	// Timeout, through a running ReadLinesLoop.
+	Command string
+	// Expect matches the response to Command; nil accepts any line.
+	Expect *regexp.Regexp
+	// Timeout bounds the wait for the response. Zero means one second.
+	Timeout time.Duration
+	// MaxIdle, if positive, requires data to have been received within
+	// MaxIdle, for devices that stream on their own. Without Command
+	// that is the whole check.
+	MaxIdle time.Duration
+}
+
+// HealthReport is the outcome of HealthCheck, shaped for readiness
+// endpoints: it encodes to JSON as is.
+type HealthReport struct {
+	Healthy     bool          `json:"healthy"`
+	Device      string        `json:"device"`
+	Open        bool          `json:"open"`
+	LastReceive time.Time     `json:"last_receive,omitzero"` // zero if nothing was received
+	Response    string        `json:"response,omitempty"`    // the line answering the probe
+	RoundTrip   time.Duration `json:"round_trip_ns,omitempty"`
+	Reason      string        `json:"reason,omitempty"` // Err as text
+	Err         error         `json:"-"`                // why the port is unhealthy
+}
+
+// HealthCheck checks the port as Config.Health says: that it is open, that
+// data arrived recently and that the device answers the probe command.
+// The probe response is taken from the lines delivered by ReadLinesLoop,
+// which must be running. ctx bounds the wait for the response along with
+// HealthProbe.Timeout.
+func (s *SerialReader) HealthCheck(ctx context.Context) HealthReport {
+	r := HealthReport{Device: s.Device(), Open: s.IsOpen()}
+	if ns := s.lastRead.Load(); ns != 0 {
+		r.LastReceive = time.Unix(0, ns)
+	}
+	r.Err = s.healthCheck(ctx, &r)
+	if ns := s.lastRead.Load(); ns != 0 {
+		r.LastReceive = time.Unix(0, ns) // the probe may have been answered
+	}
+	r.Healthy = r.Err == nil
+	if r.Err != nil {
+		r.Reason = r.Err.Error()
+	}
+	return r
+}
+
+func (s *SerialReader) healthCheck(ctx context.Context, r *HealthReport) error {
+	if !r.Open {
+		return ErrClosed
+	}
+	h := s.config.Health
+	if h == nil {
+		return nil
+	}
+	if h.MaxIdle > 0 {
+		if idle := time.Since(r.LastReceive); r.LastReceive.IsZero() || idle > h.MaxIdle {
+			return fmt.Errorf("%w for more than %v", ErrIdle, h.MaxIdle)
+		}
+	}
+	if h.Command == "" {
+		return nil
+	}
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	sub := s.Subscribe(16)
+	defer sub.Unsubscribe()
+	sub.SetPolicy(DropOldest) // never stall the read loop
+	start := time.Now()
+	if err := s.WriteLine(h.Command, s.config.delimiter()); err != nil {
+		return err
+	}
+	for {
+		select {
+		case line, ok := <-sub.C():
+			if !ok {
+				return ErrClosed
+			}
+			if h.Expect == nil || h.Expect.MatchString(line) {
+				r.Response, r.RoundTrip = line, time.Since(start)
+				return nil
+			}
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("probe %q: %w", h.Command, ErrTimeout)
+			}
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package serial
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_HealthCheck(t *testing.T) {
+	reader, master := newTestReader(t, Config{Health: &HealthProbe{
+		Command: "*IDN?",
+		Expect:  regexp.MustCompile(`^ACME`),
+		Timeout: 200 * time.Millisecond,
+	}})
+	go reader.ReadLinesLoop(nil, func(error) {})
+
+	// An instrument that answers once, after some chatter.
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := master.Read(buf)
+		if string(buf[:n]) == "*IDN?\n" {
+			master.Write([]byte("T=21.5\nACME,DMM-1,42\n"))
+		}
+	}()
+	r := reader.HealthCheck(context.Background())
+	require.True(t, r.Healthy, r.Reason)
+	require.Equal(t, "ACME,DMM-1,42", r.Response)
+	require.Positive(t, r.RoundTrip)
+	require.False(t, r.LastReceive.IsZero())
+
+	r = reader.HealthCheck(context.Background())
+	require.False(t, r.Healthy)
+	require.ErrorIs(t, r.Err, ErrTimeout)
+	b, err := json.Marshal(r)
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"healthy":false`)
+	require.Contains(t, string(b), `"reason":"probe \"*IDN?\": read timeout"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, reader.HealthCheck(ctx).Err, context.Canceled)
+
+	reader.Close()
+	r = reader.HealthCheck(context.Background())
+	require.False(t, r.Open)
+	require.ErrorIs(t, r.Err, ErrClosed)
+}
+
+func TestSerialReader_HealthCheckIdle(t *testing.T) {
+	reader, master := newTestReader(t, Config{Health: &HealthProbe{MaxIdle: 50 * time.Millisecond}})
+	go reader.ReadLinesLoop(nil, func(error) {})
+	require.ErrorIs(t, reader.HealthCheck(context.Background()).Err, ErrIdle)
+
+	_, err := master.Write([]byte("sample\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return reader.HealthCheck(context.Background()).Healthy }, time.Second, time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+	require.ErrorIs(t, reader.HealthCheck(context.Background()).Err, ErrIdle)
+
+	plain, _ := newTestReader(t, Config{})
+	require.True(t, plain.HealthCheck(context.Background()).Healthy)
+}
@@ -0,0 +1,58 @@
+package serial
+
+// LineReader is the read side of the line API. Downstream code can depend on
+// it instead of *SerialReader and substitute a fake in unit tests.
+type LineReader interface {
+	ReadLine() (string, error)
+	ReadLinesLoop(onLine func(string), onError func(error))
+	Close() error
+}
+
+// LineWriter is the write side of the line API.
+type LineWriter interface {
+	WriteLine(line, newline string) error
+	Close() error
+}
+
+// LineReadWriter combines LineReader and LineWriter.
+type LineReadWriter interface {
+	LineReader
+	LineWriter
+}
+
+// LineSource is a stream of received lines, as consumed by the sse, wsbridge
+// and mqttbridge packages. Lines are only delivered while the application
+// runs a ReadLinesLoop on the port.
+type LineSource interface {
+	Subscribe(buffer int) *Subscription
+}
+
+// Opener opens a LineReadWriter from a Config. Depend on an Opener rather than
+// calling Open directly to make port creation replaceable in tests.
+type Opener interface {
+	Open(cfg Config) (LineReadWriter, error)
+}
+
+// OpenerFunc adapts a function to the Opener interface.
+type OpenerFunc func(cfg Config) (LineReadWriter, error)
+
+// Open calls f(cfg).
+func (f OpenerFunc) Open(cfg Config) (LineReadWriter, error) {
+	return f(cfg)
+}
+
+// DefaultOpener opens real serial ports with Open.
+var DefaultOpener Opener = OpenerFunc(func(cfg Config) (LineReadWriter, error) {
+	s, err := Open(cfg)
+	if err != nil {
+		return nil, err // avoid a non-nil interface holding a nil *SerialReader
+	}
+	return s, nil
+})
+
+var (
+	_ LineReadWriter = (*SerialReader)(nil)
+	_ LineSource     = (*SerialReader)(nil)
+	_ LineReader     = (*PortReader)(nil)
+	_ LineWriter     = (*PortWriter)(nil)
+)
@@ -0,0 +1,83 @@
+package serial
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDevice is the kind of stand-in the interfaces make possible.
+type fakeDevice struct {
+	lines   []string
+	written []string
+}
+
+func (f *fakeDevice) ReadLine() (string, error) {
+	if len(f.lines) == 0 {
+		return "", ErrClosed
+	}
+	l := f.lines[0]
+	f.lines = f.lines[1:]
+	return l, nil
+}
+
+func (f *fakeDevice) ReadLinesLoop(onLine func(string), onError func(error)) {
+	for {
+		l, err := f.ReadLine()
+		if err != nil {
+			return
+		}
+		onLine(l)
+	}
+}
+
+func (f *fakeDevice) WriteLine(line, newline string) error {
+	f.written = append(f.written, line+newline)
+	return nil
+}
+
+func (f *fakeDevice) Close() error { return nil }
+
+// queryVersion is protocol logic written against the interfaces.
+func queryVersion(o Opener, cfg Config) (string, error) {
+	rw, err := o.Open(cfg)
+	if err != nil {
+		return "", err
+	}
+	defer rw.Close()
+	if err := rw.WriteLine("VER?", "\r\n"); err != nil {
+		return "", err
+	}
+	return rw.ReadLine()
+}
+
+func TestOpener_Fake(t *testing.T) {
+	fake := &fakeDevice{lines: []string{"v1.2"}}
+	v, err := queryVersion(OpenerFunc(func(Config) (LineReadWriter, error) { return fake, nil }), Config{})
+	require.NoError(t, err)
+	require.Equal(t, "v1.2", v)
+	require.Equal(t, []string{"VER?\r\n"}, fake.written)
+}
+
+func TestDefaultOpener(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+
+	rw, err := DefaultOpener.Open(Config{Device: slave.Name(), BaudRate: 115200, Delimiter: "\n"})
+	require.NoError(t, err)
+	defer rw.Close()
+	_, err = master.Write([]byte("hi\n"))
+	require.NoError(t, err)
+	line, err := rw.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "hi", line)
+
+	rw, err = DefaultOpener.Open(Config{Device: "/dev/does-not-exist"})
+	require.Error(t, err)
+	require.True(t, rw == nil)
+	var serr *SerialError
+	require.True(t, errors.As(err, &serr))
+}
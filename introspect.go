@@ -0,0 +1,71 @@
+package serial
+
+import "time"
+
+// Device returns the device path or endpoint URL the reader was opened with.
+func (s *SerialReader) Device() string {
+	return s.config.Device
+}
+
+// Config returns the configuration in effect, including changes made by
+// SetLineSettings. The Middleware slice is shared with the reader and must
+// not be modified.
+func (s *SerialReader) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config
+}
+
+// IsOpen reports whether the reader is usable: Close has not been called and
+// the current port has not been closed. A device that has gone away stays
+// "open" until Reopen replaces it or Close is called; read errors report
+// that case.
+func (s *SerialReader) IsOpen() bool {
+	if s.closed.Load() {
+		return false
+	}
+	select {
+	case <-s.port().done:
+		return false
+	default:
+		return true
+	}
+}
+
+// LastActivity returns when bytes were last read from or written to the
+// port, or the zero time if none have been.
+func (s *SerialReader) LastActivity() time.Time {
+	ns := s.lastActivity.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// String describes the reader for logs: its configuration as Config.String
+// shows it, followed by "(open)" or "(closed)".
+func (s *SerialReader) String() string {
+	state := "(open)"
+	if !s.IsOpen() {
+		state = "(closed)"
+	}
+	return s.Config().String() + " " + state
+}
+
+// countRead records n bytes read.
+func (s *SerialReader) countRead(n int) {
+	if n > 0 {
+		now := time.Now().UnixNano()
+		s.bytesRead.Add(uint64(n))
+		s.lastActivity.Store(now)
+		s.lastRead.Store(now)
+	}
+}
+
+// countWritten records n bytes written.
+func (s *SerialReader) countWritten(n int) {
+	if n > 0 {
+		s.bytesWritten.Add(uint64(n))
+		s.lastActivity.Store(time.Now().UnixNano())
+	}
+}
@@ -0,0 +1,31 @@
+package serial
+
+import "encoding/json"
+
+// JSONLines returns an onLine function for ReadLinesLoop, for devices that
+// emit one JSON object per line: each line is unmarshalled into a T and
+// passed to onValue. Lines that are not valid JSON, or do not fit T, are
+// passed to onError as *ParseError, the offending line attached. With
+// json.RawMessage as T, lines are only validated.
+func JSONLines[T any](onValue func(T), onError func(error)) func(line string) {
+	return func(line string) {
+		v, err := parseJSON[T](line)
+		if err != nil {
+			onError(&ParseError{Line: line, Err: err})
+			return
+		}
+		onValue(v)
+	}
+}
+
+// DecodeJSON is Decode with each line unmarshalled into a T; malformed lines
+// are sent on errs as *ParseError.
+func DecodeJSON[T any](r *SerialReader) (values <-chan T, errs <-chan error) {
+	return Decode(r, parseJSON[T])
+}
+
+func parseJSON[T any](line string) (T, error) {
+	var v T
+	err := json.Unmarshal([]byte(line), &v)
+	return v, err
+}
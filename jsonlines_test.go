@@ -0,0 +1,41 @@
+package serial
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONLines(t *testing.T) {
+	type reading struct {
+		Temp float64 `json:"temp"`
+	}
+	var got []reading
+	var errs []error
+	onLine := JSONLines(func(r reading) { got = append(got, r) }, func(err error) { errs = append(errs, err) })
+	for _, line := range []string{`{"temp":21.5}`, `{"temp":`, `{"temp":"hot"}`, `{"temp":-3}`} {
+		onLine(line)
+	}
+	require.Equal(t, []reading{{21.5}, {-3}}, got)
+	require.Len(t, errs, 2)
+	var perr *ParseError
+	require.ErrorAs(t, errs[0], &perr)
+	require.Equal(t, `{"temp":`, perr.Line)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, errs[1], &typeErr)
+}
+
+func TestDecodeJSON(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	values, errs := DecodeJSON[json.RawMessage](reader)
+	_, err := master.Write([]byte("{\"a\":1}\nnot json\n[1, 2]\n"))
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{"a":1}`, string(<-values))
+	var perr *ParseError
+	require.ErrorAs(t, <-errs, &perr)
+	require.Equal(t, "not json", perr.Line)
+	require.Equal(t, json.RawMessage("[1, 2]"), <-values)
+	require.EqualValues(t, 1, reader.Stats().ParseErrors)
+}
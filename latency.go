@@ -0,0 +1,140 @@
+package serial
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Histogram bucket layout: values below 16ns get a bucket each; above that,
+// every power-of-two range is split into 16 linear sub-buckets, so a bucket
+// is at most 1/16 (6.25%) of its value wide, in the style of HDR histograms.
+const (
+	histSubBits = 4
+	histSub     = 1 << histSubBits
+	histBuckets = (64 - histSubBits + 1) * histSub
+)
+
+// latencyHistogram records durations with atomic counters, so the read loop
+// never blocks and Stats can take a snapshot at any time.
+type latencyHistogram struct {
+	counts [histBuckets]atomic.Uint64
+	total  atomic.Uint64
+	sum    atomic.Uint64 // nanoseconds
+	min    atomic.Int64
+	max    atomic.Int64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	h := &latencyHistogram{}
+	h.min.Store(math.MaxInt64)
+	return h
+}
+
+// reset clears h. Samples recorded concurrently may be half cleared.
+func (h *latencyHistogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.total.Store(0)
+	h.sum.Store(0)
+	h.min.Store(math.MaxInt64)
+	h.max.Store(0)
+}
+
+func histIndex(v uint64) int {
+	if v < histSub {
+		return int(v)
+	}
+	k := bits.Len64(v) - 1
+	sub := (v >> (k - histSubBits)) & (histSub - 1)
+	return (k-histSubBits+1)*histSub + int(sub)
+}
+
+// histUpper returns the highest value that falls in bucket i.
+func histUpper(i int) uint64 {
+	if i < histSub {
+		return uint64(i)
+	}
+	k := i/histSub + histSubBits - 1
+	sub := uint64(i % histSub)
+	return (histSub+sub+1)<<(k-histSubBits) - 1
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.counts[histIndex(uint64(d))].Add(1)
+	h.total.Add(1)
+	h.sum.Add(uint64(d))
+	for cur := h.min.Load(); int64(d) < cur && !h.min.CompareAndSwap(cur, int64(d)); cur = h.min.Load() {
+	}
+	for cur := h.max.Load(); int64(d) > cur && !h.max.CompareAndSwap(cur, int64(d)); cur = h.max.Load() {
+	}
+}
+
+func (h *latencyHistogram) snapshot() *Histogram {
+	s := &Histogram{
+		count: h.total.Load(),
+		sum:   h.sum.Load(),
+		min:   time.Duration(h.min.Load()),
+		max:   time.Duration(h.max.Load()),
+	}
+	for i := range h.counts {
+		s.counts[i] = h.counts[i].Load()
+	}
+	return s
+}
+
+// Histogram is a snapshot of the read latency: the time from the poll wakeup
+// that delivered a line's last bytes until its callback returned. It includes
+// framing, middleware and the onLine callback, so it shows whether GC pauses
+// or slow callbacks add jitter.
+type Histogram struct {
+	counts   [histBuckets]uint64
+	count    uint64
+	sum      uint64
+	min, max time.Duration
+}
+
+// Count returns the number of recorded lines.
+func (h *Histogram) Count() uint64 { return h.count }
+
+// Min returns the smallest recorded latency, or 0 if none.
+func (h *Histogram) Min() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.min
+}
+
+// Max returns the largest recorded latency.
+func (h *Histogram) Max() time.Duration { return h.max }
+
+// Mean returns the average latency.
+func (h *Histogram) Mean() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / h.count)
+}
+
+// Percentile returns the latency at or below which the fraction q (0 to 1)
+// of lines fell, accurate to the histogram's 6.25% bucket width.
+func (h *Histogram) Percentile(q float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.count)))
+	rank = max(rank, 1)
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			return min(time.Duration(histUpper(i)), h.max)
+		}
+	}
+	return h.max
+}
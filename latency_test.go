@@ -0,0 +1,52 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	for _, v := range []uint64{0, 1, 15, 16, 17, 31, 32, 1000, 123456789, 1 << 62, 1<<64 - 1} {
+		i := histIndex(v)
+		require.GreaterOrEqual(t, histUpper(i), v, "value %d", v)
+		if i > 0 {
+			require.Less(t, histUpper(i-1), v, "value %d", v)
+		}
+	}
+	require.Less(t, histIndex(1<<64-1), histBuckets)
+}
+
+func TestHistogramPercentile(t *testing.T) {
+	h := newLatencyHistogram()
+	for i := 1; i <= 100; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+	s := h.snapshot()
+	require.EqualValues(t, 100, s.Count())
+	require.Equal(t, time.Microsecond, s.Min())
+	require.Equal(t, 100*time.Microsecond, s.Max())
+	require.Equal(t, 50500*time.Nanosecond, s.Mean())
+	require.InEpsilon(t, float64(50*time.Microsecond), float64(s.Percentile(0.5)), 1.0/16)
+	require.InEpsilon(t, float64(99*time.Microsecond), float64(s.Percentile(0.99)), 1.0/16)
+	require.Equal(t, 100*time.Microsecond, s.Percentile(1))
+}
+
+func TestSerialReader_MeasureLatency(t *testing.T) {
+	reader, master := newTestReader(t, Config{MeasureLatency: true})
+	require.Nil(t, (&SerialReader{}).Stats().Latency)
+
+	done := make(chan struct{})
+	go reader.ReadLinesLoop(func(l string) {
+		if l == "slow" {
+			time.Sleep(5 * time.Millisecond)
+			close(done)
+		}
+	}, func(error) {})
+	_, err := master.Write([]byte("fast\nslow\n"))
+	require.NoError(t, err)
+	<-done
+	require.Eventually(t, func() bool { return reader.Stats().Latency.Count() == 2 }, time.Second, time.Millisecond)
+	require.GreaterOrEqual(t, reader.Stats().Latency.Max(), 5*time.Millisecond)
+}
@@ -0,0 +1,160 @@
+package serial
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parity selects the parity bit of each character.
+type Parity int
+
+const (
+	ParityNone Parity = iota
+	ParityOdd
+	ParityEven
+	ParityMark  // parity bit always 1
+	ParitySpace // parity bit always 0
+)
+
+// LineState is the state requested for a modem control line at open.
+type LineState int
+
+const (
+	LineDefault LineState = iota // whatever the driver does on open, normally raised
+	LineOn                       // raised (asserted)
+	LineOff                      // lowered (deasserted)
+)
+
+var lineStateNames = [...]string{"default", "on", "off"}
+
+// String returns "default", "on" or "off".
+func (s LineState) String() string {
+	if s < 0 || int(s) >= len(lineStateNames) {
+		return "LineState(" + strconv.Itoa(int(s)) + ")"
+	}
+	return lineStateNames[s]
+}
+
+// MarshalText encodes s by name, for configuration files.
+func (s LineState) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(lineStateNames) {
+		return nil, fmt.Errorf("invalid line state %d", int(s))
+	}
+	return []byte(lineStateNames[s]), nil
+}
+
+// UnmarshalText decodes the names returned by String, in any case.
+func (s *LineState) UnmarshalText(b []byte) error {
+	for i, name := range lineStateNames {
+		if strings.EqualFold(string(b), name) {
+			*s = LineState(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid line state %q", b)
+}
+
+// modemLine identifies an output modem control line.
+type modemLine int
+
+const (
+	lineDTR modemLine = iota
+	lineRTS
+)
+
+// lineControl drives the line settings and modem control lines of ports
+// that are not local ttys.
+type lineControl interface {
+	configure(cfg Config) error
+	setModemLine(line modemLine, on bool) error
+}
+
+// SetDTR raises (true) or lowers (false) the DTR line. On RFC 2217
+// connections the request is forwarded to the remote serial server.
+func (s *SerialReader) SetDTR(on bool) error {
+	return s.setModemLine(lineDTR, on)
+}
+
+// SetRTS raises (true) or lowers (false) the RTS line. On RFC 2217
+// connections the request is forwarded to the remote serial server.
+func (s *SerialReader) SetRTS(on bool) error {
+	return s.setModemLine(lineRTS, on)
+}
+
+// SetLineSettings changes the baud rate and character format of the open
+// port; a zero baud, dataBits or stopBits keeps the current value. The new
+// settings also apply after Reopen. On RFC 2217 connections they are sent
+// to the remote server.
+func (s *SerialReader) SetLineSettings(baud, dataBits int, parity Parity, stopBits int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cfg := s.config
+	if baud > 0 {
+		cfg.BaudRate = baud
+	}
+	if dataBits > 0 {
+		cfg.DataBits = dataBits
+	}
+	cfg.Parity = parity
+	if stopBits > 0 {
+		cfg.StopBits = stopBits
+	}
+	p := s.port()
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+	if p.ctl != nil {
+		if err := p.ctl.configure(cfg); err != nil {
+			return s.opErr("set line settings", err)
+		}
+	} else if err := s.setTTYLine(p, cfg); err != nil {
+		return err
+	}
+	// Input framed so far was received with the old settings.
+	s.partial.discard()
+	// Assign field by field: read loops access other fields concurrently.
+	s.config.BaudRate, s.config.DataBits = cfg.BaudRate, cfg.DataBits
+	s.config.Parity, s.config.StopBits = cfg.Parity, cfg.StopBits
+	return nil
+}
+
+// SendBreak holds the transmit line in the break condition for d, which
+// some bootloaders and consoles take as a request to stop and listen. It
+// fails with errors.ErrUnsupported on network ports.
+func (s *SerialReader) SendBreak(d time.Duration) error {
+	if s.config.Access == ReadOnly {
+		return s.opErr("send break", ErrAccessMode)
+	}
+	p, err := s.uartPort()
+	if err != nil {
+		return s.opErr("send break", err)
+	}
+	if err := setBreak(p.fd, true); err != nil {
+		return s.opErr("send break", err)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-p.done:
+		return ErrClosed
+	}
+	return s.opErr("send break", setBreak(p.fd, false))
+}
+
+func (s *SerialReader) setModemLine(line modemLine, on bool) error {
+	p := s.port()
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+	if p.ctl != nil {
+		return s.opErr("set modem line", p.ctl.setModemLine(line, on))
+	}
+	return s.opErr("set modem line", setTTYModemLine(p.fd, line, on))
+}
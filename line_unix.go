@@ -0,0 +1,141 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// setLineSettings applies the character format from cfg (data bits, parity,
+// stop bits) and its flow control to t.
+func setLineSettings(t *unix.Termios, cfg Config) error {
+	t.Cflag &^= unix.CSIZE | unix.PARENB | unix.PARODD | cmspar | unix.CSTOPB
+	t.Iflag &^= unix.INPCK
+	switch cfg.DataBits {
+	case 5:
+		t.Cflag |= unix.CS5
+	case 6:
+		t.Cflag |= unix.CS6
+	case 7:
+		t.Cflag |= unix.CS7
+	case 0, 8:
+		t.Cflag |= unix.CS8
+	default:
+		return fmt.Errorf("invalid data bits %d", cfg.DataBits)
+	}
+	switch cfg.Parity {
+	case ParityNone:
+	case ParityOdd:
+		t.Cflag |= unix.PARENB | unix.PARODD
+	case ParityEven:
+		t.Cflag |= unix.PARENB
+	case ParityMark, ParitySpace:
+		if cmspar == 0 {
+			return fmt.Errorf("mark/space parity not supported on this system")
+		}
+		t.Cflag |= unix.PARENB | cmspar
+		if cfg.Parity == ParityMark {
+			t.Cflag |= unix.PARODD
+		}
+	default:
+		return fmt.Errorf("invalid parity %d", cfg.Parity)
+	}
+	if cfg.Parity != ParityNone {
+		t.Iflag |= unix.INPCK
+	}
+	t.Cflag &^= unix.CRTSCTS
+	t.Iflag &^= unix.IXON | unix.IXOFF
+	if cfg.RTSCTS {
+		t.Cflag |= unix.CRTSCTS
+	}
+	if cfg.XONXOFF {
+		t.Iflag |= unix.IXON | unix.IXOFF
+	}
+	switch cfg.StopBits {
+	case 0, 1:
+	case 2:
+		t.Cflag |= unix.CSTOPB
+	default:
+		return fmt.Errorf("invalid stop bits %d", cfg.StopBits)
+	}
+	return nil
+}
+
+// setInitialLines applies cfg.InitialDTR and cfg.InitialRTS (or the RS-485
+// receive state) to the tty fd with a single TIOCMSET, so both lines change
+// together.
+func setInitialLines(fd int, cfg Config) error {
+	rts := cfg.InitialRTS
+	if cfg.RS485 != nil {
+		rts = cfg.RS485.rtsState(false) // start out receiving
+	}
+	if cfg.InitialDTR == LineDefault && rts == LineDefault {
+		return nil
+	}
+	bits, err := unix.IoctlGetInt(fd, unix.TIOCMGET)
+	if err != nil {
+		return err
+	}
+	bits = applyLineState(bits, unix.TIOCM_DTR, cfg.InitialDTR)
+	bits = applyLineState(bits, unix.TIOCM_RTS, rts)
+	return unix.IoctlSetPointerInt(fd, unix.TIOCMSET, bits)
+}
+
+func applyLineState(bits, bit int, state LineState) int {
+	switch state {
+	case LineOn:
+		return bits | bit
+	case LineOff:
+		return bits &^ bit
+	}
+	return bits
+}
+
+// setTTYLine programs the speed and character format of cfg into the tty
+// of p.
+func (s *SerialReader) setTTYLine(p *port, cfg Config) error {
+	t, err := unix.IoctlGetTermios(p.fd, ioctlGetTermios)
+	if err != nil {
+		return s.opErr("get termios", err)
+	}
+	if err := setLineSettings(t, cfg); err != nil {
+		return s.opErr("set termios", err)
+	}
+	setSpeed(t, cfg.BaudRate)
+	return s.opErr("set termios", unix.IoctlSetTermios(p.fd, ioctlSetTermios, t))
+}
+
+// setBreak starts (on) or ends the break condition on the tty fd.
+func setBreak(fd int, on bool) error {
+	req := uint(unix.TIOCCBRK)
+	if on {
+		req = unix.TIOCSBRK
+	}
+	return unix.IoctlSetInt(fd, req, 0)
+}
+
+// setTTYModemLine raises (on) or lowers an output modem line of the tty fd.
+func setTTYModemLine(fd int, line modemLine, on bool) error {
+	bit := unix.TIOCM_DTR
+	if line == lineRTS {
+		bit = unix.TIOCM_RTS
+	}
+	req := uint(unix.TIOCMBIC)
+	if on {
+		req = unix.TIOCMBIS
+	}
+	return unix.IoctlSetPointerInt(fd, req, bit)
+}
+
+// setTTYSpeed changes the speed of the tty fd, leaving the character format
+// alone.
+func setTTYSpeed(fd int, baud int) error {
+	t, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return err
+	}
+	setSpeed(t, baud)
+	return unix.IoctlSetTermios(fd, ioctlSetTermios, t)
+}
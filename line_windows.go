@@ -0,0 +1,56 @@
+package serial
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// setTTYLine programs the speed and character format of cfg into the COM
+// port of p.
+func (s *SerialReader) setTTYLine(p *port, cfg Config) error {
+	var d windows.DCB
+	d.DCBlength = uint32(unsafe.Sizeof(d))
+	if err := windows.GetCommState(p.fd, &d); err != nil {
+		return s.opErr("get comm state", err)
+	}
+	if err := setLineSettings(&d, cfg); err != nil {
+		return s.opErr("set comm state", err)
+	}
+	return s.opErr("set comm state", windows.SetCommState(p.fd, &d))
+}
+
+// setBreak starts (on) or ends the break condition on the COM port h.
+func setBreak(h windows.Handle, on bool) error {
+	if on {
+		return windows.SetCommBreak(h)
+	}
+	return windows.ClearCommBreak(h)
+}
+
+// setTTYModemLine raises (on) or lowers an output modem line of the COM
+// port h.
+func setTTYModemLine(h windows.Handle, line modemLine, on bool) error {
+	fn := uint32(windows.CLRDTR)
+	switch {
+	case line == lineDTR && on:
+		fn = windows.SETDTR
+	case line == lineRTS && on:
+		fn = windows.SETRTS
+	case line == lineRTS:
+		fn = windows.CLRRTS
+	}
+	return windows.EscapeCommFunction(h, fn)
+}
+
+// setTTYSpeed changes the speed of the COM port h, leaving the character
+// format alone.
+func setTTYSpeed(h windows.Handle, baud int) error {
+	var d windows.DCB
+	d.DCBlength = uint32(unsafe.Sizeof(d))
+	if err := windows.GetCommState(h, &d); err != nil {
+		return err
+	}
+	d.BaudRate = uint32(baud)
+	return windows.SetCommState(h, &d)
+}
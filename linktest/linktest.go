@@ -0,0 +1,384 @@
+// Package linktest qualifies a serial link, such as a long RS-485 or RS-232
+// cable run, before installation: it sends frames of a known pseudo-random
+// pattern and checks them bit by bit at the other end, reporting the bit
+// error rate, lost and corrupt frames, throughput and, when both ends are on
+// one host, latency.
+//
+// Run drives a port whose TX is looped back to its RX, or two ports on one
+// host wired to the two ends of the link. Across two hosts, one runs
+// Transmit and the other Receive with the same Options.
+//
+// Each frame carries a sequence number, its send time and Size bytes of
+// PRBS-15 (x^15 + x^14 + 1) seeded from the sequence number, protected by a
+// header checksum and COBS-framed so the receiver resynchronises after any
+// error.
+package linktest
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/bits"
+	"slices"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+)
+
+// headerLen is the sequence number, send time and header checksum.
+const headerLen = 4 + 8 + 4
+
+// Options configures a test.
+type Options struct {
+	// Config holds the port settings; Device is set from Device and
+	// RxDevice.
+	Config serial.Config
+	// Device is the transmitting port. Without RxDevice its TX must be
+	// wired to its RX; Receive reads it.
+	Device string
+	// RxDevice, if set, is the port on the far end of the link, attached
+	// to the same host, that Run reads instead of Device.
+	RxDevice string
+	// Size is the number of pattern bytes per frame; default 64.
+	Size int
+	// Rate is the number of frames per second; zero sends as fast as the
+	// link takes them.
+	Rate float64
+	// Frames is the number of frames to send; zero sends until Duration
+	// or ctx ends.
+	Frames   int
+	Duration time.Duration
+	// Settle is how long Run waits for outstanding frames after the last
+	// one was sent; default 1s.
+	Settle time.Duration
+}
+
+func (o *Options) size() int {
+	if o.Size > 0 {
+		return o.Size
+	}
+	return 64
+}
+
+// Result holds the measurements of a test.
+type Result struct {
+	// Sent is the number of frames sent; Receive, which cannot know, takes
+	// the highest sequence number received plus one.
+	Sent     int
+	Received int // frames with an intact header, duplicates excluded
+	Corrupt  int // frames with a garbled header or length
+	Errored  int // received frames with at least one bit error
+
+	Bits      uint64 // pattern bits checked
+	BitErrors uint64
+	Bytes     int // bytes received on the wire, framing included
+	Elapsed   time.Duration
+
+	// Latencies are the sorted times from sending a frame until it was
+	// received, measured by Run only.
+	Latencies []time.Duration
+}
+
+// Lost returns the number of frames sent that never arrived intact.
+func (r *Result) Lost() int {
+	return r.Sent - r.Received
+}
+
+// BER returns the bit error rate over the pattern bits checked. Frames
+// lost or garbled beyond recognition are counted by Lost and Corrupt
+// instead.
+func (r *Result) BER() float64 {
+	if r.Bits == 0 {
+		return 0
+	}
+	return float64(r.BitErrors) / float64(r.Bits)
+}
+
+// Percentile returns the latency at or below which the fraction q (0 to 1)
+// of the received frames fell. A q outside that range is clamped to it.
+func (r *Result) Percentile(q float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	switch {
+	case !(q > 0): // also NaN
+		q = 0
+	case q > 1:
+		q = 1
+	}
+	return r.Latencies[int(q*float64(len(r.Latencies)-1))]
+}
+
+// Report writes a human-readable summary to w.
+func (r *Result) Report(w io.Writer) {
+	secs := r.Elapsed.Seconds()
+	fmt.Fprintf(w, "frames      sent %d, received %d, lost %d, corrupt %d, with bit errors %d\n",
+		r.Sent, r.Received, r.Lost(), r.Corrupt, r.Errored)
+	fmt.Fprintf(w, "bit errors  %d in %d bits, BER %.3g\n", r.BitErrors, r.Bits, r.BER())
+	fmt.Fprintf(w, "throughput  %.1f frames/s, %.0f bytes/s\n", float64(r.Received)/secs, float64(r.Bytes)/secs)
+	if len(r.Latencies) > 0 {
+		fmt.Fprintf(w, "latency     min %v  p50 %v  p99 %v  max %v\n",
+			r.Latencies[0], r.Percentile(0.50), r.Percentile(0.99), r.Latencies[len(r.Latencies)-1])
+	}
+}
+
+// Run sends frames on Device and checks them as they arrive on RxDevice, or
+// on Device itself, until the configured number of frames or duration is
+// reached, or ctx is done.
+func Run(ctx context.Context, o Options) (*Result, error) {
+	tx, err := open(o, o.Device)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Close()
+	rx := tx
+	if o.RxDevice != "" {
+		if rx, err = open(o, o.RxDevice); err != nil {
+			return nil, err
+		}
+		defer rx.Close()
+	}
+
+	c := newChecker(o.size(), true)
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		rx.ReadFramesLoop(c, c.check, func(error) {})
+	}()
+
+	start := time.Now()
+	sent, err := transmit(ctx, tx, o)
+	if err != nil {
+		return nil, err
+	}
+	c.expect(sent)
+	settle := o.Settle
+	if settle == 0 {
+		settle = time.Second
+	}
+	select {
+	case <-c.all:
+	case <-time.After(settle):
+	}
+	elapsed := time.Since(start)
+	rx.Close()
+	<-loopDone
+
+	r := c.result()
+	r.Sent, r.Elapsed = sent, elapsed
+	return r, nil
+}
+
+// Transmit sends frames on Device for a Receive at the other end of the
+// link, until the configured number of frames or duration is reached, or
+// ctx is done. It returns the number of frames sent.
+func Transmit(ctx context.Context, o Options) (int, error) {
+	tx, err := open(o, o.Device)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Close()
+	return transmit(ctx, tx, o)
+}
+
+// Receive checks the frames a Transmit at the other end of the link sends
+// to Device, until Frames have been received, Duration has passed or ctx is
+// done.
+func Receive(ctx context.Context, o Options) (*Result, error) {
+	rx, err := open(o, o.Device)
+	if err != nil {
+		return nil, err
+	}
+	defer rx.Close()
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+	c := newChecker(o.size(), false)
+	c.expect(o.Frames)
+	loopDone := make(chan struct{})
+	go func() {
+		defer close(loopDone)
+		rx.ReadFramesLoop(c, c.check, func(error) {})
+	}()
+	start := time.Now()
+	select {
+	case <-c.all:
+	case <-ctx.Done():
+	case <-loopDone:
+	}
+	elapsed := time.Since(start)
+	rx.Close()
+	<-loopDone
+
+	r := c.result()
+	r.Sent, r.Elapsed = c.maxSeq, elapsed
+	return r, nil
+}
+
+func open(o Options, device string) (*serial.SerialReader, error) {
+	cfg := o.Config
+	cfg.Device = device
+	return serial.Open(cfg)
+}
+
+// transmit sends frames on tx at the configured rate.
+func transmit(ctx context.Context, tx *serial.SerialReader, o Options) (int, error) {
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+	size := o.size()
+	g := serialtest.Generator{Rate: o.Rate, Count: o.Frames, Line: func(seq uint64, t time.Time) string {
+		return string(serial.COBS{}.Encode(makeFrame(seq, t, size)))
+	}}
+	send := func(frame string) error {
+		_, err := tx.Write([]byte(frame))
+		return err
+	}
+	var sent int
+	var err error
+	if o.Rate > 0 {
+		sent, err = g.Run(ctx, send)
+	} else {
+		for sent = 0; o.Frames == 0 || sent < o.Frames; sent++ {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+			if err = send(g.Line(uint64(sent), time.Now())); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return sent, err
+	}
+	return sent, nil
+}
+
+// makeFrame returns frame seq, sent at t, with size pattern bytes, before COBS
+// encoding.
+func makeFrame(seq uint64, t time.Time, size int) []byte {
+	b := make([]byte, headerLen, headerLen+size)
+	binary.BigEndian.PutUint32(b, uint32(seq))
+	binary.BigEndian.PutUint64(b[4:], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint32(b[12:], crc32.ChecksumIEEE(b[:12]))
+	return appendPattern(b, uint32(seq), size)
+}
+
+// appendPattern appends size bytes of PRBS-15 seeded from seq to b.
+func appendPattern(b []byte, seq uint32, size int) []byte {
+	s := uint16(seq%0x7fff + 1) // any non-zero state
+	for range size {
+		var c byte
+		for range 8 {
+			bit := (s>>14 ^ s>>13) & 1
+			s = (s<<1 | bit) & 0x7fff
+			c = c<<1 | byte(bit)
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// checker is the serial.Framer and frame check of the receiving side. Frames
+// whose COBS encoding is broken count as corrupt instead of ending the read
+// loop.
+type checker struct {
+	size    int
+	latency bool
+	want    []byte
+
+	mu     sync.Mutex
+	r      Result
+	seen   map[uint32]bool
+	maxSeq int
+	target int           // frames that end the test; 0 while unknown
+	all    chan struct{} // closed once target frames have been received
+	ended  bool
+}
+
+func newChecker(size int, latency bool) *checker {
+	return &checker{size: size, latency: latency, seen: make(map[uint32]bool), all: make(chan struct{})}
+}
+
+// expect sets the number of frames that ends the test; they may all have
+// arrived already.
+func (c *checker) expect(frames int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.target = frames
+	c.endIfDone()
+}
+
+// endIfDone closes all, once, when target frames have been received. Frames
+// keep arriving until the port is closed, so it is called for each of them.
+// c.mu must be held.
+func (c *checker) endIfDone() {
+	if !c.ended && c.target > 0 && c.r.Received >= c.target {
+		c.ended = true
+		close(c.all)
+	}
+}
+
+// Frame implements serial.Framer.
+func (c *checker) Frame(data []byte) (int, []byte, error) {
+	n, frame, err := serial.COBS{}.Frame(data)
+	c.mu.Lock()
+	c.r.Bytes += n
+	if errors.Is(err, serial.ErrBadFrame) {
+		c.r.Corrupt++
+		err = nil
+	}
+	c.mu.Unlock()
+	return n, frame, err
+}
+
+// check verifies one decoded frame.
+func (c *checker) check(frame []byte) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(frame) != headerLen+c.size || binary.BigEndian.Uint32(frame[12:]) != crc32.ChecksumIEEE(frame[:12]) {
+		c.r.Corrupt++
+		return
+	}
+	seq := binary.BigEndian.Uint32(frame)
+	if c.seen[seq] {
+		return
+	}
+	c.seen[seq] = true
+	c.r.Received++
+	c.endIfDone()
+	c.maxSeq = max(c.maxSeq, int(seq)+1)
+	c.want = appendPattern(c.want[:0], seq, c.size)
+	errs := 0
+	for i, b := range frame[headerLen:] {
+		errs += bits.OnesCount8(b ^ c.want[i])
+	}
+	c.r.Bits += uint64(8 * c.size)
+	c.r.BitErrors += uint64(errs)
+	if errs > 0 {
+		c.r.Errored++
+	}
+	if c.latency {
+		sent := time.Unix(0, int64(binary.BigEndian.Uint64(frame[4:])))
+		c.r.Latencies = append(c.r.Latencies, now.Sub(sent))
+	}
+}
+
+// result returns the measurements so far.
+func (c *checker) result() *Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.r
+	slices.Sort(r.Latencies)
+	return &r
+}
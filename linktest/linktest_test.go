@@ -0,0 +1,126 @@
+package linktest
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// ptyPair returns the device path of a new PTY and its master side.
+func ptyPair(t *testing.T) (string, *os.File) {
+	t.Helper()
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+	return slave.Name(), master
+}
+
+func TestRun(t *testing.T) {
+	dev, master := ptyPair(t)
+	go io.Copy(master, master) // loopback plug
+
+	r, err := Run(context.Background(), Options{Device: dev, Size: 100, Rate: 2000, Frames: 100})
+	require.NoError(t, err)
+	require.Equal(t, 100, r.Sent)
+	require.Equal(t, 100, r.Received)
+	require.Zero(t, r.Lost())
+	require.Zero(t, r.Corrupt)
+	require.EqualValues(t, 100*100*8, r.Bits)
+	require.Zero(t, r.BER())
+	require.Len(t, r.Latencies, 100)
+	require.Greater(t, r.Bytes, 100*(headerLen+100))
+
+	var b strings.Builder
+	r.Report(&b)
+	require.Contains(t, b.String(), "lost 0, corrupt 0")
+	require.Contains(t, b.String(), "BER 0\n")
+}
+
+func TestTransmitReceive(t *testing.T) {
+	txDev, txMaster := ptyPair(t)
+	rxDev, rxMaster := ptyPair(t)
+	go io.Copy(rxMaster, txMaster) // the cable
+
+	tx := Options{Config: serial.Config{BaudRate: 9600}, Device: txDev, Size: 16, Frames: 50}
+	rx := tx
+	rx.Device, rx.Duration = rxDev, 5*time.Second
+	results := make(chan *Result, 1)
+	go func() {
+		r, _ := Receive(context.Background(), rx)
+		results <- r
+	}()
+	time.Sleep(50 * time.Millisecond) // let Receive open its port
+	sent, err := Transmit(context.Background(), tx)
+	require.NoError(t, err)
+	require.Equal(t, 50, sent)
+	r := <-results
+	require.NotNil(t, r)
+	require.Equal(t, 50, r.Sent)
+	require.Equal(t, 50, r.Received)
+	require.Nil(t, r.Latencies)
+}
+
+func TestChecker(t *testing.T) {
+	c := newChecker(32, false)
+	f := makeFrame(7, time.Now(), 32)
+	f[headerLen] ^= 0x81
+	f[headerLen+31] ^= 0x01
+	c.check(f)
+	c.check(f) // a duplicate is ignored
+	c.check(makeFrame(9, time.Now(), 32))
+	bad := makeFrame(8, time.Now(), 32)
+	bad[0] ^= 1 // the header checksum catches it
+	c.check(bad)
+	c.check(makeFrame(10, time.Now(), 16))
+
+	r := c.result()
+	require.Equal(t, 2, r.Received)
+	require.Equal(t, 2, r.Corrupt)
+	require.Equal(t, 1, r.Errored)
+	require.EqualValues(t, 3, r.BitErrors)
+	require.EqualValues(t, 2*32*8, r.Bits)
+	require.Equal(t, 10, c.maxSeq)
+
+	// Broken COBS framing counts as corrupt without an error.
+	n, frame, err := c.Frame([]byte{5, 1, 0})
+	require.NoError(t, err)
+	require.Nil(t, frame)
+	require.Equal(t, 3, n)
+	require.Equal(t, 3, c.result().Corrupt)
+}
+
+func TestChecker_FramesAfterTarget(t *testing.T) {
+	c := newChecker(32, false)
+	c.expect(1)
+	c.check(makeFrame(0, time.Now(), 32))
+	select {
+	case <-c.all:
+	default:
+		t.Fatal("all not closed at the target")
+	}
+
+	// Frames keep arriving until the port is closed.
+	bad := makeFrame(1, time.Now(), 32)
+	bad[0] ^= 1
+	c.check(bad)
+	c.check(makeFrame(0, time.Now(), 32))
+	c.check(makeFrame(2, time.Now(), 32))
+	require.Equal(t, 2, c.result().Received)
+	require.Equal(t, 1, c.result().Corrupt)
+}
+
+func TestResult_Percentile(t *testing.T) {
+	r := &Result{Latencies: []time.Duration{1, 2, 3, 4, 5}}
+	require.Equal(t, time.Duration(3), r.Percentile(0.5))
+	require.Equal(t, time.Duration(1), r.Percentile(-1))
+	require.Equal(t, time.Duration(5), r.Percentile(2))
+	require.Zero(t, (&Result{}).Percentile(0.5))
+}
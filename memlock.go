@@ -0,0 +1,29 @@
+package serial
+
+import "errors"
+
+// ErrMemlockLimit is returned by LockMemory when RLIMIT_MEMLOCK is finite
+// and the process lacks CAP_IPC_LOCK.
+var ErrMemlockLimit = errors.New("serial: locked-memory limit too low for mlockall")
+
+// LockMemory locks all current and future pages of the process in RAM
+// (mlockall), so page faults cannot stall the read loops on a
+// memory-pressured gateway. It affects the whole process, not just the
+// package, and is best called once at startup.
+//
+// With MCL_FUTURE every later heap growth must fit under RLIMIT_MEMLOCK, and
+// the Go runtime aborts when it cannot map memory. LockMemory therefore
+// refuses with ErrMemlockLimit unless the limit is unlimited or the process
+// has CAP_IPC_LOCK (e.g. root, or LimitMEMLOCK=infinity in a systemd unit).
+// On Windows it fails with errors.ErrUnsupported.
+func LockMemory() error {
+	if !canLockAll() {
+		return ErrMemlockLimit
+	}
+	return lockAll()
+}
+
+// UnlockMemory undoes LockMemory.
+func UnlockMemory() error {
+	return unlockAll()
+}
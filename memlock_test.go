@@ -0,0 +1,26 @@
+package serial
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockMemory(t *testing.T) {
+	err := LockMemory()
+	if errors.Is(err, ErrMemlockLimit) || errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.ENOMEM) {
+		t.Skip("memory locking not permitted:", err)
+	}
+	require.NoError(t, err)
+	defer UnlockMemory()
+
+	// The process keeps working with all memory locked.
+	reader, master := newTestReader(t, Config{})
+	_, err = master.Write([]byte("locked\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "locked", line)
+}
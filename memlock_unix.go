@@ -0,0 +1,41 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// capIPCLock is the CAP_IPC_LOCK capability bit.
+const capIPCLock = 14
+
+// canLockAll reports whether mlockall cannot run into RLIMIT_MEMLOCK.
+func canLockAll() bool {
+	var lim unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_MEMLOCK, &lim); err == nil && lim.Cur == unix.RLIM_INFINITY {
+		return true
+	}
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if hex, ok := strings.CutPrefix(line, "CapEff:"); ok {
+			caps, err := strconv.ParseUint(strings.TrimSpace(hex), 16, 64)
+			return err == nil && caps&(1<<capIPCLock) != 0
+		}
+	}
+	return false
+}
+
+func lockAll() error {
+	return unix.Mlockall(unix.MCL_CURRENT | unix.MCL_FUTURE)
+}
+
+func unlockAll() error {
+	return unix.Munlockall()
+}
@@ -0,0 +1,17 @@
+package serial
+
+import "errors"
+
+// Windows has no mlockall.
+
+func canLockAll() bool {
+	return true
+}
+
+func lockAll() error {
+	return errors.ErrUnsupported
+}
+
+func unlockAll() error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,74 @@
+package serial
+
+import "strings"
+
+// Middleware wraps a line handler. A middleware may drop a line by not calling
+// next, rewrite it before passing it on, or simply observe it.
+type Middleware func(next func(string)) func(string)
+
+// Chain wraps h with the given middleware. The first middleware is outermost,
+// so it sees each line first.
+func Chain(h func(string), mws ...Middleware) func(string) {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Filter returns a Middleware that only passes lines for which keep returns true.
+func Filter(keep func(string) bool) Middleware {
+	return func(next func(string)) func(string) {
+		return func(line string) {
+			if keep(line) {
+				next(line)
+			}
+		}
+	}
+}
+
+// Transform returns a Middleware that replaces each line with fn(line),
+// e.g. to strip a checksum suffix, convert units or prepend an annotation.
+func Transform(fn func(string) string) Middleware {
+	return func(next func(string)) func(string) {
+		return func(line string) {
+			next(fn(line))
+		}
+	}
+}
+
+// Tap returns a Middleware that calls fn with each line before passing it on
+// unchanged, e.g. for debug logging.
+func Tap(fn func(string)) Middleware {
+	return func(next func(string)) func(string) {
+		return func(line string) {
+			fn(line)
+			next(line)
+		}
+	}
+}
+
+// Records returns a Middleware that assembles multi-line records: a line
+// beginning with start opens a record and a line beginning with end closes
+// it, and the whole record, sentinel lines included, is passed on as one
+// string with its lines joined by "\n". Lines outside a record are dropped,
+// and a start line inside a record discards the unfinished one.
+//
+//	cfg.Middleware = []serial.Middleware{serial.Records("BEGIN", "END")}
+func Records(start, end string) Middleware {
+	return func(next func(string)) func(string) {
+		var rec []string
+		return func(line string) {
+			switch {
+			case strings.HasPrefix(line, start):
+				rec = append(rec[:0], line)
+			case rec == nil:
+				// outside a record
+			case strings.HasPrefix(line, end):
+				next(strings.Join(append(rec, line), "\n"))
+				rec = nil
+			default:
+				rec = append(rec, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package serial
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain_Order(t *testing.T) {
+	var got []string
+	var seen []string
+	h := Chain(
+		func(l string) { got = append(got, l) },
+		Tap(func(l string) { seen = append(seen, l) }),
+		Filter(func(l string) bool { return !strings.HasPrefix(l, "#") }),
+		Transform(strings.ToUpper),
+		Transform(func(l string) string { return l + "!" }),
+	)
+
+	h("# comment")
+	h("abc")
+
+	require.Equal(t, []string{"# comment", "abc"}, seen)
+	require.Equal(t, []string{"ABC!"}, got)
+}
+
+func TestSerialReader_Middleware(t *testing.T) {
+	stripChecksum := Transform(func(l string) string {
+		if i := strings.LastIndexByte(l, '*'); i >= 0 {
+			return l[:i]
+		}
+		return l
+	})
+	reader, master := newTestReader(t, Config{
+		Middleware: []Middleware{
+			Filter(func(l string) bool { return l != "" }),
+			stripChecksum,
+		},
+	})
+
+	lines := make(chan string, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) {})
+
+	_, err := master.Write([]byte("\n$GPGGA,1*5B\n"))
+	require.NoError(t, err)
+
+	select {
+	case l := <-lines:
+		require.Equal(t, "$GPGGA,1", l)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for line")
+	}
+}
+
+func TestDecimator(t *testing.T) {
+	var got []string
+	d := &Decimator{Every: 3}
+	h := Chain(func(l string) { got = append(got, l) }, d.Middleware())
+	for _, l := range strings.Split("a b c d e f g", " ") {
+		h(l)
+	}
+	require.Equal(t, []string{"a", "d", "g"}, got)
+	require.EqualValues(t, 4, d.Dropped())
+
+	got = nil
+	d = &Decimator{MaxRate: 20} // one line per 50ms
+	h = Chain(func(l string) { got = append(got, l) }, d.Middleware())
+	h("a")
+	h("b")
+	time.Sleep(60 * time.Millisecond)
+	h("c")
+	require.Equal(t, []string{"a", "c"}, got)
+	require.EqualValues(t, 1, d.Dropped())
+}
+
+func TestRecords(t *testing.T) {
+	var got []string
+	h := Chain(func(l string) { got = append(got, l) }, Records("BEGIN", "END"))
+	for _, l := range []string{"noise", "BEGIN 1", "t=20.5", "p=1013", "END", "BEGIN 2", "t=21", "BEGIN 3", "t=22", "END"} {
+		h(l)
+	}
+	require.Equal(t, []string{"BEGIN 1\nt=20.5\np=1013\nEND", "BEGIN 3\nt=22\nEND"}, got)
+}
@@ -0,0 +1,201 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Client is a Modbus RTU master. It is safe for concurrent use; requests are
+// serialised on the bus.
+type Client struct {
+	// Timeout bounds the wait for a response to each attempt. Zero means one second.
+	Timeout time.Duration
+	// Retries is the number of extra attempts after a timeout or corrupted
+	// response. Exception responses are never retried.
+	Retries int
+	// Silence overrides the inter-frame interval derived from the baud rate,
+	// e.g. for USB adapters whose latency exceeds 1.75ms.
+	Silence time.Duration
+
+	t    Transport
+	baud int
+
+	mu   sync.Mutex
+	last time.Time // end of the last bus activity
+}
+
+// NewClient returns a Client sending requests over t at the given baud rate.
+func NewClient(t Transport, baud int) *Client {
+	return &Client{t: t, baud: baud}
+}
+
+func (c *Client) silence() time.Duration {
+	if c.Silence > 0 {
+		return c.Silence
+	}
+	return Silence(c.baud)
+}
+
+// Send sends a raw request PDU (function code followed by data) to slave and
+// returns the response PDU. Slave 0 broadcasts: nothing is read back and the
+// returned PDU is nil.
+func (c *Client) Send(slave byte, pdu []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	req := appendCRC(append([]byte{slave}, pdu...))
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = time.Second
+	}
+	var err error
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		var resp []byte
+		resp, err = c.roundTrip(req, timeout)
+		if err == nil {
+			if slave == 0 {
+				return nil, nil
+			}
+			return c.check(slave, pdu[0], resp)
+		}
+		if !isTimeout(err) && !errors.Is(err, ErrCRC) && !errors.Is(err, ErrShortFrame) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (c *Client) roundTrip(req []byte, timeout time.Duration) ([]byte, error) {
+	silence := c.silence()
+	// Keep the bus quiet for t3.5 between frames.
+	if wait := time.Until(c.last.Add(silence)); wait > 0 {
+		time.Sleep(wait)
+	}
+	defer func() { c.last = time.Now() }()
+	if _, err := c.t.Write(req); err != nil {
+		return nil, err
+	}
+	if req[0] == 0 {
+		return nil, nil
+	}
+	frame, err := readFrame(c.t, time.Now().Add(timeout), silence, responseLen)
+	if err != nil {
+		return nil, err
+	}
+	return checkFrame(frame)
+}
+
+// check validates a CRC-checked response against the request.
+func (c *Client) check(slave, fn byte, resp []byte) ([]byte, error) {
+	if resp[0] != slave {
+		return nil, ErrUnexpectedResponse
+	}
+	switch resp[1] {
+	case fn:
+		return resp[1:], nil
+	case fn | 0x80:
+		if len(resp) < 3 {
+			return nil, ErrShortFrame // no exception code
+		}
+		return nil, &ExceptionError{Function: fn, Code: resp[2]}
+	}
+	return nil, ErrUnexpectedResponse
+}
+
+func (c *Client) readBits(slave, fn byte, addr, qty uint16) ([]bool, error) {
+	resp, err := c.Send(slave, be(fn, addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 || int(resp[1]) != (int(qty)+7)/8 || len(resp) != 2+int(resp[1]) {
+		return nil, ErrUnexpectedResponse
+	}
+	return unpackBits(resp[2:], int(qty)), nil
+}
+
+func (c *Client) readRegisters(slave, fn byte, addr, qty uint16) ([]uint16, error) {
+	resp, err := c.Send(slave, be(fn, addr, qty))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 || int(resp[1]) != 2*int(qty) || len(resp) != 2+int(resp[1]) {
+		return nil, ErrUnexpectedResponse
+	}
+	regs := make([]uint16, qty)
+	for i := range regs {
+		regs[i] = binary.BigEndian.Uint16(resp[2+2*i:])
+	}
+	return regs, nil
+}
+
+// ReadCoils reads qty coils starting at addr (function 1).
+func (c *Client) ReadCoils(slave byte, addr, qty uint16) ([]bool, error) {
+	return c.readBits(slave, FuncReadCoils, addr, qty)
+}
+
+// ReadDiscreteInputs reads qty discrete inputs starting at addr (function 2).
+func (c *Client) ReadDiscreteInputs(slave byte, addr, qty uint16) ([]bool, error) {
+	return c.readBits(slave, FuncReadDiscreteInputs, addr, qty)
+}
+
+// ReadHoldingRegisters reads qty holding registers starting at addr (function 3).
+func (c *Client) ReadHoldingRegisters(slave byte, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(slave, FuncReadHoldingRegisters, addr, qty)
+}
+
+// ReadInputRegisters reads qty input registers starting at addr (function 4).
+func (c *Client) ReadInputRegisters(slave byte, addr, qty uint16) ([]uint16, error) {
+	return c.readRegisters(slave, FuncReadInputRegisters, addr, qty)
+}
+
+// WriteSingleCoil sets the coil at addr (function 5).
+func (c *Client) WriteSingleCoil(slave byte, addr uint16, on bool) error {
+	v := uint16(0x0000)
+	if on {
+		v = 0xFF00
+	}
+	return c.write(slave, be(FuncWriteSingleCoil, addr, v))
+}
+
+// WriteSingleRegister writes the holding register at addr (function 6).
+func (c *Client) WriteSingleRegister(slave byte, addr, value uint16) error {
+	return c.write(slave, be(FuncWriteSingleRegister, addr, value))
+}
+
+// WriteMultipleCoils writes consecutive coils starting at addr (function 15).
+func (c *Client) WriteMultipleCoils(slave byte, addr uint16, values []bool) error {
+	data := packBits(values)
+	pdu := append(be(FuncWriteMultipleCoils, addr, uint16(len(values))), byte(len(data)))
+	return c.write(slave, append(pdu, data...))
+}
+
+// WriteMultipleRegisters writes consecutive holding registers starting at addr (function 16).
+func (c *Client) WriteMultipleRegisters(slave byte, addr uint16, values []uint16) error {
+	pdu := append(be(FuncWriteMultipleRegisters, addr, uint16(len(values))), byte(2*len(values)))
+	for _, v := range values {
+		pdu = binary.BigEndian.AppendUint16(pdu, v)
+	}
+	return c.write(slave, pdu)
+}
+
+// write sends a write request; the normal response echoes its first four data bytes.
+func (c *Client) write(slave byte, pdu []byte) error {
+	resp, err := c.Send(slave, pdu)
+	if err != nil || slave == 0 {
+		return err
+	}
+	if len(resp) != 5 || string(resp[1:5]) != string(pdu[1:5]) {
+		return ErrUnexpectedResponse
+	}
+	return nil
+}
+
+// be builds a PDU from a function code and big-endian 16-bit fields.
+func be(fn byte, fields ...uint16) []byte {
+	pdu := []byte{fn}
+	for _, f := range fields {
+		pdu = binary.BigEndian.AppendUint16(pdu, f)
+	}
+	return pdu
+}
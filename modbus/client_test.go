@@ -0,0 +1,158 @@
+//go:build linux || darwin || freebsd
+
+package modbus
+
+import (
+	"encoding/binary"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// newTestBus opens a SerialReader on a fresh PTY and returns it together with
+// the master end, which plays the other station. The master is non-blocking
+// so that read deadlines work on it.
+func newTestBus(t *testing.T) (*serial.SerialReader, *os.File) {
+	t.Helper()
+	ptmx, slave, err := pty.Open()
+	require.NoError(t, err)
+	fd, err := unix.Dup(int(ptmx.Fd()))
+	ptmx.Close()
+	require.NoError(t, err)
+	require.NoError(t, unix.SetNonblock(fd, true))
+	master := os.NewFile(uintptr(fd), "/dev/ptmx")
+	reader, err := serial.Open(serial.Config{Device: slave.Name(), BaudRate: 115200})
+	require.NoError(t, err)
+	// Only the reader keeps the slave side open, so master reads fail once it closes.
+	slave.Close()
+	t.Cleanup(func() { reader.Close(); master.Close() })
+	return reader, master
+}
+
+// fakeSlave answers each CRC-valid request read from master with reply(req),
+// where req excludes the CRC; a nil reply sends nothing.
+func fakeSlave(master *os.File, reply func(req []byte) []byte) {
+	go func() {
+		var frame []byte
+		buf := make([]byte, 256)
+		for {
+			n, err := master.Read(buf)
+			if err != nil {
+				return
+			}
+			frame = append(frame, buf[:n]...)
+			req, err := checkFrame(frame)
+			if err != nil {
+				continue
+			}
+			frame = nil
+			if resp := reply(req); resp != nil {
+				master.Write(appendCRC(resp))
+			}
+		}
+	}()
+}
+
+func TestCRC16(t *testing.T) {
+	require.Equal(t, []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD},
+		appendCRC([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A}))
+	_, err := checkFrame([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCE})
+	require.ErrorIs(t, err, ErrCRC)
+}
+
+func TestSilence(t *testing.T) {
+	require.Equal(t, 1750*time.Microsecond, Silence(115200))
+	require.InDelta(t, float64(4010*time.Microsecond), float64(Silence(9600)), float64(time.Microsecond))
+}
+
+func TestClient_Functions(t *testing.T) {
+	bus, master := newTestBus(t)
+	regs := map[uint16]uint16{10: 0x1234, 11: 0xBEEF}
+	coils := []bool{true, false, true, true, false, false, false, false, true}
+	fakeSlave(master, func(req []byte) []byte {
+		addr := binary.BigEndian.Uint16(req[2:])
+		qty := binary.BigEndian.Uint16(req[4:])
+		switch req[1] {
+		case FuncReadHoldingRegisters:
+			resp := []byte{req[0], req[1], byte(2 * qty)}
+			for i := uint16(0); i < qty; i++ {
+				resp = binary.BigEndian.AppendUint16(resp, regs[addr+i])
+			}
+			return resp
+		case FuncReadCoils:
+			data := packBits(coils[addr : addr+qty])
+			return append([]byte{req[0], req[1], byte(len(data))}, data...)
+		case FuncWriteSingleRegister:
+			regs[addr] = qty
+			return req
+		case FuncWriteMultipleRegisters:
+			for i := uint16(0); i < qty; i++ {
+				regs[addr+i] = binary.BigEndian.Uint16(req[7+2*i:])
+			}
+			return req[:6]
+		}
+		return []byte{req[0], req[1] | 0x80, ExceptionIllegalFunction}
+	})
+
+	c := NewClient(bus, 115200)
+	got, err := c.ReadHoldingRegisters(1, 10, 2)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{0x1234, 0xBEEF}, got)
+
+	bits, err := c.ReadCoils(1, 0, 9)
+	require.NoError(t, err)
+	require.Equal(t, coils, bits)
+
+	require.NoError(t, c.WriteSingleRegister(1, 12, 7))
+	require.NoError(t, c.WriteMultipleRegisters(1, 20, []uint16{1, 2, 3}))
+	got, err = c.ReadHoldingRegisters(1, 20, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{1, 2, 3}, got)
+
+	_, err = c.ReadInputRegisters(1, 0, 1)
+	var exc *ExceptionError
+	require.ErrorAs(t, err, &exc)
+	require.Equal(t, ExceptionIllegalFunction, exc.Code)
+	require.Equal(t, FuncReadInputRegisters, exc.Function)
+}
+
+func TestClient_RetriesAfterTimeout(t *testing.T) {
+	bus, master := newTestBus(t)
+	var requests atomic.Int32
+	fakeSlave(master, func(req []byte) []byte {
+		if requests.Add(1) <= 2 {
+			return nil // lost request
+		}
+		return []byte{req[0], req[1], 2, 0x00, 0x2A}
+	})
+
+	c := NewClient(bus, 115200)
+	c.Timeout = 50 * time.Millisecond
+	got, err := c.ReadHoldingRegisters(7, 0, 1)
+	require.ErrorIs(t, err, serial.ErrTimeout)
+	require.Nil(t, got)
+
+	c.Retries = 1
+	got, err = c.ReadHoldingRegisters(7, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{42}, got)
+	require.EqualValues(t, 3, requests.Load())
+}
+
+func TestClient_ExceptionWithoutCode(t *testing.T) {
+	bus, master := newTestBus(t)
+	// A CRC-valid 4-byte ADU: address and exception function, but no code.
+	fakeSlave(master, func(req []byte) []byte { return []byte{req[0], req[1] | 0x80} })
+
+	c := NewClient(bus, 115200)
+	c.Timeout = 50 * time.Millisecond
+	_, err := c.ReadHoldingRegisters(7, 0, 1)
+	require.ErrorIs(t, err, ErrShortFrame)
+}
@@ -0,0 +1,45 @@
+package modbus
+
+import (
+	"fmt"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+func init() {
+	serial.RegisterFramer("modbus-rtu", func(serial.Config) serial.Framer { return RTUFramer{} })
+}
+
+// RTUFramer is a serial.Framer that picks Modbus RTU responses out of a
+// byte stream, for monitoring a bus with ReadFramesLoop rather than polling
+// it with Client. It is registered as "modbus-rtu". A frame is sized from
+// its function code and byte count instead of the silent interval, and
+// checked by CRC; the frame passed on is the ADU without the CRC. After a
+// CRC mismatch, reported as serial.ErrBadFrame, and before an unknown
+// function code it skips one byte to resynchronise. As a serial.Encoder it
+// appends the CRC to an ADU, so serial.FrameWriter can send requests.
+type RTUFramer struct{}
+
+// Encode implements serial.Encoder.
+func (RTUFramer) Encode(adu []byte) []byte {
+	return appendCRC(adu[:len(adu):len(adu)])
+}
+
+// Frame implements serial.Framer.
+func (RTUFramer) Frame(data []byte) (int, []byte, error) {
+	l := responseLen(data)
+	if l == 0 {
+		if len(data) >= 3 {
+			return 1, nil, nil // not a function code we can size
+		}
+		return 0, nil, nil
+	}
+	if len(data) < l {
+		return 0, nil, nil
+	}
+	body, err := checkFrame(data[:l])
+	if err != nil {
+		return 1, nil, fmt.Errorf("%w: %w", serial.ErrBadFrame, err)
+	}
+	return l, body, nil
+}
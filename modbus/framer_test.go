@@ -0,0 +1,47 @@
+package modbus
+
+import (
+	"testing"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTUFramer(t *testing.T) {
+	f, err := serial.NewFramer("modbus-rtu", serial.Config{})
+	require.NoError(t, err)
+
+	resp := appendCRC([]byte{0x11, FuncReadHoldingRegisters, 0x02, 0x00, 0x2A})
+	advance, frame, err := f.Frame(resp[:4])
+	require.NoError(t, err)
+	require.Zero(t, advance)
+	require.Nil(t, frame)
+
+	stream := append([]byte{0x11, 0x42, 0x00}, resp...) // noise first
+	advance, frame, err = f.Frame(stream)
+	require.NoError(t, err)
+	require.Equal(t, 1, advance)
+	require.Nil(t, frame)
+	stream = stream[1:]
+	for len(stream) > len(resp) {
+		advance, _, _ = f.Frame(stream)
+		stream = stream[advance:]
+	}
+	advance, frame, err = f.Frame(stream)
+	require.NoError(t, err)
+	require.Equal(t, len(resp), advance)
+	require.Equal(t, resp[:len(resp)-2], frame)
+
+	resp[len(resp)-1] ^= 0xFF
+	advance, _, err = f.Frame(resp)
+	require.ErrorIs(t, err, serial.ErrBadFrame)
+	require.ErrorIs(t, err, ErrCRC)
+	require.Equal(t, 1, advance)
+}
+
+func TestRTUFramer_Encode(t *testing.T) {
+	adu := []byte{0x11, FuncReadHoldingRegisters, 0x00, 0x6B, 0x00, 0x03}
+	frame := RTUFramer{}.Encode(adu)
+	require.Equal(t, appendCRC([]byte{0x11, FuncReadHoldingRegisters, 0x00, 0x6B, 0x00, 0x03}), frame)
+	require.Len(t, adu, 6)
+}
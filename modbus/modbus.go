@@ -0,0 +1,186 @@
+// Package modbus implements the Modbus RTU protocol over a serial byte stream,
+// typically a *serial.SerialReader used through its raw Read/Write path.
+//
+// Frames are delimited by the 3.5-character silent interval required by the
+// specification (fixed at 1.75ms above 19200 baud) and protected by CRC-16.
+package modbus
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// Function codes supported by Client and Server.
+const (
+	FuncReadCoils              byte = 0x01
+	FuncReadDiscreteInputs     byte = 0x02
+	FuncReadHoldingRegisters   byte = 0x03
+	FuncReadInputRegisters     byte = 0x04
+	FuncWriteSingleCoil        byte = 0x05
+	FuncWriteSingleRegister    byte = 0x06
+	FuncWriteMultipleCoils     byte = 0x0F
+	FuncWriteMultipleRegisters byte = 0x10
+)
+
+// Exception codes carried by exception responses.
+const (
+	ExceptionIllegalFunction    byte = 0x01
+	ExceptionIllegalDataAddress byte = 0x02
+	ExceptionIllegalDataValue   byte = 0x03
+	ExceptionDeviceFailure      byte = 0x04
+)
+
+var (
+	// ErrCRC reports a frame whose CRC does not match its contents.
+	ErrCRC = errors.New("modbus: crc mismatch")
+	// ErrShortFrame reports a frame too short to hold an address, function and CRC.
+	ErrShortFrame = errors.New("modbus: short frame")
+	// ErrUnexpectedResponse reports a well-formed response that does not answer the request.
+	ErrUnexpectedResponse = errors.New("modbus: unexpected response")
+)
+
+// ExceptionError is an exception response from a slave.
+type ExceptionError struct {
+	Function byte // function code of the request
+	Code     byte // exception code
+}
+
+func (e *ExceptionError) Error() string {
+	return fmt.Sprintf("modbus: exception %#02x for function %#02x", e.Code, e.Function)
+}
+
+// Transport is the byte stream Modbus frames travel over. *serial.SerialReader
+// implements it, as does a pollable *os.File.
+type Transport interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+}
+
+// Silence returns the 3.5-character inter-frame interval for baud, assuming
+// 11 bits per character; above 19200 baud it is the fixed 1.75ms.
+func Silence(baud int) time.Duration {
+	if baud <= 0 || baud > 19200 {
+		return 1750 * time.Microsecond
+	}
+	return time.Duration(3.5 * 11 * float64(time.Second) / float64(baud))
+}
+
+// CRC16 computes the Modbus CRC-16 (polynomial 0xA001, initial value 0xFFFF).
+// It is appended to frames low byte first.
+func CRC16(b []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, c := range b {
+		crc ^= uint16(c)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// appendCRC appends the CRC of frame to it.
+func appendCRC(frame []byte) []byte {
+	crc := CRC16(frame)
+	return append(frame, byte(crc), byte(crc>>8))
+}
+
+// checkFrame validates the CRC of an ADU and returns it without the CRC.
+func checkFrame(adu []byte) ([]byte, error) {
+	if len(adu) < 4 {
+		return nil, ErrShortFrame
+	}
+	body := adu[:len(adu)-2]
+	if CRC16(body) != uint16(adu[len(adu)-2])|uint16(adu[len(adu)-1])<<8 {
+		return nil, ErrCRC
+	}
+	return body, nil
+}
+
+// isTimeout reports whether err is a read deadline expiring.
+func isTimeout(err error) bool {
+	return errors.Is(err, serial.ErrTimeout) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// readFrame reads one frame: it waits until deadline for the first byte, then
+// collects bytes until the line has been silent for silence, or until want
+// (if non-nil) reports the frame complete.
+func readFrame(t Transport, deadline time.Time, silence time.Duration, want func([]byte) int) ([]byte, error) {
+	var frame []byte
+	buf := make([]byte, 256)
+	if err := t.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	defer t.SetReadDeadline(time.Time{})
+	for {
+		n, err := t.Read(buf)
+		frame = append(frame, buf[:n]...)
+		if err != nil {
+			if isTimeout(err) && len(frame) > 0 {
+				return frame, nil // end of frame
+			}
+			return frame, err
+		}
+		if want != nil {
+			if l := want(frame); l > 0 && len(frame) >= l {
+				return frame[:l], nil
+			}
+		}
+		if len(frame) > 256 {
+			return frame, ErrShortFrame // runaway; the caller resynchronises
+		}
+		if err := t.SetReadDeadline(time.Now().Add(silence)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// responseLen returns the total length of a response ADU given its first
+// bytes, or 0 if it cannot be determined yet.
+func responseLen(frame []byte) int {
+	if len(frame) < 2 {
+		return 0
+	}
+	fn := frame[1]
+	switch {
+	case fn&0x80 != 0:
+		return 5
+	case fn >= FuncReadCoils && fn <= FuncReadInputRegisters:
+		if len(frame) < 3 {
+			return 0
+		}
+		return 3 + int(frame[2]) + 2
+	case fn == FuncWriteSingleCoil, fn == FuncWriteSingleRegister,
+		fn == FuncWriteMultipleCoils, fn == FuncWriteMultipleRegisters:
+		return 8
+	}
+	return 0
+}
+
+// packBits packs coil values LSB first, as used by function codes 1, 2 and 15.
+func packBits(values []bool) []byte {
+	out := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			out[i/8] |= 1 << (i % 8)
+		}
+	}
+	return out
+}
+
+// unpackBits is the inverse of packBits for n values.
+func unpackBits(b []byte, n int) []bool {
+	out := make([]bool, n)
+	for i := range out {
+		out[i] = b[i/8]&(1<<(i%8)) != 0
+	}
+	return out
+}
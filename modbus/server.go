@@ -0,0 +1,222 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Server is a Modbus RTU slave answering requests addressed to ID through
+// register map callbacks. A nil callback answers with ExceptionIllegalFunction.
+// A callback error that is an *ExceptionError is sent back with its Code;
+// any other error becomes ExceptionDeviceFailure.
+// Broadcast writes (address 0) are executed without a response.
+type Server struct {
+	ID byte
+	// Silence overrides the inter-frame interval derived from the baud rate.
+	Silence time.Duration
+
+	ReadCoils            func(addr, qty uint16) ([]bool, error)
+	ReadDiscreteInputs   func(addr, qty uint16) ([]bool, error)
+	ReadHoldingRegisters func(addr, qty uint16) ([]uint16, error)
+	ReadInputRegisters   func(addr, qty uint16) ([]uint16, error)
+	WriteCoils           func(addr uint16, values []bool) error
+	WriteRegisters       func(addr uint16, values []uint16) error
+
+	t    Transport
+	baud int
+}
+
+// NewServer returns a Server for slave address id on t. Set the callbacks,
+// then call Serve.
+func NewServer(t Transport, id byte, baud int) *Server {
+	return &Server{ID: id, t: t, baud: baud}
+}
+
+// Serve answers requests until the transport fails, e.g. with serial.ErrClosed
+// once the port is closed, and returns that error. Corrupted frames and
+// frames for other addresses are ignored, as the protocol requires.
+func (s *Server) Serve() error {
+	silence := s.Silence
+	if silence <= 0 {
+		silence = Silence(s.baud)
+	}
+	for {
+		frame, err := readFrame(s.t, time.Time{}, silence, requestLen)
+		if err != nil {
+			if isTimeout(err) || errors.Is(err, ErrShortFrame) {
+				continue
+			}
+			return err
+		}
+		req, err := checkFrame(frame)
+		if err != nil || (req[0] != s.ID && req[0] != 0) {
+			continue
+		}
+		resp := s.handle(req[1:])
+		if req[0] == 0 {
+			continue
+		}
+		time.Sleep(silence)
+		if _, err := s.t.Write(appendCRC(append([]byte{s.ID}, resp...))); err != nil {
+			return err
+		}
+	}
+}
+
+// handle executes a request PDU and returns the response PDU.
+func (s *Server) handle(pdu []byte) []byte {
+	fn := pdu[0]
+	resp, err := s.dispatch(fn, pdu[1:])
+	if err != nil {
+		code := ExceptionDeviceFailure
+		var exc *ExceptionError
+		if errors.As(err, &exc) {
+			code = exc.Code
+		}
+		return []byte{fn | 0x80, code}
+	}
+	return append([]byte{fn}, resp...)
+}
+
+func exception(code byte) error { return &ExceptionError{Code: code} }
+
+func (s *Server) dispatch(fn byte, data []byte) ([]byte, error) {
+	switch fn {
+	case FuncReadCoils, FuncReadDiscreteInputs:
+		read := s.ReadCoils
+		if fn == FuncReadDiscreteInputs {
+			read = s.ReadDiscreteInputs
+		}
+		if read == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) != 4 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		addr, qty := binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:])
+		if qty < 1 || qty > 2000 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		values, err := read(addr, qty)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != int(qty) {
+			return nil, exception(ExceptionDeviceFailure)
+		}
+		packed := packBits(values)
+		return append([]byte{byte(len(packed))}, packed...), nil
+
+	case FuncReadHoldingRegisters, FuncReadInputRegisters:
+		read := s.ReadHoldingRegisters
+		if fn == FuncReadInputRegisters {
+			read = s.ReadInputRegisters
+		}
+		if read == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) != 4 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		addr, qty := binary.BigEndian.Uint16(data), binary.BigEndian.Uint16(data[2:])
+		if qty < 1 || qty > 125 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		values, err := read(addr, qty)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != int(qty) {
+			return nil, exception(ExceptionDeviceFailure)
+		}
+		resp := []byte{byte(2 * qty)}
+		for _, v := range values {
+			resp = binary.BigEndian.AppendUint16(resp, v)
+		}
+		return resp, nil
+
+	case FuncWriteSingleCoil:
+		if s.WriteCoils == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) != 4 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		v := binary.BigEndian.Uint16(data[2:])
+		if v != 0x0000 && v != 0xFF00 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		if err := s.WriteCoils(binary.BigEndian.Uint16(data), []bool{v == 0xFF00}); err != nil {
+			return nil, err
+		}
+		return data, nil
+
+	case FuncWriteSingleRegister:
+		if s.WriteRegisters == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) != 4 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		if err := s.WriteRegisters(binary.BigEndian.Uint16(data), []uint16{binary.BigEndian.Uint16(data[2:])}); err != nil {
+			return nil, err
+		}
+		return data, nil
+
+	case FuncWriteMultipleCoils:
+		if s.WriteCoils == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) < 5 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		qty := binary.BigEndian.Uint16(data[2:])
+		if qty < 1 || qty > 1968 || int(data[4]) != (int(qty)+7)/8 || len(data) != 5+int(data[4]) {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		if err := s.WriteCoils(binary.BigEndian.Uint16(data), unpackBits(data[5:], int(qty))); err != nil {
+			return nil, err
+		}
+		return data[:4], nil
+
+	case FuncWriteMultipleRegisters:
+		if s.WriteRegisters == nil {
+			return nil, exception(ExceptionIllegalFunction)
+		}
+		if len(data) < 5 {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		qty := binary.BigEndian.Uint16(data[2:])
+		if qty < 1 || qty > 123 || int(data[4]) != 2*int(qty) || len(data) != 5+int(data[4]) {
+			return nil, exception(ExceptionIllegalDataValue)
+		}
+		values := make([]uint16, qty)
+		for i := range values {
+			values[i] = binary.BigEndian.Uint16(data[5+2*i:])
+		}
+		if err := s.WriteRegisters(binary.BigEndian.Uint16(data), values); err != nil {
+			return nil, err
+		}
+		return data[:4], nil
+	}
+	return nil, exception(ExceptionIllegalFunction)
+}
+
+// requestLen returns the total length of a request ADU given its first bytes,
+// or 0 if it cannot be determined (yet).
+func requestLen(frame []byte) int {
+	if len(frame) < 2 {
+		return 0
+	}
+	switch fn := frame[1]; {
+	case fn >= FuncReadCoils && fn <= FuncWriteSingleRegister:
+		return 8
+	case fn == FuncWriteMultipleCoils, fn == FuncWriteMultipleRegisters:
+		if len(frame) < 7 {
+			return 0
+		}
+		return 7 + int(frame[6]) + 2
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+//go:build linux || darwin || freebsd
+
+package modbus
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+func TestServer_RegisterMap(t *testing.T) {
+	bus, master := newTestBus(t)
+
+	var mu sync.Mutex
+	regs := make([]uint16, 16)
+	coils := make([]bool, 16)
+	srv := NewServer(bus, 5, 115200)
+	srv.ReadHoldingRegisters = func(addr, qty uint16) ([]uint16, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if int(addr)+int(qty) > len(regs) {
+			return nil, &ExceptionError{Code: ExceptionIllegalDataAddress}
+		}
+		return append([]uint16(nil), regs[addr:addr+qty]...), nil
+	}
+	srv.WriteRegisters = func(addr uint16, values []uint16) error {
+		mu.Lock()
+		defer mu.Unlock()
+		copy(regs[addr:], values)
+		return nil
+	}
+	srv.ReadCoils = func(addr, qty uint16) ([]bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]bool(nil), coils[addr:addr+qty]...), nil
+	}
+	srv.WriteCoils = func(addr uint16, values []bool) error {
+		mu.Lock()
+		defer mu.Unlock()
+		copy(coils[addr:], values)
+		return nil
+	}
+	srv.ReadInputRegisters = func(addr, qty uint16) ([]uint16, error) {
+		return nil, errors.New("sensor offline")
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+
+	c := NewClient(master, 115200)
+	c.Timeout = time.Second
+
+	require.NoError(t, c.WriteMultipleRegisters(5, 2, []uint16{100, 200}))
+	require.NoError(t, c.WriteSingleRegister(5, 4, 300))
+	got, err := c.ReadHoldingRegisters(5, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{100, 200, 300}, got)
+
+	require.NoError(t, c.WriteMultipleCoils(5, 0, []bool{true, false, true}))
+	require.NoError(t, c.WriteSingleCoil(5, 9, true))
+	bits, err := c.ReadCoils(5, 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, true, false, false, false, false, false, false, true}, bits)
+
+	var exc *ExceptionError
+	_, err = c.ReadHoldingRegisters(5, 15, 2)
+	require.ErrorAs(t, err, &exc)
+	require.Equal(t, ExceptionIllegalDataAddress, exc.Code)
+	_, err = c.ReadInputRegisters(5, 0, 1)
+	require.ErrorAs(t, err, &exc)
+	require.Equal(t, ExceptionDeviceFailure, exc.Code)
+	_, err = c.ReadDiscreteInputs(5, 0, 1)
+	require.ErrorAs(t, err, &exc)
+	require.Equal(t, ExceptionIllegalFunction, exc.Code)
+
+	// Another slave's address gets no answer; a broadcast write is applied silently.
+	c.Timeout = 50 * time.Millisecond
+	_, err = c.ReadHoldingRegisters(6, 0, 1)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	require.NoError(t, c.WriteSingleRegister(0, 0, 42))
+	c.Timeout = time.Second
+	got, err = c.ReadHoldingRegisters(5, 0, 1)
+	require.NoError(t, err)
+	require.Equal(t, []uint16{42}, got)
+
+	require.NoError(t, bus.Close())
+	select {
+	case err := <-served:
+		require.ErrorIs(t, err, serial.ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Serve did not return after Close")
+	}
+}
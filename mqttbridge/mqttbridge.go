@@ -0,0 +1,308 @@
+// Package mqttbridge publishes the lines received on a serial port to an MQTT
+// broker and writes messages from a command topic back to the port. It
+// speaks MQTT 3.1.1 over plain TCP with QoS 0 or 1, keeps the connection
+// alive with pings and reconnects with backoff when the broker goes away.
+package mqttbridge
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+const (
+	dialTimeout       = 10 * time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// Bridge connects one port to an MQTT broker. Set the exported fields before
+// calling Run.
+type Bridge struct {
+	// ClientID identifies the bridge to the broker.
+	ClientID string
+	// Username and Password, if set, authenticate the connection.
+	Username, Password string
+	// QoS is the quality of service for published lines and the command
+	// subscription: 0 (at most once) or 1 (at least once). Unacknowledged
+	// QoS 1 messages are resent after a reconnect.
+	QoS byte
+	// Retain sets the retain flag on published lines.
+	Retain bool
+	// Format turns a line into the message payload, e.g. to publish a parsed
+	// frame as JSON. A nil result skips the line. Default: the line itself.
+	Format func(line string) []byte
+	// CommandTopic, if set together with Writer, is subscribed to and every
+	// message received on it is written to the port as a line.
+	CommandTopic string
+	Writer       serial.LineWriter
+	// Newline terminates command lines; default "\r\n".
+	Newline string
+	// KeepAlive is the MQTT keep-alive interval. Zero means 30s; the bridge
+	// does not turn keep-alive off, as it relies on the pings to notice a
+	// dead connection.
+	KeepAlive time.Duration
+	// ReconnectDelay is the first wait before reconnecting, doubled after
+	// each failure up to a minute. Zero means 1s.
+	ReconnectDelay time.Duration
+	// Buffer is the number of lines held while the broker is unreachable
+	// (the oldest are dropped first) and the QoS 1 in-flight window.
+	// Zero means 1024.
+	Buffer int
+	// OnError, if set, is called with connection errors before reconnecting.
+	OnError func(error)
+
+	broker string
+	topic  string
+	src    serial.LineSource
+
+	wmu     sync.Mutex
+	conn    net.Conn
+	mu      sync.Mutex
+	pending map[uint16][]byte // QoS 1 PUBLISH packets awaiting PUBACK
+	nextID  uint16
+	acked   chan struct{}
+}
+
+var errSourceClosed = errors.New("mqttbridge: source closed")
+
+// New returns a Bridge that publishes the lines of src to topic on the broker
+// at addr ("host:1883").
+func New(addr, clientID, topic string, src serial.LineSource) *Bridge {
+	return &Bridge{
+		ClientID: clientID,
+		Newline:  "\r\n",
+		broker:   addr,
+		topic:    topic,
+		src:      src,
+		pending:  make(map[uint16][]byte),
+		acked:    make(chan struct{}, 1),
+	}
+}
+
+func (b *Bridge) keepAlive() time.Duration {
+	if b.KeepAlive > 0 {
+		return b.KeepAlive
+	}
+	return 30 * time.Second
+}
+
+func (b *Bridge) reconnectDelay() time.Duration {
+	if b.ReconnectDelay > 0 {
+		return b.ReconnectDelay
+	}
+	return time.Second
+}
+
+func (b *Bridge) buffer() int {
+	if b.Buffer > 0 {
+		return b.Buffer
+	}
+	return 1024
+}
+
+// Run publishes lines until ctx is canceled, returning ctx.Err(), or the port
+// is closed, returning serial.ErrClosed.
+func (b *Bridge) Run(ctx context.Context) error {
+	sub := b.src.Subscribe(b.buffer())
+	sub.SetPolicy(serial.DropOldest)
+	defer sub.Unsubscribe()
+	delay := b.reconnectDelay()
+	for {
+		connected, err := b.session(ctx, sub)
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, errSourceClosed):
+			return serial.ErrClosed
+		}
+		if b.OnError != nil {
+			b.OnError(err)
+		}
+		if connected {
+			delay = b.reconnectDelay()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxReconnectDelay)
+	}
+}
+
+// session runs one broker connection. connected reports whether the broker
+// accepted it.
+func (b *Bridge) session(ctx context.Context, sub *serial.Subscription) (connected bool, err error) {
+	var d net.Dialer
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	conn, err := d.DialContext(dctx, "tcp", b.broker)
+	cancel()
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	br := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+	if _, err := conn.Write(connectPacket(b.ClientID, b.Username, b.Password, uint16(b.keepAlive()/time.Second))); err != nil {
+		return false, err
+	}
+	typ, _, body, err := readPacket(br)
+	if err != nil {
+		return false, err
+	}
+	if typ != pktConnack || len(body) != 2 {
+		return false, errMalformed
+	}
+	if body[1] != 0 {
+		return false, &ConnectError{Code: body[1]}
+	}
+	conn.SetDeadline(time.Time{})
+	b.wmu.Lock()
+	b.conn = conn
+	b.wmu.Unlock()
+
+	if b.CommandTopic != "" && b.Writer != nil {
+		if err := b.send(subscribePacket(1, b.CommandTopic, b.QoS)); err != nil {
+			return true, err
+		}
+	}
+	if err := b.resend(); err != nil {
+		return true, err
+	}
+
+	errc := make(chan error, 1)
+	go func() { errc <- b.receive(conn, br) }()
+	ping := time.NewTicker(b.keepAlive())
+	defer ping.Stop()
+	for {
+		lines := sub.C()
+		if b.QoS > 0 && b.inflight() >= b.buffer() {
+			lines = nil // wait for acknowledgements
+		}
+		select {
+		case <-ctx.Done():
+			b.send(packet(pktDisconnect, 0, nil))
+			return true, ctx.Err()
+		case err := <-errc:
+			return true, err
+		case <-b.acked:
+		case <-ping.C:
+			if err := b.send(packet(pktPingreq, 0, nil)); err != nil {
+				return true, err
+			}
+		case line, ok := <-lines:
+			if !ok {
+				b.send(packet(pktDisconnect, 0, nil))
+				return true, errSourceClosed
+			}
+			if err := b.publish(line); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+func (b *Bridge) publish(line string) error {
+	payload := []byte(line)
+	if b.Format != nil {
+		if payload = b.Format(line); payload == nil {
+			return nil
+		}
+	}
+	if b.QoS == 0 {
+		return b.send(publishPacket(b.topic, payload, 0, b.Retain, false, 0))
+	}
+	b.mu.Lock()
+	for {
+		b.nextID++
+		if _, used := b.pending[b.nextID]; b.nextID != 0 && !used {
+			break
+		}
+	}
+	id := b.nextID
+	pkt := publishPacket(b.topic, payload, b.QoS, b.Retain, false, id)
+	b.pending[id] = pkt
+	b.mu.Unlock()
+	return b.send(pkt)
+}
+
+// resend retransmits unacknowledged QoS 1 messages, oldest first, with the
+// DUP flag set.
+func (b *Bridge) resend() error {
+	b.mu.Lock()
+	ids := make([]uint16, 0, len(b.pending))
+	for id := range b.pending {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i]-b.nextID-1 < ids[j]-b.nextID-1 })
+	pkts := make([][]byte, len(ids))
+	for i, id := range ids {
+		b.pending[id][0] |= 0x08
+		pkts[i] = b.pending[id]
+	}
+	b.mu.Unlock()
+	for _, pkt := range pkts {
+		if err := b.send(pkt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Bridge) inflight() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+func (b *Bridge) send(pkt []byte) error {
+	b.wmu.Lock()
+	defer b.wmu.Unlock()
+	_, err := b.conn.Write(pkt)
+	return err
+}
+
+// receive handles packets from the broker until the connection fails. The
+// broker must answer our pings, so silence for two keep-alive intervals
+// counts as a dead connection.
+func (b *Bridge) receive(conn net.Conn, br *bufio.Reader) error {
+	for {
+		conn.SetReadDeadline(time.Now().Add(2 * b.keepAlive()))
+		typ, flags, body, err := readPacket(br)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case pktPuback:
+			if len(body) != 2 {
+				return errMalformed
+			}
+			b.mu.Lock()
+			delete(b.pending, uint16(body[0])<<8|uint16(body[1]))
+			b.mu.Unlock()
+			select {
+			case b.acked <- struct{}{}:
+			default:
+			}
+		case pktPublish:
+			topic, id, payload, err := parsePublish(flags, body)
+			if err != nil {
+				return err
+			}
+			if topic == b.CommandTopic && b.Writer != nil {
+				b.Writer.WriteLine(string(payload), b.Newline)
+			}
+			if (flags>>1)&0x03 > 0 {
+				if err := b.send(pubackPacket(id)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package mqttbridge
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPort(t *testing.T) (*serial.SerialReader, *serialtest.Simulator) {
+	t.Helper()
+	sim, err := serialtest.NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	port, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\r\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+	return port, sim
+}
+
+// broker accepts one connection, answers CONNECT and returns the client's
+// packet reader.
+func broker(t *testing.T, l net.Listener) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	br := bufio.NewReader(conn)
+	typ, _, body, err := readPacket(br)
+	require.NoError(t, err)
+	require.EqualValues(t, pktConnect, typ)
+	require.Equal(t, "\x00\x04MQTT\x04", string(body[:7]))
+	_, err = conn.Write(packet(pktConnack, 0, []byte{0, 0}))
+	require.NoError(t, err)
+	return conn, br
+}
+
+func expectPublish(t *testing.T, br *bufio.Reader) (flags byte, topic string, id uint16, payload string) {
+	t.Helper()
+	for {
+		typ, flags, body, err := readPacket(br)
+		require.NoError(t, err)
+		if typ == pktPingreq {
+			continue
+		}
+		require.EqualValues(t, pktPublish, typ)
+		topic, id, p, err := parsePublish(flags, body)
+		require.NoError(t, err)
+		return flags, topic, id, string(p)
+	}
+}
+
+func TestBridge(t *testing.T) {
+	port, sim := newTestPort(t)
+	go port.ReadLinesLoop(nil, func(error) {})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	b := New(l.Addr().String(), "gw1", "seis/raw", port)
+	b.QoS = 1
+	b.CommandTopic = "seis/cmd"
+	b.Writer = port
+	b.ReconnectDelay = 10 * time.Millisecond
+	errs := make(chan error, 4)
+	b.OnError = func(err error) { errs <- err }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	conn, br := broker(t, l)
+	typ, flags, body, err := readPacket(br)
+	require.NoError(t, err)
+	require.EqualValues(t, pktSubscribe, typ)
+	require.EqualValues(t, 0x02, flags)
+	require.Equal(t, "\x00\x01\x00\x08seis/cmd\x01", string(body))
+
+	require.NoError(t, sim.Send("1,2,3"))
+	flags, topic, id, payload := expectPublish(t, br)
+	require.Equal(t, "seis/raw", topic)
+	require.EqualValues(t, 1, (flags>>1)&0x03)
+	require.Equal(t, "1,2,3", payload)
+
+	// Commands from the broker reach the device.
+	_, err = conn.Write(publishPacket("seis/cmd", []byte("C,START"), 0, false, false, 0))
+	require.NoError(t, err)
+	cmd, err := sim.Expect(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "C,START", cmd)
+
+	// The broker goes away before acknowledging: the line is resent as a
+	// duplicate after reconnecting.
+	conn.Close()
+	require.Error(t, <-errs)
+	conn, br = broker(t, l)
+	defer conn.Close()
+	_, _, _, err = readPacket(br) // SUBSCRIBE
+	require.NoError(t, err)
+	flags, _, id2, payload := expectPublish(t, br)
+	require.NotZero(t, flags&0x08)
+	require.Equal(t, id, id2)
+	require.Equal(t, "1,2,3", payload)
+	_, err = conn.Write(pubackPacket(id2))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return b.inflight() == 0 }, time.Second, time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	typ, _, _, err = readPacket(br)
+	require.NoError(t, err)
+	require.EqualValues(t, pktDisconnect, typ)
+}
+
+func TestBridgeZeroSettings(t *testing.T) {
+	port, sim := newTestPort(t)
+	go port.ReadLinesLoop(nil, func(error) {})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	b := New(l.Addr().String(), "gw1", "t", port)
+	b.QoS = 1
+	b.KeepAlive, b.ReconnectDelay, b.Buffer = 0, 0, 0
+	require.Equal(t, 30*time.Second, b.keepAlive())
+	require.Equal(t, time.Second, b.reconnectDelay())
+	require.Equal(t, 1024, b.buffer())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- b.Run(ctx) }()
+
+	conn, br := broker(t, l)
+	defer conn.Close()
+	time.Sleep(20 * time.Millisecond) // let the bridge subscribe
+	require.NoError(t, sim.Send("1,2,3"))
+	_, _, _, payload := expectPublish(t, br)
+	require.Equal(t, "1,2,3", payload)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestBridgeConnectRefused(t *testing.T) {
+	port, _ := newTestPort(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		readPacket(bufio.NewReader(conn))
+		conn.Write(packet(pktConnack, 0, []byte{0, 5}))
+	}()
+
+	b := New(l.Addr().String(), "gw1", "t", port)
+	errs := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.OnError = func(err error) {
+		select {
+		case errs <- err:
+			cancel()
+		default:
+		}
+	}
+	b.Run(ctx)
+	var ce *ConnectError
+	require.ErrorAs(t, <-errs, &ce)
+	require.EqualValues(t, 5, ce.Code)
+}
+
+func TestBridgeStopsWhenPortCloses(t *testing.T) {
+	port, _ := newTestPort(t)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	b := New(l.Addr().String(), "gw1", "t", port)
+	done := make(chan error, 1)
+	go func() { done <- b.Run(context.Background()) }()
+	conn, _ := broker(t, l)
+	defer conn.Close()
+	time.Sleep(20 * time.Millisecond)
+	port.Close()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, serial.ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Run kept going after the port was closed")
+	}
+}
@@ -0,0 +1,161 @@
+package mqttbridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MQTT 3.1.1 control packet types.
+const (
+	pktConnect    = 1
+	pktConnack    = 2
+	pktPublish    = 3
+	pktPuback     = 4
+	pktSubscribe  = 8
+	pktSuback     = 9
+	pktPingreq    = 12
+	pktPingresp   = 13
+	pktDisconnect = 14
+)
+
+var errMalformed = errors.New("mqttbridge: malformed packet")
+
+// ConnectError reports a broker refusing the connection (CONNACK return code).
+type ConnectError struct {
+	Code byte
+}
+
+func (e *ConnectError) Error() string {
+	reasons := map[byte]string{
+		1: "unacceptable protocol version",
+		2: "identifier rejected",
+		3: "server unavailable",
+		4: "bad user name or password",
+		5: "not authorized",
+	}
+	if r, ok := reasons[e.Code]; ok {
+		return "mqttbridge: connection refused: " + r
+	}
+	return fmt.Sprintf("mqttbridge: connection refused (code %d)", e.Code)
+}
+
+// packet builds a control packet: fixed header, remaining length, body.
+func packet(typ, flags byte, body []byte) []byte {
+	b := []byte{typ<<4 | flags}
+	n := len(body)
+	for {
+		c := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			c |= 0x80
+		}
+		b = append(b, c)
+		if n == 0 {
+			break
+		}
+	}
+	return append(b, body...)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// readPacket reads one control packet.
+func readPacket(r *bufio.Reader) (typ, flags byte, body []byte, err error) {
+	h, err := r.ReadByte()
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	n, mult := 0, 1
+	for i := 0; ; i++ {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, 0, nil, err
+		}
+		n += int(c&0x7F) * mult
+		if c&0x80 == 0 {
+			break
+		}
+		if i == 3 {
+			return 0, 0, nil, errMalformed
+		}
+		mult *= 128
+	}
+	body = make([]byte, n)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, nil, err
+	}
+	return h >> 4, h & 0x0F, body, nil
+}
+
+// connectPacket builds CONNECT with a clean session.
+func connectPacket(clientID, user, pass string, keepAlive uint16) []byte {
+	body := appendString(nil, "MQTT")
+	flags := byte(0x02)
+	if user != "" {
+		flags |= 0x80
+	}
+	if pass != "" {
+		flags |= 0x40
+	}
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAlive)
+	body = appendString(body, clientID)
+	if user != "" {
+		body = appendString(body, user)
+	}
+	if pass != "" {
+		body = appendString(body, pass)
+	}
+	return packet(pktConnect, 0, body)
+}
+
+// publishPacket builds PUBLISH; id is used only for QoS 1.
+func publishPacket(topic string, payload []byte, qos byte, retain, dup bool, id uint16) []byte {
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+	if dup {
+		flags |= 0x08
+	}
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	return packet(pktPublish, flags, append(body, payload...))
+}
+
+// parsePublish splits a PUBLISH body into topic, packet id and payload.
+func parsePublish(flags byte, body []byte) (topic string, id uint16, payload []byte, err error) {
+	if len(body) < 2 {
+		return "", 0, nil, errMalformed
+	}
+	n := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+n {
+		return "", 0, nil, errMalformed
+	}
+	topic, body = string(body[2:2+n]), body[2+n:]
+	if (flags>>1)&0x03 > 0 {
+		if len(body) < 2 {
+			return "", 0, nil, errMalformed
+		}
+		id, body = binary.BigEndian.Uint16(body), body[2:]
+	}
+	return topic, id, body, nil
+}
+
+func subscribePacket(id uint16, topic string, qos byte) []byte {
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendString(body, topic)
+	return packet(pktSubscribe, 0x02, append(body, qos))
+}
+
+func pubackPacket(id uint16) []byte {
+	return packet(pktPuback, 0, binary.BigEndian.AppendUint16(nil, id))
+}
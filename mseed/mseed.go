@@ -0,0 +1,246 @@
+// Package mseed writes integer sample streams as miniSEED (SEED 2.4 data-only)
+// records, the archival format of seismological data centres.
+//
+// Each record has a 48-byte fixed header and a blockette 1000, followed by the
+// samples either uncompressed (32-bit integers) or Steim-2 compressed, in
+// big-endian byte order.
+package mseed
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Encoding selects the data encoding of records (SEED encoding format codes).
+type Encoding uint8
+
+const (
+	// Int32 stores uncompressed 32-bit integers.
+	Int32 Encoding = 3
+	// Steim2 stores Steim-2 compressed first differences.
+	Steim2 Encoding = 11
+)
+
+// headerLen is the fixed header plus blockette 1000, padded so that Steim
+// frames start on a 64-byte boundary.
+const headerLen = 64
+
+// ErrDifferenceTooLarge is returned when consecutive samples differ by more
+// than Steim-2 can represent (30 bits); use Int32 for such data.
+var ErrDifferenceTooLarge = errors.New("mseed: sample difference exceeds Steim-2 range")
+
+// Writer packs samples into miniSEED records for one channel. Samples are
+// buffered until a record is full; call Flush to write a final partial record.
+type Writer struct {
+	Encoding     Encoding // default Steim2
+	RecordLength int      // power of two from 256 to 8192; default 512
+	Quality      byte     // data quality indicator; default 'D'
+
+	w          io.Writer
+	net, sta   string
+	loc, cha   string
+	rate       float64
+	seq        int
+	buf        []int32
+	start      time.Time // time of buf[0]
+	last       int32     // last sample written, for the Steim-2 d0
+	continuous bool      // last is the sample preceding buf[0]
+}
+
+// NewWriter returns a Writer for the channel identified by its SEED network,
+// station, location and channel codes, sampled at rate Hz.
+func NewWriter(w io.Writer, network, station, location, channel string, rate float64) *Writer {
+	return &Writer{w: w, net: network, sta: station, loc: location, cha: channel, rate: rate}
+}
+
+// period returns the sample interval.
+func (wr *Writer) period() time.Duration {
+	return time.Duration(float64(time.Second) / wr.rate)
+}
+
+// Write appends samples, the first of which was taken at start. A start that
+// does not continue the buffered samples (off by more than half a sample
+// interval) flushes them first, so every record is contiguous. With Steim-2,
+// samples too far apart are rejected whole with ErrDifferenceTooLarge and
+// the Writer carries on as if they had not been given.
+func (wr *Writer) Write(start time.Time, samples []int32) error {
+	if wr.rate <= 0 {
+		return errors.New("mseed: sample rate must be positive")
+	}
+	if len(wr.buf) > 0 {
+		expected := wr.start.Add(time.Duration(len(wr.buf)) * wr.period())
+		if d := start.Sub(expected); d > wr.period()/2 || d < -wr.period()/2 {
+			if err := wr.Flush(); err != nil {
+				return err
+			}
+			wr.continuous = false
+		}
+	}
+	if wr.Encoding == 0 || wr.Encoding == Steim2 {
+		// Refuse samples Steim-2 cannot encode before buffering them, or
+		// they would fail every later Write and Flush.
+		prev, have := wr.last, wr.continuous
+		if len(wr.buf) > 0 {
+			prev, have = wr.buf[len(wr.buf)-1], true
+		}
+		for _, v := range samples {
+			if have && !fits(v-prev, 30) {
+				return ErrDifferenceTooLarge
+			}
+			prev, have = v, true
+		}
+	}
+	if len(wr.buf) == 0 {
+		wr.start = start
+	}
+	wr.buf = append(wr.buf, samples...)
+	for {
+		n, err := wr.writeRecord(false)
+		if err != nil || n == 0 {
+			return err
+		}
+	}
+}
+
+// Flush writes all buffered samples, the last record possibly partly filled.
+func (wr *Writer) Flush() error {
+	for len(wr.buf) > 0 {
+		if _, err := wr.writeRecord(true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRecord writes one record from the buffer if it fills a record (or,
+// with partial, if there is anything at all) and returns the samples used.
+func (wr *Writer) writeRecord(partial bool) (int, error) {
+	reclen := wr.RecordLength
+	if reclen == 0 {
+		reclen = 512
+	}
+	exp := int(math.Log2(float64(reclen)))
+	if reclen < 256 || reclen > 8192 || 1<<exp != reclen {
+		return 0, fmt.Errorf("mseed: invalid record length %d", reclen)
+	}
+	rec := make([]byte, reclen)
+	var n int
+	var enc Encoding
+	switch wr.Encoding {
+	case Int32:
+		enc = Int32
+		n = min(len(wr.buf), (reclen-headerLen)/4)
+		if n < (reclen-headerLen)/4 && !partial {
+			return 0, nil
+		}
+		for i, v := range wr.buf[:n] {
+			binary.BigEndian.PutUint32(rec[headerLen+4*i:], uint32(v))
+		}
+	case 0, Steim2:
+		enc = Steim2
+		var d0 int32
+		if wr.continuous {
+			d0 = wr.buf[0] - wr.last
+		}
+		var err error
+		n, err = encodeSteim2(rec[headerLen:], wr.buf, d0)
+		if err != nil {
+			return 0, err
+		}
+		if n == len(wr.buf) && !partial {
+			return 0, nil // the record may not be full yet
+		}
+	default:
+		return 0, fmt.Errorf("mseed: unsupported encoding %d", wr.Encoding)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	wr.seq++
+	if wr.seq > 999999 {
+		wr.seq = 1
+	}
+	wr.putHeader(rec, n, enc, uint8(exp))
+	if _, err := wr.w.Write(rec); err != nil {
+		return 0, err
+	}
+	wr.last = wr.buf[n-1]
+	wr.continuous = true
+	wr.start = wr.start.Add(time.Duration(float64(n) * float64(time.Second) / wr.rate))
+	wr.buf = append(wr.buf[:0], wr.buf[n:]...)
+	return n, nil
+}
+
+// putHeader fills in the fixed header and blockette 1000.
+func (wr *Writer) putHeader(rec []byte, n int, enc Encoding, exp uint8) {
+	q := wr.Quality
+	if q == 0 {
+		q = 'D'
+	}
+	copy(rec[0:6], fmt.Sprintf("%06d", wr.seq))
+	rec[6] = q
+	rec[7] = ' '
+	putCode(rec[8:13], wr.sta)
+	putCode(rec[13:15], wr.loc)
+	putCode(rec[15:18], wr.cha)
+	putCode(rec[18:20], wr.net)
+	putBTime(rec[20:30], wr.start)
+	binary.BigEndian.PutUint16(rec[30:], uint16(n))
+	factor, mult := rateFactors(wr.rate)
+	binary.BigEndian.PutUint16(rec[32:], uint16(factor))
+	binary.BigEndian.PutUint16(rec[34:], uint16(mult))
+	rec[39] = 1 // blockettes that follow
+	binary.BigEndian.PutUint16(rec[44:], headerLen)
+	binary.BigEndian.PutUint16(rec[46:], 48)
+	// Blockette 1000: data only SEED.
+	binary.BigEndian.PutUint16(rec[48:], 1000)
+	rec[52] = byte(enc)
+	rec[53] = 1 // big-endian
+	rec[54] = exp
+}
+
+// putCode writes an upper-case, space-padded SEED code.
+func putCode(dst []byte, code string) {
+	code = strings.ToUpper(code)
+	for i := range dst {
+		if i < len(code) {
+			dst[i] = code[i]
+		} else {
+			dst[i] = ' '
+		}
+	}
+}
+
+// putBTime encodes t (rounded to 100µs) as a SEED BTIME.
+func putBTime(dst []byte, t time.Time) {
+	t = t.UTC().Round(100 * time.Microsecond)
+	binary.BigEndian.PutUint16(dst[0:], uint16(t.Year()))
+	binary.BigEndian.PutUint16(dst[2:], uint16(t.YearDay()))
+	dst[4] = byte(t.Hour())
+	dst[5] = byte(t.Minute())
+	dst[6] = byte(t.Second())
+	dst[7] = 0
+	binary.BigEndian.PutUint16(dst[8:], uint16(t.Nanosecond()/100000))
+}
+
+// rateFactors expresses rate as a SEED sample rate factor and multiplier.
+func rateFactors(rate float64) (factor, mult int16) {
+	if rate >= 1 && rate == math.Trunc(rate) && rate <= math.MaxInt16 {
+		return int16(rate), 1
+	}
+	if p := 1 / rate; rate < 1 && p == math.Trunc(p) && p <= math.MaxInt16 {
+		return -int16(p), 1 // negative factor: sample period in seconds
+	}
+	// Otherwise rate = factor / -mult, with the largest divisor that fits.
+	for d := 10000; d >= 1; d /= 10 {
+		if f := math.Round(rate * float64(d)); f <= math.MaxInt16 {
+			return int16(f), -int16(d)
+		}
+	}
+	return math.MaxInt16, 1
+}
@@ -0,0 +1,181 @@
+package mseed
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// record is a decoded miniSEED record, as far as the tests need it.
+type record struct {
+	seq      string
+	sta, cha string
+	start    time.Time
+	samples  []int32
+	encoding Encoding
+	factor   int16
+	mult     int16
+}
+
+func decodeRecords(t *testing.T, b []byte, reclen int) []record {
+	t.Helper()
+	require.Zero(t, len(b)%reclen)
+	var out []record
+	for ; len(b) > 0; b = b[reclen:] {
+		rec := b[:reclen]
+		r := record{
+			seq: string(rec[0:6]),
+			sta: string(bytes.TrimRight(rec[8:13], " ")),
+			cha: string(rec[15:18]),
+		}
+		year := int(binary.BigEndian.Uint16(rec[20:]))
+		doy := int(binary.BigEndian.Uint16(rec[22:]))
+		r.start = time.Date(year, 1, doy, int(rec[24]), int(rec[25]), int(rec[26]),
+			int(binary.BigEndian.Uint16(rec[28:]))*100000, time.UTC)
+		n := int(binary.BigEndian.Uint16(rec[30:]))
+		r.factor = int16(binary.BigEndian.Uint16(rec[32:]))
+		r.mult = int16(binary.BigEndian.Uint16(rec[34:]))
+		require.EqualValues(t, 1000, binary.BigEndian.Uint16(rec[48:]))
+		require.Equal(t, reclen, 1<<rec[54])
+		r.encoding = Encoding(rec[52])
+		data := rec[binary.BigEndian.Uint16(rec[44:]):]
+		if r.encoding == Int32 {
+			for i := 0; i < n; i++ {
+				r.samples = append(r.samples, int32(binary.BigEndian.Uint32(data[4*i:])))
+			}
+		} else {
+			r.samples = decodeSteim2(t, data, n)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// decodeSteim2 is an independent Steim-2 decoder following the SEED manual.
+func decodeSteim2(t *testing.T, data []byte, n int) []int32 {
+	var diffs []int32
+	x0 := int32(binary.BigEndian.Uint32(data[4:]))
+	xn := int32(binary.BigEndian.Uint32(data[8:]))
+	signExtend := func(v uint32, bits int) int32 {
+		return int32(v<<(32-bits)) >> (32 - bits)
+	}
+	for f := 0; f < len(data)/64; f++ {
+		frame := data[64*f:]
+		nibbles := binary.BigEndian.Uint32(frame)
+		for w := 0; w < 16; w++ {
+			word := binary.BigEndian.Uint32(frame[4*w:])
+			var bits, count int
+			switch nib := nibbles >> (30 - 2*w) & 3; nib {
+			case 0:
+				continue
+			case 1:
+				bits, count = 8, 4
+			case 2:
+				bits, count = [4]int{0, 30, 15, 10}[word>>30], [4]int{0, 1, 2, 3}[word>>30]
+			case 3:
+				bits, count = [4]int{6, 5, 4, 0}[word>>30], [4]int{5, 6, 7, 0}[word>>30]
+			}
+			require.NotZero(t, count)
+			for i := 0; i < count; i++ {
+				shift := bits * (count - 1 - i)
+				diffs = append(diffs, signExtend(word>>shift&(1<<bits-1), bits))
+			}
+		}
+	}
+	require.GreaterOrEqual(t, len(diffs), n)
+	out := []int32{x0}
+	for i := 1; i < n; i++ {
+		out = append(out, out[i-1]+diffs[i])
+	}
+	require.Equal(t, xn, out[n-1], "reverse integration constant")
+	return out
+}
+
+func TestWriter_Steim2(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]int32, 5000)
+	v := int32(0)
+	for i := range samples {
+		// Mostly small steps, with the odd spike to exercise wide packings.
+		step := int32(rng.Intn(21) - 10)
+		if i%97 == 0 {
+			step = int32(rng.Intn(1<<20) - 1<<19)
+		}
+		v += step
+		samples[i] = v
+	}
+	start := time.Date(2024, 7, 4, 20, 15, 30, 0, time.UTC)
+
+	var out bytes.Buffer
+	w := NewWriter(&out, "XX", "sta1", "00", "HHZ", 100)
+	// Feed in chunks, as a read loop would.
+	for i := 0; i < len(samples); i += 200 {
+		require.NoError(t, w.Write(start.Add(time.Duration(i)*10*time.Millisecond), samples[i:i+200]))
+	}
+	require.NoError(t, w.Flush())
+
+	recs := decodeRecords(t, out.Bytes(), 512)
+	require.Greater(t, len(recs), 3)
+	var got []int32
+	for i, r := range recs {
+		require.Equal(t, Steim2, r.encoding)
+		require.Equal(t, "STA1", r.sta)
+		require.Equal(t, "HHZ", r.cha)
+		require.EqualValues(t, 100, r.factor)
+		require.Equal(t, start.Add(time.Duration(len(got))*10*time.Millisecond), r.start, "record %d", i)
+		got = append(got, r.samples...)
+	}
+	require.Equal(t, samples, got)
+	require.Equal(t, "000001", recs[0].seq)
+}
+
+func TestWriter_Int32AndGaps(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, "XX", "STA", "", "BHN", 0.5)
+	w.Encoding = Int32
+	w.RecordLength = 256
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, w.Write(start, []int32{1, 2, 3}))
+	// A gap of one sample interval starts a new record.
+	require.NoError(t, w.Write(start.Add(8*time.Second), []int32{-4, 1 << 30}))
+	require.NoError(t, w.Flush())
+
+	recs := decodeRecords(t, out.Bytes(), 256)
+	require.Len(t, recs, 2)
+	require.Equal(t, []int32{1, 2, 3}, recs[0].samples)
+	require.Equal(t, []int32{-4, 1 << 30}, recs[1].samples)
+	require.Equal(t, start.Add(8*time.Second), recs[1].start)
+	require.EqualValues(t, -2, recs[0].factor)
+
+	w = NewWriter(&out, "XX", "STA", "", "BHN", 1)
+	require.ErrorIs(t, w.Write(start, []int32{0, 1 << 30}), ErrDifferenceTooLarge)
+}
+
+func TestWriter_Steim2RecoversFromBadBatch(t *testing.T) {
+	var out bytes.Buffer
+	w := NewWriter(&out, "XX", "STA", "", "BHZ", 1)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, w.Write(start, []int32{1, 2, 3}))
+	// Too far from the buffered 3, and within the batch itself.
+	require.ErrorIs(t, w.Write(start.Add(3*time.Second), []int32{1 << 30}), ErrDifferenceTooLarge)
+	require.ErrorIs(t, w.Write(start.Add(3*time.Second), []int32{4, 1 << 30}), ErrDifferenceTooLarge)
+
+	require.NoError(t, w.Write(start.Add(3*time.Second), []int32{4, 5}))
+	require.NoError(t, w.Flush())
+	recs := decodeRecords(t, out.Bytes(), 512)
+	require.Len(t, recs, 1)
+	require.Equal(t, []int32{1, 2, 3, 4, 5}, recs[0].samples)
+}
+
+func TestRateFactors(t *testing.T) {
+	f, m := rateFactors(200)
+	require.Equal(t, [2]int16{200, 1}, [2]int16{f, m})
+	f, m = rateFactors(0.1)
+	require.Equal(t, [2]int16{-10, 1}, [2]int16{f, m})
+	f, m = rateFactors(2.5)
+	require.InDelta(t, 2.5, float64(f)/float64(-m), 1e-9)
+}
@@ -0,0 +1,90 @@
+package mseed
+
+import "encoding/binary"
+
+// steim2Packings lists the Steim-2 data word layouts from densest to
+// sparsest: differences per word, bits per difference, the 2-bit nibble in
+// word 0 and the 2-bit dnib in the data word itself.
+var steim2Packings = []struct {
+	count, bits  int
+	nibble, dnib uint32
+}{
+	{7, 4, 3, 2},
+	{6, 5, 3, 1},
+	{5, 6, 3, 0},
+	{4, 8, 1, 0}, // no dnib: four plain bytes
+	{3, 10, 2, 3},
+	{2, 15, 2, 2},
+	{1, 30, 2, 1},
+}
+
+// encodeSteim2 encodes as many samples as fit into dst, a whole number of
+// 64-byte frames, and returns how many it encoded. d0 is the difference of
+// samples[0] from the previous sample (0 at the start of a stream).
+func encodeSteim2(dst []byte, samples []int32, d0 int32) (int, error) {
+	frames := len(dst) / 64
+	if frames == 0 || len(samples) == 0 {
+		return 0, nil
+	}
+	diffs := make([]int32, len(samples))
+	diffs[0] = d0
+	for i := 1; i < len(samples); i++ {
+		diffs[i] = samples[i] - samples[i-1]
+	}
+	for _, d := range diffs {
+		if !fits(d, 30) {
+			return 0, ErrDifferenceTooLarge
+		}
+	}
+
+	n := 0 // differences encoded
+	for f := 0; f < frames && n < len(diffs); f++ {
+		frame := dst[64*f : 64*(f+1)]
+		var nibbles uint32
+		w := 1
+		if f == 0 {
+			w = 3 // words 1 and 2 hold X0 and Xn
+		}
+		for ; w < 16 && n < len(diffs); w++ {
+			for _, p := range steim2Packings {
+				if n+p.count > len(diffs) || !allFit(diffs[n:n+p.count], p.bits) {
+					continue
+				}
+				var word uint32
+				if p.nibble == 1 {
+					for i := 0; i < 4; i++ {
+						word |= uint32(uint8(diffs[n+i])) << (24 - 8*i)
+					}
+				} else {
+					word = p.dnib << 30
+					mask := uint32(1)<<p.bits - 1
+					for i := 0; i < p.count; i++ {
+						word |= (uint32(diffs[n+i]) & mask) << (p.bits * (p.count - 1 - i))
+					}
+				}
+				binary.BigEndian.PutUint32(frame[4*w:], word)
+				nibbles |= p.nibble << (30 - 2*w)
+				n += p.count
+				break
+			}
+		}
+		binary.BigEndian.PutUint32(frame, nibbles)
+	}
+	binary.BigEndian.PutUint32(dst[4:], uint32(samples[0]))
+	binary.BigEndian.PutUint32(dst[8:], uint32(samples[n-1]))
+	return n, nil
+}
+
+func fits(d int32, bits int) bool {
+	limit := int32(1) << (bits - 1)
+	return d >= -limit && d < limit
+}
+
+func allFit(ds []int32, bits int) bool {
+	for _, d := range ds {
+		if !fits(d, bits) {
+			return false
+		}
+	}
+	return true
+}
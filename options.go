@@ -0,0 +1,239 @@
+package serial
+
+import (
+	"regexp"
+	"time"
+)
+
+// An Option sets one field of the Config built by OpenDevice.
+type Option func(*Config)
+
+// OpenDevice opens device configured by opts, as an alternative to filling in
+// a Config:
+//
+//	sr, err := serial.OpenDevice("/dev/ttyUSB0",
+//	    serial.WithBaud(9600), serial.WithParity(serial.ParityEven),
+//	    serial.WithDelimiter("\r\n"))
+//
+// Options start from explicit defaults (115200 baud, 8N1, "\r\n"), so an
+// option is only needed to change something and never has to spell out a
+// zero value. Options apply in order; a later one overrides an earlier one.
+func OpenDevice(device string, opts ...Option) (*SerialReader, error) {
+	cfg := Config{
+		Device:    device,
+		BaudRate:  115200,
+		DataBits:  8,
+		StopBits:  1,
+		Delimiter: "\r\n",
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return Open(cfg)
+}
+
+// WithConfig replaces every setting with cfg, keeping the device passed to
+// OpenDevice. Later options still apply on top of it.
+func WithConfig(cfg Config) Option {
+	return func(c *Config) {
+		device := c.Device
+		*c = cfg
+		c.Device = device
+	}
+}
+
+// WithBaud sets the baud rate.
+func WithBaud(baud int) Option {
+	return func(c *Config) { c.BaudRate = baud }
+}
+
+// WithDataBits sets the number of data bits, 5 to 8.
+func WithDataBits(bits int) Option {
+	return func(c *Config) { c.DataBits = bits }
+}
+
+// WithParity sets the parity.
+func WithParity(p Parity) Option {
+	return func(c *Config) { c.Parity = p }
+}
+
+// WithStopBits sets the number of stop bits, 1 or 2.
+func WithStopBits(bits int) Option {
+	return func(c *Config) { c.StopBits = bits }
+}
+
+// WithAccess opens the port read-only or write-only.
+func WithAccess(m AccessMode) Option {
+	return func(c *Config) { c.Access = m }
+}
+
+// WithRTSCTS enables hardware (RTS/CTS) flow control.
+func WithRTSCTS() Option {
+	return func(c *Config) { c.RTSCTS = true }
+}
+
+// WithXONXOFF enables software (XON/XOFF) flow control.
+func WithXONXOFF() Option {
+	return func(c *Config) { c.XONXOFF = true }
+}
+
+// WithInitialLines sets the DTR and RTS states applied right after open.
+func WithInitialLines(dtr, rts LineState) Option {
+	return func(c *Config) { c.InitialDTR, c.InitialRTS = dtr, rts }
+}
+
+// WithHoldModemLines sets Config.HoldModemLines.
+func WithHoldModemLines() Option {
+	return func(c *Config) { c.HoldModemLines = true }
+}
+
+// WithDelimiter sets the line delimiter.
+func WithDelimiter(delim string) Option {
+	return func(c *Config) { c.Delimiter = delim }
+}
+
+// WithKeepStaleInput sets Config.KeepStaleInput.
+func WithKeepStaleInput() Option {
+	return func(c *Config) { c.KeepStaleInput = true }
+}
+
+// WithReadTimeout sets Config.ReadTimeout.
+func WithReadTimeout(d time.Duration) Option {
+	return func(c *Config) { c.ReadTimeout = d }
+}
+
+// WithMaxLineLength sets Config.MaxLineLength.
+func WithMaxLineLength(n int) Option {
+	return func(c *Config) { c.MaxLineLength = n }
+}
+
+// WithMiddleware appends to Config.Middleware.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Config) { c.Middleware = append(c.Middleware, mw...) }
+}
+
+// WithContinueOnError sets Config.ContinueOnError.
+func WithContinueOnError() Option {
+	return func(c *Config) { c.ContinueOnError = true }
+}
+
+// WithDrainTimeout sets Config.DrainTimeout.
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *Config) { c.DrainTimeout = d }
+}
+
+// WithChecksum sets Config.Checksum and Config.OnBadLine.
+func WithChecksum(cs *LineChecksum, onBadLine func(line string, err error)) Option {
+	return func(c *Config) { c.Checksum, c.OnBadLine = cs, onBadLine }
+}
+
+// WithWriteChecksum sets Config.WriteChecksum.
+func WithWriteChecksum(cs *LineChecksum) Option {
+	return func(c *Config) { c.WriteChecksum = cs }
+}
+
+// WithRS485 sets Config.RS485.
+func WithRS485(r RS485) Option {
+	return func(c *Config) { c.RS485 = &r }
+}
+
+// WithWriteHooks sets Config.BeforeWrite and Config.AfterWrite.
+func WithWriteHooks(before, after func() error) Option {
+	return func(c *Config) { c.BeforeWrite, c.AfterWrite = before, after }
+}
+
+// WithMarkErrors sets Config.MarkErrors and Config.OnBadChar.
+func WithMarkErrors(onBadChar func(c byte)) Option {
+	return func(c *Config) { c.MarkErrors, c.OnBadChar = true, onBadChar }
+}
+
+// WithBreakDetection sets Config.DetectBreaks and Config.OnBreak.
+func WithBreakDetection(onBreak func(BreakReceived)) Option {
+	return func(c *Config) { c.DetectBreaks, c.OnBreak = true, onBreak }
+}
+
+// WithWorkers sets Config.Workers.
+func WithWorkers(wp WorkerPool) Option {
+	return func(c *Config) { c.Workers = &wp }
+}
+
+// WithAsync sets Config.Async.
+func WithAsync(a AsyncDelivery) Option {
+	return func(c *Config) { c.Async = &a }
+}
+
+// WithDiscardPartial sets Config.DiscardPartial.
+func WithDiscardPartial() Option {
+	return func(c *Config) { c.DiscardPartial = true }
+}
+
+// WithTerminator sets Config.Terminator.
+func WithTerminator(re *regexp.Regexp) Option {
+	return func(c *Config) { c.Terminator = re }
+}
+
+// WithReadChunkSize sets Config.ReadChunkSize.
+func WithReadChunkSize(n int) Option {
+	return func(c *Config) { c.ReadChunkSize = n }
+}
+
+// WithBacklogInterval sets Config.BacklogInterval.
+func WithBacklogInterval(d time.Duration) Option {
+	return func(c *Config) { c.BacklogInterval = d }
+}
+
+// WithOverrunAlert sets Config.OnOverrun.
+func WithOverrunAlert(onOverrun func(Overrun)) Option {
+	return func(c *Config) { c.OnOverrun = onOverrun }
+}
+
+// WithFramer sets Config.Framer.
+func WithFramer(name string) Option {
+	return func(c *Config) { c.Framer = name }
+}
+
+// WithHealthProbe sets Config.Health.
+func WithHealthProbe(h HealthProbe) Option {
+	return func(c *Config) { c.Health = &h }
+}
+
+// WithSampleRate sets Config.SampleRate.
+func WithSampleRate(hz float64) Option {
+	return func(c *Config) { c.SampleRate = hz }
+}
+
+// WithDetectDelimiter sets Config.DetectDelimiter and
+// Config.OnDelimiterDetected.
+func WithDetectDelimiter(onDetected func(delim string)) Option {
+	return func(c *Config) { c.DetectDelimiter, c.OnDelimiterDetected = true, onDetected }
+}
+
+// WithNotifyReady sets Config.NotifyReady.
+func WithNotifyReady() Option {
+	return func(c *Config) { c.NotifyReady = true }
+}
+
+// WithRing sets Config.RingSize.
+func WithRing(size int) Option {
+	return func(c *Config) { c.RingSize = size }
+}
+
+// WithRealtimePriority sets Config.RealtimePriority.
+func WithRealtimePriority(priority int) Option {
+	return func(c *Config) { c.RealtimePriority = priority }
+}
+
+// WithBlockingRead sets Config.BlockingRead.
+func WithBlockingRead(d time.Duration) Option {
+	return func(c *Config) { c.BlockingRead = d }
+}
+
+// WithReadSettle sets Config.ReadSettle.
+func WithReadSettle(d time.Duration) Option {
+	return func(c *Config) { c.ReadSettle = d }
+}
+
+// WithLatency sets Config.MeasureLatency.
+func WithLatency() Option {
+	return func(c *Config) { c.MeasureLatency = true }
+}
@@ -0,0 +1,43 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenDevice(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+
+	reader, err := OpenDevice(slave.Name(),
+		WithBaud(9600), WithDelimiter("\n"), WithReadTimeout(time.Second), WithRing(16))
+	require.NoError(t, err)
+	t.Cleanup(func() { reader.Close() })
+
+	require.Equal(t, Config{
+		Device:      slave.Name(),
+		BaudRate:    9600,
+		DataBits:    8,
+		StopBits:    1,
+		Delimiter:   "\n",
+		ReadTimeout: time.Second,
+		RingSize:    16,
+	}, reader.config)
+
+	_, err = master.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "hello", line)
+}
+
+func TestWithConfig(t *testing.T) {
+	c := Config{Device: "/dev/ttyS0"}
+	WithConfig(Config{Device: "/dev/ignored", BaudRate: 4800})(&c)
+	WithParity(ParityOdd)(&c)
+	require.Equal(t, Config{Device: "/dev/ttyS0", BaudRate: 4800, Parity: ParityOdd}, c)
+}
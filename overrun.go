@@ -0,0 +1,69 @@
+package serial
+
+import (
+	"sync"
+	"time"
+)
+
+// Overrun reports characters the driver lost since the previous report;
+// see Config.OnOverrun.
+type Overrun struct {
+	Time time.Time // when the read loop noticed the loss
+	// Overruns counts characters lost because the UART's receive FIFO
+	// filled before the driver emptied it, typically from interrupt latency.
+	Overruns uint64
+	// BufferOverruns counts characters lost because the tty buffer was
+	// full, that is, the application read too slowly.
+	BufferOverruns uint64
+}
+
+// overrunCounter holds the driver's overrun counters as last seen on a
+// port, shared by the loops reading it.
+type overrunCounter struct {
+	mu              sync.Mutex
+	overrun, bufOvr uint64
+}
+
+// update stores the counters just read and returns how much each grew.
+// Counters that went backwards, as after a driver reload, only set the new
+// baseline.
+func (c *overrunCounter) update(overrun, bufOvr uint64) (dOverrun, dBufOvr uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if overrun > c.overrun {
+		dOverrun = overrun - c.overrun
+	}
+	if bufOvr > c.bufOvr {
+		dBufOvr = bufOvr - c.bufOvr
+	}
+	c.overrun, c.bufOvr = overrun, bufOvr
+	return dOverrun, dBufOvr
+}
+
+// newOverrunCounter returns a counter primed with the overrun counters of
+// the tty of p, or nil if its driver does not keep them.
+func newOverrunCounter(p *port) *overrunCounter {
+	overrun, bufOvr, err := readOverruns(p.fd)
+	if err != nil {
+		return nil
+	}
+	return &overrunCounter{overrun: overrun, bufOvr: bufOvr}
+}
+
+// checkOverruns reads the overrun counters of p and reports an increase to
+// Config.OnOverrun.
+func (s *SerialReader) checkOverruns(p *port) {
+	if p.overruns == nil {
+		return
+	}
+	overrun, bufOvr, err := readOverruns(p.fd)
+	if err != nil {
+		return
+	}
+	dOverrun, dBufOvr := p.overruns.update(overrun, bufOvr)
+	if dOverrun == 0 && dBufOvr == 0 {
+		return
+	}
+	s.overruns.Add(dOverrun + dBufOvr)
+	s.config.OnOverrun(Overrun{Time: time.Now(), Overruns: dOverrun, BufferOverruns: dBufOvr})
+}
@@ -0,0 +1,35 @@
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverrunCounter(t *testing.T) {
+	c := &overrunCounter{overrun: 5, bufOvr: 1}
+	d, b := c.update(5, 1)
+	require.Zero(t, d+b)
+	d, b = c.update(8, 1)
+	require.EqualValues(t, 3, d)
+	require.Zero(t, b)
+	d, b = c.update(8, 4)
+	require.Zero(t, d)
+	require.EqualValues(t, 3, b)
+	// A counter reset only moves the baseline.
+	d, b = c.update(0, 0)
+	require.Zero(t, d+b)
+	d, _ = c.update(2, 0)
+	require.EqualValues(t, 2, d)
+}
+
+func TestSerialReader_OverrunUnsupported(t *testing.T) {
+	// PTYs keep no overrun counters: the check is skipped, not an error.
+	reader, master := newTestReader(t, Config{OnOverrun: func(Overrun) { t.Error("overrun on a PTY") }})
+	require.Nil(t, reader.port().overruns)
+	_, err := master.Write([]byte("a\n"))
+	require.NoError(t, err)
+	lines := make(chan string, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	require.Equal(t, "a", <-lines)
+}
@@ -0,0 +1,84 @@
+package serial
+
+import "time"
+
+// markDecoder undoes the PARMRK marking of a tty's input stream: the driver
+// prefixes every character received with a parity or framing error with
+// 0xFF 0x00 and doubles literal 0xFF bytes. Sequences may straddle reads, so
+// the decoder carries its state from one chunk to the next.
+type markDecoder struct {
+	state uint8 // bytes of a marking sequence seen so far: 0, 1 (0xFF) or 2 (0xFF 0x00)
+	out   []byte
+}
+
+// decode returns the data bytes in b, passing every character marked as
+// corrupted to bad instead. The result is only valid until the next call.
+func (d *markDecoder) decode(b []byte, bad func(c byte)) []byte {
+	out := d.out[:0]
+	for _, c := range b {
+		switch d.state {
+		case 0:
+			if c == 0xFF {
+				d.state = 1
+				continue
+			}
+			out = append(out, c)
+		case 1:
+			d.state = 0
+			switch c {
+			case 0xFF:
+				out = append(out, c)
+			case 0x00:
+				d.state = 2
+			default: // not a marking sequence; the driver never sends this
+				out = append(out, 0xFF, c)
+			}
+		case 2:
+			d.state = 0
+			bad(c)
+		}
+	}
+	d.out = out
+	return out
+}
+
+// BreakReceived reports a break condition on the line; see
+// Config.DetectBreaks.
+type BreakReceived struct {
+	Time time.Time // when the break was read, shortly after it ended
+}
+
+// received accounts for the bytes b just read from p and returns the data in
+// them, with marking sequences decoded when Config.MarkErrors or
+// Config.DetectBreaks is set. The
+// byte counters count what was read, the ring and raw taps get the decoded
+// data.
+func (s *SerialReader) received(p *port, b []byte) []byte {
+	s.countRead(len(b))
+	if p.marks != nil {
+		b = p.marks.decode(b, s.badChar)
+	}
+	if s.ring != nil {
+		s.ring.Write(b)
+	}
+	if len(b) > 0 {
+		s.tapRaw(b)
+	}
+	return b
+}
+
+// badChar handles a marked character. The driver marks a break as a
+// corrupted NUL.
+func (s *SerialReader) badChar(c byte) {
+	if c == 0 && s.config.DetectBreaks {
+		s.breaks.Add(1)
+		if s.config.OnBreak != nil {
+			s.config.OnBreak(BreakReceived{Time: time.Now()})
+		}
+		return
+	}
+	s.badChars.Add(1)
+	if s.config.OnBadChar != nil {
+		s.config.OnBadChar(c)
+	}
+}
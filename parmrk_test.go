@@ -0,0 +1,58 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestMarkDecoder(t *testing.T) {
+	var d markDecoder
+	var bad []byte
+	onBad := func(c byte) { bad = append(bad, c) }
+
+	require.Equal(t, []byte("a\xffb"), d.decode([]byte("a\xff\xffb\xff\x00c"), onBad))
+	require.Equal(t, []byte("c"), bad)
+
+	// Sequences split across reads.
+	require.Empty(t, d.decode([]byte("\xff"), onBad))
+	require.Empty(t, d.decode([]byte("\x00"), onBad))
+	require.Equal(t, []byte("d"), d.decode([]byte("xd"), onBad))
+	require.Equal(t, []byte("cx"), bad)
+}
+
+func TestSerialReader_MarkErrors(t *testing.T) {
+	var bad []byte
+	reader, master := newTestReader(t, Config{MarkErrors: true, OnBadChar: func(c byte) { bad = append(bad, c) }})
+	tio, err := unix.IoctlGetTermios(reader.port().fd, ioctlGetTermios)
+	require.NoError(t, err)
+	require.NotZero(t, tio.Iflag&unix.PARMRK)
+
+	// A PTY cannot produce parity errors, but the line discipline still
+	// doubles 0xFF under PARMRK; the reader must undo that.
+	_, err = master.Write([]byte("\xffok\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "\xffok", line)
+	require.Empty(t, bad)
+	require.EqualValues(t, 5, reader.Stats().BytesRead)
+}
+
+func TestSerialReader_DetectBreaks(t *testing.T) {
+	var breaks []BreakReceived
+	reader, _ := newTestReader(t, Config{DetectBreaks: true, OnBreak: func(b BreakReceived) { breaks = append(breaks, b) }})
+
+	// PTYs cannot carry a break, so feed the driver's marking directly.
+	data := reader.received(reader.port(), []byte("a\xff\x00\x00b\xff\x00\x7f"))
+	require.Equal(t, "ab", string(data))
+	require.Len(t, breaks, 1)
+	require.WithinDuration(t, time.Now(), breaks[0].Time, time.Second)
+	st := reader.Stats()
+	require.EqualValues(t, 1, st.Breaks)
+	require.EqualValues(t, 1, st.BadChars)
+}
@@ -0,0 +1,115 @@
+package serial
+
+import "sync"
+
+// partialState holds the unframed tail a read loop or ReadLine left behind
+// when it returned, so that the next ReadLine, ReadLinesLoop or
+// ReadFramesLoop on the same port, or on the port Reopen replaced it with,
+// carries on with it instead of losing, or corrupting, a sample split across
+// the restart. Taking the tail hands over its buffer, so concurrent readers
+// never share one.
+type partialState struct {
+	mu       sync.Mutex
+	port     *port // the port the tail belongs to
+	from, to *port // the last Reopen, for loops that return after it
+	line     []byte
+	frame    []byte
+	buf      []byte // ReadLine's read buffer, kept between calls
+}
+
+// reopened moves the tail read from the port from over to its replacement
+// to, or drops it if discard is set.
+func (ps *partialState) reopened(from, to *port, discard bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if discard {
+		ps.from, ps.to = nil, nil
+		ps.line, ps.frame = nil, nil
+		return
+	}
+	ps.from, ps.to = from, to
+	if ps.port == from {
+		ps.port = to
+	}
+}
+
+// discard drops the tail.
+func (ps *partialState) discard() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.line, ps.frame = nil, nil
+}
+
+// owner maps p to the port its tail now belongs to. Called with ps.mu held.
+func (ps *partialState) owner(p *port) *port {
+	if p != nil && p == ps.from {
+		return ps.to
+	}
+	return p
+}
+
+// takeLine returns and forgets the partial line left on p. It is empty, but
+// may have capacity to reuse, if there is none.
+func (ps *partialState) takeLine(p *port) []byte {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	line := ps.line
+	ps.line = nil
+	if ps.port != p {
+		return line[:0]
+	}
+	return line
+}
+
+// keepLine stores the partial line read from p.
+func (ps *partialState) keepLine(p *port, line []byte) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p = ps.owner(p)
+	if ps.port != p {
+		ps.frame = nil
+	}
+	ps.port, ps.line = p, line
+}
+
+// takeFrame returns and forgets the partial frame left on p.
+func (ps *partialState) takeFrame(p *port) []byte {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	frame := ps.frame
+	ps.frame = nil
+	if ps.port != p {
+		return nil
+	}
+	return frame
+}
+
+// keepFrame stores the partial frame read from p.
+func (ps *partialState) keepFrame(p *port, frame []byte) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	p = ps.owner(p)
+	if ps.port != p {
+		ps.line = nil
+	}
+	ps.port, ps.frame = p, frame
+}
+
+// takeBuf returns ReadLine's read buffer, of size bytes.
+func (ps *partialState) takeBuf(size int) []byte {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	buf := ps.buf
+	ps.buf = nil
+	if len(buf) != size {
+		buf = make([]byte, size)
+	}
+	return buf
+}
+
+// putBuf returns buf for the next ReadLine.
+func (ps *partialState) putBuf(buf []byte) {
+	ps.mu.Lock()
+	ps.buf = buf
+	ps.mu.Unlock()
+}
@@ -0,0 +1,119 @@
+package serial
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLinesLoop_KeepsPartialLine(t *testing.T) {
+	p, _, write := newTestPort(t, ReadWrite)
+	w, err := p.NewWriter() // keeps the port open between readers
+	require.NoError(t, err)
+	defer w.Close()
+
+	lines := make(chan string, 4)
+	loop := func() (*PortReader, chan struct{}) {
+		r, err := p.NewLineReader()
+		require.NoError(t, err)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+		}()
+		return r, done
+	}
+
+	r, done := loop()
+	write("1.25,3.5\n2.50,")
+	require.Equal(t, "1.25,3.5", <-lines)
+	require.Eventually(t, func() bool { return p.sr.Stats().BytesRead == 14 }, time.Second, time.Millisecond)
+	r.Close()
+	<-done
+
+	// The next loop finishes the sample the first one had started.
+	_, done = loop()
+	write("7.0\n")
+	require.Equal(t, "2.50,7.0", <-lines)
+	p.Close()
+	<-done
+}
+
+func TestReopen_KeepsPartialLine(t *testing.T) {
+	for _, discard := range []bool{false, true} {
+		reader, master := newTestReader(t, Config{DiscardPartial: discard})
+		lines := make(chan string, 4)
+		loop := func() chan struct{} {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+			}()
+			return done
+		}
+
+		done := loop()
+		_, err := master.Write([]byte("a\nb"))
+		require.NoError(t, err)
+		require.Equal(t, "a", <-lines)
+		require.Eventually(t, func() bool { return reader.Stats().BytesRead == 3 }, time.Second, time.Millisecond)
+		require.NoError(t, reader.Reopen())
+		<-done
+
+		done = loop()
+		_, err = master.Write([]byte("c\n"))
+		require.NoError(t, err)
+		if discard {
+			require.Equal(t, "c", <-lines)
+		} else {
+			require.Equal(t, "bc", <-lines)
+		}
+		reader.Close()
+		<-done
+	}
+}
+
+func TestReadLine_KeepsBytesPastDelimiter(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	_, err := master.Write([]byte("a\nb\nc"))
+	require.NoError(t, err)
+	for _, want := range []string{"a", "b"} {
+		line, err := reader.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, want, line)
+	}
+	_, err = master.Write([]byte("d\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "cd", line)
+
+	// Lines already buffered cost only the returned string.
+	_, err = master.Write([]byte(strings.Repeat("line\n", 200)))
+	require.NoError(t, err)
+	_, err = reader.ReadLine() // pulls in the whole write
+	require.NoError(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := reader.ReadLine(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	require.LessOrEqual(t, allocs, 1.0)
+}
+
+func TestReadLinesLoop_AfterReadLine(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	_, err := master.Write([]byte("a\nb\nc\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "a", line)
+
+	// The lines ReadLine read past are delivered without waiting for more.
+	lines := make(chan string, 2)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	require.Equal(t, "b", <-lines)
+	require.Equal(t, "c", <-lines)
+}
@@ -0,0 +1,48 @@
+package serial
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PermissionError explains why opening a device was denied and how to fix
+// it, e.g. by joining the "dialout" group. It is the Err of the SerialError
+// returned by Open and unwraps to the errno, so errors.Is(err,
+// fs.ErrPermission) still holds.
+type PermissionError struct {
+	Device string
+	Mode   os.FileMode // permission bits of the device node
+	Owner  string      // owning user name, or its uid
+	Group  string      // owning group name, or its gid; usually "dialout" or "uucp"
+	User   string      // current user name, or its uid
+
+	// InGroup reports whether the user's account is a member of Group, and
+	// SessionInGroup whether this process actually has it: after
+	// "usermod -aG" the account is a member, but only new logins get the
+	// group.
+	InGroup        bool
+	SessionInGroup bool
+
+	Err error // the underlying errno, EACCES or EPERM
+}
+
+func (e *PermissionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v (device %s is %v, owner %s, group %s)", e.Err, e.Device, e.Mode.Perm(), e.Owner, e.Group)
+	switch {
+	case e.Mode&0060 == 0:
+		fmt.Fprintf(&b, "; the group has no access either: fix the device mode with a udev rule, e.g. MODE=\"0660\", GROUP=%q", e.Group)
+	case e.SessionInGroup:
+		b.WriteString("; the process already has the group, so check ACLs, SELinux/AppArmor or a container device policy")
+	case e.InGroup:
+		fmt.Fprintf(&b, "; user %s was added to group %s but this session predates it: log out and back in (or run \"newgrp %s\")", e.User, e.Group, e.Group)
+	default:
+		fmt.Fprintf(&b, "; add user %s to group %s with \"sudo usermod -aG %s %s\", then log out and back in", e.User, e.Group, e.Group, e.User)
+	}
+	return b.String()
+}
+
+func (e *PermissionError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,48 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionError(t *testing.T) {
+	dev := filepath.Join(t.TempDir(), "ttyUSB0")
+	require.NoError(t, os.WriteFile(dev, nil, 0600))
+
+	// Errors other than EACCES and EPERM pass through.
+	require.Equal(t, syscall.ENOENT, permissionError(dev, syscall.ENOENT))
+
+	err := permissionError(dev, syscall.EACCES)
+	var perr *PermissionError
+	require.ErrorAs(t, err, &perr)
+	require.ErrorIs(t, err, fs.ErrPermission)
+	require.Contains(t, err.Error(), "udev rule")
+
+	// A group-accessible device owned by a group the process lacks.
+	g, lookupErr := user.LookupGroup("dialout")
+	if lookupErr != nil || os.Getuid() != 0 {
+		t.Skip("needs root and a dialout group to chown the fake device")
+	}
+	gid, _ := strconv.Atoi(g.Gid)
+	require.NoError(t, os.Chown(dev, 0, gid))
+	require.NoError(t, os.Chmod(dev, 0660))
+	err = permissionError(dev, syscall.EACCES)
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, "dialout", perr.Group)
+	require.False(t, perr.SessionInGroup)
+	require.Contains(t, err.Error(), "usermod -aG dialout")
+
+	serr := &SerialError{Device: dev, Op: "open", Err: err}
+	require.True(t, serr.IsPermission())
+	require.True(t, errors.Is(serr, syscall.EACCES))
+}
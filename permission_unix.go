@@ -0,0 +1,52 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"os"
+	"os/user"
+	"slices"
+	"strconv"
+	"syscall"
+)
+
+// permissionError returns a PermissionError for the device if err is EACCES
+// or EPERM and the device can be inspected, and err otherwise.
+func permissionError(device string, err error) error {
+	if err != syscall.EACCES && err != syscall.EPERM {
+		return err
+	}
+	info, statErr := os.Stat(device)
+	if statErr != nil {
+		return err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return err
+	}
+	gid := strconv.FormatUint(uint64(st.Gid), 10)
+	e := &PermissionError{
+		Device: device,
+		Mode:   info.Mode(),
+		Owner:  strconv.FormatUint(uint64(st.Uid), 10),
+		Group:  gid,
+		User:   strconv.Itoa(os.Getuid()),
+		Err:    err,
+	}
+	if u, err := user.LookupId(e.Owner); err == nil {
+		e.Owner = u.Username
+	}
+	if g, err := user.LookupGroupId(gid); err == nil {
+		e.Group = g.Name
+	}
+	if u, err := user.LookupId(e.User); err == nil {
+		e.User = u.Username
+		if ids, err := u.GroupIds(); err == nil {
+			e.InGroup = slices.Contains(ids, gid)
+		}
+	}
+	if groups, err := os.Getgroups(); err == nil {
+		e.SessionInGroup = slices.Contains(groups, int(st.Gid)) || os.Getegid() == int(st.Gid)
+	}
+	return e
+}
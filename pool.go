@@ -0,0 +1,80 @@
+package serial
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// WorkerPool runs ReadLinesLoop's onLine on a bounded set of goroutines, so
+// expensive per-line processing does not hold up the read loop until the
+// kernel's input buffer overruns. See Config.Workers.
+//
+// Each worker has a queue of Queue lines; when the queue a line is bound for
+// is full, the read loop waits for it. A pool therefore absorbs bursts and
+// spreads sustained load over Workers cores, but cannot keep up with a
+// device that is permanently faster than all workers together.
+type WorkerPool struct {
+	Workers int // goroutines calling onLine; default 1
+	Queue   int // lines buffered per worker; default 64
+
+	// Key, if set, keeps lines with equal keys in order by handing them all
+	// to the same worker, e.g. the sentence type of NMEA lines or the
+	// sensor ID of a multiplexed stream. Without it lines are handled in
+	// any order.
+	Key func(line string) string
+}
+
+// poolFunc returns a handler that queues lines for onLine on the workers of
+// Config.Workers, and a function that waits until every queued line has been
+// handled and stops them. Without a pool it returns onLine itself.
+func (s *SerialReader) poolFunc(onLine func(string)) (func(string), func()) {
+	wp := s.config.Workers
+	if wp == nil || onLine == nil {
+		return onLine, func() {}
+	}
+	workers, queue := max(wp.Workers, 1), wp.Queue
+	if queue <= 0 {
+		queue = 64
+	}
+	var wg sync.WaitGroup
+	chans := make([]chan string, workers)
+	if wp.Key == nil {
+		// One shared queue: an idle worker takes the next line.
+		ch := make(chan string, queue*workers)
+		for i := range chans {
+			chans[i] = ch
+		}
+	} else {
+		for i := range chans {
+			chans[i] = make(chan string, queue)
+		}
+	}
+	for i := range workers {
+		wg.Add(1)
+		go func(ch <-chan string) {
+			defer wg.Done()
+			for line := range ch {
+				onLine(line)
+			}
+		}(chans[i])
+	}
+	seed := maphash.MakeSeed()
+	handle := func(line string) {
+		i := 0
+		if wp.Key != nil {
+			i = int(maphash.String(seed, wp.Key(line)) % uint64(workers))
+		}
+		chans[i] <- line
+	}
+	stop := func() {
+		if wp.Key == nil {
+			close(chans[0])
+		} else {
+			for _, ch := range chans {
+				close(ch)
+			}
+		}
+		wg.Wait()
+	}
+	return handle, stop
+}
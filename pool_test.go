@@ -0,0 +1,65 @@
+package serial
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// runPool feeds 32 lines, a0 b0 c0 d0 a1 ..., through a reader with the
+// given pool and returns them grouped by letter in the order handled, with
+// the most lines handled at once.
+func runPool(t *testing.T, wp *WorkerPool) (map[string][]string, int32) {
+	reader, master := newTestReader(t, Config{Workers: wp})
+	var (
+		mu            sync.Mutex
+		got           = map[string][]string{}
+		busy, maxBusy atomic.Int32
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader.ReadLinesLoop(func(l string) {
+			n := busy.Add(1)
+			for cur := maxBusy.Load(); n > cur && !maxBusy.CompareAndSwap(cur, n); cur = maxBusy.Load() {
+			}
+			time.Sleep(5 * time.Millisecond) // expensive processing
+			busy.Add(-1)
+			mu.Lock()
+			got[l[:1]] = append(got[l[:1]], l)
+			mu.Unlock()
+		}, func(error) {})
+	}()
+
+	var in []string
+	for i := range 8 {
+		for _, k := range []string{"a", "b", "c", "d"} {
+			in = append(in, k+string(rune('0'+i)))
+		}
+	}
+	_, err := master.Write([]byte(strings.Join(in, "\n") + "\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return reader.Stats().Lines == 32 }, time.Second, time.Millisecond)
+	reader.Close()
+	<-done // every line handed to the pool has been handled
+	return got, maxBusy.Load()
+}
+
+func TestSerialReader_WorkerPool(t *testing.T) {
+	got, maxBusy := runPool(t, &WorkerPool{Workers: 4})
+	require.Greater(t, maxBusy, int32(1))
+	n := 0
+	for _, lines := range got {
+		n += len(lines)
+	}
+	require.Equal(t, 32, n)
+
+	got, _ = runPool(t, &WorkerPool{Workers: 4, Key: func(l string) string { return l[:1] }})
+	for _, k := range []string{"a", "b", "c", "d"} {
+		require.Equal(t, []string{k + "0", k + "1", k + "2", k + "3", k + "4", k + "5", k + "6", k + "7"}, got[k])
+	}
+}
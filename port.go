@@ -0,0 +1,216 @@
+package serial
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Port is a shared handle on an open serial device from which independent
+// read and write facets are created. Each facet has its own lifecycle: closing
+// a PortReader unblocks only that reader, and closing a PortWriter only stops
+// that writer. The device itself is released by Port.Close or when the last
+// facet is closed.
+type Port struct {
+	sr *SerialReader
+
+	mu     sync.Mutex
+	facets int
+	closed bool
+}
+
+// OpenPort opens the device described by cfg and returns a Port.
+// Facets are limited by cfg.Access: a ReadOnly port has no writers and a
+// WriteOnly port has no readers.
+func OpenPort(cfg Config) (*Port, error) {
+	sr, err := Open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Port{sr: sr}, nil
+}
+
+// NewLineReader returns a new read facet. It fails with ErrAccessMode on a
+// WriteOnly port and ErrClosed once the port is closed.
+func (p *Port) NewLineReader() (*PortReader, error) {
+	if p.sr.config.Access == WriteOnly {
+		return nil, p.sr.opErr("read", ErrAccessMode)
+	}
+	w, err := newWaker()
+	if err != nil {
+		return nil, p.sr.opErr("pipe", err)
+	}
+	if err := p.acquire(); err != nil {
+		w.close()
+		return nil, err
+	}
+	return &PortReader{port: p, stop: w}, nil
+}
+
+// NewWriter returns a new write facet. It fails with ErrAccessMode on a
+// ReadOnly port and ErrClosed once the port is closed.
+func (p *Port) NewWriter() (*PortWriter, error) {
+	if p.sr.config.Access == ReadOnly {
+		return nil, p.sr.opErr("write", ErrAccessMode)
+	}
+	if err := p.acquire(); err != nil {
+		return nil, err
+	}
+	return &PortWriter{port: p}, nil
+}
+
+// Close closes the device, ending all facets.
+func (p *Port) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return p.sr.Close()
+}
+
+func (p *Port) acquire() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	p.facets++
+	return nil
+}
+
+// release drops a facet reference, closing the device after the last one.
+func (p *Port) release() error {
+	p.mu.Lock()
+	p.facets--
+	last := p.facets == 0 && !p.closed
+	p.mu.Unlock()
+	if last {
+		return p.Close()
+	}
+	return nil
+}
+
+// PortReader is the read facet of a Port.
+type PortReader struct {
+	port *Port
+	stop *waker
+	once sync.Once
+}
+
+// ReadLine reads a single line, like SerialReader.ReadLine. It returns
+// ErrClosed once this reader or the port is closed.
+func (r *PortReader) ReadLine() (string, error) {
+	if !r.stop.enter() {
+		return "", ErrClosed
+	}
+	defer r.stop.exit()
+	return r.port.sr.readLine(r.stop)
+}
+
+// ReadLinesLoop reads lines until an error occurs or this reader or the port
+// is closed, like SerialReader.ReadLinesLoop.
+func (r *PortReader) ReadLinesLoop(onLine func(string), onError func(error)) {
+	if !r.stop.enter() {
+		return
+	}
+	defer r.stop.exit()
+	r.port.sr.readLinesLoop(r.stop, onLine, onError)
+}
+
+// Close stops this reader, unblocking its ReadLine and ReadLinesLoop calls.
+// Safe to call multiple times.
+func (r *PortReader) Close() error {
+	var err error
+	r.once.Do(func() {
+		r.stop.close()
+		err = r.port.release()
+	})
+	return err
+}
+
+// PortWriter is the write facet of a Port.
+type PortWriter struct {
+	port   *Port
+	closed atomic.Bool
+}
+
+// WriteLine writes line followed by newline, like SerialReader.WriteLine.
+// It returns ErrClosed once this writer or the port is closed.
+func (w *PortWriter) WriteLine(line, newline string) error {
+	if w.closed.Load() {
+		return ErrClosed
+	}
+	return w.port.sr.WriteLine(line, newline)
+}
+
+// Close stops this writer. Safe to call multiple times.
+func (w *PortWriter) Close() error {
+	if w.closed.Swap(true) {
+		return nil
+	}
+	return w.port.release()
+}
+
+// waker is a self-pipe that, once closed, makes poll return and fired report
+// true. Its read end stays open until the last poller has left, so a
+// concurrent poll never sees a reused descriptor.
+type waker struct {
+	wakePipe
+	done chan struct{}
+
+	mu     sync.Mutex
+	users  int
+	closed bool
+}
+
+func newWaker() (*waker, error) {
+	wp, err := newWakePipe()
+	if err != nil {
+		return nil, err
+	}
+	return &waker{wakePipe: wp, done: make(chan struct{})}, nil
+}
+
+func (w *waker) fired() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// enter registers a poller; it returns false if the waker already fired.
+func (w *waker) enter() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return false
+	}
+	w.users++
+	return true
+}
+
+// exit unregisters a poller, releasing the pipe after the last one leaves a
+// fired waker.
+func (w *waker) exit() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.users--
+	if w.closed && w.users == 0 {
+		w.release()
+	}
+}
+
+// close fires the waker. Safe to call multiple times.
+func (w *waker) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+	w.closed = true
+	close(w.done)
+	w.wake()
+	if w.users == 0 {
+		w.release()
+	}
+}
@@ -0,0 +1,87 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPort(t *testing.T, access AccessMode) (*Port, func() []byte, func(string)) {
+	t.Helper()
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+
+	p, err := OpenPort(Config{Device: slave.Name(), BaudRate: 115200, Delimiter: "\n", Access: access})
+	require.NoError(t, err)
+	t.Cleanup(func() { p.Close() })
+
+	read := func() []byte {
+		buf := make([]byte, 64)
+		n, err := master.Read(buf)
+		require.NoError(t, err)
+		return buf[:n]
+	}
+	write := func(s string) {
+		_, err := master.Write([]byte(s))
+		require.NoError(t, err)
+	}
+	return p, read, write
+}
+
+func TestPort_IndependentFacets(t *testing.T) {
+	p, read, write := newTestPort(t, ReadWrite)
+
+	r, err := p.NewLineReader()
+	require.NoError(t, err)
+	w, err := p.NewWriter()
+	require.NoError(t, err)
+
+	exited := make(chan struct{})
+	go func() {
+		r.ReadLinesLoop(func(string) {}, func(error) {})
+		close(exited)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// Closing the reader stops only the read side.
+	require.NoError(t, r.Close())
+	select {
+	case <-exited:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("ReadLinesLoop did not exit after PortReader.Close")
+	}
+	_, err = r.ReadLine()
+	require.ErrorIs(t, err, ErrClosed)
+
+	require.NoError(t, w.WriteLine("still open", "\n"))
+	require.Equal(t, "still open\n", string(read()))
+
+	// A fresh reader still works on the shared fd.
+	r2, err := p.NewLineReader()
+	require.NoError(t, err)
+	write("hello\n")
+	line, err := r2.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "hello", line)
+
+	// Releasing the last facet releases the device.
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	require.ErrorIs(t, w.WriteLine("x", "\n"), ErrClosed)
+	require.NoError(t, r2.Close())
+	_, err = p.NewWriter()
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestPort_AccessModeLimitsFacets(t *testing.T) {
+	ro, _, _ := newTestPort(t, ReadOnly)
+	_, err := ro.NewWriter()
+	require.ErrorIs(t, err, ErrAccessMode)
+
+	wo, _, _ := newTestPort(t, WriteOnly)
+	_, err = wo.NewLineReader()
+	require.ErrorIs(t, err, ErrAccessMode)
+}
@@ -0,0 +1,19 @@
+// Package pps reads pulse-per-second timestamps from the Linux kernel PPS
+// subsystem (/dev/pps*) using the RFC 2783 ioctl interface.
+// Device is only available on Linux; Edge and PairLines are portable so
+// that consumers such as gpstime build everywhere.
+package pps
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by Device.Wait when no edge arrives in time.
+var ErrTimeout = errors.New("pps: timeout")
+
+// Edge is one captured PPS assert edge.
+type Edge struct {
+	Sequence uint32    // kernel assert sequence number, incremented per pulse
+	Time     time.Time // system time (CLOCK_REALTIME) at which the edge was captured
+}
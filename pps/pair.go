@@ -0,0 +1,33 @@
+package pps
+
+import "time"
+
+// Fetcher provides the most recent PPS edge; *Device implements it.
+type Fetcher interface {
+	Fetch() (Edge, error)
+}
+
+// PairLines returns a line handler, for ReadLinesLoop or a serial.Router,
+// that passes each line to onLine together with the latest edge of src,
+// for instruments whose timing pulse arrives on a separate PPS device tied
+// to the same adapter. ok is false when no edge was captured within maxAge
+// (default one second) before the line arrived, or when src fails.
+func PairLines(src Fetcher, maxAge time.Duration, onLine func(line string, edge Edge, ok bool)) func(line string) {
+	if maxAge <= 0 {
+		maxAge = time.Second
+	}
+	return func(line string) {
+		arrival := time.Now()
+		e, err := src.Fetch()
+		if err != nil {
+			onLine(line, Edge{}, false)
+			return
+		}
+		age := arrival.Sub(e.Time)
+		ok := e.Sequence != 0 && age >= 0 && age < maxAge // sequence 0: no pulse yet
+		if !ok {
+			e = Edge{}
+		}
+		onLine(line, e, ok)
+	}
+}
@@ -0,0 +1,44 @@
+package pps
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFetcher struct {
+	edge Edge
+	err  error
+}
+
+func (f *fakeFetcher) Fetch() (Edge, error) { return f.edge, f.err }
+
+func TestPairLines(t *testing.T) {
+	src := &fakeFetcher{edge: Edge{Sequence: 3, Time: time.Now()}}
+	type paired struct {
+		line string
+		seq  uint32
+		ok   bool
+	}
+	var got []paired
+	h := PairLines(src, 0, func(line string, e Edge, ok bool) {
+		got = append(got, paired{line, e.Sequence, ok})
+	})
+	h("$PSAMPLE,1")
+
+	src.edge.Time = time.Now().Add(-2 * time.Second) // stale
+	h("$PSAMPLE,2")
+	src.edge = Edge{} // no pulse captured yet
+	h("$PSAMPLE,3")
+	src.err = errors.New("gone")
+	h("$PSAMPLE,4")
+
+	require.Equal(t, []paired{
+		{"$PSAMPLE,1", 3, true},
+		{"$PSAMPLE,2", 0, false},
+		{"$PSAMPLE,3", 0, false},
+		{"$PSAMPLE,4", 0, false},
+	}, got)
+}
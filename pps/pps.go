@@ -0,0 +1,100 @@
+//go:build linux
+
+package pps
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Device is an open kernel PPS source.
+type Device struct {
+	f *os.File
+}
+
+// Open opens a PPS device such as /dev/pps0.
+func Open(path string) (*Device, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{f: f}, nil
+}
+
+// Fetch returns the most recent assert edge without waiting for a new one.
+func (d *Device) Fetch() (Edge, error) {
+	var data unix.PPSFData
+	if err := d.fetch(&data); err != nil {
+		return Edge{}, err
+	}
+	return edgeFrom(data.Info), nil
+}
+
+// Wait blocks until the next assert edge and returns it, or fails with
+// ErrTimeout once timeout has passed without one. A timeout of zero or less
+// waits indefinitely. Close does not interrupt a waiting call.
+func (d *Device) Wait(timeout time.Duration) (Edge, error) {
+	var data unix.PPSFData
+	data.Timeout = waitTimeout(timeout)
+	err := d.fetch(&data)
+	if errors.Is(err, unix.ETIMEDOUT) {
+		return Edge{}, fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+	if err != nil {
+		return Edge{}, err
+	}
+	return edgeFrom(data.Info), nil
+}
+
+// Close releases the device.
+func (d *Device) Close() error {
+	return d.f.Close()
+}
+
+func (d *Device) fetch(data *unix.PPSFData) error {
+	conn, err := d.f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno unix.Errno
+	err = conn.Control(func(fd uintptr) {
+		for {
+			_, _, errno = unix.Syscall(unix.SYS_IOCTL, fd, unix.PPS_FETCH, uintptr(unsafe.Pointer(data)))
+			if errno != unix.EINTR {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return fmt.Errorf("pps fetch %s: %w", d.f.Name(), errno)
+	}
+	return nil
+}
+
+// timeInvalid marks a PPS_FETCH timeout as absent (PPS_TIME_INVALID).
+const timeInvalid = 1
+
+// waitTimeout converts a Wait timeout for PPS_FETCH. A zero timeout would
+// make the kernel return at once, so it is marked invalid instead, which
+// waits for ever.
+func waitTimeout(timeout time.Duration) unix.PPSKTime {
+	if timeout <= 0 {
+		return unix.PPSKTime{Flags: timeInvalid}
+	}
+	return unix.PPSKTime{Sec: int64(timeout / time.Second), Nsec: int32(timeout % time.Second)}
+}
+
+func edgeFrom(info unix.PPSKInfo) Edge {
+	return Edge{
+		Sequence: info.Assert_sequence,
+		Time:     time.Unix(info.Assert_tu.Sec, int64(info.Assert_tu.Nsec)),
+	}
+}
@@ -0,0 +1,44 @@
+//go:build linux
+
+package pps
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestFetch_NotAPPSDevice(t *testing.T) {
+	d, err := Open("/dev/null")
+	require.NoError(t, err)
+	defer d.Close()
+
+	_, err = d.Fetch()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EINVAL))
+}
+
+func TestWait_NotAPPSDevice(t *testing.T) {
+	d, err := Open("/dev/null")
+	require.NoError(t, err)
+	defer d.Close()
+
+	_, err = d.Wait(10 * time.Millisecond)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrTimeout)
+}
+
+func TestWaitTimeout(t *testing.T) {
+	require.Equal(t, unix.PPSKTime{Sec: 1, Nsec: 500000000}, waitTimeout(1500*time.Millisecond))
+	require.Equal(t, unix.PPSKTime{Flags: timeInvalid}, waitTimeout(0))
+}
+
+func TestEdgeFrom(t *testing.T) {
+	e := edgeFrom(unix.PPSKInfo{Assert_sequence: 7, Assert_tu: unix.PPSKTime{Sec: 1700000000, Nsec: 250}})
+	require.EqualValues(t, 7, e.Sequence)
+	require.EqualValues(t, 1700000000, e.Time.Unix())
+	require.Equal(t, 250, e.Time.Nanosecond())
+}
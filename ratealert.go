@@ -0,0 +1,85 @@
+package serial
+
+import (
+	"context"
+	"time"
+)
+
+// RateBand is the expected line rate of a port, for MonitorRate.
+type RateBand struct {
+	Min float64 // lines per second; below it the sensor has stalled. 0 disables
+	Max float64 // lines per second; above it something chatters (wrong baud, stuck loop). 0 disables
+
+	// For is how long the rate must stay outside the band before an alert,
+	// so a single late or bunched line does not raise one; default one
+	// Interval.
+	For time.Duration
+
+	// Interval is the period over which the rate is measured; default 1s.
+	Interval time.Duration
+}
+
+// RateAlert reports the line rate leaving the band, or returning to it.
+type RateAlert struct {
+	Rate      float64   // lines per second over the last Interval
+	Band      RateBand  // the band being monitored
+	Since     time.Time // when the rate left the band
+	Recovered bool      // the rate is back inside the band
+}
+
+// Low reports whether the rate is below the band: a stalled sensor.
+func (a RateAlert) Low() bool { return a.Rate < a.Band.Min }
+
+// MonitorRate measures the rate of lines framed by the read loops and
+// ReadLine, and calls onAlert once when it has stayed outside band for
+// band.For, and once more when it is back inside. It blocks until ctx is
+// done or the reader is closed, so run it in its own goroutine. ResetStats
+// while monitoring skews one measurement.
+func (s *SerialReader) MonitorRate(ctx context.Context, band RateBand, onAlert func(RateAlert)) {
+	if band.Interval <= 0 {
+		band.Interval = time.Second
+	}
+	if band.For <= 0 {
+		band.For = band.Interval
+	}
+	t := time.NewTicker(band.Interval)
+	defer t.Stop()
+	last, lastAt := s.linesRead.Load(), time.Now()
+	var since time.Time // zero while inside the band
+	alerted := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if s.closed.Load() {
+				return
+			}
+			cur := s.linesRead.Load()
+			delta := cur - last
+			if cur < last {
+				delta = cur // counters were reset
+			}
+			rate := float64(delta) / now.Sub(lastAt).Seconds()
+			last, lastAt = cur, now
+			outside := (band.Min > 0 && rate < band.Min) || (band.Max > 0 && rate > band.Max)
+			switch {
+			case outside && since.IsZero():
+				// The rate covers the whole interval, so it left the band
+				// at its start.
+				since = now.Add(-band.Interval)
+				fallthrough
+			case outside:
+				if !alerted && now.Sub(since) >= band.For {
+					alerted = true
+					onAlert(RateAlert{Rate: rate, Band: band, Since: since})
+				}
+			case !since.IsZero():
+				if alerted {
+					onAlert(RateAlert{Rate: rate, Band: band, Since: since, Recovered: true})
+				}
+				since, alerted = time.Time{}, false
+			}
+		}
+	}
+}
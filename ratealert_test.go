@@ -0,0 +1,47 @@
+package serial
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_MonitorRate(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	go reader.ReadLinesLoop(func(string) {}, func(error) {})
+
+	alerts := make(chan RateAlert, 4)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	band := RateBand{Min: 20, Max: 1000, For: 100 * time.Millisecond, Interval: 50 * time.Millisecond}
+	go reader.MonitorRate(ctx, band, func(a RateAlert) { alerts <- a })
+
+	// Nothing arrives: the sensor has stalled.
+	a := <-alerts
+	require.True(t, a.Low())
+	require.False(t, a.Recovered)
+	require.Zero(t, a.Rate)
+
+	// Lines at about 100/s bring the rate back into the band.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(10 * time.Millisecond):
+				master.Write([]byte("x\n"))
+			}
+		}
+	}()
+	select {
+	case a = <-alerts:
+		require.True(t, a.Recovered)
+		require.Greater(t, a.Rate, band.Min)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no recovery alert")
+	}
+}
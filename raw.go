@@ -0,0 +1,84 @@
+package serial
+
+import (
+	"io"
+	"time"
+)
+
+// Compile-time check that SerialReader can stand in for a byte stream.
+var _ io.ReadWriter = (*SerialReader)(nil)
+
+// Write writes raw bytes to the port without appending a delimiter.
+func (s *SerialReader) Write(b []byte) (int, error) {
+	if s.config.Access == ReadOnly {
+		return 0, s.opErr("write", ErrAccessMode)
+	}
+	p := s.port()
+	select {
+	case <-p.done:
+		return 0, ErrClosed
+	default:
+	}
+	n, err := s.write(p, b, nil)
+	s.countWritten(n)
+	return n, s.opErr("write", err)
+}
+
+// write writes b to p, keyed for RS-485 or wrapped in the write hooks if
+// configured, and counts failures. With stop set the write waits for room
+// with pollWrite instead of blocking in the kernel. It holds the write lock
+// throughout, so b reaches the port in one piece.
+func (s *SerialReader) write(p *port, b []byte, stop *waker) (n int, err error) {
+	if err := s.lockTx(p, stop); err != nil {
+		return 0, err
+	}
+	defer s.unlockTx()
+	if s.config.RS485 != nil || s.config.BeforeWrite != nil || s.config.AfterWrite != nil {
+		n, err = s.keyedWrite(p, b, stop)
+	} else {
+		n, err = writeTo(p, b, stop)
+	}
+	if err != nil {
+		s.writeErrors.Add(1)
+	}
+	return n, err
+}
+
+// lockTx takes the write lock, so that a line written in chunks, or inside
+// an RS-485 transmit window, is not interleaved with another caller's. It
+// gives up with ErrClosed once p is closed or stop fires.
+func (s *SerialReader) lockTx(p *port, stop *waker) error {
+	var fired <-chan struct{}
+	if stop != nil {
+		fired = stop.done
+	}
+	select {
+	case s.tx <- struct{}{}:
+		return nil
+	case <-p.done:
+		return ErrClosed
+	case <-fired:
+		return ErrClosed
+	}
+}
+
+func (s *SerialReader) unlockTx() {
+	<-s.tx
+}
+
+// SetReadDeadline sets the absolute time after which Read fails with
+// ErrTimeout. A zero value clears the deadline, restoring Config.ReadTimeout.
+func (s *SerialReader) SetReadDeadline(t time.Time) error {
+	if t.IsZero() {
+		s.readDeadline.Store(0)
+	} else {
+		s.readDeadline.Store(t.UnixNano())
+	}
+	return nil
+}
+
+// Drain discards any bytes already received but not yet read, e.g. the
+// remains of a corrupted frame before a binary request is sent.
+func (s *SerialReader) Drain() error {
+	return s.opErr("flush", flushInput(s.port().fd))
+}
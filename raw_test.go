@@ -0,0 +1,50 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_RawReadWrite(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	n, err := reader.Write([]byte{0x01, 0x03, 0x00})
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	got := make([]byte, 3)
+	_, err = master.Read(got)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x03, 0x00}, got)
+
+	_, err = master.Write([]byte{0xff, 0x00, 0x0a})
+	require.NoError(t, err)
+	buf := make([]byte, 16)
+	total := 0
+	for total < 3 {
+		n, err := reader.Read(buf[total:])
+		require.NoError(t, err)
+		total += n
+	}
+	require.Equal(t, []byte{0xff, 0x00, 0x0a}, buf[:total])
+
+	require.NoError(t, reader.SetReadDeadline(time.Now().Add(20*time.Millisecond)))
+	_, err = reader.Read(buf)
+	require.ErrorIs(t, err, ErrTimeout)
+	require.NoError(t, reader.SetReadDeadline(time.Time{}))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := reader.Read(buf)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, reader.Close())
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrClosed)
+	case <-time.After(time.Second):
+		t.Fatal("Read not unblocked by Close")
+	}
+}
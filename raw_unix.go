@@ -0,0 +1,133 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Read reads raw bytes from the port, bypassing line framing, for binary
+// protocols such as Modbus RTU. It blocks until at least one byte is available,
+// the read deadline (or, without one, Config.ReadTimeout) expires with
+// ErrTimeout, or the reader is closed.
+// Read must not be mixed with a running ReadLinesLoop: whichever reads first
+// consumes the bytes.
+func (s *SerialReader) Read(b []byte) (int, error) {
+	if s.config.Access == WriteOnly {
+		return 0, s.opErr("read", ErrAccessMode)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	p := s.port()
+	var deadline time.Time
+	if d := s.readDeadline.Load(); d != 0 {
+		deadline = time.Unix(0, d)
+	} else if s.config.ReadTimeout > 0 {
+		deadline = time.Now().Add(s.config.ReadTimeout)
+	}
+	var fds [3]unix.PollFd
+	pfd := pollFds(&fds, p, nil)
+	for {
+		if p.stopped(nil) {
+			return 0, ErrClosed
+		}
+		timeout := -1
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return 0, s.opErr("read", ErrTimeout)
+			}
+			timeout = int((remaining + time.Millisecond - 1) / time.Millisecond)
+		}
+		n, err := unix.Poll(pfd, timeout)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return 0, s.opErr("poll", err)
+		}
+		if n == 0 {
+			continue
+		}
+		if p.stopped(nil) || pfd[1].Revents&unix.POLLIN != 0 {
+			return 0, ErrClosed
+		}
+		if err := pollErr(pfd[0].Revents); err != nil {
+			return 0, s.opErr("poll", err)
+		}
+		if pfd[0].Revents&unix.POLLIN != 0 {
+			if d := s.config.ReadSettle; d > 0 {
+				time.Sleep(d) // let more of the burst arrive
+				if p.stopped(nil) {
+					return 0, ErrClosed
+				}
+			}
+			n, err := p.file.Read(b)
+			if err != nil {
+				return 0, s.opErr("read", err)
+			}
+			if n = copy(b, s.received(p, b[:n])); n == 0 {
+				continue // only a marking sequence or a marked character
+			}
+			return n, nil
+		}
+	}
+}
+
+// writeTo writes b to p, with pollWrite if stop is set.
+func writeTo(p *port, b []byte, stop *waker) (int, error) {
+	if stop == nil {
+		return p.file.Write(b)
+	}
+	return pollWrite(p, b, stop)
+}
+
+// pollChunk bounds each write after POLLOUT. A tty reports POLLOUT once
+// fewer than 256 bytes wait in its output buffer, so a chunk this size
+// fits without the blocking descriptor putting the caller to sleep.
+const pollChunk = 256
+
+// pollWrite writes b to p in chunks, polling for POLLOUT before each, so a
+// full output buffer (flow control held off, a stalled remote end) leaves
+// the caller in poll, where closing p or firing stop returns ErrClosed.
+func pollWrite(p *port, b []byte, stop *waker) (int, error) {
+	fds := [3]unix.PollFd{
+		{Fd: int32(p.fd), Events: unix.POLLOUT},
+		{Fd: int32(p.pipeR), Events: unix.POLLIN},
+		{Fd: int32(stop.r), Events: unix.POLLIN},
+	}
+	written := 0
+	for written < len(b) {
+		if p.stopped(stop) {
+			return written, ErrClosed
+		}
+		n, err := unix.Poll(fds[:], -1)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return written, err
+		}
+		if n == 0 || p.stopped(stop) {
+			continue
+		}
+		switch re := fds[0].Revents; {
+		case re&unix.POLLNVAL != 0:
+			return written, ErrClosed
+		case re&(unix.POLLHUP|unix.POLLERR) != 0:
+			return written, ErrDeviceRemoved
+		case re&unix.POLLOUT == 0:
+			continue
+		}
+		n, err = p.file.Write(b[written:min(written+pollChunk, len(b))])
+		written += n
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,73 @@
+package serial
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// Read reads raw bytes from the port, bypassing line framing, for binary
+// protocols such as Modbus RTU. It blocks until at least one byte is available,
+// the read deadline (or, without one, Config.ReadTimeout) expires with
+// ErrTimeout, or the reader is closed.
+// Read must not be mixed with a running ReadLinesLoop: whichever reads first
+// consumes the bytes.
+func (s *SerialReader) Read(b []byte) (int, error) {
+	if s.config.Access == WriteOnly {
+		return 0, s.opErr("read", ErrAccessMode)
+	}
+	if len(b) == 0 {
+		return 0, nil
+	}
+	p := s.port()
+	var deadline time.Time
+	if d := s.readDeadline.Load(); d != 0 {
+		deadline = time.Unix(0, d)
+	} else if s.config.ReadTimeout > 0 {
+		deadline = time.Now().Add(s.config.ReadTimeout)
+	}
+	for {
+		if p.stopped(nil) {
+			return 0, ErrClosed
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return 0, s.opErr("read", ErrTimeout)
+		}
+		n, err := p.read(b)
+		if p.stopped(nil) {
+			return 0, ErrClosed
+		}
+		if err != nil {
+			return 0, s.opErr("read", err)
+		}
+		if n = copy(b, s.received(p, b[:n])); n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// writeTo writes b to p. Writes time out every readWait, so a write held
+// up by flow control notices Close, or stop if set, between the pieces.
+func writeTo(p *port, b []byte, stop *waker) (int, error) {
+	written := 0
+	for written < len(b) {
+		if p.stopped(stop) {
+			return written, ErrClosed
+		}
+		var n uint32
+		var werr error
+		if err := p.raw.Write(func(fd uintptr) bool {
+			werr = windows.WriteFile(windows.Handle(fd), b[written:], &n, nil)
+			return true
+		}); err != nil {
+			return written, ErrClosed
+		}
+		written += int(n)
+		// Drivers report the timeout either way: success with a short
+		// count, or ERROR_SEM_TIMEOUT.
+		if werr != nil && werr != windows.ERROR_SEM_TIMEOUT {
+			return written, werr
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,40 @@
+package serial
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockRealtime locks the calling goroutine to its OS thread and gives the
+// thread the SCHED_FIFO policy at priority (1-99), so the goroutine preempts
+// ordinary work and scheduling jitter drops. It needs CAP_SYS_NICE or an
+// RLIMIT_RTPRIO allowance; without one it fails with EPERM and changes
+// nothing.
+//
+// The returned restore function puts the thread back to its previous policy
+// and unlocks it; call it from the same goroutine. If the policy cannot be
+// restored, the goroutine stays locked, so the thread is discarded when the
+// goroutine exits instead of running other goroutines in real time.
+func LockRealtime(priority int) (restore func(), err error) {
+	runtime.LockOSThread()
+	prev, err := unix.SchedGetAttr(0, 0)
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	attr := unix.SchedAttr{
+		Policy:   unix.SCHED_FIFO,
+		Flags:    unix.SCHED_FLAG_RESET_ON_FORK,
+		Priority: uint32(priority),
+	}
+	if err := unix.SchedSetAttr(0, &attr, 0); err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	return func() {
+		if unix.SchedSetAttr(0, prev, 0) == nil {
+			runtime.UnlockOSThread()
+		}
+	}, nil
+}
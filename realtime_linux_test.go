@@ -0,0 +1,53 @@
+package serial
+
+import (
+	"errors"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestLockRealtime(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Hold an outer lock so the goroutine keeps its thread after restore.
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		restore, err := LockRealtime(10)
+		if errors.Is(err, syscall.EPERM) {
+			t.Log("no real-time privilege:", err)
+			return
+		}
+		require.NoError(t, err)
+		attr, err := unix.SchedGetAttr(0, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, unix.SCHED_FIFO, attr.Policy)
+		require.EqualValues(t, 10, attr.Priority)
+		restore()
+		attr, err = unix.SchedGetAttr(0, 0)
+		require.NoError(t, err)
+		require.NotEqualValues(t, unix.SCHED_FIFO, attr.Policy)
+	}()
+	<-done
+}
+
+func TestSerialReader_RealtimePriority(t *testing.T) {
+	reader, master := newTestReader(t, Config{RealtimePriority: 5})
+	lines := make(chan string, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) {
+		require.ErrorIs(t, err, syscall.EPERM) // only acceptable failure
+	})
+	_, err := master.Write([]byte("rt\n"))
+	require.NoError(t, err)
+	select {
+	case l := <-lines:
+		require.Equal(t, "rt", l)
+	case <-time.After(time.Second):
+		t.Fatal("no line")
+	}
+}
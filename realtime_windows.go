@@ -0,0 +1,10 @@
+package serial
+
+import "errors"
+
+// LockRealtime is only implemented on Linux; elsewhere it returns
+// errors.ErrUnsupported, which Config.RealtimePriority reports through
+// onError before reading on at normal priority.
+func LockRealtime(priority int) (restore func(), err error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,383 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// PortState is the lifecycle state of a port in a Registry.
+type PortState int
+
+const (
+	PortStopped      PortState = iota // registered, not open
+	PortRunning                       // open and reading
+	PortReconnecting                  // read failed, reopening
+	PortFailed                        // Start could not open it
+)
+
+var portStateNames = [...]string{"stopped", "running", "reconnecting", "failed"}
+
+// String returns "stopped", "running", "reconnecting" or "failed".
+func (s PortState) String() string {
+	if s < 0 || int(s) >= len(portStateNames) {
+		return "PortState(" + strconv.Itoa(int(s)) + ")"
+	}
+	return portStateNames[s]
+}
+
+// PortStatus is a snapshot of a port in a Registry.
+type PortStatus struct {
+	Name   string
+	Device string
+	State  PortState
+	Since  time.Time // when State was entered
+	Err    error     // most recent error; Start and Stop clear it
+	Stats  Stats     // zero while stopped
+
+	// Errors and Reconnects count the read and reopen errors and the
+	// successful reopens since Start.
+	Errors     uint64
+	Reconnects uint64
+	// ReadRate and LineRate are the bytes and lines read per second,
+	// averaged since Start; zero while stopped.
+	ReadRate float64
+	LineRate float64
+}
+
+// Registry manages the ports of a multi-instrument daemon by logical name,
+// such as "seismo-1" or "gps". Each port is registered with its Config and
+// line handler; Start opens it and runs ReadLinesLoop on it, reopening it
+// after RetryInterval whenever the loop ends with an error, until Stop.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	// OnError, if set, receives the read and reopen errors of every port.
+	OnError func(name string, err error)
+	// RetryInterval is the wait before reopening a failed port. Zero means
+	// one second.
+	RetryInterval time.Duration
+
+	mu    sync.Mutex
+	ports map[string]*registeredPort
+}
+
+type registeredPort struct {
+	cfg    Config
+	onLine func(line string)
+
+	// guarded by Registry.mu
+	reader *SerialReader
+	stop   chan struct{} // closed by Stop
+	done   chan struct{} // closed when the read goroutine returns
+	state  PortState
+	since  time.Time
+	err    error
+
+	started    time.Time // when Start opened the port
+	errors     uint64
+	reconnects uint64
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{ports: make(map[string]*registeredPort)}
+}
+
+// Register adds a stopped port under name. It fails if name is empty or
+// taken, or if cfg does not validate.
+func (r *Registry) Register(name string, cfg Config, onLine func(line string)) error {
+	if name == "" {
+		return fmt.Errorf("serial: register: empty port name")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("serial: register %s: %w", name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, dup := r.ports[name]; dup {
+		return fmt.Errorf("serial: register %s: name already registered", name)
+	}
+	r.ports[name] = &registeredPort{cfg: cfg, onLine: onLine, since: time.Now()}
+	return nil
+}
+
+// Unregister stops the port called name and removes it.
+func (r *Registry) Unregister(name string) error {
+	if err := r.Stop(name); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	delete(r.ports, name)
+	r.mu.Unlock()
+	return nil
+}
+
+// Start opens the port called name and starts reading it. Starting a
+// running port does nothing. If the port cannot be opened, it is left in
+// PortFailed and the error is returned.
+func (r *Registry) Start(name string) error {
+	r.mu.Lock()
+	p, ok := r.ports[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("serial: start %s: no such port", name)
+	}
+	if p.reader != nil {
+		r.mu.Unlock()
+		return nil
+	}
+	r.mu.Unlock()
+
+	reader, err := Open(p.cfg)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if p.reader != nil { // started concurrently
+		if reader != nil {
+			reader.Close()
+		}
+		return nil
+	}
+	if err != nil {
+		r.setState(p, PortFailed, err)
+		return fmt.Errorf("serial: start %s: %w", name, err)
+	}
+	p.reader, p.stop, p.done = reader, make(chan struct{}), make(chan struct{})
+	p.started, p.errors, p.reconnects = time.Now(), 0, 0
+	r.setState(p, PortRunning, nil)
+	go r.run(name, p, reader, p.stop, p.done)
+	return nil
+}
+
+// Stop closes the port called name and waits for its handler to return.
+// Stopping a stopped port does nothing.
+func (r *Registry) Stop(name string) error {
+	r.mu.Lock()
+	p, ok := r.ports[name]
+	if !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("serial: stop %s: no such port", name)
+	}
+	reader, stop, done := p.reader, p.stop, p.done
+	if reader == nil {
+		r.mu.Unlock()
+		return nil
+	}
+	p.reader = nil
+	close(stop)
+	r.mu.Unlock()
+
+	reader.Close()
+	<-done
+	r.mu.Lock()
+	if p.reader == nil {
+		r.setState(p, PortStopped, nil)
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// StartAll starts every registered port, in name order, and returns the
+// errors of those that failed to open.
+func (r *Registry) StartAll() error {
+	var errs []error
+	for _, name := range r.Names() {
+		errs = append(errs, r.Start(name))
+	}
+	return errors.Join(errs...)
+}
+
+// StopAll stops every port.
+func (r *Registry) StopAll() {
+	names := r.Names()
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.Stop(name)
+		}()
+	}
+	wg.Wait()
+}
+
+// Lookup returns the reader of the port called name while it is started.
+func (r *Registry) Lookup(name string) (*SerialReader, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.ports[name]
+	if !ok || p.reader == nil {
+		return nil, false
+	}
+	return p.reader, true
+}
+
+// Names returns the names of the registered ports, sorted.
+func (r *Registry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.ports))
+	for name := range r.ports {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Status returns the status of the port called name.
+func (r *Registry) Status(name string) (PortStatus, bool) {
+	r.mu.Lock()
+	p, ok := r.ports[name]
+	if !ok {
+		r.mu.Unlock()
+		return PortStatus{}, false
+	}
+	st := PortStatus{
+		Name: name, Device: p.cfg.Device, State: p.state, Since: p.since, Err: p.err,
+		Errors: p.errors, Reconnects: p.reconnects,
+	}
+	reader, started := p.reader, p.started
+	r.mu.Unlock()
+	if reader != nil {
+		st.Stats = reader.Stats()
+		if secs := time.Since(started).Seconds(); secs > 0 {
+			st.ReadRate = float64(st.Stats.BytesRead) / secs
+			st.LineRate = float64(st.Stats.Lines) / secs
+		}
+	}
+	return st, true
+}
+
+// Statuses returns the status of every port, sorted by name.
+func (r *Registry) Statuses() []PortStatus {
+	var sts []PortStatus
+	for _, name := range r.Names() {
+		if st, ok := r.Status(name); ok {
+			sts = append(sts, st)
+		}
+	}
+	return sts
+}
+
+// RegistryStats combines the statuses of the ports in a Registry, for
+// monitoring loops that report on a whole gateway at once.
+type RegistryStats struct {
+	Ports []PortStatus // every port, sorted by name
+
+	// Total sums the counters of the started ports, except MaxBacklog,
+	// which is the largest of theirs. Latency is always nil.
+	Total      Stats
+	Errors     uint64
+	Reconnects uint64
+	ReadRate   float64
+	LineRate   float64
+
+	// Ports in each state, indexed by PortState.
+	States [len(portStateNames)]int
+}
+
+// Stats returns the status of every port together with their totals.
+func (r *Registry) Stats() RegistryStats {
+	var rs RegistryStats
+	rs.Ports = r.Statuses()
+	for _, st := range rs.Ports {
+		rs.States[st.State]++
+		rs.Errors += st.Errors
+		rs.Reconnects += st.Reconnects
+		rs.ReadRate += st.ReadRate
+		rs.LineRate += st.LineRate
+		t, s := &rs.Total, st.Stats
+		t.BytesRead += s.BytesRead
+		t.BytesWritten += s.BytesWritten
+		t.Lines += s.Lines
+		t.LinesWritten += s.LinesWritten
+		t.WriteErrors += s.WriteErrors
+		t.Dropped += s.Dropped
+		t.BadLines += s.BadLines
+		t.ParseErrors += s.ParseErrors
+		t.BadChars += s.BadChars
+		t.Breaks += s.Breaks
+		t.Overruns += s.Overruns
+		t.MaxBacklog = max(t.MaxBacklog, s.MaxBacklog)
+	}
+	return rs
+}
+
+// run reads the port until Stop, reopening it after errors.
+func (r *Registry) run(name string, p *registeredPort, reader *SerialReader, stop, done chan struct{}) {
+	defer close(done)
+	interval := r.RetryInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	for {
+		reader.ReadLinesLoop(p.onLine, func(err error) { r.report(name, p, stop, err) })
+		if !r.transition(p, stop, PortReconnecting) {
+			return
+		}
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(interval):
+			}
+			err := reader.Reopen()
+			if err == nil {
+				break
+			}
+			if errors.Is(err, ErrClosed) {
+				return
+			}
+			r.report(name, p, stop, err)
+		}
+		if !r.transition(p, stop, PortRunning) {
+			return
+		}
+	}
+}
+
+// report records err for a port that has not been stopped and passes it to
+// OnError.
+func (r *Registry) report(name string, p *registeredPort, stop chan struct{}, err error) {
+	r.mu.Lock()
+	select {
+	case <-stop:
+		r.mu.Unlock()
+		return // closed by Stop
+	default:
+	}
+	p.err = err
+	p.errors++
+	r.mu.Unlock()
+	if r.OnError != nil {
+		r.OnError(name, err)
+	}
+}
+
+// transition moves a port that has not been stopped to state, and reports
+// whether it did.
+func (r *Registry) transition(p *registeredPort, stop chan struct{}, state PortState) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	select {
+	case <-stop:
+		return false
+	default:
+	}
+	if state == PortRunning {
+		p.reconnects++ // only reached after a reopen
+	}
+	r.setState(p, state, p.err)
+	return true
+}
+
+// setState must be called with r.mu held.
+func (r *Registry) setState(p *registeredPort, state PortState, err error) {
+	if p.state != state {
+		p.since = time.Now()
+	}
+	p.state, p.err = state, err
+}
@@ -0,0 +1,102 @@
+package serial
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	defer master.Close()
+	defer slave.Close()
+
+	r := NewRegistry()
+	r.RetryInterval = 10 * time.Millisecond
+	lines := make(chan string, 4)
+	require.NoError(t, r.Register("gps", Config{Device: slave.Name(), Delimiter: "\n"}, func(l string) { lines <- l }))
+	require.NoError(t, r.Register("seismo-1", Config{Device: "/dev/nonexistent-serial"}, func(string) {}))
+	require.Error(t, r.Register("gps", Config{Device: slave.Name()}, nil))
+	require.Error(t, r.Register("bad", Config{}, nil))
+	require.Equal(t, []string{"gps", "seismo-1"}, r.Names())
+
+	err = r.StartAll()
+	require.ErrorIs(t, err, os.ErrNotExist)
+	st, ok := r.Status("seismo-1")
+	require.True(t, ok)
+	require.Equal(t, PortFailed, st.State)
+	require.Error(t, st.Err)
+
+	_, err = master.Write([]byte("$GPRMC\n"))
+	require.NoError(t, err)
+	require.Equal(t, "$GPRMC", <-lines)
+	reader, ok := r.Lookup("gps")
+	require.True(t, ok)
+	require.Equal(t, slave.Name(), reader.Device())
+	st, _ = r.Status("gps")
+	require.Equal(t, PortRunning, st.State)
+	require.EqualValues(t, 1, st.Stats.Lines)
+	require.Positive(t, st.LineRate)
+	rs := r.Stats()
+	require.Len(t, rs.Ports, 2)
+	require.EqualValues(t, 1, rs.Total.Lines)
+	require.EqualValues(t, 7, rs.Total.BytesRead)
+	require.Equal(t, 1, rs.States[PortRunning])
+	require.Equal(t, 1, rs.States[PortFailed])
+	require.Positive(t, rs.LineRate)
+
+	// A port that drops out is reopened and counted.
+	reader.port().close()
+	require.Eventually(t, func() bool { return r.Stats().Reconnects == 1 }, time.Second, time.Millisecond)
+	_, err = master.Write([]byte("$GPGGA\n"))
+	require.NoError(t, err)
+	require.Equal(t, "$GPGGA", <-lines)
+
+	r.StopAll()
+	_, ok = r.Lookup("gps")
+	require.False(t, ok)
+	sts := r.Statuses()
+	require.Len(t, sts, 2)
+	require.Equal(t, PortStopped, sts[0].State)
+	require.Equal(t, "stopped", sts[0].State.String())
+
+	require.NoError(t, r.Start("gps"))
+	require.NoError(t, r.Unregister("gps"))
+	require.Equal(t, []string{"seismo-1"}, r.Names())
+	require.Error(t, r.Start("gps"))
+}
+
+func TestRegistry_Reconnect(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	defer slave.Close()
+
+	r := NewRegistry()
+	r.RetryInterval = 10 * time.Millisecond
+	errs := make(chan error, 16)
+	r.OnError = func(name string, err error) {
+		require.Equal(t, "gps", name)
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+	require.NoError(t, r.Register("gps", Config{Device: slave.Name()}, nil))
+	require.NoError(t, r.Start("gps"))
+	defer r.StopAll()
+
+	master.Close() // the device goes away
+	require.Error(t, <-errs)
+	require.Eventually(t, func() bool {
+		st, _ := r.Status("gps")
+		return st.State == PortReconnecting && st.Err != nil
+	}, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool {
+		rs := r.Stats()
+		return rs.Errors >= 2 // the read and a reopen
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,309 @@
+package serial
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"sync"
+)
+
+// Telnet protocol bytes (RFC 854) and the options used by RFC 2217.
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetWONT = 252
+	telnetDO   = 253
+	telnetDONT = 254
+	telnetIAC  = 255
+
+	telnetBinary  = 0
+	telnetSGA     = 3
+	telnetComPort = 44
+)
+
+// RFC 2217 COM-PORT-OPTION commands sent by the client.
+const (
+	cpcSetBaudRate = 1
+	cpcSetDataSize = 2
+	cpcSetParity   = 3
+	cpcSetStopSize = 4
+	cpcSetControl  = 5
+)
+
+// SET-CONTROL values for flow control and the DTR and RTS lines.
+const (
+	cpcFlowXONXOFF  = 2
+	cpcFlowHardware = 3
+	cpcDTROn        = 8
+	cpcDTROff       = 9
+	cpcRTSOn        = 11
+	cpcRTSOff       = 12
+)
+
+// DialRFC2217 connects to a serial server speaking Telnet COM-Port-Control
+// (RFC 2217), such as ser2net with the telnet option, and returns a
+// SerialReader with the usual API. The server's port is configured from
+// cfg.BaudRate, DataBits, Parity, StopBits, RTSCTS and XONXOFF, and SetDTR and SetRTS are
+// forwarded to it; Telnet escaping is handled transparently.
+func DialRFC2217(addr string, cfg Config) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = addr
+	}
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		return dialRFC2217(addr, cfg)
+	})
+}
+
+// rfc2217 bridges the Telnet connection to a local socketpair whose other end
+// is the port's fd, so the poll engine sees a plain byte stream.
+type rfc2217 struct {
+	conn  net.Conn
+	local *os.File // bridge end of the socketpair
+	wmu   sync.Mutex
+}
+
+func dialRFC2217(addr string, cfg Config) (*port, error) {
+	conn, err := net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "dial", Err: err}
+	}
+	p, local, err := bridgePort(false, "rfc2217", cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	b := &rfc2217{conn: conn, local: local}
+	p.ctl = b
+	if err := b.negotiate(cfg); err != nil {
+		b.close()
+		p.close()
+		return nil, &SerialError{Device: cfg.Device, Op: "rfc2217 negotiate", Err: err}
+	}
+	go b.fromNet()
+	go b.toNet()
+	return p, nil
+}
+
+func (b *rfc2217) close() {
+	b.conn.Close()
+	b.local.Close()
+}
+
+// negotiate enables the COM-PORT option and binary transmission and sends
+// the line settings and initial modem line states from cfg.
+func (b *rfc2217) negotiate(cfg Config) error {
+	if err := b.write([]byte{
+		telnetIAC, telnetWILL, telnetComPort,
+		telnetIAC, telnetWILL, telnetBinary,
+		telnetIAC, telnetDO, telnetBinary,
+		telnetIAC, telnetWILL, telnetSGA,
+		telnetIAC, telnetDO, telnetSGA,
+	}); err != nil {
+		return err
+	}
+	if err := b.configure(cfg); err != nil {
+		return err
+	}
+	for _, l := range []struct {
+		line  modemLine
+		state LineState
+	}{{lineDTR, cfg.InitialDTR}, {lineRTS, cfg.InitialRTS}} {
+		if l.state == LineDefault {
+			continue
+		}
+		if err := b.setModemLine(l.line, l.state == LineOn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configure sends the line settings from cfg to the server.
+func (b *rfc2217) configure(cfg Config) error {
+	if cfg.BaudRate > 0 {
+		if err := b.command(cpcSetBaudRate, binary.BigEndian.AppendUint32(nil, uint32(cfg.BaudRate))...); err != nil {
+			return err
+		}
+	}
+	dataBits := cfg.DataBits
+	if dataBits == 0 {
+		dataBits = 8
+	}
+	stopBits := cfg.StopBits
+	if stopBits == 0 {
+		stopBits = 1
+	}
+	// RFC 2217 parity codes are NONE=1, ODD, EVEN, MARK, SPACE, in Parity's order.
+	for _, c := range [][2]byte{
+		{cpcSetDataSize, byte(dataBits)},
+		{cpcSetParity, byte(cfg.Parity) + 1},
+		{cpcSetStopSize, byte(stopBits)},
+	} {
+		if err := b.command(c[0], c[1]); err != nil {
+			return err
+		}
+	}
+	switch {
+	case cfg.RTSCTS:
+		if err := b.command(cpcSetControl, cpcFlowHardware); err != nil {
+			return err
+		}
+	case cfg.XONXOFF:
+		if err := b.command(cpcSetControl, cpcFlowXONXOFF); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// command sends a COM-PORT-OPTION subnegotiation.
+func (b *rfc2217) command(code byte, data ...byte) error {
+	msg := []byte{telnetIAC, telnetSB, telnetComPort, code}
+	msg = append(msg, escapeIAC(data)...)
+	return b.write(append(msg, telnetIAC, telnetSE))
+}
+
+func (b *rfc2217) write(p []byte) error {
+	b.wmu.Lock()
+	defer b.wmu.Unlock()
+	_, err := b.conn.Write(p)
+	return err
+}
+
+func (b *rfc2217) setModemLine(line modemLine, on bool) error {
+	v := map[modemLine][2]byte{lineDTR: {cpcDTROff, cpcDTROn}, lineRTS: {cpcRTSOff, cpcRTSOn}}[line]
+	if on {
+		return b.command(cpcSetControl, v[1])
+	}
+	return b.command(cpcSetControl, v[0])
+}
+
+// toNet forwards writes from the port to the server, doubling IAC bytes.
+func (b *rfc2217) toNet() {
+	defer b.close()
+	buf := make([]byte, 4096)
+	for {
+		n, err := b.local.Read(buf)
+		if err != nil {
+			return // the port was closed
+		}
+		if b.write(escapeIAC(buf[:n])) != nil {
+			return
+		}
+	}
+}
+
+// fromNet strips Telnet commands from the server's stream and forwards the
+// data to the port. Option requests other than those negotiated are refused;
+// server notifications (line and modem state) are not used.
+func (b *rfc2217) fromNet() {
+	defer b.close()
+	var dec telnetDecoder
+	buf := make([]byte, 4096)
+	for {
+		n, err := b.conn.Read(buf)
+		if err != nil {
+			return // the server hung up; closing the bridge reports it as removal
+		}
+		data := dec.decode(buf[:n], b.answer, nil)
+		if len(data) > 0 {
+			if _, err := b.local.Write(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// telnetDecoder separates data from Telnet commands in a byte stream. Its
+// state carries over between calls, so commands may be split across reads.
+type telnetDecoder struct {
+	state int
+	verb  byte
+	sb    []byte
+}
+
+const (
+	tdData = iota
+	tdIAC
+	tdOption // after WILL, WONT, DO or DONT
+	tdSB
+	tdSBIAC
+)
+
+// decode returns the data bytes of in, calling onOption for option
+// negotiations and onSB (if non-nil) with the payload of each subnegotiation.
+// The returned slice aliases in.
+func (d *telnetDecoder) decode(in []byte, onOption func(verb, opt byte), onSB func([]byte)) []byte {
+	data := in[:0]
+	for _, c := range in {
+		switch d.state {
+		case tdData:
+			if c == telnetIAC {
+				d.state = tdIAC
+			} else {
+				data = append(data, c)
+			}
+		case tdIAC:
+			d.state = tdData
+			switch c {
+			case telnetIAC:
+				data = append(data, c)
+			case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+				d.verb, d.state = c, tdOption
+			case telnetSB:
+				d.sb, d.state = d.sb[:0], tdSB
+			}
+		case tdOption:
+			d.state = tdData
+			onOption(d.verb, c)
+		case tdSB:
+			if c == telnetIAC {
+				d.state = tdSBIAC
+			} else {
+				d.sb = append(d.sb, c)
+			}
+		case tdSBIAC:
+			switch c {
+			case telnetSE:
+				d.state = tdData
+				if onSB != nil {
+					onSB(d.sb)
+				}
+			case telnetIAC:
+				d.state = tdSB
+				d.sb = append(d.sb, c)
+			default:
+				d.state = tdSB
+			}
+		}
+	}
+	return data
+}
+
+// answer refuses option requests we did not offer; requests for options we
+// already agreed to need no reply.
+func (b *rfc2217) answer(verb, opt byte) {
+	switch verb {
+	case telnetDO:
+		if opt != telnetBinary && opt != telnetSGA && opt != telnetComPort {
+			b.write([]byte{telnetIAC, telnetWONT, opt})
+		}
+	case telnetWILL:
+		if opt != telnetBinary && opt != telnetSGA {
+			b.write([]byte{telnetIAC, telnetDONT, opt})
+		}
+	}
+}
+
+// escapeIAC doubles every IAC byte in p.
+func escapeIAC(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for _, c := range p {
+		out = append(out, c)
+		if c == telnetIAC {
+			out = append(out, telnetIAC)
+		}
+	}
+	return out
+}
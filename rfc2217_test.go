@@ -0,0 +1,200 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// fakeComPortServer accepts one RFC 2217 client and reports the
+// subnegotiations and the unescaped data it receives.
+func fakeComPortServer(t *testing.T) (addr string, conns <-chan net.Conn, sbs <-chan []byte, data <-chan []byte) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	connCh := make(chan net.Conn, 1)
+	sbCh := make(chan []byte, 16)
+	dataCh := make(chan []byte, 16)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		connCh <- c
+		buf := make([]byte, 1024)
+		var sb []byte
+		inSB, iac := false, false
+		for {
+			n, err := c.Read(buf)
+			if err != nil {
+				return
+			}
+			var d []byte
+			for i := 0; i < n; i++ {
+				b := buf[i]
+				switch {
+				case iac:
+					iac = false
+					switch b {
+					case telnetIAC:
+						if inSB {
+							sb = append(sb, b)
+						} else {
+							d = append(d, b)
+						}
+					case telnetSB:
+						inSB, sb = true, nil
+					case telnetSE:
+						inSB = false
+						sbCh <- sb
+					case telnetWILL, telnetWONT, telnetDO, telnetDONT:
+						i++ // skip the option
+					}
+				case b == telnetIAC:
+					iac = true
+				case inSB:
+					sb = append(sb, b)
+				default:
+					d = append(d, b)
+				}
+			}
+			if len(d) > 0 {
+				dataCh <- d
+			}
+		}
+	}()
+	return l.Addr().String(), connCh, sbCh, dataCh
+}
+
+func TestDialRFC2217(t *testing.T) {
+	addr, conns, sbs, data := fakeComPortServer(t)
+	reader, err := DialRFC2217(addr, Config{BaudRate: 9600, Parity: ParityEven, DataBits: 7, Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+	server := <-conns
+	defer server.Close()
+
+	for _, want := range [][]byte{
+		{telnetComPort, cpcSetBaudRate, 0, 0, 0x25, 0x80},
+		{telnetComPort, cpcSetDataSize, 7},
+		{telnetComPort, cpcSetParity, 3},
+		{telnetComPort, cpcSetStopSize, 1},
+	} {
+		require.Equal(t, want, <-sbs)
+	}
+
+	require.NoError(t, reader.SetDTR(false))
+	require.Equal(t, []byte{telnetComPort, cpcSetControl, cpcDTROff}, <-sbs)
+	require.NoError(t, reader.SetRTS(true))
+	require.Equal(t, []byte{telnetComPort, cpcSetControl, cpcRTSOn}, <-sbs)
+
+	// 0xFF survives the trip in both directions.
+	require.NoError(t, reader.WriteLine("A\xffB", "\n"))
+	require.Equal(t, "A\xffB\n", string(<-data))
+
+	lines := make(chan string, 2)
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) { errs <- err })
+	_, err = server.Write([]byte{'x', telnetIAC, telnetIAC, telnetIAC, telnetWILL, 1, telnetIAC, telnetSB, telnetComPort, 107, 0x30, telnetIAC, telnetSE, 'y', '\n'})
+	require.NoError(t, err)
+	require.Equal(t, "x\xffy", <-lines)
+
+	server.Close()
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, ErrDeviceRemoved)
+	case <-time.After(time.Second):
+		t.Fatal("no error after server hung up")
+	}
+}
+
+func TestDialRFC2217_InitialLines(t *testing.T) {
+	addr, conns, sbs, _ := fakeComPortServer(t)
+	reader, err := DialRFC2217(addr, Config{XONXOFF: true, InitialDTR: LineOff, InitialRTS: LineOn, Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+	server := <-conns
+	defer server.Close()
+
+	for range 3 { // data size, parity, stop size
+		<-sbs
+	}
+	for _, want := range [][]byte{
+		{telnetComPort, cpcSetControl, cpcFlowXONXOFF},
+		{telnetComPort, cpcSetControl, cpcDTROff},
+		{telnetComPort, cpcSetControl, cpcRTSOn},
+	} {
+		require.Equal(t, want, <-sbs)
+	}
+}
+
+func TestInitialLines(t *testing.T) {
+	bits := unix.TIOCM_DTR | unix.TIOCM_RTS | unix.TIOCM_CTS
+	bits = applyLineState(bits, unix.TIOCM_DTR, LineOff)
+	bits = applyLineState(bits, unix.TIOCM_RTS, LineDefault)
+	require.Equal(t, unix.TIOCM_RTS|unix.TIOCM_CTS, bits)
+	require.Equal(t, unix.TIOCM_DTR, applyLineState(0, unix.TIOCM_DTR, LineOn))
+
+	// PTYs have no modem lines, so asking for a state fails the open
+	// instead of being silently ignored.
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	defer master.Close()
+	defer slave.Close()
+	_, err = Open(Config{Device: slave.Name(), InitialRTS: LineOff})
+	require.Error(t, err)
+}
+
+func TestLineSettings(t *testing.T) {
+	// PTYs force CS8 without parity, so check the termios bits directly.
+	var tio unix.Termios
+	require.NoError(t, setLineSettings(&tio, Config{DataBits: 7, Parity: ParityOdd, StopBits: 2}))
+	require.EqualValues(t, unix.CS7, tio.Cflag&unix.CSIZE)
+	require.NotZero(t, tio.Cflag&unix.PARENB)
+	require.NotZero(t, tio.Cflag&unix.PARODD)
+	require.NotZero(t, tio.Cflag&unix.CSTOPB)
+	require.NotZero(t, tio.Iflag&unix.INPCK)
+
+	require.NoError(t, setLineSettings(&tio, Config{}))
+	require.EqualValues(t, unix.CS8, tio.Cflag&unix.CSIZE)
+	require.Zero(t, tio.Cflag&(unix.PARENB|unix.CSTOPB))
+
+	require.Error(t, setLineSettings(&tio, Config{DataBits: 9}))
+	require.Error(t, setLineSettings(&tio, Config{StopBits: 3}))
+}
+
+func TestSetLineSettings(t *testing.T) {
+	reader, _ := newTestReader(t, Config{BaudRate: 9600})
+	require.NoError(t, reader.SetLineSettings(57600, 0, ParityNone, 0))
+	tio, err := unix.IoctlGetTermios(reader.port().fd, ioctlGetTermios)
+	require.NoError(t, err)
+	want := *tio
+	setSpeed(&want, 57600)
+	require.Equal(t, want, *tio)
+	require.Equal(t, 57600, reader.config.BaudRate)
+
+	require.Error(t, reader.SetLineSettings(0, 9, ParityNone, 0))
+	require.Equal(t, 57600, reader.config.BaudRate)
+}
+
+func TestSendBreak(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+	start := time.Now()
+	require.NoError(t, reader.SendBreak(20*time.Millisecond))
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	addr, conns, _, _ := fakeComPortServer(t)
+	remote, err := DialRFC2217(addr, Config{})
+	require.NoError(t, err)
+	defer remote.Close()
+	(<-conns).Close()
+	require.ErrorIs(t, remote.SendBreak(time.Millisecond), errors.ErrUnsupported)
+}
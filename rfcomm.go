@@ -0,0 +1,129 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RFCOMM describes the Bluetooth serial link behind a /dev/rfcomm device,
+// for OpenRFCOMM. Portable sensors with Bluetooth serial adapters connect
+// when the device is opened, which fails while the sensor is switched off,
+// out of range or pairing, so OpenRFCOMM keeps trying.
+type RFCOMM struct {
+	// Address, if set, is the Bluetooth address of the remote adapter,
+	// such as "00:11:22:33:44:55". The device is then bound to it first,
+	// like `rfcomm bind`, unless it already is. Empty uses the device as
+	// bound by the system.
+	Address string
+	// Channel is the RFCOMM channel of the serial port service; zero means
+	// 1, the channel of most adapters.
+	Channel int
+	// Device is the number N of /dev/rfcommN.
+	Device int
+	// Retry is how long OpenRFCOMM keeps trying to connect; zero means 30
+	// seconds.
+	Retry time.Duration
+	// RetryInterval is the wait between attempts; zero means two seconds.
+	RetryInterval time.Duration
+}
+
+// OpenRFCOMM opens a Bluetooth serial link described by rc with cfg,
+// retrying while the connection fails in the ways a sensor that is off or
+// out of range makes it fail. An empty cfg.Device means /dev/rfcommN of
+// rc.Device. A link that drops later ends the read loops with
+// ErrDeviceRemoved, like an unplugged USB adapter, so Reopen or a
+// Supervisor can reconnect it.
+func OpenRFCOMM(cfg Config, rc RFCOMM) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = "/dev/rfcomm" + strconv.Itoa(rc.Device)
+	}
+	if rc.Address != "" {
+		channel := rc.Channel
+		if channel == 0 {
+			channel = 1
+		}
+		if err := BindRFCOMM(rc.Device, rc.Address, channel); err != nil && !errors.Is(err, syscall.EADDRINUSE) {
+			return nil, err
+		}
+	}
+	retry, interval := rc.Retry, rc.RetryInterval
+	if retry <= 0 {
+		retry = 30 * time.Second
+	}
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	deadline := time.Now().Add(retry)
+	for {
+		s, err := Open(cfg)
+		if err == nil || !rfcommTransient(err) || time.Now().Add(interval).After(deadline) {
+			return s, err
+		}
+		time.Sleep(interval)
+	}
+}
+
+// rfcommTransient reports whether an open of an RFCOMM device may succeed
+// if tried again: the remote adapter is off, out of range or busy, or udev
+// has yet to create the device node.
+func rfcommTransient(err error) bool {
+	for _, e := range []error{
+		syscall.EHOSTDOWN, syscall.EHOSTUNREACH, syscall.ECONNREFUSED,
+		syscall.ETIMEDOUT, syscall.ECONNRESET, syscall.EBUSY, os.ErrNotExist,
+	} {
+		if errors.Is(err, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// BindRFCOMM binds /dev/rfcommN, N being dev, to channel of the Bluetooth
+// device at addr, like `rfcomm bind`, so that opening it connects. It
+// needs CAP_NET_ADMIN, fails with EADDRINUSE if dev is already bound, and
+// with errors.ErrUnsupported outside Linux.
+func BindRFCOMM(dev int, addr string, channel int) error {
+	a, err := parseBDAddr(addr)
+	if err != nil {
+		return fmt.Errorf("serial: bind rfcomm%d: %w", dev, err)
+	}
+	if channel < 1 || channel > 30 {
+		return fmt.Errorf("serial: bind rfcomm%d: channel %d not in 1-30", dev, channel)
+	}
+	if err := bindRFCOMM(dev, a, channel); err != nil {
+		return fmt.Errorf("serial: bind rfcomm%d: %w", dev, err)
+	}
+	return nil
+}
+
+// ReleaseRFCOMM undoes BindRFCOMM, hanging up the link if it is open, like
+// `rfcomm release`.
+func ReleaseRFCOMM(dev int) error {
+	if err := releaseRFCOMM(dev); err != nil {
+		return fmt.Errorf("serial: release rfcomm%d: %w", dev, err)
+	}
+	return nil
+}
+
+// parseBDAddr parses a Bluetooth address into the kernel's bdaddr_t, which
+// stores the bytes in reverse.
+func parseBDAddr(s string) ([6]byte, error) {
+	var a [6]byte
+	parts := strings.Split(s, ":")
+	if len(parts) != 6 {
+		return a, fmt.Errorf("invalid Bluetooth address %q", s)
+	}
+	for i, p := range parts {
+		b, err := strconv.ParseUint(p, 16, 8)
+		if err != nil || len(p) != 2 {
+			return a, fmt.Errorf("invalid Bluetooth address %q", s)
+		}
+		a[5-i] = byte(b)
+	}
+	return a, nil
+}
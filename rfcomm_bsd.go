@@ -0,0 +1,15 @@
+//go:build darwin || freebsd
+
+package serial
+
+import "errors"
+
+// RFCOMM TTYs are a Linux interface.
+
+func bindRFCOMM(dev int, addr [6]byte, channel int) error {
+	return errors.ErrUnsupported
+}
+
+func releaseRFCOMM(dev int) error {
+	return errors.ErrUnsupported
+}
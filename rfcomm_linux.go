@@ -0,0 +1,47 @@
+package serial
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// RFCOMM TTY ioctls, _IOW('R', 200/201, int).
+const (
+	rfcommCreateDev  = 0x400452c8
+	rfcommReleaseDev = 0x400452c9
+	rfcommHangupNow  = 1 << 2 // RFCOMM_HANGUP_NOW
+)
+
+// rfcommDevReq is struct rfcomm_dev_req.
+type rfcommDevReq struct {
+	devID   int16
+	_       [2]byte
+	flags   uint32
+	src     [6]byte // BDADDR_ANY: any local adapter
+	dst     [6]byte
+	channel uint8
+	_       [3]byte
+}
+
+func bindRFCOMM(dev int, addr [6]byte, channel int) error {
+	return rfcommIoctl(rfcommCreateDev, &rfcommDevReq{devID: int16(dev), dst: addr, channel: uint8(channel)})
+}
+
+func releaseRFCOMM(dev int) error {
+	return rfcommIoctl(rfcommReleaseDev, &rfcommDevReq{devID: int16(dev), flags: rfcommHangupNow})
+}
+
+// rfcommIoctl issues req on an RFCOMM control socket.
+func rfcommIoctl(req uint, r *rfcommDevReq) error {
+	fd, err := unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.BTPROTO_RFCOMM)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(unsafe.Pointer(r)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package serial
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBDAddr(t *testing.T) {
+	a, err := parseBDAddr("00:11:22:33:44:5F")
+	require.NoError(t, err)
+	require.Equal(t, [6]byte{0x5F, 0x44, 0x33, 0x22, 0x11, 0x00}, a)
+
+	for _, bad := range []string{"", "00:11:22:33:44", "00:11:22:33:44:GG", "0:11:22:33:44:55"} {
+		_, err := parseBDAddr(bad)
+		require.Error(t, err, bad)
+	}
+	require.Equal(t, uintptr(24), unsafe.Sizeof(rfcommDevReq{}))
+}
+
+func TestBindRFCOMM(t *testing.T) {
+	require.Error(t, BindRFCOMM(0, "bogus", 1))
+	require.Error(t, BindRFCOMM(0, "00:11:22:33:44:55", 31))
+}
+
+func TestOpenRFCOMM_Retries(t *testing.T) {
+	start := time.Now()
+	_, err := OpenRFCOMM(Config{Device: "/dev/rfcomm-missing"}, RFCOMM{
+		Retry:         60 * time.Millisecond,
+		RetryInterval: 20 * time.Millisecond,
+	})
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	require.True(t, rfcommTransient(syscall.EHOSTDOWN))
+	require.False(t, rfcommTransient(syscall.EACCES))
+	require.True(t, isDisconnect(syscall.ECONNRESET))
+}
@@ -0,0 +1,117 @@
+package serial
+
+import "sync/atomic"
+
+// RingBuffer is a fixed-size, lock-free byte ring for a single producer and a
+// single consumer. When the producer outruns the consumer the oldest bytes are
+// overwritten and counted, so the writer never blocks.
+//
+// When Config.RingSize is set, the read loop copies every raw byte it reads
+// into the reader's RingBuffer (see SerialReader.Ring), letting DSP-style
+// consumers drain contiguous bytes at their own pace alongside line callbacks.
+type RingBuffer struct {
+	// The bytes are packed eight to a word and accessed atomically, so a
+	// Read copying bytes the producer is overwriting is a detected retry
+	// rather than a data race.
+	words       []atomic.Uint64
+	size        uint64
+	head        atomic.Uint64 // total bytes written
+	tail        atomic.Uint64 // total bytes consumed or overwritten
+	overwritten atomic.Uint64
+}
+
+// NewRingBuffer returns a RingBuffer holding up to size bytes.
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		size = 1
+	}
+	return &RingBuffer{words: make([]atomic.Uint64, (size+7)/8), size: uint64(size)}
+}
+
+// Write appends p, overwriting the oldest unread bytes if the ring is full.
+// It always returns len(p), nil.
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	size := r.size
+	if uint64(len(p)) > size {
+		r.overwritten.Add(uint64(len(p)) - size)
+		p = p[uint64(len(p))-size:]
+	}
+	h := r.head.Load()
+	newHead := h + uint64(len(p))
+	// Reserve space first so a concurrent Read notices the overwrite.
+	for {
+		t := r.tail.Load()
+		if newHead-t <= size {
+			break
+		}
+		if r.tail.CompareAndSwap(t, newHead-size) {
+			r.overwritten.Add(newHead - size - t)
+			break
+		}
+	}
+	r.put(h, p)
+	r.head.Store(newHead) // publish the bytes
+	return n, nil
+}
+
+// Read copies up to len(p) unread bytes into p and returns the count.
+// It never blocks; 0 means the ring is empty.
+func (r *RingBuffer) Read(p []byte) int {
+	for {
+		t := r.tail.Load()
+		h := r.head.Load() // the bytes before h are written
+		n := min(h-t, uint64(len(p)))
+		if n == 0 {
+			return 0
+		}
+		r.get(t, p[:n])
+		// Only now release the bytes to the producer; if it has already
+		// moved tail, it overwrote some of them while we copied.
+		if r.tail.CompareAndSwap(t, t+n) {
+			return int(n)
+		}
+	}
+}
+
+// put stores p at stream position pos. Only the producer calls it, so each
+// word can be updated with a plain load and store.
+func (r *RingBuffer) put(pos uint64, p []byte) {
+	for _, b := range p {
+		i := pos % r.size
+		w := &r.words[i/8]
+		shift := i % 8 * 8
+		w.Store(w.Load()&^(0xff<<shift) | uint64(b)<<shift)
+		pos++
+	}
+}
+
+// get loads len(p) bytes from stream position pos into p.
+func (r *RingBuffer) get(pos uint64, p []byte) {
+	for j := range p {
+		i := pos % r.size
+		p[j] = byte(r.words[i/8].Load() >> (i % 8 * 8))
+		pos++
+	}
+}
+
+// Len returns the number of unread bytes.
+func (r *RingBuffer) Len() int {
+	return int(r.head.Load() - r.tail.Load())
+}
+
+// Cap returns the ring's capacity in bytes.
+func (r *RingBuffer) Cap() int {
+	return int(r.size)
+}
+
+// Overwritten returns the total number of bytes lost to overwrites.
+func (r *RingBuffer) Overwritten() uint64 {
+	return r.overwritten.Load()
+}
+
+// Ring returns the raw-byte ring filled by the read loop, or nil if
+// Config.RingSize is zero.
+func (s *SerialReader) Ring() *RingBuffer {
+	return s.ring
+}
@@ -0,0 +1,94 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRingBuffer_WrapAndOverwrite(t *testing.T) {
+	r := NewRingBuffer(8)
+	r.Write([]byte("abcdef"))
+	buf := make([]byte, 4)
+	require.Equal(t, 4, r.Read(buf))
+	require.Equal(t, "abcd", string(buf))
+
+	// Wraps around the end without loss.
+	r.Write([]byte("ghijkl"))
+	require.Equal(t, 8, r.Len())
+	require.Zero(t, r.Overwritten())
+
+	// Two more bytes overwrite the two oldest.
+	r.Write([]byte("mn"))
+	require.EqualValues(t, 2, r.Overwritten())
+	out := make([]byte, 16)
+	n := r.Read(out)
+	require.Equal(t, "ghijklmn", string(out[:n]))
+	require.Zero(t, r.Read(out))
+
+	// A write larger than the ring keeps only the newest bytes.
+	r.Write([]byte("0123456789"))
+	n = r.Read(out)
+	require.Equal(t, "23456789", string(out[:n]))
+	require.EqualValues(t, 4, r.Overwritten())
+}
+
+func TestRingBuffer_Concurrent(t *testing.T) {
+	// Byte k of the stream is k%251, so every Read must return a run of
+	// consecutive values, however often the producer wraps and overwrites.
+	const total = 1 << 20
+	r := NewRingBuffer(64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		chunk := make([]byte, 0, 100)
+		for k := 0; k < total; {
+			chunk = chunk[:0]
+			for range 1 + k%97 {
+				chunk = append(chunk, byte(k%251))
+				k++
+			}
+			r.Write(chunk)
+		}
+	}()
+
+	buf := make([]byte, 48)
+	read := 0
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		n := r.Read(buf)
+		for i := 1; i < n; i++ {
+			if buf[i] != byte((int(buf[i-1])+1)%251) {
+				t.Fatalf("read %v: byte %d breaks the run", buf[:n], i)
+			}
+		}
+		read += n
+	}
+	require.Positive(t, read)
+	require.Positive(t, r.Overwritten())
+}
+
+func TestSerialReader_Ring(t *testing.T) {
+	reader, master := newTestReader(t, Config{RingSize: 64})
+	require.Nil(t, (&SerialReader{}).Ring())
+
+	lines := make(chan string, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) {})
+
+	_, err := master.Write([]byte("\x01\x02raw\n"))
+	require.NoError(t, err)
+	select {
+	case <-lines:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for line")
+	}
+
+	buf := make([]byte, 64)
+	n := reader.Ring().Read(buf)
+	require.Equal(t, "\x01\x02raw\n", string(buf[:n]))
+}
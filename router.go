@@ -0,0 +1,77 @@
+package serial
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Router dispatches lines to handlers registered by prefix or regular expression.
+// Routes are evaluated in registration order and the first match wins; lines that
+// match no route go to the default handler, if one is set.
+// Dispatch has the onLine signature, so a Router plugs directly into ReadLinesLoop.
+// The zero value is ready to use and a Router is safe for concurrent use.
+type Router struct {
+	mu       sync.RWMutex
+	routes   []route
+	fallback func(string)
+}
+
+type route struct {
+	match   func(string) bool
+	handler func(string)
+}
+
+// NewRouter returns an empty Router.
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// HandlePrefix routes lines starting with prefix (e.g. "$GPGGA") to h.
+func (r *Router) HandlePrefix(prefix string, h func(string)) {
+	r.add(func(line string) bool { return strings.HasPrefix(line, prefix) }, h)
+}
+
+// HandleRegexp routes lines matching re to h.
+func (r *Router) HandleRegexp(re *regexp.Regexp, h func(string)) {
+	r.add(re.MatchString, h)
+}
+
+// Handle compiles pattern as a regular expression and routes matching lines to h.
+func (r *Router) Handle(pattern string, h func(string)) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+	r.HandleRegexp(re, h)
+	return nil
+}
+
+// Default sets the handler for lines that match no route. A nil h discards them.
+func (r *Router) Default(h func(string)) {
+	r.mu.Lock()
+	r.fallback = h
+	r.mu.Unlock()
+}
+
+// Dispatch delivers line to the first matching handler, or to the default handler.
+func (r *Router) Dispatch(line string) {
+	r.mu.RLock()
+	h := r.fallback
+	for _, rt := range r.routes {
+		if rt.match(line) {
+			h = rt.handler
+			break
+		}
+	}
+	r.mu.RUnlock()
+	if h != nil {
+		h(line)
+	}
+}
+
+func (r *Router) add(match func(string) bool, h func(string)) {
+	r.mu.Lock()
+	r.routes = append(r.routes, route{match: match, handler: h})
+	r.mu.Unlock()
+}
@@ -0,0 +1,51 @@
+package serial
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Dispatch(t *testing.T) {
+	var got []string
+	r := NewRouter()
+	r.HandlePrefix("$GPGGA", func(l string) { got = append(got, "gga:"+l) })
+	r.HandleRegexp(regexp.MustCompile(`^ERR\b`), func(l string) { got = append(got, "err:"+l) })
+	require.NoError(t, r.Handle(`^\$GP`, func(l string) { got = append(got, "gp:"+l) }))
+	require.Error(t, r.Handle(`(`, func(string) {}))
+
+	r.Dispatch("$GPGGA,1")
+	r.Dispatch("$GPRMC,2")
+	r.Dispatch("ERR 5")
+	r.Dispatch("data") // no default yet: dropped
+
+	r.Default(func(l string) { got = append(got, "default:"+l) })
+	r.Dispatch("data")
+
+	require.Equal(t, []string{"gga:$GPGGA,1", "gp:$GPRMC,2", "err:ERR 5", "default:data"}, got)
+}
+
+func TestRouter_ReadLinesLoop(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	errs := make(chan string, 1)
+	other := make(chan string, 1)
+	r := NewRouter()
+	r.HandlePrefix("ERR", func(l string) { errs <- l })
+	r.Default(func(l string) { other <- l })
+
+	go reader.ReadLinesLoop(r.Dispatch, func(err error) {})
+
+	_, err := master.Write([]byte("1,2,3\nERR overload\n"))
+	require.NoError(t, err)
+
+	for _, ch := range []chan string{other, errs} {
+		select {
+		case <-ch:
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("timeout waiting for routed line")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package serial
+
+import (
+	"syscall"
+	"time"
+)
+
+// RS485 keys an RS-485 transceiver's driver enable from RTS in software, for
+// UARTs and USB adapters without kernel RS-485 support. Every write raises
+// RTS, waits DelayBeforeSend, writes, waits until the last stop bit has left
+// the UART (tcdrain), waits DelayAfterSend and lowers RTS again, so the bus
+// is released for the reply. Between writes RTS is low (receive).
+//
+// Timing is only as good as the scheduler allows: expect tens of
+// microseconds of jitter around the drain, more under load. Use it with
+// Config.RealtimePriority for tighter turnarounds, or with kernel RS-485 mode
+// where the hardware supports it.
+type RS485 struct {
+	RTSActiveLow    bool          // lower RTS to transmit, for inverted driver enables
+	DelayBeforeSend time.Duration // after enabling the driver, before the first bit
+	DelayAfterSend  time.Duration // after the last bit, before releasing the bus
+}
+
+// rtsState returns the RTS state that enables the driver (tx) or the receiver.
+func (r *RS485) rtsState(tx bool) LineState {
+	if tx != r.RTSActiveLow {
+		return LineOn
+	}
+	return LineOff
+}
+
+// keyedWrite writes b framed by the RS-485 direction changes and the
+// BeforeWrite/AfterWrite hooks, draining the output before the bus is
+// released. The caller holds the write lock, so two callers cannot
+// interleave inside one transmit window.
+func (s *SerialReader) keyedWrite(p *port, b []byte, stop *waker) (int, error) {
+	r := s.config.RS485
+	if p.ctl != nil {
+		r = nil // RTS belongs to the remote server
+	}
+	if r != nil {
+		if err := setRTS(p, r.rtsState(true)); err != nil {
+			return 0, err
+		}
+		// Release the bus whatever happens below.
+		defer setRTS(p, r.rtsState(false))
+		preciseSleep(r.DelayBeforeSend)
+	}
+	if s.config.BeforeWrite != nil {
+		if err := s.config.BeforeWrite(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := writeTo(p, b, stop)
+	if err == nil {
+		// Sockets have nothing to drain: the data has left once written.
+		if err = drainOutput(p.fd); err == syscall.ENOTTY {
+			err = nil
+		}
+	}
+	if s.config.AfterWrite != nil {
+		if herr := s.config.AfterWrite(); err == nil {
+			err = herr
+		}
+	}
+	if r != nil && err == nil {
+		preciseSleep(r.DelayAfterSend)
+	}
+	return n, err
+}
+
+func setRTS(p *port, state LineState) error {
+	return setTTYModemLine(p.fd, lineRTS, state == LineOn)
+}
+
+// preciseSleep waits for d. Timer sleeps can overshoot by a millisecond, so
+// the final stretch of short waits is spun out instead.
+func preciseSleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	deadline := time.Now().Add(d)
+	if d > 2*time.Millisecond {
+		time.Sleep(d - time.Millisecond)
+	}
+	for time.Now().Before(deadline) {
+	}
+}
@@ -0,0 +1,59 @@
+package serial
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRS485_RTSState(t *testing.T) {
+	r := &RS485{}
+	require.Equal(t, LineOn, r.rtsState(true))
+	require.Equal(t, LineOff, r.rtsState(false))
+	r.RTSActiveLow = true
+	require.Equal(t, LineOff, r.rtsState(true))
+	require.Equal(t, LineOn, r.rtsState(false))
+}
+
+func TestPreciseSleep(t *testing.T) {
+	for _, d := range []time.Duration{200 * time.Microsecond, 5 * time.Millisecond} {
+		start := time.Now()
+		preciseSleep(d)
+		require.GreaterOrEqual(t, time.Since(start), d)
+	}
+}
+
+func TestOpen_RS485NeedsModemLines(t *testing.T) {
+	// RS485 parks RTS in the receive state at open; a PTY has no RTS, so the
+	// open fails rather than leaving the transceiver driving the bus.
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	defer master.Close()
+	defer slave.Close()
+	_, err = Open(Config{Device: slave.Name(), RS485: &RS485{}})
+	require.Error(t, err)
+}
+
+func TestSerialReader_WriteHooks(t *testing.T) {
+	var events []string
+	reader, master := newTestReader(t, Config{
+		BeforeWrite: func() error { events = append(events, "before"); return nil },
+		AfterWrite:  func() error { events = append(events, "after"); return nil },
+	})
+	require.NoError(t, reader.WriteLine("ping", "\n"))
+	require.Equal(t, []string{"before", "after"}, events)
+	buf := make([]byte, 16)
+	n, err := master.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping\n", string(buf[:n]))
+
+	// A failing BeforeWrite keeps the data off the line.
+	keyErr := errors.New("gpio busy")
+	reader.config.BeforeWrite = func() error { return keyErr }
+	_, err = reader.Write([]byte("x"))
+	require.ErrorIs(t, err, keyErr)
+	require.Equal(t, []string{"before", "after"}, events)
+}
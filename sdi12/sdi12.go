@@ -0,0 +1,283 @@
+// Package sdi12 talks to SDI-12 environmental sensors (soil moisture, water
+// level, weather stations) through a serial adapter that puts the port's
+// transmit and receive lines on the one-wire SDI-12 bus.
+//
+// SDI-12 runs at 1200 baud, 7 data bits, even parity and one stop bit; see
+// Config. The recorder wakes the sensors with a break, sends a command such
+// as "0M!" (address 0, start a measurement) and reads the response, which
+// starts with the address and ends with CR LF. Commands that go unanswered
+// are retried, with a fresh break before each retry. Adapters that hear
+// their own transmission echo the command; the echo is removed.
+package sdi12
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+var (
+	// ErrNoResponse is returned when a sensor does not answer a command,
+	// retries included.
+	ErrNoResponse = errors.New("sdi12: no response")
+	// ErrBadResponse reports a response that does not fit the command.
+	ErrBadResponse = errors.New("sdi12: malformed response")
+)
+
+// Port is the connection to the bus. *serial.SerialReader implements it.
+type Port interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+	SendBreak(d time.Duration) error
+}
+
+// Config returns the serial settings of an SDI-12 bus on device.
+func Config(device string) serial.Config {
+	return serial.Config{
+		Device:    device,
+		BaudRate:  1200,
+		DataBits:  7,
+		Parity:    serial.ParityEven,
+		StopBits:  1,
+		Delimiter: "\r\n",
+	}
+}
+
+const (
+	breakLen = 13 * time.Millisecond // at least 12ms of spacing
+	markLen  = 9 * time.Millisecond  // at least 8.33ms of marking after the break
+)
+
+// Recorder issues commands on an SDI-12 bus. Commands are serialised; a
+// Recorder is safe for concurrent use.
+type Recorder struct {
+	// Timeout is how long to wait for a whole response line. Zero means
+	// 500ms, enough for a 75-character response at 1200 baud after the
+	// 15ms the sensor may take to start answering, and for USB adapter
+	// latency.
+	Timeout time.Duration
+	// Retries is the number of times an unanswered command is sent again.
+	// Zero means 3, the minimum the standard asks of recorders.
+	Retries int
+
+	p   Port
+	mu  sync.Mutex
+	buf []byte
+}
+
+// New returns a Recorder on p, which must be configured as Config
+// describes.
+func New(p Port) *Recorder {
+	return &Recorder{p: p}
+}
+
+// Command sends cmd, such as "0I!", and returns the response line without
+// its CR LF. The response must start with the address of cmd, except for
+// the address query "?!" and the address change "aAb!", answered from b.
+func (r *Recorder) Command(cmd string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.command(cmd)
+}
+
+func (r *Recorder) command(cmd string) (string, error) {
+	if len(cmd) < 2 || !strings.HasSuffix(cmd, "!") {
+		return "", fmt.Errorf("sdi12: invalid command %q", cmd)
+	}
+	retries := r.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	for try := 0; ; try++ {
+		resp, err := r.try(cmd)
+		if err == nil {
+			if addr := responder(cmd); addr != '?' && resp[0] != addr {
+				return resp, fmt.Errorf("%w: %q answering %q", ErrBadResponse, resp, cmd)
+			}
+			return resp, nil
+		}
+		if !errors.Is(err, ErrNoResponse) || try == retries {
+			return "", err
+		}
+	}
+}
+
+// responder returns the address that answers cmd, or '?' for any.
+func responder(cmd string) byte {
+	if len(cmd) == 4 && cmd[1] == 'A' {
+		return cmd[2]
+	}
+	return cmd[0]
+}
+
+// try wakes the bus, sends cmd once and reads the response.
+func (r *Recorder) try(cmd string) (string, error) {
+	r.buf = r.buf[:0] // stale bytes from an earlier, abandoned response
+	if err := r.p.SendBreak(breakLen); err != nil {
+		return "", err
+	}
+	time.Sleep(markLen)
+	if _, err := io.WriteString(r.p, cmd); err != nil {
+		return "", err
+	}
+	line, err := r.readLine(r.timeout())
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimPrefix(line, cmd) // adapter echo
+	if line == "" {
+		return "", ErrNoResponse
+	}
+	return line, nil
+}
+
+// readLine reads one CR LF terminated line within timeout.
+func (r *Recorder) readLine(timeout time.Duration) (string, error) {
+	if err := r.p.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return "", err
+	}
+	defer r.p.SetReadDeadline(time.Time{})
+	chunk := make([]byte, 128)
+	for {
+		if i := strings.Index(string(r.buf), "\r\n"); i >= 0 {
+			line := string(r.buf[:i])
+			r.buf = r.buf[:copy(r.buf, r.buf[i+2:])]
+			return line, nil
+		}
+		n, err := r.p.Read(chunk)
+		r.buf = append(r.buf, chunk[:n]...)
+		if err != nil && n == 0 {
+			if errors.Is(err, serial.ErrTimeout) || errors.Is(err, os.ErrDeadlineExceeded) {
+				return "", ErrNoResponse
+			}
+			return "", err
+		}
+	}
+}
+
+func (r *Recorder) timeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return 500 * time.Millisecond
+}
+
+// Address returns the address of the only sensor on the bus, with the
+// query "?!". With several sensors the answers collide.
+func (r *Recorder) Address() (byte, error) {
+	resp, err := r.Command("?!")
+	if err != nil {
+		return 0, err
+	}
+	if len(resp) != 1 {
+		return 0, fmt.Errorf("%w: %q answering %q", ErrBadResponse, resp, "?!")
+	}
+	return resp[0], nil
+}
+
+// Identify returns the identification of the sensor at addr: SDI-12
+// version, vendor, model, version and optional serial number, after the
+// address.
+func (r *Recorder) Identify(addr byte) (string, error) {
+	resp, err := r.Command(string(addr) + "I!")
+	if err != nil {
+		return "", err
+	}
+	return resp[1:], nil
+}
+
+// ChangeAddress moves the sensor at from to address to.
+func (r *Recorder) ChangeAddress(from, to byte) error {
+	cmd := string(from) + "A" + string(to) + "!"
+	resp, err := r.Command(cmd)
+	if err != nil {
+		return err
+	}
+	if resp != string(to) {
+		return fmt.Errorf("%w: %q answering %q", ErrBadResponse, resp, cmd)
+	}
+	return nil
+}
+
+// Measure starts a measurement on the sensor at addr with "aM!", waits until
+// the sensor reports it ready (a service request) or the time it announced
+// has passed, and collects the values with "aD0!", "aD1!" and so on.
+func (r *Recorder) Measure(addr byte) ([]float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cmd := string(addr) + "M!"
+	resp, err := r.command(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// atttn: ready in ttt seconds with n values.
+	if len(resp) != 5 {
+		return nil, fmt.Errorf("%w: %q answering %q", ErrBadResponse, resp, cmd)
+	}
+	wait, err1 := strconv.Atoi(resp[1:4])
+	count, err2 := strconv.Atoi(resp[4:])
+	if err1 != nil || err2 != nil {
+		return nil, fmt.Errorf("%w: %q answering %q", ErrBadResponse, resp, cmd)
+	}
+	// The sensor may announce that it is ready early with a service
+	// request, the address alone; otherwise wait the time it asked for.
+	deadline := time.Now().Add(time.Duration(wait) * time.Second)
+	for wait > 0 {
+		line, err := r.readLine(time.Until(deadline))
+		if errors.Is(err, ErrNoResponse) || err == nil && line == string(addr) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	values := make([]float64, 0, count)
+	for i := 0; len(values) < count && i <= 9; i++ {
+		cmd := string(addr) + "D" + strconv.Itoa(i) + "!"
+		resp, err := r.command(cmd)
+		if err != nil {
+			return values, err
+		}
+		vs, err := ParseValues(resp[1:])
+		if err != nil {
+			return values, err
+		}
+		if len(vs) == 0 {
+			break
+		}
+		values = append(values, vs...)
+	}
+	if len(values) != count {
+		return values, fmt.Errorf("%w: %d of %d values", ErrBadResponse, len(values), count)
+	}
+	return values, nil
+}
+
+// ParseValues parses the values of a data response, such as "+3.14-0.5+12",
+// where every value starts with its sign.
+func ParseValues(s string) ([]float64, error) {
+	var values []float64
+	for s != "" {
+		if s[0] != '+' && s[0] != '-' {
+			return values, fmt.Errorf("%w: value without a sign in %q", ErrBadResponse, s)
+		}
+		end := strings.IndexAny(s[1:], "+-") + 1
+		if end == 0 {
+			end = len(s)
+		}
+		v, err := strconv.ParseFloat(s[:end], 64)
+		if err != nil {
+			return values, fmt.Errorf("%w: %w", ErrBadResponse, err)
+		}
+		values = append(values, v)
+		s = s[end:]
+	}
+	return values, nil
+}
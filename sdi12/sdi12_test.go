@@ -0,0 +1,135 @@
+package sdi12
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pipePort is one end of a net.Pipe standing in for the serial port.
+type pipePort struct {
+	net.Conn
+	breaks atomic.Int32
+}
+
+func (p *pipePort) SendBreak(time.Duration) error {
+	p.breaks.Add(1)
+	return nil
+}
+
+// fakeSensor answers commands read from conn with answer(cmd), sending
+// nothing for an empty answer. With echo set, it first repeats the
+// command, like an adapter hearing its own transmission.
+func fakeSensor(t *testing.T, echo bool, answer func(cmd string) string) (*Recorder, *pipePort) {
+	t.Helper()
+	a, b := net.Pipe()
+	t.Cleanup(func() { a.Close(); b.Close() })
+	go func() {
+		buf := make([]byte, 1)
+		var cmd []byte
+		for {
+			if _, err := b.Read(buf); err != nil {
+				return
+			}
+			cmd = append(cmd, buf[0])
+			if buf[0] != '!' {
+				continue
+			}
+			resp := answer(string(cmd))
+			if echo {
+				resp = string(cmd) + resp
+			}
+			if resp != "" {
+				b.Write([]byte(resp))
+			}
+			cmd = cmd[:0]
+		}
+	}()
+	p := &pipePort{Conn: a}
+	r := New(p)
+	r.Timeout = 50 * time.Millisecond
+	return r, p
+}
+
+func TestRecorder(t *testing.T) {
+	r, p := fakeSensor(t, true, func(cmd string) string {
+		switch cmd {
+		case "?!":
+			return "3\r\n"
+		case "3I!":
+			return "313ACME    SOIL01001SN42\r\n"
+		case "3A5!":
+			return "5\r\n"
+		}
+		return ""
+	})
+	addr, err := r.Address()
+	require.NoError(t, err)
+	require.Equal(t, byte('3'), addr)
+	id, err := r.Identify('3')
+	require.NoError(t, err)
+	require.Equal(t, "13ACME    SOIL01001SN42", id)
+	require.NoError(t, r.ChangeAddress('3', '5'))
+	require.EqualValues(t, 3, p.breaks.Load())
+
+	_, err = r.Command("0I")
+	require.Error(t, err)
+}
+
+func TestRecorder_Retry(t *testing.T) {
+	var tries atomic.Int32
+	r, p := fakeSensor(t, false, func(cmd string) string {
+		if tries.Add(1) < 3 {
+			return "" // still waking up
+		}
+		return "0\r\n"
+	})
+	addr, err := r.Address()
+	require.NoError(t, err)
+	require.Equal(t, byte('0'), addr)
+	require.EqualValues(t, 3, p.breaks.Load())
+
+	tries.Store(-10)
+	_, err = r.Address()
+	require.ErrorIs(t, err, ErrNoResponse)
+}
+
+func TestRecorder_Measure(t *testing.T) {
+	r, _ := fakeSensor(t, false, func(cmd string) string {
+		switch cmd {
+		case "1M!":
+			return "10013\r\n1\r\n" // ready in 1s, but done at once
+		case "1D0!":
+			return "1+21.5-0.25\r\n"
+		case "1D1!":
+			return "1+1013\r\n"
+		}
+		return ""
+	})
+	start := time.Now()
+	values, err := r.Measure('1')
+	require.NoError(t, err)
+	require.Equal(t, []float64{21.5, -0.25, 1013}, values)
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestParseValues(t *testing.T) {
+	v, err := ParseValues("+3.14-0.5+12")
+	require.NoError(t, err)
+	require.Equal(t, []float64{3.14, -0.5, 12}, v)
+	v, err = ParseValues("")
+	require.NoError(t, err)
+	require.Empty(t, v)
+	_, err = ParseValues("3.14")
+	require.ErrorIs(t, err, ErrBadResponse)
+}
+
+func TestConfig(t *testing.T) {
+	cfg := Config("/dev/ttyUSB0")
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, 1200, cfg.BaudRate)
+	require.Equal(t, 7, cfg.DataBits)
+}
@@ -0,0 +1,92 @@
+package serial
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Notify sends state, such as "READY=1" or "STATUS=waiting for GPS", to the
+// service manager, like sd_notify(3). Without $NOTIFY_SOCKET, when not run
+// by systemd with Type=notify or WatchdogSec=, it does nothing.
+func Notify(state string) error {
+	path := os.Getenv("NOTIFY_SOCKET")
+	if path == "" {
+		return nil
+	}
+	if strings.HasPrefix(path, "@") {
+		path = "\x00" + path[1:] // abstract namespace
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// Watchdog configures WatchdogLoop.
+type Watchdog struct {
+	// MinRate is the line rate, in lines per second, below which the
+	// stream counts as dead; zero means any line at all.
+	MinRate float64
+	// Interval is how often the rate is measured and the watchdog fed;
+	// default half the WatchdogSec= of the service, as systemd recommends.
+	Interval time.Duration
+}
+
+// WatchdogLoop feeds the systemd service watchdog (WATCHDOG=1) every
+// Interval in which lines framed by the read loops and ReadLine flowed at
+// w.MinRate or more, and withholds it otherwise, so that systemd restarts
+// the service, after WatchdogSec=, when the stream silently dies even
+// though the process is alive. It blocks until ctx is done or the reader
+// is closed, so run it in its own goroutine. Without a watchdog configured
+// for this process it returns at once, unless w.Interval is set.
+func (s *SerialReader) WatchdogLoop(ctx context.Context, w Watchdog) {
+	if w.Interval <= 0 {
+		w.Interval = watchdogInterval()
+		if w.Interval <= 0 {
+			return
+		}
+	}
+	t := time.NewTicker(w.Interval)
+	defer t.Stop()
+	last, lastAt := s.linesRead.Load(), time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			if s.closed.Load() {
+				return
+			}
+			cur := s.linesRead.Load()
+			delta := cur - last
+			if cur < last {
+				delta = cur // counters were reset
+			}
+			rate := float64(delta) / now.Sub(lastAt).Seconds()
+			last, lastAt = cur, now
+			if delta > 0 && rate >= w.MinRate {
+				Notify("WATCHDOG=1")
+			}
+		}
+	}
+}
+
+// watchdogInterval returns half the watchdog timeout systemd set for this
+// process, or zero if it set none, like sd_watchdog_enabled(3).
+func watchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond / 2
+}
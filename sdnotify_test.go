@@ -0,0 +1,96 @@
+package serial
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifySocket points $NOTIFY_SOCKET at a socket and returns the
+// messages sent to it.
+func fakeNotifySocket(t *testing.T) <-chan string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "sd") // short: socket paths are limited
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	msgs := make(chan string, 16)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			msgs <- string(buf[:n])
+		}
+	}()
+	return msgs
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	require.NoError(t, Notify("READY=1"))
+
+	msgs := fakeNotifySocket(t)
+	newTestReader(t, Config{NotifyReady: true})
+	require.Equal(t, "READY=1", <-msgs)
+}
+
+func TestWatchdogLoop(t *testing.T) {
+	msgs := fakeNotifySocket(t)
+	reader, master := newTestReader(t, Config{})
+	go reader.ReadLinesLoop(func(string) {}, func(error) {})
+
+	// Without WatchdogSec= there is nothing to feed.
+	t.Setenv("WATCHDOG_USEC", "")
+	reader.WatchdogLoop(context.Background(), Watchdog{})
+	t.Setenv("WATCHDOG_USEC", "100000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	require.Zero(t, watchdogInterval())
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+	require.Equal(t, 50*time.Millisecond, watchdogInterval())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reader.WatchdogLoop(ctx, Watchdog{MinRate: 20})
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				master.Write([]byte("tick\n"))
+			}
+		}
+	}()
+	select {
+	case msg := <-msgs:
+		require.Equal(t, "WATCHDOG=1", msg)
+	case <-time.After(time.Second):
+		t.Fatal("watchdog not fed while lines flow")
+	}
+
+	// A silent stream starves the watchdog.
+	close(stop)
+	time.Sleep(120 * time.Millisecond)
+	for len(msgs) > 0 {
+		<-msgs
+	}
+	select {
+	case <-msgs:
+		t.Fatal("watchdog fed without lines")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
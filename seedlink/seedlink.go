@@ -0,0 +1,389 @@
+// Package seedlink republishes miniSEED records to TCP clients with the
+// SeedLink 3 protocol, so standard seismological clients (slinktool,
+// SeisComP, ObsPy) can connect to an acquisition box directly.
+//
+// The server implements the commands needed for data streaming: HELLO,
+// STATION, SELECT, DATA, END and BYE, in uni- and multi-station mode, with
+// resumption from a sequence number within the server's buffer. INFO and
+// time windows (TIME, FETCH) are answered with ERROR.
+//
+// A Server is an io.Writer of 512-byte records, so an mseed.Writer can write
+// straight into it:
+//
+//	srv := seedlink.NewServer("Example Observatory", 1000)
+//	go srv.ListenAndServe(":18000")
+//	w := mseed.NewWriter(srv, "XX", "STA1", "00", "HHZ", 100)
+package seedlink
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RecordLength is the miniSEED record size SeedLink 3 carries.
+const RecordLength = 512
+
+// maxSeq is the largest SeedLink sequence number (six hex digits).
+const maxSeq = 0xFFFFFF
+
+// ErrServerClosed is returned by Serve after Close.
+var ErrServerClosed = errors.New("seedlink: server closed")
+
+// packet is a record with its sequence number and stream identity.
+type packet struct {
+	seq      uint32
+	net, sta string
+	loc, cha string
+	data     []byte
+}
+
+// Server distributes published records to connected clients.
+type Server struct {
+	org    string
+	buffer int
+
+	mu        sync.Mutex
+	seq       uint32
+	ring      []packet // most recent packets, oldest first
+	clients   map[*client]struct{}
+	listeners map[net.Listener]struct{}
+	closed    bool
+}
+
+// NewServer returns a Server announcing organization in its HELLO response
+// and keeping the last buffer records for clients resuming with DATA.
+func NewServer(organization string, buffer int) *Server {
+	return &Server{
+		org:       organization,
+		buffer:    buffer,
+		clients:   make(map[*client]struct{}),
+		listeners: make(map[net.Listener]struct{}),
+	}
+}
+
+// Write publishes p, which must hold whole 512-byte miniSEED records.
+func (s *Server) Write(p []byte) (int, error) {
+	if len(p)%RecordLength != 0 {
+		return 0, fmt.Errorf("seedlink: write of %d bytes is not a whole number of %d-byte records", len(p), RecordLength)
+	}
+	for off := 0; off < len(p); off += RecordLength {
+		s.publish(p[off : off+RecordLength])
+	}
+	return len(p), nil
+}
+
+// Publish assigns record, a 512-byte miniSEED record, the next sequence
+// number and sends it to every streaming client whose selection matches.
+// Clients too slow to keep up are disconnected rather than allowed to stall
+// the acquisition.
+func (s *Server) Publish(record []byte) error {
+	if len(record) != RecordLength {
+		return fmt.Errorf("seedlink: record of %d bytes, want %d", len(record), RecordLength)
+	}
+	s.publish(record)
+	return nil
+}
+
+// publish is Publish for a record of the right length.
+func (s *Server) publish(record []byte) {
+	pkt := packet{
+		net:  strings.TrimSpace(string(record[18:20])),
+		sta:  strings.TrimSpace(string(record[8:13])),
+		loc:  strings.TrimSpace(string(record[13:15])),
+		cha:  strings.TrimSpace(string(record[15:18])),
+		data: append([]byte(nil), record...),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq = s.seq%maxSeq + 1
+	pkt.seq = s.seq
+	if s.buffer > 0 {
+		if len(s.ring) == s.buffer {
+			s.ring = append(s.ring[:0], s.ring[1:]...)
+		}
+		s.ring = append(s.ring, pkt)
+	}
+	for c := range s.clients {
+		if c.streaming && c.wants(pkt) {
+			select {
+			case c.out <- pkt:
+			default:
+				c.conn.Close()
+			}
+		}
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and calls Serve.
+func (s *Server) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts SeedLink clients on l until Close is called.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		c := &client{srv: s, conn: conn, out: make(chan packet, 256), done: make(chan struct{})}
+		s.mu.Lock()
+		s.clients[c] = struct{}{}
+		s.mu.Unlock()
+		go c.serve()
+	}
+}
+
+// Close stops all listeners and disconnects all clients.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// client is one SeedLink connection.
+type client struct {
+	srv       *Server
+	conn      net.Conn
+	out       chan packet
+	done      chan struct{} // closed when a streaming client hangs up
+	stations  []*selection
+	cur       *selection // station being configured in multi-station mode
+	streaming bool       // guarded by srv.mu
+}
+
+// selection is a STATION with its SELECT patterns and DATA start.
+type selection struct {
+	net, sta string
+	patterns []string
+	start    int64 // first sequence number wanted; -1 for live data only
+}
+
+func (c *client) serve() {
+	defer func() {
+		c.srv.mu.Lock()
+		delete(c.srv.clients, c)
+		c.srv.mu.Unlock()
+		c.conn.Close()
+	}()
+	r := bufio.NewReader(c.conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		cmd := strings.ToUpper(fields[0])
+		if cmd == "BYE" {
+			return
+		}
+		start, reply := c.command(cmd, fields[1:])
+		if reply != "" {
+			if _, err := c.conn.Write([]byte(reply)); err != nil {
+				return
+			}
+		}
+		if start {
+			go func() {
+				// Commands sent while streaming are not answered; just
+				// watch for the client hanging up.
+				defer close(c.done)
+				for {
+					if _, err := r.ReadString('\n'); err != nil {
+						return
+					}
+				}
+			}()
+			c.stream()
+			return
+		}
+	}
+}
+
+// command executes one command line and returns the reply; start reports
+// that streaming begins.
+func (c *client) command(cmd string, args []string) (start bool, reply string) {
+	switch cmd {
+	case "HELLO":
+		return false, "SeedLink v3.1 (go-linux-serial) :: SLPROTO:3.1\r\n" + c.srv.org + "\r\n"
+	case "STATION":
+		if len(args) < 1 {
+			return false, "ERROR\r\n"
+		}
+		sel := &selection{sta: strings.ToUpper(args[0]), start: -1}
+		if len(args) > 1 {
+			sel.net = strings.ToUpper(args[1])
+		}
+		c.stations = append(c.stations, sel)
+		c.cur = sel
+		return false, "OK\r\n"
+	case "SELECT":
+		sel := c.selection()
+		if len(args) == 0 {
+			sel.patterns = nil
+		} else {
+			sel.patterns = append(sel.patterns, strings.ToUpper(args[0]))
+		}
+		return false, c.ack()
+	case "DATA":
+		sel := c.selection()
+		if len(args) > 0 {
+			seq, err := strconv.ParseUint(args[0], 16, 32)
+			if err != nil {
+				return false, "ERROR\r\n"
+			}
+			sel.start = int64(seq%maxSeq) + 1
+		}
+		if c.cur == nil {
+			return true, "" // uni-station mode: DATA starts streaming
+		}
+		return false, "OK\r\n"
+	case "END":
+		return true, ""
+	}
+	return false, "ERROR\r\n"
+}
+
+// ack acknowledges a command in multi-station mode; uni-station mode sends
+// no acknowledgement.
+func (c *client) ack() string {
+	if c.cur == nil {
+		return ""
+	}
+	return "OK\r\n"
+}
+
+// selection returns the selection SELECT and DATA apply to, creating the
+// implicit all-stations selection of uni-station mode.
+func (c *client) selection() *selection {
+	if c.cur != nil {
+		return c.cur
+	}
+	if len(c.stations) == 0 {
+		c.stations = append(c.stations, &selection{start: -1})
+	}
+	return c.stations[0]
+}
+
+// stream sends buffered packets the client asked to resume from, then live
+// packets, until the connection fails.
+func (c *client) stream() {
+	if len(c.stations) == 0 {
+		c.stations = append(c.stations, &selection{start: -1})
+	}
+	s := c.srv
+	s.mu.Lock()
+	var backlog []packet
+	for _, p := range s.ring {
+		if sel := c.match(p); sel != nil && sel.start >= 0 && int64(p.seq) >= sel.start {
+			backlog = append(backlog, p)
+		}
+	}
+	c.streaming = true
+	s.mu.Unlock()
+	for _, p := range backlog {
+		if !c.send(p) {
+			return
+		}
+	}
+	for {
+		select {
+		case p := <-c.out:
+			if !c.send(p) {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *client) send(p packet) bool {
+	hdr := fmt.Sprintf("SL%06X", p.seq)
+	_, err := c.conn.Write(append([]byte(hdr), p.data...))
+	return err == nil
+}
+
+func (c *client) wants(p packet) bool { return c.match(p) != nil }
+
+// match returns the client's selection that p satisfies, if any.
+func (c *client) match(p packet) *selection {
+	for _, sel := range c.stations {
+		if sel.sta != "" && !wildcard(sel.sta, p.sta) || sel.net != "" && !wildcard(sel.net, p.net) {
+			continue
+		}
+		if len(sel.patterns) == 0 {
+			return sel
+		}
+		for _, pat := range sel.patterns {
+			if matchSelect(pat, p.loc, p.cha) {
+				return sel
+			}
+		}
+	}
+	return nil
+}
+
+// matchSelect matches a SELECT pattern "[LL]CCC[.T]" with ? wildcards
+// against a location and channel. Only data records are published, so the
+// type suffix is accepted if it is D or ?.
+func matchSelect(pat, loc, cha string) bool {
+	pat, typ, _ := strings.Cut(pat, ".")
+	if typ != "" && typ != "D" && typ != "?" {
+		return false
+	}
+	if len(pat) > 3 {
+		l := pat[:len(pat)-3]
+		if !wildcard(l, fmt.Sprintf("%-2s", loc)) && !(strings.Trim(l, "-") == "" && loc == "") {
+			return false
+		}
+		pat = pat[len(pat)-3:]
+	}
+	return wildcard(pat, cha)
+}
+
+// wildcard matches s against pattern, where ? matches any single character.
+func wildcard(pattern, s string) bool {
+	if len(pattern) != len(s) {
+		return false
+	}
+	for i := range len(pattern) {
+		if pattern[i] != '?' && pattern[i] != s[i] {
+			return false
+		}
+	}
+	return true
+}
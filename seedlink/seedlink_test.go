@@ -0,0 +1,128 @@
+package seedlink
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/luhtfiimanal/go-linux-serial/mseed"
+)
+
+func startServer(t *testing.T, buffer int) (*Server, string) {
+	t.Helper()
+	srv := NewServer("Test Observatory", buffer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return srv, l.Addr().String()
+}
+
+// dial connects and sends the given commands, checking each reply.
+func dial(t *testing.T, addr string, cmds ...string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, cmd := range cmds {
+		fmt.Fprintf(conn, "%s\r\n", cmd)
+		switch {
+		case cmd == "HELLO":
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(line, "SeedLink v3.1"), line)
+			line, err = r.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, "Test Observatory\r\n", line)
+		case cmd == "END":
+		default:
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, "OK\r\n", line, cmd)
+		}
+	}
+	return conn, r
+}
+
+func readPacket(t *testing.T, r io.Reader) (seq string, record []byte) {
+	t.Helper()
+	buf := make([]byte, 8+RecordLength)
+	_, err := io.ReadFull(r, buf)
+	require.NoError(t, err)
+	require.Equal(t, "SL", string(buf[:2]))
+	return string(buf[2:8]), buf[8:]
+}
+
+func publish(t *testing.T, srv *Server, sta, cha string, n int) {
+	t.Helper()
+	w := mseed.NewWriter(srv, "XX", sta, "00", cha, 100)
+	start := time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		require.NoError(t, w.Write(start.Add(time.Duration(i)*time.Second), make([]int32, 100)))
+		require.NoError(t, w.Flush())
+	}
+}
+
+func TestServer_MultiStation(t *testing.T) {
+	srv, addr := startServer(t, 100)
+	_, r := dial(t, addr, "HELLO", "STATION STA1 XX", "SELECT 00HH?", "DATA", "END")
+	time.Sleep(50 * time.Millisecond) // let the server switch to streaming
+
+	publish(t, srv, "STA2", "HHZ", 1) // other station
+	publish(t, srv, "STA1", "BHZ", 1) // other channel
+	publish(t, srv, "STA1", "HHZ", 1)
+	seq, rec := readPacket(t, r)
+	require.Equal(t, "000003", seq)
+	require.Equal(t, "STA1 00HHZXX", string(rec[8:20]))
+}
+
+func TestServer_PublishShortRecord(t *testing.T) {
+	srv := NewServer("test", 10)
+	require.Error(t, srv.Publish(make([]byte, 10)))
+	_, err := srv.Write(make([]byte, 10))
+	require.Error(t, err)
+	require.NoError(t, srv.Publish(make([]byte, RecordLength)))
+}
+
+func TestServer_ResumeFromSequence(t *testing.T) {
+	srv, addr := startServer(t, 100)
+	publish(t, srv, "STA1", "HHZ", 3)
+
+	// Uni-station mode: DATA starts the stream, resuming after packet 1.
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "DATA 000001\r\n")
+	seq, _ := readPacket(t, conn)
+	require.Equal(t, "000002", seq)
+	seq, _ = readPacket(t, conn)
+	require.Equal(t, "000003", seq)
+	publish(t, srv, "STA1", "HHZ", 1)
+	seq, _ = readPacket(t, conn)
+	require.Equal(t, "000004", seq)
+}
+
+func TestServer_Commands(t *testing.T) {
+	_, addr := startServer(t, 0)
+	conn, r := dial(t, addr, "HELLO")
+	fmt.Fprintf(conn, "INFO ID\r\n")
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "ERROR\r\n", line)
+	fmt.Fprintf(conn, "BYE\r\n")
+	_, err = r.ReadByte()
+	require.ErrorIs(t, err, io.EOF)
+
+	require.True(t, matchSelect("BH?", "", "BHZ"))
+	require.True(t, matchSelect("--BHZ.D", "", "BHZ"))
+	require.False(t, matchSelect("10BHZ", "00", "BHZ"))
+}
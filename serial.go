@@ -1,151 +1,474 @@
 package serial
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"sync"
-	"syscall"
+	"sync/atomic"
 	"time"
-
-	"golang.org/x/sys/unix"
 )
 
 // SerialReader provides low-latency, killable, line-oriented access to a Linux serial port.
 // It is safe for concurrent use by multiple goroutines.
 type SerialReader struct {
-	fd        int
-	file      *os.File
-	done      chan struct{}
-	closeOnce sync.Once
-	config    Config
-	pipeR     int // self-pipe read fd
-	pipeW     int // self-pipe write fd
+	mu     sync.Mutex // serialises Reopen and Close
+	cur    atomic.Pointer[port]
+	closed atomic.Bool
+	config Config
+
+	subMu       sync.RWMutex
+	subs        []*Subscription
+	taps        []*rawTap
+	transcripts []*Transcript
+	ring        *RingBuffer
+
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
+	linesRead    atomic.Uint64
+	linesWritten atomic.Uint64
+	writeErrors  atomic.Uint64
+	dropped      atomic.Uint64
+	badLines     atomic.Uint64
+	parseErrors  atomic.Uint64
+	badChars     atomic.Uint64
+	breaks       atomic.Uint64
+	overruns     atomic.Uint64
+	maxBacklog   atomic.Uint64
+	backlogAt    atomic.Int64      // last backlog sample in Unix nanoseconds
+	latency      *latencyHistogram // nil unless Config.MeasureLatency
+
+	partial partialState                             // unframed input left by the last read or loop
+	lineEnd func(b []byte, from int) (end, next int) // Config.lineEnd, built once at open
+
+	readDeadline atomic.Int64  // Read deadline in Unix nanoseconds; 0 means none
+	lastActivity atomic.Int64  // last read or write in Unix nanoseconds; 0 means none
+	lastRead     atomic.Int64  // last read in Unix nanoseconds; 0 means none
+	tx           chan struct{} // one-slot write lock; see lockTx
+
+	open func(Config) (*port, error) // opens the device or endpoint; used again by Reopen
+}
+
+// AccessMode selects whether a port is opened for reading, writing or both.
+type AccessMode int
+
+const (
+	// ReadWrite opens the port for reading and writing (the default).
+	ReadWrite AccessMode = iota
+	// ReadOnly opens the port with O_RDONLY, e.g. for monitoring without write permission.
+	ReadOnly
+	// WriteOnly opens the port with O_WRONLY, e.g. for command-only writers.
+	WriteOnly
+)
+
+var accessNames = [...]string{"read-write", "read-only", "write-only"}
+
+// String returns "read-write", "read-only" or "write-only".
+func (m AccessMode) String() string {
+	if m < 0 || int(m) >= len(accessNames) {
+		return "AccessMode(" + strconv.Itoa(int(m)) + ")"
+	}
+	return accessNames[m]
+}
+
+// MarshalText encodes m by name, for configuration files.
+func (m AccessMode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(accessNames) {
+		return nil, fmt.Errorf("invalid access mode %d", int(m))
+	}
+	return []byte(accessNames[m]), nil
+}
+
+// UnmarshalText decodes the names returned by String, in any case.
+func (m *AccessMode) UnmarshalText(b []byte) error {
+	for i, name := range accessNames {
+		if strings.EqualFold(string(b), name) {
+			*m = AccessMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid access mode %q", b)
 }
 
 // Config holds configuration parameters for opening a serial port.
 type Config struct {
 	Device      string
-	BaudRate    int
-	Delimiter   string // default "\r\n"
-	ReadTimeout time.Duration
+	BaudRate    int           // 1200 to 230400; default 115200
+	DataBits    int           // 5 to 8; default 8
+	Parity      Parity        // default ParityNone
+	StopBits    int           // 1 or 2; default 1
+	Access      AccessMode    // default ReadWrite
+	RTSCTS      bool          // hardware (RTS/CTS) flow control
+	XONXOFF     bool          // software (XON/XOFF) flow control
+	InitialDTR  LineState     // DTR right after open; default leaves the driver's choice
+	InitialRTS  LineState     // RTS right after open; default leaves the driver's choice
+	Delimiter   string        // any byte sequence, NUL and 0xFF included; default "\r\n"
+	ReadTimeout time.Duration // ReadLine gives up with ErrTimeout after this long; zero waits forever
+
+	// Terminator, if set, ends lines instead of Delimiter, for devices with
+	// inconsistent line endings (`\r?\n`) or responses that end in a prompt
+	// (`>\s$`, where $ is the end of the input received so far). The line
+	// is the input before the first match; the match itself is dropped.
+	// A match is taken as soon as it is complete, so `\r\n?` ends a line at
+	// the \r before the \n has arrived. Each chunk read rescans the pending
+	// partial line, so keep lines short or the pattern cheap; Delimiter is
+	// faster. A Terminator matching the empty string is invalid.
+	Terminator *regexp.Regexp
+
+	// DetectDelimiter learns the line ending from the first data received
+	// instead of using Delimiter: "\n", "\r\n" or "\r", whichever ends
+	// the first line. Data is held back until the choice is made, which
+	// happens at the first line end, or a byte after it for a "\r"; a
+	// "\n" that is the very first byte received may be the tail of a
+	// "\r\n" and is passed over. The choice is kept for the life of the
+	// reader, Reopen included, and reported to OnDelimiterDetected, if set.
+	// It cannot be combined with Delimiter or Terminator, and applies to
+	// reading only: WriteLine still takes its newline explicitly.
+	DetectDelimiter     bool
+	OnDelimiterDetected func(delim string)
+
+	// Framer names a registered framer (see RegisterFramer), such as
+	// "nmea", "cobs" or "modbus-rtu", that ReadFramesLoop uses when called
+	// with a nil Framer, so the protocol can be chosen in a configuration
+	// file or endpoint URL.
+	Framer string
+
+	// HoldModemLines avoids DTR and RTS transitions, for boards whose
+	// bootloader or reset circuit reacts to them (Arduino-style auto-reset,
+	// ESP32 download mode). The port is opened with HUPCL cleared, so Close
+	// no longer drops the lines, and termios is left untouched when it
+	// already matches. The kernel still raises the lines on open if they are
+	// low, so after the first open they simply stay up; use
+	// "stty -F <dev> -hupcl" once at boot to avoid even that transition.
+	// It cannot be combined with InitialDTR or InitialRTS.
+	HoldModemLines bool
+
+	// RS485, if set, keys an RS-485 transceiver from RTS around every write;
+	// see RS485. It applies to local ttys only.
+	RS485 *RS485
+
+	// BeforeWrite and AfterWrite, if set, run immediately before each
+	// physical write and after it has been drained from the UART, for
+	// transceivers keyed by other means: a GPIO driver enable, a radio's
+	// PTT line. An error from BeforeWrite aborts the write; AfterWrite
+	// always runs once BeforeWrite succeeded, and its error is returned if
+	// the write itself succeeded. On network ports there is nothing to
+	// drain and AfterWrite runs once the data is handed to the socket.
+	BeforeWrite func() error
+	AfterWrite  func() error
+
+	// MarkErrors makes a local tty mark characters received with a parity
+	// or framing error (PARMRK) instead of passing them on as if they were
+	// good. Marked characters are dropped from the data, counted in
+	// Stats().BadChars and handed to OnBadChar, if set.
+	MarkErrors bool
+	OnBadChar  func(c byte)
+
+	// DetectBreaks reports break conditions on a local tty, which some
+	// instruments send to end a record or raise an alarm, to OnBreak and
+	// Stats().Breaks instead of reading them as a NUL byte. It works like
+	// MarkErrors, whose marking it relies on, and a NUL received with a
+	// framing error is indistinguishable from a break. Without MarkErrors,
+	// other corrupted characters are dropped and counted silently.
+	DetectBreaks bool
+	OnBreak      func(BreakReceived)
+
+	// OnOverrun, if set, is called by the read loops when the driver of a
+	// local tty reports characters lost to UART FIFO or tty buffer
+	// overruns since the previous poll, so losses are flagged as they
+	// happen; they are also counted in Stats().Overruns. It needs the
+	// TIOCGICOUNT counters of Linux serial drivers: on PTYs, network ports
+	// and BSD it is never called. Checking costs one ioctl per read.
+	OnOverrun func(Overrun)
+
+	// KeepStaleInput keeps bytes the device sent before the port was opened
+	// and configured. By default they are discarded, because they are often
+	// a partial line or were received at the wrong baud rate.
+	KeepStaleInput bool
+
+	// MaxLineLength, if positive, bounds the bytes buffered while waiting for a
+	// delimiter; longer lines are discarded with ErrLineTooLong.
+	MaxLineLength int
+
+	// Middleware is applied by ReadLinesLoop between framing and onLine,
+	// in order (the first entry sees each line first).
+	Middleware []Middleware
+
+	// Workers, if set, makes ReadLinesLoop call onLine on a pool of
+	// goroutines instead of the read loop's own; see WorkerPool. Middleware
+	// and subscriptions still run on the read loop, and MeasureLatency then
+	// measures up to the hand-over to the pool. ReadLinesLoop returns once
+	// the pool has handled every line it was given.
+	Workers *WorkerPool
+
+	// Async, if set, makes ReadLinesLoop hand lines to a delivery goroutine
+	// through a queue, so the read loop never runs callbacks; see
+	// AsyncDelivery. MeasureLatency then measures up to the hand-over.
+	// It may be combined with Workers.
+	Async *AsyncDelivery
+
+	// DiscardPartial drops the partial line or frame on Reopen, so the read
+	// loops start with a clean slate. By default the next loop continues
+	// it, which joins the halves of a sample split by a reconnect, but
+	// misframes one if bytes in between were lost: KeepStaleInput keeps
+	// those that arrived while the port was closed.
+	DiscardPartial bool
+
+	// ContinueOnError keeps ReadLinesLoop and ReadFramesLoop running after
+	// recoverable errors (EAGAIN, ErrLineTooLong, ErrBadFrame); onError is
+	// still called for each one.
+	// Fatal errors such as ErrDeviceRemoved always end the loop.
+	ContinueOnError bool
+
+	// DrainTimeout, if positive, makes Close behave like CloseWithTimeout.
+	DrainTimeout time.Duration
+
+	// Checksum, if set, verifies every received line: ReadLinesLoop passes
+	// only the payload of valid lines on and hands the others to OnBadLine;
+	// ReadLine returns ErrChecksum for them.
+	Checksum  *LineChecksum
+	OnBadLine func(line string, err error)
+
+	// WriteChecksum, if set, makes WriteLine append a checksum to every line
+	// (before the newline), e.g. NMEAChecksum for "$PUBX,00*33".
+	WriteChecksum *LineChecksum
+
+	// RingSize, if positive, enables a raw-byte RingBuffer of that many bytes
+	// that the read loop fills with everything it reads.
+	RingSize int
+
+	// RealtimePriority, if positive, runs the read loops with the SCHED_FIFO
+	// policy at this priority (1-99) on a dedicated OS thread; see
+	// LockRealtime. When the process lacks the privilege, onError receives
+	// the error once and the loop carries on with normal scheduling.
+	RealtimePriority int
+
+	// BlockingRead, if positive, makes ReadLinesLoop and ReadFramesLoop on a
+	// local tty use plain blocking reads that return as soon as data arrives
+	// or after BlockingRead (VMIN=0 and VTIME, in tenths of a second up to
+	// 25.5s) instead of poll and the self-pipe. That halves the syscalls per
+	// chunk, at the cost of Close and Reopen taking effect only when the
+	// current read returns, up to BlockingRead later.
+	BlockingRead time.Duration
+
+	// ReadSettle, if positive, makes reads wait this long after
+	// poll reports data before reading, so bursts arriving in quick
+	// succession are read with one syscall instead of one each. A settle of
+	// 1-2ms cuts the syscall rate of many slow ports sharply for a matching
+	// rise in latency.
+	ReadSettle time.Duration
+
+	// ReadChunkSize is the most bytes the read loops and ReadLine take per
+	// read syscall; default 4096. Small buffers stay cache-resident for
+	// ports sending short lines at a few hundred Hz, and 64KB reads cut the
+	// syscall rate of bulk transfers.
+	ReadChunkSize int
+
+	// BacklogInterval, if positive, makes the read loops sample the number
+	// of bytes waiting in the kernel input queue before a read, at most
+	// once per interval, and keep the largest in Stats().MaxBacklog. A
+	// backlog creeping towards the driver's buffer size is the warning
+	// that the consumer is too slow, before bytes are actually lost.
+	BacklogInterval time.Duration
+
+	// MeasureLatency records, for every line and frame delivered by the read
+	// loops, the time from poll wakeup until the callback returned; see
+	// Stats().Latency.
+	MeasureLatency bool
+
+	// Health, if set, configures the probe HealthCheck runs; see
+	// HealthProbe. Without it HealthCheck only checks that the port is
+	// open.
+	Health *HealthProbe
+
+	// SampleRate, if positive, is the rate in Hz at which the device sends
+	// lines. ReadStampedLinesLoop then spaces the timestamps of lines that
+	// arrived in one read (batched by a USB adapter, say) 1/SampleRate
+	// apart, ending at the read, instead of giving them all its time.
+	SampleRate float64
+
+	// NotifyReady tells systemd that the service is ready (READY=1, see
+	// Notify) once the port is open, for services with Type=notify that
+	// are only up when their sensor is. Reopen does not notify again.
+	NotifyReady bool
 }
 
 // Open opens a serial port using the provided Config and returns a SerialReader.
 // The port is configured for raw, low-latency, non-buffered operation.
+//
+// Device may also be an endpoint URL, so one config string covers every
+// deployment: "serial:///dev/ttyUSB0?baud=115200&parity=even",
+// "tcp://host:4001", "rfc2217://host:4001", "udp://host:5000",
+// "unix:///run/sim.sock" or "stdin://" (see OpenStream). Query parameters
+// override the line settings in cfg.
+//
+// cfg is checked with Validate first, so a bad setting fails here instead of
+// being replaced by a default.
 func Open(cfg Config) (*SerialReader, error) {
-	fd, err := syscall.Open(cfg.Device, syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_NONBLOCK, 0666)
-	if err != nil {
-		return nil, fmt.Errorf("open failed: %w", err)
+	if err := cfg.Validate(); err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: err}
 	}
+	if strings.Contains(cfg.Device, "://") {
+		return openURL(cfg)
+	}
+	return openWith(cfg, openPort)
+}
 
-	termios, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+// openWith returns a SerialReader whose port is opened, and reopened, by open.
+func openWith(cfg Config, open func(Config) (*port, error)) (*SerialReader, error) {
+	p, err := open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("get termios: %w", err)
+		return nil, err
 	}
-
-	// Raw mode
-	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
-	termios.Oflag &^= unix.OPOST
-	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
-	termios.Cflag &^= unix.CSIZE | unix.PARENB
-	termios.Cflag |= unix.CS8
-
-	// Baud rate
-	baud := baudToUnix(cfg.BaudRate)
-	termios.Cflag &^= unix.CBAUD
-	termios.Cflag |= baud
-
-	// Set VMIN=1, VTIME=0 for immediate, non-blocking reads
-	termios.Cc[unix.VMIN] = 1
-	termios.Cc[unix.VTIME] = 0
-
-	if err := unix.IoctlSetTermios(fd, unix.TCSETS, termios); err != nil {
-		return nil, fmt.Errorf("set termios: %w", err)
+	s := &SerialReader{config: cfg, open: open, lineEnd: cfg.lineEnd(), tx: make(chan struct{}, 1)}
+	if cfg.RingSize > 0 {
+		s.ring = NewRingBuffer(cfg.RingSize)
 	}
+	if cfg.MeasureLatency {
+		s.latency = newLatencyHistogram()
+	}
+	s.cur.Store(p)
+	if cfg.NotifyReady {
+		Notify("READY=1") // best effort, like sd_notify
+	}
+	return s, nil
+}
 
-	// Turn back into blocking mode now that config is done
-	syscall.SetNonblock(fd, false)
-
-	// Create self-pipe for killability
-	pipeFds := make([]int, 2)
-	if err := unix.Pipe(pipeFds); err != nil {
-		return nil, fmt.Errorf("pipe: %w", err)
+// stopped reports whether p has been closed or stop has fired.
+func (p *port) stopped(stop *waker) bool {
+	select {
+	case <-p.done:
+		return true
+	default:
 	}
+	return stop != nil && stop.fired()
+}
 
-	file := os.NewFile(uintptr(fd), cfg.Device)
-	return &SerialReader{
-		fd:        fd,
-		file:      file,
-		done:      make(chan struct{}),
-		closeOnce: sync.Once{},
-		config:    cfg,
-		pipeR:     pipeFds[0],
-		pipeW:     pipeFds[1],
-	}, nil
+// port returns the currently open port instance.
+func (s *SerialReader) port() *port {
+	return s.cur.Load()
 }
 
 // WriteLine writes a line (with specified newline) to the serial port.
+// With Config.WriteChecksum set, the checksum is appended to line first.
 func (s *SerialReader) WriteLine(line string, newline string) error {
-	_, err := s.file.WriteString(line + newline)
-	return err
+	if s.config.Access == ReadOnly {
+		return s.opErr("write", ErrAccessMode)
+	}
+	p := s.port()
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+	if c := s.config.WriteChecksum; c != nil {
+		line = c.Append(line)
+	}
+	n, err := s.write(p, []byte(line+newline), nil)
+	s.countWritten(n)
+	if err == nil {
+		s.linesWritten.Add(1)
+		s.transcribe('>', line)
+	}
+	return s.opErr("write", err)
+}
+
+// WriteLineContext is WriteLine that gives up when ctx is cancelled or its
+// deadline passes, returning ctx.Err(), even while the kernel output buffer
+// is full because flow control holds the line off. It waits for room with
+// poll and writes in small chunks, so a cancelled call may have sent the
+// start of the line. Other writers are held off until the line is done.
+func (s *SerialReader) WriteLineContext(ctx context.Context, line, newline string) error {
+	if s.config.Access == ReadOnly {
+		return s.opErr("write", ErrAccessMode)
+	}
+	p := s.port()
+	select {
+	case <-p.done:
+		return ErrClosed
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stop, err := newWaker()
+	if err != nil {
+		return s.opErr("write", err)
+	}
+	stop.enter()
+	defer stop.exit()
+	defer stop.close()
+	defer context.AfterFunc(ctx, stop.close)()
+	if c := s.config.WriteChecksum; c != nil {
+		line = c.Append(line)
+	}
+	n, err := s.write(p, []byte(line+newline), stop)
+	s.countWritten(n)
+	if err == nil {
+		s.linesWritten.Add(1)
+		s.transcribe('>', line)
+	} else if cerr := ctx.Err(); cerr != nil && stop.fired() {
+		return cerr
+	}
+	return s.opErr("write", err)
 }
 
 // ReadLine reads a line using a custom buffer, avoiding bufio for lowest latency.
 // ReadLine reads a single line from the serial port, blocking until a full line is received or an error occurs.
 // The delimiter is specified in Config. This avoids bufio for lowest latency.
+// If Config.ReadTimeout is set, ReadLine returns ErrTimeout when no full line arrives in time.
+// Bytes received past the delimiter, and a line cut short by the timeout, are
+// kept for the next call; the read buffer is reused, so a call allocates only
+// the returned string.
 func (s *SerialReader) ReadLine() (string, error) {
-	buf := make([]byte, 4096)
-	line := ""
-	for {
-		// Use poll to wait for data or kill signal
-		pfd := []unix.PollFd{
-			{Fd: int32(s.fd), Events: unix.POLLIN},
-			{Fd: int32(s.pipeR), Events: unix.POLLIN},
-		}
-		_, err := unix.Poll(pfd, -1)
+	return s.readLine(nil)
+}
+
+// lineRead accounts for a line that ReadLine returns, verifying and
+// stripping its checksum if Config.Checksum is set.
+func (s *SerialReader) lineRead(line string) (string, error) {
+	s.linesRead.Add(1)
+	s.transcribe('<', line)
+	if c := s.config.Checksum; c != nil {
+		payload, err := c.Verify(line)
 		if err != nil {
-			return "", err
-		}
-		// Check killability
-		select {
-		case <-s.done:
-			return "", fmt.Errorf("serialreader closed")
-		default:
-		}
-		if pfd[1].Revents&unix.POLLIN != 0 {
-			// Drain pipe
-			var b [1]byte
-			unix.Read(s.pipeR, b[:])
-			return "", fmt.Errorf("serialreader closed")
-		}
-		if pfd[0].Revents&unix.POLLIN != 0 {
-			n, err := s.file.Read(buf)
-			if err != nil {
-				return "", err
-			}
-			line += string(buf[:n])
-			if idx := strings.Index(line, s.config.Delimiter); idx >= 0 {
-				result := line[:idx]
-				return result, nil
-			}
+			s.badLines.Add(1)
+			return "", s.opErr("read", err)
 		}
+		line = payload
 	}
+	return line, nil
 }
 
 // Reopen closes and reopens the serial port with the same configuration.
+// The SerialReader itself stays valid: subscriptions, the ring buffer,
+// counters and, unless Config.DiscardPartial is set, the partial line or
+// frame of the read loops carry over, so references held elsewhere need not
+// be replaced.
+// Any ReadLine or ReadLinesLoop running on the old port returns as if closed.
+// Reopen fails with ErrClosed once Close has been called.
 func (s *SerialReader) Reopen() error {
-	s.Close() // Clean up old fd, file, etc.
-	newReader, err := Open(s.config)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed.Load() {
+		return ErrClosed
+	}
+	// Open the replacement before closing the old port so their fd numbers
+	// differ: a loop about to poll the old fds then sees POLLNVAL instead of
+	// blocking on reused descriptors.
+	old := s.port()
+	p, err := s.open(s.config)
 	if err != nil {
+		old.close()
 		return err
 	}
-	s.fd = newReader.fd
-		s.file = newReader.file
-		s.done = newReader.done
-		s.closeOnce = sync.Once{}
-		// Copy any other fields as needed
+	s.cur.Store(p)
+	s.partial.reopened(old, p, s.config.DiscardPartial)
+	old.close()
 	return nil
 }
 
@@ -162,6 +485,9 @@ func (s *SerialReader) ReadLinesWithReconnect(
 			slog.Error("Serial read error", "error", err, "retry", retries)
 			onError(err)
 		})
+		if s.closed.Load() {
+			return
+		}
 
 		retries++
 		if maxRetries > 0 && retries >= maxRetries {
@@ -173,6 +499,9 @@ func (s *SerialReader) ReadLinesWithReconnect(
 		time.Sleep(1 * time.Second)
 
 		if err := s.Reopen(); err != nil {
+			if errors.Is(err, ErrClosed) {
+				return
+			}
 			slog.Error("Failed to reopen serial port", "error", err)
 			continue
 		}
@@ -181,97 +510,116 @@ func (s *SerialReader) ReadLinesWithReconnect(
 
 // ReadLinesLoop reads lines with lowest latency, using poll and custom buffer, and reports errors immediately.
 // ReadLinesLoop continuously reads lines from the serial port and invokes onLine for each complete line.
-// If an error occurs, onError is called and the loop exits.
+// If an error occurs, onError is called and the loop exits, unless
+// Config.ContinueOnError is set and the error is recoverable.
+// Every line that reaches onLine is also published to the reader's subscriptions.
+// A partial line left when the loop returns is kept, and the next
+// ReadLinesLoop continues it, after Reopen too (see Config.DiscardPartial).
 func (s *SerialReader) ReadLinesLoop(onLine func(string), onError func(error)) {
-	buf := make([]byte, 4096)
-	line := ""
-	for {
-		// Use poll to wait for data or kill signal
-		pfd := []unix.PollFd{
-			{Fd: int32(s.fd), Events: unix.POLLIN},
-			{Fd: int32(s.pipeR), Events: unix.POLLIN},
+	s.readLinesLoop(nil, onLine, onError)
+}
+
+// readLinesLoop implements ReadLinesLoop; it also returns when stop fires.
+func (s *SerialReader) readLinesLoop(stop *waker, onLine func(string), onError func(error)) {
+	onLine, wait := s.poolFunc(onLine)
+	defer wait()
+	onLine, flush := s.asyncFunc(s.validateFunc(Chain(s.deliverFunc(onLine), s.config.Middleware...)))
+	defer flush()
+	p := s.port()
+	line := s.partial.takeLine(p)
+	defer func() { s.partial.keepLine(p, line) }()
+	// Lines are scanned in place: each chunk is appended once, only its
+	// bytes are searched for the delimiter, and the unfinished tail is moved
+	// to the front after the last complete line.
+	scanned := 0 // line[:scanned] holds no line end
+	var wake time.Time
+	emit := func(b []byte) {
+		s.linesRead.Add(1)
+		l := string(b)
+		s.transcribe('<', l)
+		onLine(l)
+		if s.latency != nil {
+			s.latency.record(time.Since(wake))
 		}
-		_, err := unix.Poll(pfd, -1)
-		if err != nil {
-			if err == syscall.EINTR {
-				continue // Retry on interrupted system call
-			}
+	}
+	split := func() bool {
+		n := splitLines(line, scanned, s.lineEnd, emit)
+		line = line[:copy(line, line[n:])]
+		scanned = len(line)
+		if s.config.MaxLineLength > 0 && len(line) > s.config.MaxLineLength {
+			line, scanned = line[:0], 0 // discard the oversized partial line
+			err := s.opErr("read", ErrLineTooLong)
 			onError(err)
-			return
-		}
-		// Check killability
-		select {
-		case <-s.done:
-			return
-		default:
+			return s.keepGoing(err)
 		}
-		if pfd[1].Revents&unix.POLLIN != 0 {
-			// Drain pipe
-			var b [1]byte
-			unix.Read(s.pipeR, b[:])
+		return true
+	}
+	// ReadLine may have left whole lines behind.
+	if len(line) > 0 {
+		if wake = time.Now(); !split() {
 			return
 		}
-		if pfd[0].Revents&unix.POLLIN != 0 {
-			n, err := s.file.Read(buf)
-			if err != nil {
-				if err == syscall.EINTR {
-					continue // Retry on interrupted system call
-				}
-				onError(err)
-				return
-			}
-			line += string(buf[:n])
-			for {
-				idx := strings.Index(line, s.config.Delimiter)
-				if idx < 0 {
-					break
-				}
-				onLine(line[:idx])
-				line = line[idx+len(s.config.Delimiter):]
-			}
-		}
 	}
+	s.readChunks(stop, func(chunk []byte, woke time.Time) bool {
+		line = append(line, chunk...)
+		wake = woke
+		return split()
+	}, onError)
+}
+
+// readChunks is the engine behind the read loops: it polls the current port
+// and passes every chunk read to onChunk until the port is closed, stop
+// fires, a fatal error has been reported through onError, or onChunk returns
+// false. The chunk is only valid during the call. wake is the time poll
+// returned, set only when latency is measured.
+func (s *SerialReader) readChunks(stop *waker, onChunk func(chunk []byte, wake time.Time) bool, onError func(error)) {
+	s.readTicking(stop, 0, nil, onChunk, onError)
 }
 
 // Close closes the serial port and unblocks any ReadLine/ReadLinesLoop calls.
+// Subscription channels are closed as well.
 // Safe to call multiple times; subsequent calls are no-ops.
+// If Config.DrainTimeout is set, Close drains first, like CloseWithTimeout.
 func (s *SerialReader) Close() error {
-	var err error
-	s.closeOnce.Do(func() {
-		close(s.done)
-		// Wake up poll using self-pipe
-		if s.pipeW > 0 {
-			unix.Write(s.pipeW, []byte{1})
-		}
-		if s.file != nil {
-			err = s.file.Close()
-		}
-		syscall.Close(s.fd)
-		if s.pipeR > 0 {
-			unix.Close(s.pipeR)
-		}
-		if s.pipeW > 0 {
-			unix.Close(s.pipeW)
+	if s.config.DrainTimeout > 0 {
+		return s.CloseWithTimeout(s.config.DrainTimeout)
+	}
+	return s.close()
+}
+
+// CloseWithTimeout closes the port gracefully: it waits for pending output to
+// be transmitted and for a running ReadLinesLoop to consume and deliver the
+// complete lines already received, then closes as Close does and waits for
+// the loop to return. The whole sequence is bounded by timeout.
+func (s *SerialReader) CloseWithTimeout(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	p := s.port()
+	select {
+	case <-p.done:
+	default:
+		// Pending TX first, then RX that a running loop still has to consume.
+		waitUntil(deadline, func() bool { return queueLen(p.fd, ioctlOutQueue) == 0 })
+		if p.loops.Load() > 0 {
+			waitUntil(deadline, func() bool { return queueLen(p.fd, ioctlInQueue) == 0 })
 		}
-	})
+	}
+	err := s.close()
+	waitUntil(deadline, func() bool { return p.loops.Load() == 0 })
 	return err
 }
 
-func baudToUnix(baud int) uint32 {
-	switch baud {
-	case 9600:
-		return unix.B9600
-	case 19200:
-		return unix.B19200
-	case 38400:
-		return unix.B38400
-	case 57600:
-		return unix.B57600
-	case 115200:
-		return unix.B115200
-	case 230400:
-		return unix.B230400
-	default:
-		return unix.B115200 // fallback
+func (s *SerialReader) close() error {
+	s.mu.Lock()
+	s.closed.Store(true)
+	err := s.port().close()
+	s.mu.Unlock()
+	s.closeSubscriptions()
+	return err
+}
+
+// waitUntil polls cond every millisecond until it holds or deadline passes.
+func waitUntil(deadline time.Time, cond func() bool) {
+	for !cond() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
 	}
 }
@@ -0,0 +1,483 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// port is one open instance of the device. Reopen swaps in a fresh port while
+// the SerialReader, with its subscriptions, ring and counters, stays the same.
+type port struct {
+	fd        int
+	file      *os.File
+	done      chan struct{}
+	closeOnce sync.Once
+	pipeR     int             // self-pipe read fd
+	pipeW     int             // self-pipe write fd
+	loops     atomic.Int32    // running ReadLinesLoop calls
+	ctl       lineControl     // modem line control; nil means tty ioctls on fd
+	vtime     bool            // tty configured with VMIN=0/VTIME for Config.BlockingRead
+	marks     *markDecoder    // PARMRK decoder; nil unless Config.MarkErrors or DetectBreaks
+	overruns  *overrunCounter // nil unless Config.OnOverrun is set and the driver keeps counters
+}
+
+func (m AccessMode) flag() int {
+	switch m {
+	case ReadOnly:
+		return syscall.O_RDONLY
+	case WriteOnly:
+		return syscall.O_WRONLY
+	}
+	return syscall.O_RDWR
+}
+
+// openPort opens and configures the device described by cfg.
+func openPort(cfg Config) (*port, error) {
+	// O_CLOEXEC: a child process must not keep the port open after Close.
+	fd, err := syscall.Open(cfg.Device, cfg.Access.flag()|syscall.O_NOCTTY|syscall.O_NONBLOCK|syscall.O_CLOEXEC, 0666)
+	if err != nil {
+		if errors.Is(err, syscall.EBUSY) {
+			err = fmt.Errorf("%w: %w", ErrPortBusy, err)
+		}
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: permissionError(cfg.Device, err)}
+	}
+
+	termios, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, &SerialError{Device: cfg.Device, Op: "get termios", Err: err}
+	}
+
+	orig := *termios
+
+	// Raw mode
+	termios.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	if cfg.MarkErrors || cfg.DetectBreaks {
+		termios.Iflag |= unix.PARMRK | unix.INPCK
+		termios.Iflag &^= unix.IGNPAR
+	}
+	termios.Oflag &^= unix.OPOST
+	termios.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	if err := setLineSettings(termios, cfg); err != nil {
+		syscall.Close(fd)
+		return nil, &SerialError{Device: cfg.Device, Op: "set termios", Err: err}
+	}
+
+	// Baud rate
+	setSpeed(termios, cfg.BaudRate)
+
+	// Set VMIN=1, VTIME=0 for immediate, non-blocking reads
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if cfg.BlockingRead > 0 {
+		// Reads return when data arrives or after VTIME tenths of a second.
+		termios.Cc[unix.VMIN] = 0
+		termios.Cc[unix.VTIME] = vtime(cfg.BlockingRead)
+	}
+	if cfg.HoldModemLines {
+		// Without HUPCL, closing leaves DTR and RTS alone, so the next open
+		// finds them already raised.
+		termios.Cflag &^= unix.HUPCL
+	}
+
+	// Some USB serial drivers reprogram the UART, glitching the modem lines,
+	// on every set_termios; skip it when nothing changes.
+	if !cfg.HoldModemLines || *termios != orig {
+		if err := unix.IoctlSetTermios(fd, ioctlSetTermios, termios); err != nil {
+			syscall.Close(fd)
+			return nil, &SerialError{Device: cfg.Device, Op: "set termios", Err: err}
+		}
+	}
+	if err := setInitialLines(fd, cfg); err != nil {
+		syscall.Close(fd)
+		return nil, &SerialError{Device: cfg.Device, Op: "set modem lines", Err: err}
+	}
+	if !cfg.KeepStaleInput {
+		if err := flushInput(fd); err != nil {
+			syscall.Close(fd)
+			return nil, &SerialError{Device: cfg.Device, Op: "flush", Err: err}
+		}
+	}
+
+	// Turn back into blocking mode now that config is done
+	syscall.SetNonblock(fd, false)
+
+	p, err := newPort(fd, cfg.Device)
+	if err != nil {
+		return nil, err
+	}
+	p.vtime = cfg.BlockingRead > 0
+	if cfg.MarkErrors || cfg.DetectBreaks {
+		p.marks = &markDecoder{}
+	}
+	if cfg.OnOverrun != nil {
+		p.overruns = newOverrunCounter(p)
+	}
+	return p, nil
+}
+
+// vtime converts d to a VTIME value: tenths of a second, rounded up, from 1
+// to 255.
+func vtime(d time.Duration) uint8 {
+	return uint8(min(max((d+100*time.Millisecond-1)/(100*time.Millisecond), 1), 255))
+}
+
+// newPort wraps an open, blocking-mode fd with a self-pipe for killability.
+// It takes ownership of fd, closing it on failure.
+func newPort(fd int, name string) (*port, error) {
+	// Create self-pipe for killability
+	pipeFds, err := pipe()
+	if err != nil {
+		syscall.Close(fd)
+		return nil, &SerialError{Device: name, Op: "pipe", Err: err}
+	}
+
+	return &port{
+		fd:    fd,
+		file:  os.NewFile(uintptr(fd), name),
+		done:  make(chan struct{}),
+		pipeR: pipeFds[0],
+		pipeW: pipeFds[1],
+	}, nil
+}
+
+// pollFds builds the poll set in fds: the device, the port's self-pipe and,
+// if non-nil, the stop waker's pipe. The set never changes for a port, and
+// poll overwrites every Revents, so callers build it once and reuse it for
+// each wait.
+func pollFds(fds *[3]unix.PollFd, p *port, stop *waker) []unix.PollFd {
+	fds[0] = unix.PollFd{Fd: int32(p.fd), Events: unix.POLLIN}
+	fds[1] = unix.PollFd{Fd: int32(p.pipeR), Events: unix.POLLIN}
+	if stop == nil {
+		return fds[:2]
+	}
+	fds[2] = unix.PollFd{Fd: int32(stop.r), Events: unix.POLLIN}
+	return fds[:3]
+}
+
+// readLine implements ReadLine; it also returns ErrClosed when stop fires.
+func (s *SerialReader) readLine(stop *waker) (string, error) {
+	if s.config.Access == WriteOnly {
+		return "", s.opErr("read", ErrAccessMode)
+	}
+	p := s.port()
+	// Bytes read past the delimiter stay in line for the next call, which
+	// may find its whole line there without reading at all.
+	line := s.partial.takeLine(p)
+	defer func() { s.partial.keepLine(p, line) }()
+	buf := s.partial.takeBuf(s.config.readChunkSize())
+	defer s.partial.putBuf(buf)
+	var deadline time.Time
+	if s.config.ReadTimeout > 0 {
+		deadline = time.Now().Add(s.config.ReadTimeout)
+	}
+	var fds [3]unix.PollFd
+	pfd := pollFds(&fds, p, stop)
+	scanned := 0 // line[:scanned] holds no line end
+	for {
+		if end, next := s.lineEnd(line, scanned); end >= 0 {
+			result := string(line[:end])
+			line = line[:copy(line, line[next:])]
+			return s.lineRead(result)
+		}
+		scanned = len(line)
+		if s.config.MaxLineLength > 0 && len(line) > s.config.MaxLineLength {
+			line = line[:0]
+			return "", s.opErr("read", ErrLineTooLong)
+		}
+		if p.stopped(stop) {
+			return "", ErrClosed
+		}
+		timeout := -1
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return "", s.opErr("read", ErrTimeout)
+			}
+			timeout = int((remaining + time.Millisecond - 1) / time.Millisecond)
+		}
+		// Use poll to wait for data or kill signal
+		n, err := unix.Poll(pfd, timeout)
+		if err == syscall.EINTR {
+			continue // Interrupted by a signal (e.g. SIGPROF); the deadline is recomputed
+		}
+		if err != nil {
+			return "", s.opErr("poll", err)
+		}
+		if n == 0 {
+			continue // deadline check above reports the timeout
+		}
+		// Check killability
+		if p.stopped(stop) {
+			return "", ErrClosed
+		}
+		if pfd[1].Revents&unix.POLLIN != 0 {
+			// Drain pipe
+			var b [1]byte
+			unix.Read(p.pipeR, b[:])
+			return "", ErrClosed
+		}
+		if err := pollErr(pfd[0].Revents); err != nil {
+			return "", s.opErr("poll", err)
+		}
+		if pfd[0].Revents&unix.POLLIN != 0 {
+			if d := s.config.ReadSettle; d > 0 {
+				time.Sleep(d) // let more of the burst arrive
+				if p.stopped(stop) {
+					return "", ErrClosed
+				}
+			}
+			n, err := p.file.Read(buf)
+			if err != nil {
+				return "", s.opErr("read", err)
+			}
+			line = append(line, s.received(p, buf[:n])...)
+		}
+	}
+}
+
+// readTicking is readChunks that, with a positive tick, also calls onTick
+// every tick, timed by the poll timeout, until onTick returns false.
+// Config.BlockingRead is not used then.
+func (s *SerialReader) readTicking(stop *waker, tick time.Duration, onTick func() bool, onChunk func(chunk []byte, wake time.Time) bool, onError func(error)) {
+	if s.config.Access == WriteOnly {
+		onError(s.opErr("read", ErrAccessMode))
+		return
+	}
+	if prio := s.config.RealtimePriority; prio > 0 {
+		restore, err := LockRealtime(prio)
+		if err != nil {
+			onError(s.opErr("sched_setattr", err))
+		} else {
+			defer restore()
+		}
+	}
+	p := s.port()
+	p.loops.Add(1)
+	defer p.loops.Add(-1)
+	buf := make([]byte, s.config.readChunkSize())
+	if p.vtime && tick <= 0 {
+		s.readBlocking(p, stop, buf, onChunk, onError)
+		return
+	}
+	var fds [3]unix.PollFd
+	pfd := pollFds(&fds, p, stop)
+	next := time.Now().Add(tick)
+	for {
+		// Bail out before polling: after Close or Reopen these fd numbers may
+		// already belong to something else.
+		if p.stopped(stop) {
+			return
+		}
+		if tick > 0 {
+			if now := time.Now(); !now.Before(next) {
+				if !onTick() {
+					return
+				}
+				if next = next.Add(tick); next.Before(now) {
+					next = now.Add(tick) // fell behind; skip the missed ticks
+				}
+			}
+		}
+		timeout := -1
+		if tick > 0 {
+			timeout = int((time.Until(next) + time.Millisecond - 1) / time.Millisecond)
+			timeout = max(timeout, 0)
+		}
+		// Use poll to wait for data, kill signal or the next tick
+		_, err := unix.Poll(pfd, timeout)
+		var wake time.Time
+		if s.latency != nil {
+			wake = time.Now()
+		}
+		if err != nil {
+			if err == syscall.EINTR {
+				continue // Retry on interrupted system call
+			}
+			err = s.opErr("poll", err)
+			onError(err)
+			if s.keepGoing(err) {
+				continue
+			}
+			return
+		}
+		// Check killability
+		if p.stopped(stop) {
+			return
+		}
+		if pfd[1].Revents&unix.POLLIN != 0 {
+			// Drain pipe
+			var b [1]byte
+			unix.Read(p.pipeR, b[:])
+			return
+		}
+		if err := pollErr(pfd[0].Revents); err != nil {
+			onError(s.opErr("poll", err))
+			return
+		}
+		if pfd[0].Revents&unix.POLLIN != 0 {
+			if d := s.config.ReadSettle; d > 0 {
+				time.Sleep(d) // let more of the burst arrive
+				if p.stopped(stop) {
+					return
+				}
+			}
+			s.sampleBacklog(p)
+			s.checkOverruns(p)
+			n, err := p.file.Read(buf)
+			if err != nil {
+				if err == syscall.EINTR {
+					continue // Retry on interrupted system call
+				}
+				err = s.opErr("read", err)
+				onError(err)
+				if s.keepGoing(err) {
+					continue
+				}
+				return
+			}
+			if !onChunk(s.received(p, buf[:n]), wake) {
+				return
+			}
+		}
+	}
+}
+
+// readBlocking is readChunks for Config.BlockingRead: it reads without
+// polling and checks for Close or stop whenever a read returns.
+func (s *SerialReader) readBlocking(p *port, stop *waker, buf []byte, onChunk func([]byte, time.Time) bool, onError func(error)) {
+	rc, err := p.file.SyscallConn()
+	if err != nil {
+		onError(s.opErr("read", err))
+		return
+	}
+	for {
+		if p.stopped(stop) {
+			return
+		}
+		s.sampleBacklog(p)
+		s.checkOverruns(p)
+		var n int
+		var rerr error
+		// The raw read holds a reference on the file, so Close cannot
+		// release the fd number for reuse while the read is in progress.
+		if err := rc.Read(func(fd uintptr) bool {
+			n, rerr = unix.Read(int(fd), buf)
+			return true
+		}); err != nil {
+			return // closed
+		}
+		var wake time.Time
+		if s.latency != nil {
+			wake = time.Now()
+		}
+		if rerr != nil {
+			if rerr == syscall.EINTR {
+				continue
+			}
+			rerr = s.opErr("read", rerr)
+			onError(rerr)
+			if s.keepGoing(rerr) {
+				continue
+			}
+			return
+		}
+		if n == 0 {
+			// VTIME expired, or the device hung up: a hangup also reads as
+			// end of file, so ask poll which one it was.
+			var hup error
+			rc.Control(func(fd uintptr) {
+				pfd := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+				if _, err := unix.Poll(pfd, 0); err == nil {
+					hup = pollErr(pfd[0].Revents)
+				}
+			})
+			if hup != nil && !p.stopped(stop) {
+				onError(s.opErr("read", hup))
+				return
+			}
+			continue
+		}
+		if !onChunk(s.received(p, buf[:n]), wake) {
+			return
+		}
+	}
+}
+
+// close releases the fd and self-pipe, waking any poll on this port.
+func (p *port) close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		close(p.done)
+		// Wake up poll using self-pipe
+		if p.pipeW > 0 {
+			unix.Write(p.pipeW, []byte{1})
+		}
+		if p.file != nil {
+			err = p.file.Close() // also closes fd
+		}
+		if p.pipeR > 0 {
+			unix.Close(p.pipeR)
+		}
+		if p.pipeW > 0 {
+			unix.Close(p.pipeW)
+		}
+	})
+	return err
+}
+
+// queueLen returns the number of bytes in the kernel queue selected by req
+// (ioctlInQueue or ioctlOutQueue), or 0 if it cannot be determined.
+func queueLen(fd int, req uint) int {
+	n, err := unix.IoctlGetInt(fd, req)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// pollErr reports a hang-up or error condition on the device fd that is not
+// accompanied by readable data.
+func pollErr(revents int16) error {
+	if revents&unix.POLLIN != 0 {
+		return nil // read first; the read itself reports the error
+	}
+	switch {
+	case revents&unix.POLLNVAL != 0:
+		return ErrClosed
+	case revents&(unix.POLLHUP|unix.POLLERR) != 0:
+		return ErrDeviceRemoved
+	}
+	return nil
+}
+
+// baudRates maps the supported baud rates to their termios speed constants.
+var baudRates = map[int]uint32{
+	1200:   unix.B1200,
+	2400:   unix.B2400,
+	4800:   unix.B4800,
+	9600:   unix.B9600,
+	19200:  unix.B19200,
+	38400:  unix.B38400,
+	57600:  unix.B57600,
+	115200: unix.B115200,
+	230400: unix.B230400,
+}
+
+// baudToUnix returns the termios speed for baud; Validate rejects
+// unsupported rates, and zero means the 115200 default.
+func baudToUnix(baud int) uint32 {
+	if b, ok := baudRates[baud]; ok {
+		return b
+	}
+	return unix.B115200
+}
@@ -0,0 +1,43 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSerialReader_CloseOnExec(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+	p := reader.port()
+	w, err := newWaker()
+	require.NoError(t, err)
+	defer w.close()
+	for _, fd := range []int{p.fd, p.pipeR, p.pipeW, w.r, w.w} {
+		flags, err := unix.FcntlInt(uintptr(fd), unix.F_GETFD, 0)
+		require.NoError(t, err)
+		require.NotZero(t, flags&unix.FD_CLOEXEC, "fd %d", fd)
+	}
+}
+
+func TestOpen_HoldModemLines(t *testing.T) {
+	reader, _ := newTestReader(t, Config{HoldModemLines: true})
+	tio, err := unix.IoctlGetTermios(reader.port().fd, ioctlGetTermios)
+	require.NoError(t, err)
+	require.Zero(t, tio.Cflag&unix.HUPCL)
+
+	_, err = Open(Config{Device: reader.Device(), HoldModemLines: true, InitialDTR: LineOn})
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestLineSettings_FlowControl(t *testing.T) {
+	var tio unix.Termios
+	require.NoError(t, setLineSettings(&tio, Config{RTSCTS: true}))
+	require.NotZero(t, tio.Cflag&unix.CRTSCTS)
+	require.Zero(t, tio.Iflag&(unix.IXON|unix.IXOFF))
+	require.NoError(t, setLineSettings(&tio, Config{XONXOFF: true}))
+	require.Zero(t, tio.Cflag&unix.CRTSCTS)
+	require.Equal(t, uint32(unix.IXON|unix.IXOFF), uint32(tio.Iflag&(unix.IXON|unix.IXOFF)))
+}
@@ -0,0 +1,279 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// port is one open instance of the device. Reopen swaps in a fresh port while
+// the SerialReader, with its subscriptions, ring and counters, stays the same.
+//
+// On Windows there is nothing like poll(2) for a COM port, so reads time out
+// every readWait instead, and the read loops check for Close or a stop
+// between reads.
+type port struct {
+	fd        windows.Handle
+	file      *os.File
+	raw       syscall.RawConn // raw reads of file, which hold off its Close
+	done      chan struct{}
+	closeOnce sync.Once
+	loops     atomic.Int32    // running ReadLinesLoop calls
+	ctl       lineControl     // always nil: there are no network ports on Windows
+	marks     *markDecoder    // always nil: Windows does not mark errors in the data
+	overruns  *overrunCounter // always nil: Windows keeps no overrun counters
+}
+
+// readWait bounds every read and write, and with it how long Close, a stop,
+// a read deadline or a tick can go unnoticed.
+const readWait = 20 * time.Millisecond
+
+func (m AccessMode) access() uint32 {
+	switch m {
+	case ReadOnly:
+		return windows.GENERIC_READ
+	case WriteOnly:
+		return windows.GENERIC_WRITE
+	}
+	return windows.GENERIC_READ | windows.GENERIC_WRITE
+}
+
+// openPort opens and configures the device described by cfg. Device is a
+// COM port name such as "COM3"; the \\.\ prefix that ports above COM9 need
+// is added when missing.
+func openPort(cfg Config) (*port, error) {
+	if cfg.MarkErrors || cfg.DetectBreaks {
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: errors.ErrUnsupported}
+	}
+	name := cfg.Device
+	if !strings.HasPrefix(name, `\\.\`) {
+		name = `\\.\` + name
+	}
+	path, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: err}
+	}
+	h, err := windows.CreateFile(path, cfg.Access.access(), 0, nil, windows.OPEN_EXISTING, 0, 0)
+	if err != nil {
+		if err == windows.ERROR_ACCESS_DENIED {
+			// COM ports open exclusively; access is not otherwise restricted.
+			err = fmt.Errorf("%w: %w", ErrPortBusy, err)
+		}
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: err}
+	}
+
+	var dcb windows.DCB
+	dcb.DCBlength = uint32(unsafe.Sizeof(dcb))
+	if err := windows.GetCommState(h, &dcb); err != nil {
+		windows.CloseHandle(h)
+		return nil, &SerialError{Device: cfg.Device, Op: "get comm state", Err: err}
+	}
+	if err := setLineSettings(&dcb, cfg); err != nil {
+		windows.CloseHandle(h)
+		return nil, &SerialError{Device: cfg.Device, Op: "set comm state", Err: err}
+	}
+	setInitialLines(&dcb, cfg)
+	if err := windows.SetCommState(h, &dcb); err != nil {
+		windows.CloseHandle(h)
+		return nil, &SerialError{Device: cfg.Device, Op: "set comm state", Err: err}
+	}
+
+	// Reads return as soon as anything has arrived, or after readWait
+	// (MAXDWORD in both of the first two fields selects this mode); writes
+	// return what the driver took within readWait.
+	timeouts := windows.CommTimeouts{
+		ReadIntervalTimeout:        maxDWORD,
+		ReadTotalTimeoutMultiplier: maxDWORD,
+		ReadTotalTimeoutConstant:   uint32(readWait / time.Millisecond),
+		WriteTotalTimeoutConstant:  uint32(readWait / time.Millisecond),
+	}
+	if err := windows.SetCommTimeouts(h, &timeouts); err != nil {
+		windows.CloseHandle(h)
+		return nil, &SerialError{Device: cfg.Device, Op: "set comm timeouts", Err: err}
+	}
+	if !cfg.KeepStaleInput {
+		if err := flushInput(h); err != nil {
+			windows.CloseHandle(h)
+			return nil, &SerialError{Device: cfg.Device, Op: "flush", Err: err}
+		}
+	}
+
+	file := os.NewFile(uintptr(h), cfg.Device)
+	raw, err := file.SyscallConn()
+	if err != nil {
+		file.Close()
+		return nil, &SerialError{Device: cfg.Device, Op: "open", Err: err}
+	}
+	return &port{fd: h, file: file, raw: raw, done: make(chan struct{})}, nil
+}
+
+// read reads into b, returning 0 bytes once readWait has passed without
+// data. Errors of a vanished device are reported as ErrDeviceRemoved.
+func (p *port) read(b []byte) (int, error) {
+	var n uint32
+	var rerr error
+	if err := p.raw.Read(func(fd uintptr) bool {
+		rerr = windows.ReadFile(windows.Handle(fd), b, &n, nil)
+		return true
+	}); err != nil {
+		return 0, ErrClosed
+	}
+	switch rerr {
+	case nil:
+		return int(n), nil
+	case windows.ERROR_ACCESS_DENIED, windows.ERROR_BAD_COMMAND, windows.ERROR_GEN_FAILURE,
+		windows.ERROR_DEVICE_NOT_CONNECTED, windows.ERROR_OPERATION_ABORTED:
+		// USB adapters unplugged while open fail their handles this way.
+		return 0, ErrDeviceRemoved
+	}
+	return 0, rerr
+}
+
+// readLine implements ReadLine; it also returns ErrClosed when stop fires.
+func (s *SerialReader) readLine(stop *waker) (string, error) {
+	if s.config.Access == WriteOnly {
+		return "", s.opErr("read", ErrAccessMode)
+	}
+	p := s.port()
+	// Bytes read past the delimiter stay in line for the next call, which
+	// may find its whole line there without reading at all.
+	line := s.partial.takeLine(p)
+	defer func() { s.partial.keepLine(p, line) }()
+	buf := s.partial.takeBuf(s.config.readChunkSize())
+	defer s.partial.putBuf(buf)
+	var deadline time.Time
+	if s.config.ReadTimeout > 0 {
+		deadline = time.Now().Add(s.config.ReadTimeout)
+	}
+	scanned := 0 // line[:scanned] holds no line end
+	for {
+		if end, next := s.lineEnd(line, scanned); end >= 0 {
+			result := string(line[:end])
+			line = line[:copy(line, line[next:])]
+			return s.lineRead(result)
+		}
+		scanned = len(line)
+		if s.config.MaxLineLength > 0 && len(line) > s.config.MaxLineLength {
+			line = line[:0]
+			return "", s.opErr("read", ErrLineTooLong)
+		}
+		if p.stopped(stop) {
+			return "", ErrClosed
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return "", s.opErr("read", ErrTimeout)
+		}
+		n, err := p.read(buf)
+		if p.stopped(stop) {
+			return "", ErrClosed
+		}
+		if err != nil {
+			return "", s.opErr("read", err)
+		}
+		line = append(line, s.received(p, buf[:n])...)
+	}
+}
+
+// readTicking is readChunks that, with a positive tick, also calls onTick
+// every tick until onTick returns false. Ticks are checked between reads,
+// so they can be up to readWait late on an idle line.
+func (s *SerialReader) readTicking(stop *waker, tick time.Duration, onTick func() bool, onChunk func(chunk []byte, wake time.Time) bool, onError func(error)) {
+	if s.config.Access == WriteOnly {
+		onError(s.opErr("read", ErrAccessMode))
+		return
+	}
+	if prio := s.config.RealtimePriority; prio > 0 {
+		restore, err := LockRealtime(prio)
+		if err != nil {
+			onError(s.opErr("sched_setattr", err))
+		} else {
+			defer restore()
+		}
+	}
+	p := s.port()
+	p.loops.Add(1)
+	defer p.loops.Add(-1)
+	buf := make([]byte, s.config.readChunkSize())
+	next := time.Now().Add(tick)
+	for {
+		if p.stopped(stop) {
+			return
+		}
+		if tick > 0 {
+			if now := time.Now(); !now.Before(next) {
+				if !onTick() {
+					return
+				}
+				if next = next.Add(tick); next.Before(now) {
+					next = now.Add(tick) // fell behind; skip the missed ticks
+				}
+			}
+		}
+		s.sampleBacklog(p)
+		n, err := p.read(buf)
+		var wake time.Time
+		if s.latency != nil {
+			wake = time.Now()
+		}
+		if p.stopped(stop) {
+			return
+		}
+		if err != nil {
+			err = s.opErr("read", err)
+			onError(err)
+			if s.keepGoing(err) {
+				continue
+			}
+			return
+		}
+		if n > 0 && !onChunk(s.received(p, buf[:n]), wake) {
+			return
+		}
+	}
+}
+
+// close releases the handle. Reads in progress finish first, within
+// readWait.
+func (p *port) close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		close(p.done)
+		err = p.file.Close()
+	})
+	return err
+}
+
+// queueLen returns the number of bytes in the driver queue selected by req
+// (ioctlInQueue or ioctlOutQueue), or 0 if it cannot be determined.
+func queueLen(h windows.Handle, req uint) int {
+	var stat windows.ComStat
+	if windows.ClearCommError(h, nil, &stat) != nil {
+		return 0
+	}
+	if req == ioctlOutQueue {
+		return int(stat.CBOutQue)
+	}
+	return int(stat.CBInQue)
+}
+
+// baudRates lists the supported baud rates, as for the termios backends;
+// the DCB takes them as they are.
+var baudRates = map[int]uint32{
+	1200:   1200,
+	2400:   2400,
+	4800:   4800,
+	9600:   9600,
+	19200:  19200,
+	38400:  38400,
+	57600:  57600,
+	115200: 115200,
+	230400: 230400,
+}
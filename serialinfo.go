@@ -0,0 +1,109 @@
+package serial
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ClosingWaitNone, as SerialInfo.ClosingWait, makes close return without
+// waiting for output to drain.
+const ClosingWaitNone time.Duration = -1
+
+// SerialInfo holds the UART tuning of a local tty on Linux, from its
+// serial_struct (TIOCGSERIAL), for legacy hardware that needs an odd baud
+// rate or different close behaviour. Only these fields are exposed; the
+// port, IRQ and I/O settings are left alone.
+type SerialInfo struct {
+	// BaudBase is the UART clock divided by 16, the fastest rate it
+	// supports. Read only.
+	BaudBase int
+	// CustomDivisor divides BaudBase to give the line rate while
+	// CustomSpeed is set; see SetCustomBaud.
+	CustomDivisor int
+	// CustomSpeed makes the driver use BaudBase/CustomDivisor wherever the
+	// termios speed is 38400 (ASYNC_SPD_CUST).
+	CustomSpeed bool
+	// LowLatency asks the driver to pass received bytes on immediately
+	// instead of batching them (ASYNC_LOW_LATENCY); not all drivers do.
+	LowLatency bool
+	// XmitFifoSize is the depth of the transmit FIFO.
+	XmitFifoSize int
+	// CloseDelay is how long DTR stays low when the port is closed, in
+	// steps of 10ms.
+	CloseDelay time.Duration
+	// ClosingWait is how long close waits for output to drain, in steps of
+	// 10ms; zero waits however long it takes, ClosingWaitNone not at all.
+	ClosingWait time.Duration
+}
+
+// SerialInfo returns the UART tuning of the port. It fails with
+// errors.ErrUnsupported on network ports and outside Linux, and on
+// devices whose driver has no serial_struct, such as PTYs.
+func (s *SerialReader) SerialInfo() (SerialInfo, error) {
+	p, err := s.uartPort()
+	if err != nil {
+		return SerialInfo{}, s.opErr("get serial info", err)
+	}
+	info, err := getSerialInfo(p.fd)
+	return info, s.opErr("get serial info", err)
+}
+
+// SetSerialInfo changes the UART tuning of the port to info, except for
+// the read-only BaudBase. Without CAP_SYS_ADMIN the driver only allows
+// changing CustomDivisor, CustomSpeed and LowLatency and fails with EPERM
+// otherwise. The tuning belongs to the device and outlives the reader.
+func (s *SerialReader) SetSerialInfo(info SerialInfo) error {
+	p, err := s.uartPort()
+	if err != nil {
+		return s.opErr("set serial info", err)
+	}
+	return s.opErr("set serial info", setSerialInfo(p.fd, info))
+}
+
+// SetCustomBaud runs the port at baud, a rate termios cannot express, by
+// programming the divisor of BaudBase closest to it and setting the termios
+// speed to 38400, the alias the driver replaces with the custom rate. It
+// returns the rate actually obtained. Reopen and SetLineSettings apply
+// Config.BaudRate again, which ends the custom rate unless that is 38400.
+func (s *SerialReader) SetCustomBaud(baud int) (actual int, err error) {
+	if baud <= 0 {
+		return 0, s.opErr("set custom baud", fmt.Errorf("invalid baud rate %d", baud))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, err := s.uartPort()
+	if err != nil {
+		return 0, s.opErr("set custom baud", err)
+	}
+	info, err := getSerialInfo(p.fd)
+	if err != nil {
+		return 0, s.opErr("get serial info", err)
+	}
+	divisor := (info.BaudBase + baud/2) / baud
+	if divisor < 1 {
+		return 0, s.opErr("set custom baud", fmt.Errorf("baud rate %d above the UART's %d", baud, info.BaudBase))
+	}
+	info.CustomDivisor, info.CustomSpeed = divisor, true
+	if err := setSerialInfo(p.fd, info); err != nil {
+		return 0, s.opErr("set serial info", err)
+	}
+	if err := setTTYSpeed(p.fd, 38400); err != nil {
+		return 0, s.opErr("set termios", err)
+	}
+	return info.BaudBase / divisor, nil
+}
+
+// uartPort returns the current port if it is an open local tty.
+func (s *SerialReader) uartPort() (*port, error) {
+	p := s.port()
+	select {
+	case <-p.done:
+		return nil, ErrClosed
+	default:
+	}
+	if p.ctl != nil {
+		return nil, errors.ErrUnsupported
+	}
+	return p, nil
+}
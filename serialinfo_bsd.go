@@ -0,0 +1,15 @@
+//go:build darwin || freebsd
+
+package serial
+
+import "errors"
+
+// The BSD tty layer has no serial_struct.
+
+func getSerialInfo(fd int) (SerialInfo, error) {
+	return SerialInfo{}, errors.ErrUnsupported
+}
+
+func setSerialInfo(fd int, info SerialInfo) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,111 @@
+package serial
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// serial_struct flags and closing_wait values.
+const (
+	asyncSpdMask         = 0x1030
+	asyncSpdCust         = 0x0030
+	asyncLowLatency      = 0x2000
+	asyncClosingWaitInf  = 0
+	asyncClosingWaitNone = 65535
+)
+
+// serialStruct is struct serial_struct, read by TIOCGSERIAL.
+type serialStruct struct {
+	typ           int32
+	line          int32
+	port          uint32
+	irq           int32
+	flags         int32
+	xmitFifoSize  int32
+	customDivisor int32
+	baudBase      int32
+	closeDelay    uint16
+	ioType        int8
+	_             int8
+	hub6          int32
+	closingWait   uint16
+	closingWait2  uint16
+	iomemBase     uintptr
+	iomemRegShift uint16
+	portHigh      uint32
+	iomapBase     uintptr
+}
+
+func getSerialInfo(fd int) (SerialInfo, error) {
+	var ss serialStruct
+	if err := serialIoctl(fd, unix.TIOCGSERIAL, &ss); err != nil {
+		return SerialInfo{}, err
+	}
+	return ss.info(), nil
+}
+
+// setSerialInfo reads the serial_struct of fd, changes the fields info
+// covers and writes it back.
+func setSerialInfo(fd int, info SerialInfo) error {
+	var ss serialStruct
+	if err := serialIoctl(fd, unix.TIOCGSERIAL, &ss); err != nil {
+		return err
+	}
+	ss.apply(info)
+	return serialIoctl(fd, unix.TIOCSSERIAL, &ss)
+}
+
+func serialIoctl(fd int, req uint, ss *serialStruct) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), uintptr(req), uintptr(unsafe.Pointer(ss)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+func (ss *serialStruct) info() SerialInfo {
+	info := SerialInfo{
+		BaudBase:      int(ss.baudBase),
+		CustomDivisor: int(ss.customDivisor),
+		CustomSpeed:   ss.flags&asyncSpdMask == asyncSpdCust,
+		LowLatency:    ss.flags&asyncLowLatency != 0,
+		XmitFifoSize:  int(ss.xmitFifoSize),
+		CloseDelay:    time.Duration(ss.closeDelay) * 10 * time.Millisecond,
+		ClosingWait:   time.Duration(ss.closingWait) * 10 * time.Millisecond,
+	}
+	if ss.closingWait == asyncClosingWaitNone {
+		info.ClosingWait = ClosingWaitNone
+	}
+	return info
+}
+
+func (ss *serialStruct) apply(info SerialInfo) {
+	ss.customDivisor = int32(info.CustomDivisor)
+	ss.flags &^= asyncSpdMask
+	if info.CustomSpeed {
+		ss.flags |= asyncSpdCust
+	}
+	ss.flags &^= asyncLowLatency
+	if info.LowLatency {
+		ss.flags |= asyncLowLatency
+	}
+	ss.xmitFifoSize = int32(info.XmitFifoSize)
+	ss.closeDelay = centiseconds(info.CloseDelay, 0xffff)
+	switch {
+	case info.ClosingWait < 0:
+		ss.closingWait = asyncClosingWaitNone
+	case info.ClosingWait == 0:
+		ss.closingWait = asyncClosingWaitInf
+	default:
+		ss.closingWait = max(centiseconds(info.ClosingWait, asyncClosingWaitNone-1), 1)
+	}
+}
+
+// centiseconds converts d to hundredths of a second, rounded up and capped
+// at limit.
+func centiseconds(d time.Duration, limit uint16) uint16 {
+	d = max(d, 0)
+	return uint16(min((d+10*time.Millisecond-1)/(10*time.Millisecond), time.Duration(limit)))
+}
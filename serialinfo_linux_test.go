@@ -0,0 +1,41 @@
+package serial
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialStruct(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) == 8 {
+		require.EqualValues(t, 72, unsafe.Sizeof(serialStruct{}), "sizeof(struct serial_struct)")
+	}
+	ss := serialStruct{baudBase: 115200, flags: 0x0040 | asyncSpdCust, closeDelay: 50, closingWait: asyncClosingWaitNone}
+	info := ss.info()
+	require.Equal(t, SerialInfo{BaudBase: 115200, CustomSpeed: true, CloseDelay: 500 * time.Millisecond, ClosingWait: ClosingWaitNone}, info)
+
+	info.CustomDivisor, info.CustomSpeed, info.LowLatency = 3, false, true
+	info.ClosingWait = 3 * time.Second
+	ss.apply(info)
+	require.EqualValues(t, 3, ss.customDivisor)
+	require.EqualValues(t, 0x0040|asyncLowLatency, ss.flags, "other flags kept, speed flags cleared")
+	require.EqualValues(t, 300, ss.closingWait)
+	require.EqualValues(t, 50, ss.closeDelay)
+
+	info.ClosingWait = 0
+	ss.apply(info)
+	require.EqualValues(t, asyncClosingWaitInf, ss.closingWait)
+	require.Equal(t, info, ss.info())
+}
+
+func TestSerialReader_SerialInfoUnsupported(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+	_, err := reader.SerialInfo()
+	require.Error(t, err)
+	_, err = reader.SetCustomBaud(31250)
+	require.Error(t, err)
+	_, err = reader.SetCustomBaud(0)
+	require.Error(t, err)
+}
@@ -0,0 +1,17 @@
+package serial
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows COM ports have no serial_struct.
+
+func getSerialInfo(h windows.Handle) (SerialInfo, error) {
+	return SerialInfo{}, errors.ErrUnsupported
+}
+
+func setSerialInfo(h windows.Handle, info SerialInfo) error {
+	return errors.ErrUnsupported
+}
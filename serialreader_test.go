@@ -1,7 +1,15 @@
 package serial
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -143,6 +151,68 @@ func TestSerialReader_WriteLine(t *testing.T) {
 	require.Equal(t, line+newline, string(buf))
 }
 
+func TestSerialReader_WriteLineContext(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	require.NoError(t, reader.WriteLineContext(context.Background(), "ping", "\n"))
+	buf := make([]byte, 5)
+	_, err := io.ReadFull(master, buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping\n", string(buf))
+
+	// Nobody reads the master, so the pty fills up and the write stalls.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = reader.WriteLineContext(ctx, strings.Repeat("x", 1<<20), "\n")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Less(t, time.Since(start), time.Second)
+	require.Positive(t, reader.Stats().BytesWritten)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, reader.WriteLineContext(ctx, "late", "\n"), context.Canceled)
+}
+
+func TestSerialReader_WriteLineNoInterleave(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	const writers, size = 4, 1 << 16 // more than the pty buffer holds
+
+	lines := make(chan string, writers)
+	go func() {
+		br := bufio.NewReader(master)
+		for range writers {
+			line, err := br.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- line
+		}
+	}()
+
+	errs := make(chan error, writers)
+	for i := range writers {
+		go func() {
+			line := strings.Repeat(string(rune('a'+i)), size)
+			if i%2 == 0 {
+				errs <- reader.WriteLine(line, "\n")
+			} else {
+				errs <- reader.WriteLineContext(context.Background(), line, "\n")
+			}
+		}()
+	}
+	for range writers {
+		require.NoError(t, <-errs)
+	}
+
+	for range writers {
+		line, ok := <-lines
+		require.True(t, ok)
+		require.Len(t, line, size+1)
+		require.Equal(t, size, strings.Count(line, line[:1]), "line mixes writers")
+	}
+}
+
 func TestSerialReader_Killability(t *testing.T) {
 	master, slave, err := pty.Open()
 	require.NoError(t, err)
@@ -238,3 +308,231 @@ func TestSerialReader_ErrorPropagation(t *testing.T) {
 		t.Fatal("timeout waiting for error after device disconnect")
 	}
 }
+
+// newTestReader opens a SerialReader on the slave side of a fresh PTY pair and
+// returns it together with the master end. Device is filled in from the PTY.
+func newTestReader(t *testing.T, cfg Config) (*SerialReader, *os.File) {
+	t.Helper()
+	master, slave, err := pty.Open()
+	if errors.Is(err, pty.ErrUnsupported) {
+		t.Skip("no PTYs on this system")
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { master.Close(); slave.Close() })
+
+	cfg.Device = slave.Name()
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 115200
+	}
+	if cfg.Delimiter == "" && !cfg.DetectDelimiter {
+		cfg.Delimiter = "\n"
+	}
+	reader, err := Open(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { reader.Close() })
+	return reader, master
+}
+
+func TestSerialReader_ReopenKeepsState(t *testing.T) {
+	reader, master := newTestReader(t, Config{RingSize: 64})
+	sub := reader.Subscribe(4)
+
+	loopDone := make(chan struct{})
+	go func() {
+		reader.ReadLinesLoop(nil, func(error) {})
+		close(loopDone)
+	}()
+	_, err := master.Write([]byte("before\n"))
+	require.NoError(t, err)
+	require.Equal(t, "before", <-sub.C())
+
+	require.NoError(t, reader.Reopen())
+	select {
+	case <-loopDone:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("loop on the old port did not exit after Reopen")
+	}
+
+	go reader.ReadLinesLoop(nil, func(error) {})
+	_, err = master.Write([]byte("after\n"))
+	require.NoError(t, err)
+	select {
+	case l := <-sub.C():
+		require.Equal(t, "after", l)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("subscription did not survive Reopen")
+	}
+	require.EqualValues(t, len("before\nafter\n"), reader.bytesRead.Load())
+	require.Equal(t, len("before\nafter\n"), reader.Ring().Len())
+
+	require.NoError(t, reader.Close())
+	require.ErrorIs(t, reader.Reopen(), ErrClosed)
+}
+
+func TestSerialReader_CloseStopsReconnect(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+
+	done := make(chan struct{})
+	go func() {
+		reader.ReadLinesWithReconnect(func(string) {}, func(error) {}, 0)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, reader.Close())
+
+	select {
+	case <-done:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("ReadLinesWithReconnect kept running after Close")
+	}
+}
+
+func TestSerialReader_CloseWithTimeoutDeliversBufferedLines(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	var count atomic.Int32
+	started := make(chan struct{})
+	go reader.ReadLinesLoop(func(l string) {
+		if l == "start" {
+			close(started)
+			return
+		}
+		time.Sleep(time.Millisecond) // slow consumer lets a backlog build up
+		count.Add(1)
+	}, func(error) {})
+	_, err := master.Write([]byte("start\n"))
+	require.NoError(t, err)
+	<-started
+
+	const lines = 50
+	for i := 0; i < lines; i++ {
+		_, err := master.Write([]byte(fmt.Sprintf("sample %d\n", i)))
+		require.NoError(t, err)
+	}
+	time.Sleep(10 * time.Millisecond) // let the PTY move the data to the slave side
+	require.NoError(t, reader.CloseWithTimeout(2*time.Second))
+	require.EqualValues(t, lines, count.Load())
+}
+
+func TestSerialReader_CloseWithTimeoutIsBounded(t *testing.T) {
+	reader, master := newTestReader(t, Config{DrainTimeout: 50 * time.Millisecond})
+
+	block := make(chan struct{})
+	defer close(block)
+	go reader.ReadLinesLoop(func(string) { <-block }, func(error) {})
+	_, err := master.Write([]byte("stuck\nqueued\n"))
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, reader.Close())
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestSerialReader_AccessMode(t *testing.T) {
+	ro, master := newTestReader(t, Config{Access: ReadOnly})
+	require.ErrorIs(t, ro.WriteLine("x", "\n"), ErrAccessMode)
+	_, err := master.Write([]byte("monitor\n"))
+	require.NoError(t, err)
+	line, err := ro.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "monitor", line)
+
+	wo, master := newTestReader(t, Config{Access: WriteOnly})
+	_, err = wo.ReadLine()
+	require.ErrorIs(t, err, ErrAccessMode)
+	errs := make(chan error, 1)
+	wo.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	require.ErrorIs(t, <-errs, ErrAccessMode)
+
+	require.NoError(t, wo.WriteLine("cmd", "\n"))
+	buf := make([]byte, 4)
+	n, err := master.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "cmd\n", string(buf[:n]))
+}
+
+func TestOpen_FlushesStaleInput(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		master, slave, err := pty.Open()
+		require.NoError(t, err)
+		t.Cleanup(func() { master.Close(); slave.Close() })
+		_, err = master.Write([]byte("stale\n"))
+		require.NoError(t, err)
+		time.Sleep(20 * time.Millisecond) // let the line discipline queue it
+
+		reader, err := Open(Config{Device: slave.Name(), Delimiter: "\n", KeepStaleInput: keep})
+		require.NoError(t, err)
+		t.Cleanup(func() { reader.Close() })
+		_, err = master.Write([]byte("fresh\n"))
+		require.NoError(t, err)
+		line, err := reader.ReadLine()
+		require.NoError(t, err)
+		if keep {
+			require.Equal(t, "stale", line)
+		} else {
+			require.Equal(t, "fresh", line)
+		}
+	}
+}
+
+func TestSerialReader_BinaryDelimiter(t *testing.T) {
+	reader, master := newTestReader(t, Config{Delimiter: "\xff\x00"})
+	lines := make(chan string, 2)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	_, err := master.Write([]byte("\x01\xfe\xff\x02\xff"))
+	require.NoError(t, err)
+	_, err = master.Write([]byte("\x00\x03\n\xff\x00"))
+	require.NoError(t, err)
+	require.Equal(t, "\x01\xfe\xff\x02", <-lines)
+	require.Equal(t, "\x03\n", <-lines)
+}
+
+func TestSerialReader_Terminator(t *testing.T) {
+	reader, master := newTestReader(t, Config{Terminator: regexp.MustCompile(`\r?\n|>\s$`)})
+	_, err := master.Write([]byte("OK\r\nready\nlogin> "))
+	require.NoError(t, err)
+	lines := make(chan string, 4)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	require.Equal(t, "OK", <-lines)
+	require.Equal(t, "ready", <-lines)
+	require.Equal(t, "login", <-lines)
+}
+
+func TestSerialReader_ReadLineWaitAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	reader, master := newTestReader(t, Config{})
+	stop, err := newWaker()
+	require.NoError(t, err)
+	defer stop.close()
+	// Every call polls: the poll set is built on the stack, with the stop
+	// waker's pipe too, and one-byte lines need no string allocation.
+	allocs := testing.AllocsPerRun(50, func() {
+		if _, err := master.Write([]byte("x\n")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reader.readLine(stop); err != nil {
+			t.Fatal(err)
+		}
+	})
+	require.Zero(t, allocs)
+}
+
+func TestSerialReader_ReadChunkSize(t *testing.T) {
+	// Three-byte reads split the lines, and the delimiter, across chunks.
+	reader, master := newTestReader(t, Config{Delimiter: "\r\n", ReadChunkSize: 3})
+	_, err := master.Write([]byte("hello\r\nworld\r\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "hello", line)
+
+	lines := make(chan string, 2)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	require.Equal(t, "world", <-lines)
+	_, err = master.Write([]byte("ab\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, "ab", <-lines)
+}
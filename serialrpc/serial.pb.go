@@ -0,0 +1,644 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.28.3
+// source: serial.proto
+
+package serialrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Parity int32
+
+const (
+	// Keeps the current parity.
+	Parity_PARITY_UNSPECIFIED Parity = 0
+	Parity_PARITY_NONE        Parity = 1
+	Parity_PARITY_ODD         Parity = 2
+	Parity_PARITY_EVEN        Parity = 3
+	Parity_PARITY_MARK        Parity = 4
+	Parity_PARITY_SPACE       Parity = 5
+)
+
+// Enum value maps for Parity.
+var (
+	Parity_name = map[int32]string{
+		0: "PARITY_UNSPECIFIED",
+		1: "PARITY_NONE",
+		2: "PARITY_ODD",
+		3: "PARITY_EVEN",
+		4: "PARITY_MARK",
+		5: "PARITY_SPACE",
+	}
+	Parity_value = map[string]int32{
+		"PARITY_UNSPECIFIED": 0,
+		"PARITY_NONE":        1,
+		"PARITY_ODD":         2,
+		"PARITY_EVEN":        3,
+		"PARITY_MARK":        4,
+		"PARITY_SPACE":       5,
+	}
+)
+
+func (x Parity) Enum() *Parity {
+	p := new(Parity)
+	*p = x
+	return p
+}
+
+func (x Parity) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Parity) Descriptor() protoreflect.EnumDescriptor {
+	return file_serial_proto_enumTypes[0].Descriptor()
+}
+
+func (Parity) Type() protoreflect.EnumType {
+	return &file_serial_proto_enumTypes[0]
+}
+
+func (x Parity) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Parity.Descriptor instead.
+func (Parity) EnumDescriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{0}
+}
+
+type StreamLinesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Port  string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	// Lines queued for a slow client before the oldest are dropped; 0 means
+	// the server default.
+	Buffer        uint32 `protobuf:"varint,2,opt,name=buffer,proto3" json:"buffer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLinesRequest) Reset() {
+	*x = StreamLinesRequest{}
+	mi := &file_serial_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLinesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLinesRequest) ProtoMessage() {}
+
+func (x *StreamLinesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLinesRequest.ProtoReflect.Descriptor instead.
+func (*StreamLinesRequest) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamLinesRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *StreamLinesRequest) GetBuffer() uint32 {
+	if x != nil {
+		return x.Buffer
+	}
+	return 0
+}
+
+type Line struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	Text          string                 `protobuf:"bytes,3,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Line) Reset() {
+	*x = Line{}
+	mi := &file_serial_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Line) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Line) ProtoMessage() {}
+
+func (x *Line) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Line.ProtoReflect.Descriptor instead.
+func (*Line) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Line) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *Line) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Line) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type WriteLineRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Port  string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	Line  string                 `protobuf:"bytes,2,opt,name=line,proto3" json:"line,omitempty"`
+	// Terminator appended to line; empty means "\r\n".
+	Newline       string `protobuf:"bytes,3,opt,name=newline,proto3" json:"newline,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteLineRequest) Reset() {
+	*x = WriteLineRequest{}
+	mi := &file_serial_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteLineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteLineRequest) ProtoMessage() {}
+
+func (x *WriteLineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteLineRequest.ProtoReflect.Descriptor instead.
+func (*WriteLineRequest) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *WriteLineRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *WriteLineRequest) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+func (x *WriteLineRequest) GetNewline() string {
+	if x != nil {
+		return x.Newline
+	}
+	return ""
+}
+
+type WriteLineResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteLineResponse) Reset() {
+	*x = WriteLineResponse{}
+	mi := &file_serial_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteLineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteLineResponse) ProtoMessage() {}
+
+func (x *WriteLineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteLineResponse.ProtoReflect.Descriptor instead.
+func (*WriteLineResponse) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{3}
+}
+
+type GetStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_serial_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetStatsRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+type Stats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Port          string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	BytesRead     uint64                 `protobuf:"varint,2,opt,name=bytes_read,json=bytesRead,proto3" json:"bytes_read,omitempty"`
+	BytesWritten  uint64                 `protobuf:"varint,3,opt,name=bytes_written,json=bytesWritten,proto3" json:"bytes_written,omitempty"`
+	Lines         uint64                 `protobuf:"varint,4,opt,name=lines,proto3" json:"lines,omitempty"`
+	BadLines      uint64                 `protobuf:"varint,5,opt,name=bad_lines,json=badLines,proto3" json:"bad_lines,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stats) Reset() {
+	*x = Stats{}
+	mi := &file_serial_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stats) ProtoMessage() {}
+
+func (x *Stats) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stats.ProtoReflect.Descriptor instead.
+func (*Stats) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Stats) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *Stats) GetBytesRead() uint64 {
+	if x != nil {
+		return x.BytesRead
+	}
+	return 0
+}
+
+func (x *Stats) GetBytesWritten() uint64 {
+	if x != nil {
+		return x.BytesWritten
+	}
+	return 0
+}
+
+func (x *Stats) GetLines() uint64 {
+	if x != nil {
+		return x.Lines
+	}
+	return 0
+}
+
+func (x *Stats) GetBadLines() uint64 {
+	if x != nil {
+		return x.BadLines
+	}
+	return 0
+}
+
+type ReconfigureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Port  string                 `protobuf:"bytes,1,opt,name=port,proto3" json:"port,omitempty"`
+	// Zero keeps the current baud rate, data bits or stop bits, and
+	// PARITY_UNSPECIFIED the current parity, so a request need only set what
+	// it changes.
+	BaudRate      uint32 `protobuf:"varint,2,opt,name=baud_rate,json=baudRate,proto3" json:"baud_rate,omitempty"`
+	DataBits      uint32 `protobuf:"varint,3,opt,name=data_bits,json=dataBits,proto3" json:"data_bits,omitempty"`
+	Parity        Parity `protobuf:"varint,4,opt,name=parity,proto3,enum=serial.v1.Parity" json:"parity,omitempty"`
+	StopBits      uint32 `protobuf:"varint,5,opt,name=stop_bits,json=stopBits,proto3" json:"stop_bits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconfigureRequest) Reset() {
+	*x = ReconfigureRequest{}
+	mi := &file_serial_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigureRequest) ProtoMessage() {}
+
+func (x *ReconfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigureRequest.ProtoReflect.Descriptor instead.
+func (*ReconfigureRequest) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReconfigureRequest) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+func (x *ReconfigureRequest) GetBaudRate() uint32 {
+	if x != nil {
+		return x.BaudRate
+	}
+	return 0
+}
+
+func (x *ReconfigureRequest) GetDataBits() uint32 {
+	if x != nil {
+		return x.DataBits
+	}
+	return 0
+}
+
+func (x *ReconfigureRequest) GetParity() Parity {
+	if x != nil {
+		return x.Parity
+	}
+	return Parity_PARITY_UNSPECIFIED
+}
+
+func (x *ReconfigureRequest) GetStopBits() uint32 {
+	if x != nil {
+		return x.StopBits
+	}
+	return 0
+}
+
+type ReconfigureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReconfigureResponse) Reset() {
+	*x = ReconfigureResponse{}
+	mi := &file_serial_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReconfigureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReconfigureResponse) ProtoMessage() {}
+
+func (x *ReconfigureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_serial_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReconfigureResponse.ProtoReflect.Descriptor instead.
+func (*ReconfigureResponse) Descriptor() ([]byte, []int) {
+	return file_serial_proto_rawDescGZIP(), []int{7}
+}
+
+var File_serial_proto protoreflect.FileDescriptor
+
+const file_serial_proto_rawDesc = "" +
+	"\n" +
+	"\fserial.proto\x12\tserial.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"@\n" +
+	"\x12StreamLinesRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x16\n" +
+	"\x06buffer\x18\x02 \x01(\rR\x06buffer\"^\n" +
+	"\x04Line\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04text\x18\x03 \x01(\tR\x04text\"T\n" +
+	"\x10WriteLineRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x12\n" +
+	"\x04line\x18\x02 \x01(\tR\x04line\x12\x18\n" +
+	"\anewline\x18\x03 \x01(\tR\anewline\"\x13\n" +
+	"\x11WriteLineResponse\"%\n" +
+	"\x0fGetStatsRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\"\x92\x01\n" +
+	"\x05Stats\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x1d\n" +
+	"\n" +
+	"bytes_read\x18\x02 \x01(\x04R\tbytesRead\x12#\n" +
+	"\rbytes_written\x18\x03 \x01(\x04R\fbytesWritten\x12\x14\n" +
+	"\x05lines\x18\x04 \x01(\x04R\x05lines\x12\x1b\n" +
+	"\tbad_lines\x18\x05 \x01(\x04R\bbadLines\"\xaa\x01\n" +
+	"\x12ReconfigureRequest\x12\x12\n" +
+	"\x04port\x18\x01 \x01(\tR\x04port\x12\x1b\n" +
+	"\tbaud_rate\x18\x02 \x01(\rR\bbaudRate\x12\x1b\n" +
+	"\tdata_bits\x18\x03 \x01(\rR\bdataBits\x12)\n" +
+	"\x06parity\x18\x04 \x01(\x0e2\x11.serial.v1.ParityR\x06parity\x12\x1b\n" +
+	"\tstop_bits\x18\x05 \x01(\rR\bstopBits\"\x15\n" +
+	"\x13ReconfigureResponse*u\n" +
+	"\x06Parity\x12\x16\n" +
+	"\x12PARITY_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vPARITY_NONE\x10\x01\x12\x0e\n" +
+	"\n" +
+	"PARITY_ODD\x10\x02\x12\x0f\n" +
+	"\vPARITY_EVEN\x10\x03\x12\x0f\n" +
+	"\vPARITY_MARK\x10\x04\x12\x10\n" +
+	"\fPARITY_SPACE\x10\x052\xa0\x02\n" +
+	"\rSerialService\x12?\n" +
+	"\vStreamLines\x12\x1d.serial.v1.StreamLinesRequest\x1a\x0f.serial.v1.Line0\x01\x12F\n" +
+	"\tWriteLine\x12\x1b.serial.v1.WriteLineRequest\x1a\x1c.serial.v1.WriteLineResponse\x128\n" +
+	"\bGetStats\x12\x1a.serial.v1.GetStatsRequest\x1a\x10.serial.v1.Stats\x12L\n" +
+	"\vReconfigure\x12\x1d.serial.v1.ReconfigureRequest\x1a\x1e.serial.v1.ReconfigureResponseB3Z1github.com/luhtfiimanal/go-linux-serial/serialrpcb\x06proto3"
+
+var (
+	file_serial_proto_rawDescOnce sync.Once
+	file_serial_proto_rawDescData []byte
+)
+
+func file_serial_proto_rawDescGZIP() []byte {
+	file_serial_proto_rawDescOnce.Do(func() {
+		file_serial_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_serial_proto_rawDesc), len(file_serial_proto_rawDesc)))
+	})
+	return file_serial_proto_rawDescData
+}
+
+var file_serial_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_serial_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_serial_proto_goTypes = []any{
+	(Parity)(0),                   // 0: serial.v1.Parity
+	(*StreamLinesRequest)(nil),    // 1: serial.v1.StreamLinesRequest
+	(*Line)(nil),                  // 2: serial.v1.Line
+	(*WriteLineRequest)(nil),      // 3: serial.v1.WriteLineRequest
+	(*WriteLineResponse)(nil),     // 4: serial.v1.WriteLineResponse
+	(*GetStatsRequest)(nil),       // 5: serial.v1.GetStatsRequest
+	(*Stats)(nil),                 // 6: serial.v1.Stats
+	(*ReconfigureRequest)(nil),    // 7: serial.v1.ReconfigureRequest
+	(*ReconfigureResponse)(nil),   // 8: serial.v1.ReconfigureResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+}
+var file_serial_proto_depIdxs = []int32{
+	9, // 0: serial.v1.Line.time:type_name -> google.protobuf.Timestamp
+	0, // 1: serial.v1.ReconfigureRequest.parity:type_name -> serial.v1.Parity
+	1, // 2: serial.v1.SerialService.StreamLines:input_type -> serial.v1.StreamLinesRequest
+	3, // 3: serial.v1.SerialService.WriteLine:input_type -> serial.v1.WriteLineRequest
+	5, // 4: serial.v1.SerialService.GetStats:input_type -> serial.v1.GetStatsRequest
+	7, // 5: serial.v1.SerialService.Reconfigure:input_type -> serial.v1.ReconfigureRequest
+	2, // 6: serial.v1.SerialService.StreamLines:output_type -> serial.v1.Line
+	4, // 7: serial.v1.SerialService.WriteLine:output_type -> serial.v1.WriteLineResponse
+	6, // 8: serial.v1.SerialService.GetStats:output_type -> serial.v1.Stats
+	8, // 9: serial.v1.SerialService.Reconfigure:output_type -> serial.v1.ReconfigureResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_serial_proto_init() }
+func file_serial_proto_init() {
+	if File_serial_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_serial_proto_rawDesc), len(file_serial_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_serial_proto_goTypes,
+		DependencyIndexes: file_serial_proto_depIdxs,
+		EnumInfos:         file_serial_proto_enumTypes,
+		MessageInfos:      file_serial_proto_msgTypes,
+	}.Build()
+	File_serial_proto = out.File
+	file_serial_proto_goTypes = nil
+	file_serial_proto_depIdxs = nil
+}
@@ -0,0 +1,253 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.28.3
+// source: serial.proto
+
+package serialrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SerialService_StreamLines_FullMethodName = "/serial.v1.SerialService/StreamLines"
+	SerialService_WriteLine_FullMethodName   = "/serial.v1.SerialService/WriteLine"
+	SerialService_GetStats_FullMethodName    = "/serial.v1.SerialService/GetStats"
+	SerialService_Reconfigure_FullMethodName = "/serial.v1.SerialService/Reconfigure"
+)
+
+// SerialServiceClient is the client API for SerialService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SerialService exposes the serial ports of a gateway to other services.
+type SerialServiceClient interface {
+	// StreamLines streams the lines received on a port until the client
+	// cancels or the port is closed.
+	StreamLines(ctx context.Context, in *StreamLinesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Line], error)
+	// WriteLine writes one line to a port.
+	WriteLine(ctx context.Context, in *WriteLineRequest, opts ...grpc.CallOption) (*WriteLineResponse, error)
+	// GetStats returns the I/O counters of a port.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error)
+	// Reconfigure changes the line settings of an open port.
+	Reconfigure(ctx context.Context, in *ReconfigureRequest, opts ...grpc.CallOption) (*ReconfigureResponse, error)
+}
+
+type serialServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSerialServiceClient(cc grpc.ClientConnInterface) SerialServiceClient {
+	return &serialServiceClient{cc}
+}
+
+func (c *serialServiceClient) StreamLines(ctx context.Context, in *StreamLinesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Line], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SerialService_ServiceDesc.Streams[0], SerialService_StreamLines_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamLinesRequest, Line]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SerialService_StreamLinesClient = grpc.ServerStreamingClient[Line]
+
+func (c *serialServiceClient) WriteLine(ctx context.Context, in *WriteLineRequest, opts ...grpc.CallOption) (*WriteLineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteLineResponse)
+	err := c.cc.Invoke(ctx, SerialService_WriteLine_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serialServiceClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*Stats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, SerialService_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serialServiceClient) Reconfigure(ctx context.Context, in *ReconfigureRequest, opts ...grpc.CallOption) (*ReconfigureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReconfigureResponse)
+	err := c.cc.Invoke(ctx, SerialService_Reconfigure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SerialServiceServer is the server API for SerialService service.
+// All implementations must embed UnimplementedSerialServiceServer
+// for forward compatibility.
+//
+// SerialService exposes the serial ports of a gateway to other services.
+type SerialServiceServer interface {
+	// StreamLines streams the lines received on a port until the client
+	// cancels or the port is closed.
+	StreamLines(*StreamLinesRequest, grpc.ServerStreamingServer[Line]) error
+	// WriteLine writes one line to a port.
+	WriteLine(context.Context, *WriteLineRequest) (*WriteLineResponse, error)
+	// GetStats returns the I/O counters of a port.
+	GetStats(context.Context, *GetStatsRequest) (*Stats, error)
+	// Reconfigure changes the line settings of an open port.
+	Reconfigure(context.Context, *ReconfigureRequest) (*ReconfigureResponse, error)
+	mustEmbedUnimplementedSerialServiceServer()
+}
+
+// UnimplementedSerialServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSerialServiceServer struct{}
+
+func (UnimplementedSerialServiceServer) StreamLines(*StreamLinesRequest, grpc.ServerStreamingServer[Line]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLines not implemented")
+}
+func (UnimplementedSerialServiceServer) WriteLine(context.Context, *WriteLineRequest) (*WriteLineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteLine not implemented")
+}
+func (UnimplementedSerialServiceServer) GetStats(context.Context, *GetStatsRequest) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedSerialServiceServer) Reconfigure(context.Context, *ReconfigureRequest) (*ReconfigureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reconfigure not implemented")
+}
+func (UnimplementedSerialServiceServer) mustEmbedUnimplementedSerialServiceServer() {}
+func (UnimplementedSerialServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSerialServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SerialServiceServer will
+// result in compilation errors.
+type UnsafeSerialServiceServer interface {
+	mustEmbedUnimplementedSerialServiceServer()
+}
+
+func RegisterSerialServiceServer(s grpc.ServiceRegistrar, srv SerialServiceServer) {
+	// If the following call pancis, it indicates UnimplementedSerialServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SerialService_ServiceDesc, srv)
+}
+
+func _SerialService_StreamLines_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamLinesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SerialServiceServer).StreamLines(m, &grpc.GenericServerStream[StreamLinesRequest, Line]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SerialService_StreamLinesServer = grpc.ServerStreamingServer[Line]
+
+func _SerialService_WriteLine_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteLineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SerialServiceServer).WriteLine(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SerialService_WriteLine_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SerialServiceServer).WriteLine(ctx, req.(*WriteLineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SerialService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SerialServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SerialService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SerialServiceServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SerialService_Reconfigure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReconfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SerialServiceServer).Reconfigure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SerialService_Reconfigure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SerialServiceServer).Reconfigure(ctx, req.(*ReconfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SerialService_ServiceDesc is the grpc.ServiceDesc for SerialService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SerialService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "serial.v1.SerialService",
+	HandlerType: (*SerialServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "WriteLine",
+			Handler:    _SerialService_WriteLine_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _SerialService_GetStats_Handler,
+		},
+		{
+			MethodName: "Reconfigure",
+			Handler:    _SerialService_Reconfigure_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLines",
+			Handler:       _SerialService_StreamLines_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "serial.proto",
+}
@@ -0,0 +1,154 @@
+// Package serialrpc is a gRPC service that exposes one or more SerialReaders
+// to other services on a gateway: streaming received lines, writing lines,
+// reading counters and changing line settings. The service is defined in
+// serial.proto; register a Server with grpc.Server via
+// RegisterSerialServiceServer.
+package serialrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative serial.proto
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultBuffer is the per-stream line queue when the client asks for none.
+const defaultBuffer = 256
+
+// Server implements SerialServiceServer over a set of named ports. Lines are
+// only streamed while the application runs a ReadLinesLoop on the port.
+type Server struct {
+	UnimplementedSerialServiceServer
+
+	mu    sync.RWMutex
+	ports map[string]*serial.SerialReader
+}
+
+// NewServer returns a Server with no ports.
+func NewServer() *Server {
+	return &Server{ports: make(map[string]*serial.SerialReader)}
+}
+
+// Add makes r available under name, replacing any port with that name.
+func (s *Server) Add(name string, r *serial.SerialReader) {
+	s.mu.Lock()
+	s.ports[name] = r
+	s.mu.Unlock()
+}
+
+// Remove withdraws the port name. Running streams on it continue until the
+// port is closed.
+func (s *Server) Remove(name string) {
+	s.mu.Lock()
+	delete(s.ports, name)
+	s.mu.Unlock()
+}
+
+func (s *Server) port(name string) (*serial.SerialReader, error) {
+	s.mu.RLock()
+	r, ok := s.ports[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown port %q", name)
+	}
+	return r, nil
+}
+
+// StreamLines implements SerialServiceServer.
+func (s *Server) StreamLines(req *StreamLinesRequest, stream SerialService_StreamLinesServer) error {
+	r, err := s.port(req.GetPort())
+	if err != nil {
+		return err
+	}
+	buffer := int(req.GetBuffer())
+	if buffer == 0 {
+		buffer = defaultBuffer
+	}
+	sub := r.Subscribe(buffer)
+	sub.SetPolicy(serial.DropOldest)
+	defer sub.Unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case line, ok := <-sub.C():
+			if !ok {
+				return status.Error(codes.Unavailable, "port closed")
+			}
+			msg := &Line{Port: req.GetPort(), Time: timestamppb.New(time.Now()), Text: line}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WriteLine implements SerialServiceServer.
+func (s *Server) WriteLine(_ context.Context, req *WriteLineRequest) (*WriteLineResponse, error) {
+	r, err := s.port(req.GetPort())
+	if err != nil {
+		return nil, err
+	}
+	newline := req.GetNewline()
+	if newline == "" {
+		newline = "\r\n"
+	}
+	if err := r.WriteLine(req.GetLine(), newline); err != nil {
+		return nil, toStatus(err, codes.Internal)
+	}
+	return &WriteLineResponse{}, nil
+}
+
+// GetStats implements SerialServiceServer.
+func (s *Server) GetStats(_ context.Context, req *GetStatsRequest) (*Stats, error) {
+	r, err := s.port(req.GetPort())
+	if err != nil {
+		return nil, err
+	}
+	st := r.Stats()
+	return &Stats{
+		Port:         req.GetPort(),
+		BytesRead:    st.BytesRead,
+		BytesWritten: st.BytesWritten,
+		Lines:        st.Lines,
+		BadLines:     st.BadLines,
+	}, nil
+}
+
+// Reconfigure implements SerialServiceServer.
+func (s *Server) Reconfigure(_ context.Context, req *ReconfigureRequest) (*ReconfigureResponse, error) {
+	r, err := s.port(req.GetPort())
+	if err != nil {
+		return nil, err
+	}
+	parity := r.Config().Parity
+	if p := req.GetParity(); p != Parity_PARITY_UNSPECIFIED {
+		parity = serial.Parity(p - Parity_PARITY_NONE)
+	}
+	err = r.SetLineSettings(int(req.GetBaudRate()), int(req.GetDataBits()), parity, int(req.GetStopBits()))
+	if err != nil {
+		return nil, toStatus(err, codes.InvalidArgument)
+	}
+	return &ReconfigureResponse{}, nil
+}
+
+// toStatus maps port errors to gRPC codes, using fallback for the rest.
+func toStatus(err error, fallback codes.Code) error {
+	code := fallback
+	switch {
+	case errors.Is(err, serial.ErrClosed), errors.Is(err, serial.ErrDeviceRemoved):
+		code = codes.Unavailable
+	case errors.Is(err, serial.ErrAccessMode):
+		code = codes.FailedPrecondition
+	case errors.Is(err, serial.ErrTimeout):
+		code = codes.DeadlineExceeded
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,86 @@
+package serialrpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newTestClient(t *testing.T) (SerialServiceClient, *serial.SerialReader, *serialtest.Simulator) {
+	t.Helper()
+	sim, err := serialtest.NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	port, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\r\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+	go port.ReadLinesLoop(nil, func(error) {})
+
+	srv := NewServer()
+	srv.Add("seis1", port)
+	gs := grpc.NewServer()
+	RegisterSerialServiceServer(gs, srv)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go gs.Serve(l)
+	t.Cleanup(gs.Stop)
+
+	cc, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+	return NewSerialServiceClient(cc), port, sim
+}
+
+func TestServer(t *testing.T) {
+	client, port, sim := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamLines(ctx, &StreamLinesRequest{Port: "seis1"})
+	require.NoError(t, err)
+	time.Sleep(50 * time.Millisecond) // let the server subscribe
+	require.NoError(t, sim.Send("1,2,3"))
+	line, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "seis1", line.GetPort())
+	require.Equal(t, "1,2,3", line.GetText())
+	require.WithinDuration(t, time.Now(), line.GetTime().AsTime(), time.Second)
+
+	_, err = client.WriteLine(ctx, &WriteLineRequest{Port: "seis1", Line: "C,START"})
+	require.NoError(t, err)
+	cmd, err := sim.Expect(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "C,START", cmd)
+
+	stats, err := client.GetStats(ctx, &GetStatsRequest{Port: "seis1"})
+	require.NoError(t, err)
+	require.EqualValues(t, len("C,START\r\n"), stats.GetBytesWritten())
+	require.NotZero(t, stats.GetLines())
+
+	_, err = client.Reconfigure(ctx, &ReconfigureRequest{Port: "seis1", Parity: Parity_PARITY_EVEN})
+	require.NoError(t, err)
+	require.Equal(t, serial.ParityEven, port.Config().Parity)
+	// Unset fields, parity included, keep their current values.
+	_, err = client.Reconfigure(ctx, &ReconfigureRequest{Port: "seis1", BaudRate: 9600})
+	require.NoError(t, err)
+	require.Equal(t, 9600, port.Config().BaudRate)
+	require.Equal(t, serial.ParityEven, port.Config().Parity)
+	_, err = client.Reconfigure(ctx, &ReconfigureRequest{Port: "seis1", DataBits: 9})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetStats(ctx, &GetStatsRequest{Port: "nope"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	port.Close()
+	_, err = stream.Recv()
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,216 @@
+// Package serialtest provides an in-memory stand-in for a serial port so that
+// code written against the serial package's interfaces can be unit-tested
+// hermetically, without a tty or PTY.
+//
+// A Fake is scripted: lines and errors are queued for the reader with Emit
+// and EmitError, replies to specific commands are registered with Respond,
+// and delays and write failures can be injected to exercise timeout and
+// error handling paths.
+//
+//	fake := serialtest.New()
+//	fake.Respond("VER?", "v1.2")
+//	v, err := queryVersion(fake.Opener(), cfg) // your code under test
+package serialtest
+
+import (
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// Fake is an in-memory serial.LineReadWriter. It is safe for concurrent use.
+type Fake struct {
+	mu        sync.Mutex
+	queue     []event
+	notify    chan struct{}
+	done      chan struct{}
+	closed    bool
+	writes    []string
+	responses map[string][]string
+	onWrite   func(line string) []string
+	writeErr  error
+	delay     time.Duration
+}
+
+type event struct {
+	line  string
+	err   error
+	delay time.Duration
+}
+
+var _ serial.LineReadWriter = (*Fake)(nil)
+
+// New returns an empty Fake.
+func New() *Fake {
+	return &Fake{
+		notify:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		responses: make(map[string][]string),
+	}
+}
+
+// Opener returns a serial.Opener that hands out f regardless of Config.
+func (f *Fake) Opener() serial.Opener {
+	return serial.OpenerFunc(func(serial.Config) (serial.LineReadWriter, error) {
+		return f, nil
+	})
+}
+
+// Emit queues lines to be returned by ReadLine or delivered by ReadLinesLoop.
+func (f *Fake) Emit(lines ...string) {
+	f.EmitDelayed(0, lines...)
+}
+
+// EmitDelayed queues lines, each delivered d after the reader starts waiting for it.
+func (f *Fake) EmitDelayed(d time.Duration, lines ...string) {
+	f.mu.Lock()
+	for _, l := range lines {
+		f.queue = append(f.queue, event{line: l, delay: d})
+	}
+	f.mu.Unlock()
+	f.wake()
+}
+
+// EmitError queues err to be returned by the read side in order with queued lines.
+func (f *Fake) EmitError(err error) {
+	f.mu.Lock()
+	f.queue = append(f.queue, event{err: err})
+	f.mu.Unlock()
+	f.wake()
+}
+
+// Respond makes a WriteLine of exactly cmd (without its newline) queue replies.
+func (f *Fake) Respond(cmd string, replies ...string) {
+	f.mu.Lock()
+	f.responses[cmd] = replies
+	f.mu.Unlock()
+}
+
+// OnWrite sets a function that computes replies for written lines that have no
+// Respond entry.
+func (f *Fake) OnWrite(fn func(line string) []string) {
+	f.mu.Lock()
+	f.onWrite = fn
+	f.mu.Unlock()
+}
+
+// SetReplyDelay sets the delay applied to replies queued by Respond and OnWrite.
+func (f *Fake) SetReplyDelay(d time.Duration) {
+	f.mu.Lock()
+	f.delay = d
+	f.mu.Unlock()
+}
+
+// FailNextWrite makes the next WriteLine return err without recording the line.
+func (f *Fake) FailNextWrite(err error) {
+	f.mu.Lock()
+	f.writeErr = err
+	f.mu.Unlock()
+}
+
+// Writes returns the lines written so far, without their newlines.
+func (f *Fake) Writes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.writes...)
+}
+
+// Closed reports whether Close has been called.
+func (f *Fake) Closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+// ReadLine returns the next queued line or error, blocking until one is
+// queued. It returns serial.ErrClosed after Close.
+func (f *Fake) ReadLine() (string, error) {
+	for {
+		f.mu.Lock()
+		if f.closed {
+			f.mu.Unlock()
+			return "", serial.ErrClosed
+		}
+		if len(f.queue) > 0 {
+			ev := f.queue[0]
+			f.queue = f.queue[1:]
+			f.mu.Unlock()
+			if ev.delay > 0 {
+				select {
+				case <-time.After(ev.delay):
+				case <-f.done:
+					return "", serial.ErrClosed
+				}
+			}
+			return ev.line, ev.err
+		}
+		f.mu.Unlock()
+		select {
+		case <-f.notify:
+		case <-f.done:
+		}
+	}
+}
+
+// ReadLinesLoop delivers queued lines to onLine until Close or an injected
+// error, which is passed to onError and ends the loop.
+func (f *Fake) ReadLinesLoop(onLine func(string), onError func(error)) {
+	for {
+		line, err := f.ReadLine()
+		if err == serial.ErrClosed {
+			return
+		}
+		if err != nil {
+			onError(err)
+			return
+		}
+		onLine(line)
+	}
+}
+
+// WriteLine records line and queues any scripted replies.
+func (f *Fake) WriteLine(line, newline string) error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return serial.ErrClosed
+	}
+	if err := f.writeErr; err != nil {
+		f.writeErr = nil
+		f.mu.Unlock()
+		return err
+	}
+	f.writes = append(f.writes, line)
+	replies, ok := f.responses[line]
+	if !ok && f.onWrite != nil {
+		replies = f.onWrite(line)
+	}
+	for _, r := range replies {
+		f.queue = append(f.queue, event{line: r, delay: f.delay})
+	}
+	f.mu.Unlock()
+	if len(replies) > 0 {
+		f.wake()
+	}
+	return nil
+}
+
+// Close unblocks readers; later calls return serial.ErrClosed.
+// Safe to call multiple times.
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.done)
+	}
+	return nil
+}
+
+func (f *Fake) wake() {
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,79 @@
+package serialtest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake_ScriptedResponses(t *testing.T) {
+	f := New()
+	f.Respond("VER?", "v1.2", "OK")
+	f.OnWrite(func(line string) []string { return []string{"ECHO " + line} })
+
+	rw, err := f.Opener().Open(serial.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, rw.WriteLine("VER?", "\r\n"))
+	require.NoError(t, rw.WriteLine("PING", "\r\n"))
+	for _, want := range []string{"v1.2", "OK", "ECHO PING"} {
+		got, err := rw.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+	require.Equal(t, []string{"VER?", "PING"}, f.Writes())
+}
+
+func TestFake_InjectedErrorsAndDelays(t *testing.T) {
+	f := New()
+	boom := errors.New("boom")
+	f.FailNextWrite(boom)
+	require.ErrorIs(t, f.WriteLine("x", "\n"), boom)
+	require.NoError(t, f.WriteLine("y", "\n"))
+	require.Equal(t, []string{"y"}, f.Writes())
+
+	f.EmitDelayed(20*time.Millisecond, "late")
+	f.EmitError(serial.ErrDeviceRemoved)
+
+	var lines []string
+	var loopErr error
+	start := time.Now()
+	f.ReadLinesLoop(func(l string) { lines = append(lines, l) }, func(err error) { loopErr = err })
+	require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	require.Equal(t, []string{"late"}, lines)
+	require.ErrorIs(t, loopErr, serial.ErrDeviceRemoved)
+}
+
+func TestFake_CloseUnblocks(t *testing.T) {
+	f := New()
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.ReadLine()
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, f.Close())
+	require.ErrorIs(t, <-done, serial.ErrClosed)
+	require.True(t, f.Closed())
+	require.ErrorIs(t, f.WriteLine("x", "\n"), serial.ErrClosed)
+}
+
+func TestFake_WithRouter(t *testing.T) {
+	f := New()
+	f.Emit("$GPGGA,1", "ERR 3", "data")
+	r := serial.NewRouter()
+	var got []string
+	r.HandlePrefix("ERR", func(l string) { got = append(got, l) })
+	r.Default(func(l string) {
+		got = append(got, strings.ToUpper(l))
+		if l == "data" {
+			f.Close()
+		}
+	})
+	f.ReadLinesLoop(r.Dispatch, func(error) {})
+	require.Equal(t, []string{"$GPGGA,1", "ERR 3", "DATA"}, got)
+}
@@ -0,0 +1,178 @@
+package serialtest
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Faults configures realistic transmission faults applied by Simulator.Send.
+// Probabilities are per line, in [0, 1]. The zero value injects nothing.
+type Faults struct {
+	// SplitProbability is the chance a line is written in two parts,
+	// SplitDelay apart, so it arrives across separate reads.
+	SplitProbability float64
+	SplitDelay       time.Duration
+
+	// GarbageProbability is the chance that up to GarbageLen random bytes
+	// (default 8) are written before the line.
+	GarbageProbability float64
+	GarbageLen         int
+
+	// NoiseProbability is the chance a line is replaced by the kind of byte
+	// soup a receiver sees when the baud rate is wrong.
+	NoiseProbability float64
+
+	// StallProbability is the chance the device goes quiet for StallDuration
+	// before sending the line.
+	StallProbability float64
+	StallDuration    time.Duration
+
+	// DisconnectAfter, if positive, disconnects the simulator after that many
+	// lines have been sent.
+	DisconnectAfter int
+
+	// Seed makes the fault pattern reproducible; zero picks a random seed.
+	Seed uint64
+}
+
+// wrongBaudBytes are typical of framing-error garbage from a baud mismatch:
+// mostly high bits set, with stray zero bytes from breaks.
+var wrongBaudBytes = []byte{0x00, 0x80, 0xe0, 0xf0, 0xf8, 0xfc, 0xfe, 0xff, 0x78, 0x1e, 0x86, 0x98}
+
+// SetFaults installs the fault configuration used by subsequent Sends.
+func (s *Simulator) SetFaults(f Faults) {
+	seed := f.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	s.mu.Lock()
+	s.faults = f
+	s.rng = rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	s.sent = 0
+	s.mu.Unlock()
+}
+
+// SendSplit writes line plus newline in parts of roughly equal size, pausing
+// delay between them.
+func (s *Simulator) SendSplit(line string, parts int, delay time.Duration) error {
+	b := []byte(line + s.newline)
+	if parts < 1 {
+		parts = 1
+	}
+	size := (len(b) + parts - 1) / parts
+	for len(b) > 0 {
+		n := min(size, len(b))
+		if err := s.SendRaw(b[:n]); err != nil {
+			return err
+		}
+		b = b[n:]
+		if len(b) > 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
+
+// SendGarbage writes n random bytes that never contain the newline.
+func (s *Simulator) SendGarbage(n int) error {
+	s.mu.Lock()
+	b := s.garbage(n)
+	s.mu.Unlock()
+	return s.SendRaw(b)
+}
+
+// SendNoise writes n bytes resembling wrong-baud-rate reception.
+func (s *Simulator) SendNoise(n int) error {
+	s.mu.Lock()
+	b := s.noise(n)
+	s.mu.Unlock()
+	return s.SendRaw(b)
+}
+
+// sendWithFaults implements Send, applying the configured Faults.
+func (s *Simulator) sendWithFaults(line string) error {
+	s.mu.Lock()
+	f := s.faults
+	if s.rng == nil {
+		s.mu.Unlock()
+		return s.SendRaw([]byte(line + s.newline))
+	}
+	chance := func(p float64) bool { return p > 0 && s.rng.Float64() < p }
+	stall := chance(f.StallProbability)
+	var prefix []byte
+	if chance(f.GarbageProbability) {
+		n := f.GarbageLen
+		if n <= 0 {
+			n = 8
+		}
+		prefix = s.garbage(1 + s.rng.IntN(n))
+	}
+	body := []byte(line + s.newline)
+	if chance(f.NoiseProbability) {
+		body = s.noise(len(body))
+	}
+	payload := append(prefix, body...)
+	splitAt := 0
+	if chance(f.SplitProbability) && len(payload) > 1 {
+		splitAt = 1 + s.rng.IntN(len(payload)-1)
+	}
+	s.sent++
+	disconnect := f.DisconnectAfter > 0 && s.sent >= f.DisconnectAfter
+	s.mu.Unlock()
+
+	if stall {
+		time.Sleep(f.StallDuration)
+	}
+	var err error
+	if splitAt > 0 {
+		if err = s.SendRaw(payload[:splitAt]); err == nil {
+			time.Sleep(f.SplitDelay)
+			err = s.SendRaw(payload[splitAt:])
+		}
+	} else {
+		err = s.SendRaw(payload)
+	}
+	if err == nil && disconnect {
+		err = s.Disconnect()
+	}
+	return err
+}
+
+// garbage returns n random bytes excluding the newline's bytes. s.mu must be held.
+func (s *Simulator) garbage(n int) []byte {
+	r := s.rand()
+	b := make([]byte, 0, n)
+	for len(b) < n {
+		c := byte(r.UintN(256))
+		if !containsByte(s.newline, c) {
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// noise returns n wrong-baud bytes. s.mu must be held.
+func (s *Simulator) noise(n int) []byte {
+	r := s.rand()
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = wrongBaudBytes[r.IntN(len(wrongBaudBytes))]
+	}
+	return b
+}
+
+func (s *Simulator) rand() *rand.Rand {
+	if s.rng == nil {
+		s.rng = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return s.rng
+}
+
+func containsByte(s string, c byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == c {
+			return true
+		}
+	}
+	return false
+}
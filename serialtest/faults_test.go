@@ -0,0 +1,90 @@
+package serialtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulator_SplitLinesReassemble(t *testing.T) {
+	sim, err := NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	sim.SetFaults(Faults{SplitProbability: 1, SplitDelay: 2 * time.Millisecond, Seed: 1})
+	r := openSim(t, sim)
+
+	lines := make(chan string, 10)
+	go r.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	for i := 0; i < 10; i++ {
+		require.NoError(t, sim.Send(fmt.Sprintf("sample,%d", i)))
+	}
+	require.NoError(t, sim.SendSplit("tail", 4, time.Millisecond))
+	for i := 0; i < 10; i++ {
+		require.Equal(t, fmt.Sprintf("sample,%d", i), <-lines)
+	}
+	require.Equal(t, "tail", <-lines)
+}
+
+func TestSimulator_GarbageAndNoise(t *testing.T) {
+	sim, err := NewSimulator("\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	sim.SetFaults(Faults{GarbageProbability: 1, GarbageLen: 4, Seed: 7})
+	r, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	require.NoError(t, sim.Send("value"))
+	line, err := r.ReadLine()
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(line, "value"))
+	require.Greater(t, len(line), len("value"))
+
+	// Noise replaces the line, delimiter included, so it runs into the next one.
+	sim.SetFaults(Faults{NoiseProbability: 1, Seed: 7})
+	require.NoError(t, sim.Send("value"))
+	sim.SetFaults(Faults{})
+	require.NoError(t, sim.SendNoise(3))
+	require.NoError(t, sim.Send("ok"))
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.NotContains(t, line, "value")
+	require.Len(t, line, len("value\n")+3+len("ok"))
+}
+
+func TestSimulator_DisconnectAfter(t *testing.T) {
+	sim, err := NewSimulator("\r\n")
+	require.NoError(t, err)
+	sim.SetFaults(Faults{DisconnectAfter: 3})
+	r := openSim(t, sim)
+
+	lines := make(chan string, 3)
+	errs := make(chan error, 1)
+	go r.ReadLinesLoop(func(l string) { lines <- l }, func(err error) { errs <- err })
+	for i := 0; i < 3; i++ {
+		require.NoError(t, sim.Send("x"))
+	}
+	require.Error(t, sim.Send("after"))
+
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, serial.ErrDeviceRemoved)
+	case <-time.After(time.Second):
+		t.Fatal("no disconnect error")
+	}
+}
+
+func TestSimulator_Stall(t *testing.T) {
+	sim, err := NewSimulator("\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	sim.SetFaults(Faults{StallProbability: 1, StallDuration: 30 * time.Millisecond})
+
+	start := time.Now()
+	require.NoError(t, sim.Send("late"))
+	require.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
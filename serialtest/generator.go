@@ -0,0 +1,102 @@
+package serialtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generator emits lines at a fixed rate for soak and latency testing. Lines
+// are scheduled against absolute ticks, so the long-run rate does not drift
+// even when individual sends are late.
+//
+// Send it into a Simulator (sim.Send) or, through a small adapter, a real port:
+//
+//	g := serialtest.Generator{Rate: 200, Jitter: 500 * time.Microsecond}
+//	go g.Run(ctx, sim.Send)
+//
+// The default line format embeds a sequence number and the send time, which
+// Latency decodes on the receiving side.
+type Generator struct {
+	Rate   float64       // lines per second; must be positive
+	Jitter time.Duration // each line is shifted by a uniform random offset in [-Jitter, +Jitter]
+	Count  int           // number of lines to send; zero means until ctx is done
+
+	// Line formats line seq sent at t. Nil uses FormatSample.
+	Line func(seq uint64, t time.Time) string
+}
+
+// Run sends lines through send until Count lines are sent, ctx is done or send
+// fails. It returns the number of lines sent; reaching Count returns a nil
+// error, cancellation returns ctx.Err().
+func (g *Generator) Run(ctx context.Context, send func(line string) error) (int, error) {
+	if g.Rate <= 0 {
+		return 0, errors.New("serialtest: generator rate must be positive")
+	}
+	format := g.Line
+	if format == nil {
+		format = FormatSample
+	}
+	period := time.Duration(float64(time.Second) / g.Rate)
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for seq := uint64(0); g.Count == 0 || seq < uint64(g.Count); seq++ {
+		due := start.Add(time.Duration(seq) * period)
+		if g.Jitter > 0 {
+			due = due.Add(time.Duration(rand.Int64N(int64(2*g.Jitter)+1)) - g.Jitter)
+		}
+		if wait := time.Until(due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return int(seq), ctx.Err()
+			}
+		} else if err := ctx.Err(); err != nil {
+			return int(seq), err
+		}
+		if err := send(format(seq, time.Now())); err != nil {
+			return int(seq), err
+		}
+	}
+	return g.Count, nil
+}
+
+// FormatSample is the default Generator line format: "<seq>,<unix nanoseconds>".
+func FormatSample(seq uint64, t time.Time) string {
+	return strconv.FormatUint(seq, 10) + "," + strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// ParseSample decodes a line produced by FormatSample.
+func ParseSample(line string) (seq uint64, sent time.Time, err error) {
+	s, ns, ok := strings.Cut(line, ",")
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("serialtest: malformed sample %q", line)
+	}
+	seq, err = strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("serialtest: malformed sample %q: %w", line, err)
+	}
+	n, err := strconv.ParseInt(ns, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("serialtest: malformed sample %q: %w", line, err)
+	}
+	return seq, time.Unix(0, n), nil
+}
+
+// Latency returns how long ago the FormatSample line was generated, i.e. the
+// end-to-end latency when called as soon as the line is received.
+func Latency(line string) (time.Duration, error) {
+	_, sent, err := ParseSample(line)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(sent), nil
+}
@@ -0,0 +1,74 @@
+package serialtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator_RateAndCount(t *testing.T) {
+	var lines []string
+	g := Generator{Rate: 200, Count: 40, Jitter: time.Millisecond}
+	start := time.Now()
+	n, err := g.Run(context.Background(), func(l string) error {
+		lines = append(lines, l)
+		return nil
+	})
+	elapsed := time.Since(start)
+	require.NoError(t, err)
+	require.Equal(t, 40, n)
+	require.Len(t, lines, 40)
+	// 39 periods of 5ms, give or take the jitter and scheduling noise.
+	require.Greater(t, elapsed, 180*time.Millisecond)
+	require.Less(t, elapsed, time.Second)
+
+	for i, l := range lines {
+		seq, _, err := ParseSample(l)
+		require.NoError(t, err)
+		require.EqualValues(t, i, seq)
+	}
+}
+
+func TestGenerator_Cancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	g := Generator{Rate: 1000}
+	n, err := g.Run(ctx, func(string) error { return nil })
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Greater(t, n, 0)
+
+	_, err = (&Generator{}).Run(ctx, func(string) error { return nil })
+	require.Error(t, err)
+}
+
+func TestGenerator_EndToEndLatency(t *testing.T) {
+	sim, err := NewSimulator("\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	r, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+
+	latencies := make(chan time.Duration, 20)
+	go r.ReadLinesLoop(func(l string) {
+		d, err := Latency(l)
+		if err == nil {
+			latencies <- d
+		}
+	}, func(error) {})
+
+	g := Generator{Rate: 200, Count: 20}
+	_, err = g.Run(context.Background(), sim.Send)
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		select {
+		case d := <-latencies:
+			require.Less(t, d, 100*time.Millisecond)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for generated line")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+//go:build linux || darwin || freebsd
+
+package serialtest
+
+import (
+	"os"
+
+	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY opens a PTY pair in raw mode, with the master non-blocking.
+func openPTY() (master, slave *os.File, err error) {
+	ptmx, slave, err := pty.Open()
+	if err != nil {
+		return nil, nil, err
+	}
+	// pty.Open leaves the master in blocking mode, where Close cannot
+	// interrupt a pending Read. Re-wrap a non-blocking duplicate so the
+	// runtime poller serves it and Disconnect takes effect immediately.
+	fd, err := unix.Dup(int(ptmx.Fd()))
+	ptmx.Close()
+	if err != nil {
+		slave.Close()
+		return nil, nil, err
+	}
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		slave.Close()
+		return nil, nil, err
+	}
+	master = os.NewFile(uintptr(fd), "/dev/ptmx")
+	// Raw mode until the application configures the port, so nothing is
+	// echoed or translated in the meantime.
+	if t, err := unix.IoctlGetTermios(int(slave.Fd()), ioctlGetTermios); err == nil {
+		t.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+		t.Oflag &^= unix.OPOST
+		t.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+		unix.IoctlSetTermios(int(slave.Fd()), ioctlSetTermios, t)
+	}
+	return master, slave, nil
+}
@@ -0,0 +1,12 @@
+package serialtest
+
+import (
+	"errors"
+	"os"
+)
+
+// openPTY fails: Windows has no PTYs, so there is no Simulator there. Use a
+// com0com or similar virtual null-modem pair with serial.Open instead.
+func openPTY() (master, slave *os.File, err error) {
+	return nil, nil, errors.ErrUnsupported
+}
@@ -0,0 +1,112 @@
+package serialtest
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// replay is the state of a transcript being acted out.
+type replay struct {
+	entries []serial.TranscriptEntry
+	pos     int // next entry to act out
+	err     error
+}
+
+// Replay makes the simulator act out a transcript recorded from a real
+// device (see serial.Transcript), turning a field capture into a regression
+// test. Lines the device sent before the first command are sent right away;
+// after that, each command the application sends must match the next one
+// recorded, and is answered with the lines the device sent after it, with
+// the recorded delays between them. A command that does not match is
+// handled by Respond and OnCommand instead and reported by ReplayErr.
+// Replay replaces a replay in progress.
+func (s *Simulator) Replay(entries []serial.TranscriptEntry) {
+	r := &replay{entries: entries}
+	s.mu.Lock()
+	s.replay = r
+	lead := r.replies()
+	s.mu.Unlock()
+	if len(lead) > 0 {
+		go s.sendReplies(lead, lead[0].Time)
+	}
+}
+
+// ReplayFile reads a transcript file and acts it out with Replay.
+func (s *Simulator) ReplayFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := serial.ReadTranscript(f)
+	if err != nil {
+		return err
+	}
+	s.Replay(entries)
+	return nil
+}
+
+// ReplayDone reports whether every recorded command has been received and
+// answered.
+func (s *Simulator) ReplayDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replay == nil || s.replay.pos == len(s.replay.entries)
+}
+
+// ReplayErr returns the first command that did not match the transcript.
+func (s *Simulator) ReplayErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.replay == nil {
+		return nil
+	}
+	return s.replay.err
+}
+
+// replies returns the received entries from pos up to the next command and
+// moves past them. Called with s.mu held.
+func (r *replay) replies() []serial.TranscriptEntry {
+	start := r.pos
+	for r.pos < len(r.entries) && !r.entries[r.pos].Sent {
+		r.pos++
+	}
+	return r.entries[start:r.pos]
+}
+
+// match consumes cmd if it is the next recorded command and returns the
+// recorded replies and when the command was sent. Called with s.mu held.
+func (r *replay) match(cmd string) (replies []serial.TranscriptEntry, at time.Time, ok bool) {
+	if r.pos == len(r.entries) {
+		return nil, time.Time{}, false
+	}
+	next := r.entries[r.pos]
+	if next.Line != cmd {
+		if r.err == nil {
+			r.err = fmt.Errorf("serialtest: replay entry %d: got command %q, want %q", r.pos, cmd, next.Line)
+		}
+		return nil, time.Time{}, false
+	}
+	r.pos++
+	return r.replies(), next.Time, true
+}
+
+// sendReplies sends the replies, each at its recorded offset from since.
+func (s *Simulator) sendReplies(replies []serial.TranscriptEntry, since time.Time) {
+	start := time.Now()
+	for _, e := range replies {
+		if d := time.Until(start.Add(e.Time.Sub(since))); d > 0 {
+			select {
+			case <-time.After(d):
+			case <-s.done:
+				return
+			}
+		}
+		if s.Send(e.Line) != nil {
+			return
+		}
+	}
+}
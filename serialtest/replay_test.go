@@ -0,0 +1,57 @@
+package serialtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+const capture = `2026-10-16T09:30:00.000000Z < "READY"
+2026-10-16T09:30:00.500000Z > "C,INFO"
+2026-10-16T09:30:00.560000Z < "MODEL=X1"
+2026-10-16T09:30:00.570000Z < "OK"
+2026-10-16T09:30:01.000000Z > "C,STOP"
+2026-10-16T09:30:01.010000Z < "OK"
+`
+
+func TestSimulator_Replay(t *testing.T) {
+	entries, err := serial.ReadTranscript(strings.NewReader(capture))
+	require.NoError(t, err)
+	sim, err := NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	r := openSim(t, sim)
+	sim.Replay(entries)
+
+	line, err := r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "READY", line)
+
+	start := time.Now()
+	require.NoError(t, r.WriteLine("C,INFO", "\r\n"))
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "MODEL=X1", line)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond, "recorded delay honoured")
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "OK", line)
+	require.False(t, sim.ReplayDone())
+
+	// An unexpected command falls back to Respond and is reported.
+	sim.Respond("C,PING", "PONG")
+	require.NoError(t, r.WriteLine("C,PING", "\r\n"))
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "PONG", line)
+	require.ErrorContains(t, sim.ReplayErr(), `got command "C,PING", want "C,STOP"`)
+
+	require.NoError(t, r.WriteLine("C,STOP", "\r\n"))
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "OK", line)
+	require.True(t, sim.ReplayDone())
+}
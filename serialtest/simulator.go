@@ -0,0 +1,180 @@
+package serialtest
+
+import (
+	"errors"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCommand is returned by Simulator.Expect when no command arrives in time.
+var ErrNoCommand = errors.New("serialtest: no command received")
+
+// Simulator is a scriptable virtual device backed by a PTY. Point
+// serial.Config.Device at Path, then drive the device side: send lines,
+// answer commands written by the application, and simulate a disconnect.
+type Simulator struct {
+	master  *os.File
+	slave   *os.File
+	newline string
+
+	mu        sync.Mutex
+	responses map[string][]string
+	onCommand func(cmd string) []string
+	received  []string
+	commands  chan string
+	closed    bool
+	done      chan struct{}
+
+	replay *replay
+
+	faults Faults
+	rng    *rand.Rand
+	sent   int
+}
+
+// NewSimulator creates a PTY pair and starts serving the device side.
+// newline terminates lines in both directions; empty means "\n".
+func NewSimulator(newline string) (*Simulator, error) {
+	if newline == "" {
+		newline = "\n"
+	}
+	master, slave, err := openPTY()
+	if err != nil {
+		return nil, err
+	}
+	s := &Simulator{
+		master:    master,
+		slave:     slave,
+		newline:   newline,
+		responses: make(map[string][]string),
+		commands:  make(chan string, 1024),
+		done:      make(chan struct{}),
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Path returns the device path to use as serial.Config.Device.
+func (s *Simulator) Path() string {
+	return s.slave.Name()
+}
+
+// Send writes line followed by the simulator's newline to the application,
+// subject to any Faults installed with SetFaults.
+func (s *Simulator) Send(line string) error {
+	return s.sendWithFaults(line)
+}
+
+// SendRaw writes b to the application unchanged.
+func (s *Simulator) SendRaw(b []byte) error {
+	_, err := s.master.Write(b)
+	return err
+}
+
+// Respond makes the device answer the command cmd with replies.
+func (s *Simulator) Respond(cmd string, replies ...string) {
+	s.mu.Lock()
+	s.responses[cmd] = replies
+	s.mu.Unlock()
+}
+
+// OnCommand sets a function that computes replies for commands with no
+// Respond entry.
+func (s *Simulator) OnCommand(fn func(cmd string) []string) {
+	s.mu.Lock()
+	s.onCommand = fn
+	s.mu.Unlock()
+}
+
+// Received returns all commands received from the application so far.
+func (s *Simulator) Received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+// Expect waits up to timeout for the next command from the application.
+func (s *Simulator) Expect(timeout time.Duration) (string, error) {
+	select {
+	case cmd := <-s.commands:
+		return cmd, nil
+	case <-time.After(timeout):
+		return "", ErrNoCommand
+	}
+}
+
+// Disconnect simulates the device disappearing: the PTY is torn down, so the
+// application's reads fail with serial.ErrDeviceRemoved.
+func (s *Simulator) Disconnect() error {
+	return s.Close()
+}
+
+// Close stops the simulator and releases the PTY. Safe to call multiple times.
+func (s *Simulator) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+	err := s.master.Close()
+	s.slave.Close()
+	return err
+}
+
+func (s *Simulator) serve() {
+	buf := make([]byte, 4096)
+	pending := ""
+	for {
+		n, err := s.master.Read(buf)
+		if err != nil {
+			return
+		}
+		pending += string(buf[:n])
+		for {
+			idx := strings.Index(pending, s.newline)
+			if idx < 0 {
+				break
+			}
+			s.handle(pending[:idx])
+			pending = pending[idx+len(s.newline):]
+		}
+	}
+}
+
+// notify hands cmd to Expect.
+func (s *Simulator) notify(cmd string) {
+	select {
+	case s.commands <- cmd:
+	default: // nobody is calling Expect; Received still records it
+	}
+}
+
+func (s *Simulator) handle(cmd string) {
+	s.mu.Lock()
+	s.received = append(s.received, cmd)
+	if s.replay != nil {
+		if recorded, at, ok := s.replay.match(cmd); ok {
+			s.mu.Unlock()
+			s.notify(cmd)
+			s.sendReplies(recorded, at)
+			return
+		}
+	}
+	replies, ok := s.responses[cmd]
+	if !ok && s.onCommand != nil {
+		replies = s.onCommand(cmd)
+	}
+	s.mu.Unlock()
+	s.notify(cmd)
+	for _, r := range replies {
+		if s.Send(r) != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package serialtest
+
+import (
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/stretchr/testify/require"
+)
+
+func openSim(t *testing.T, sim *Simulator) *serial.SerialReader {
+	t.Helper()
+	r, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\r\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestSimulator_CommandResponse(t *testing.T) {
+	sim, err := NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	sim.Respond("C,INFO", "MODEL=X1")
+
+	r := openSim(t, sim)
+	require.NoError(t, r.WriteLine("C,INFO", "\r\n"))
+
+	cmd, err := sim.Expect(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "C,INFO", cmd)
+	line, err := r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "MODEL=X1", line)
+
+	require.NoError(t, sim.Send("1,2,3"))
+	line, err = r.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "1,2,3", line)
+	require.Equal(t, []string{"C,INFO"}, sim.Received())
+
+	_, err = sim.Expect(10 * time.Millisecond)
+	require.ErrorIs(t, err, ErrNoCommand)
+}
+
+func TestSimulator_Disconnect(t *testing.T) {
+	sim, err := NewSimulator("\r\n")
+	require.NoError(t, err)
+	r := openSim(t, sim)
+
+	errs := make(chan error, 1)
+	go r.ReadLinesLoop(func(string) {}, func(err error) { errs <- err })
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, sim.Disconnect())
+
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, serial.ErrDeviceRemoved)
+	case <-time.After(time.Second):
+		t.Fatal("no error after Disconnect")
+	}
+}
@@ -0,0 +1,10 @@
+//go:build darwin || freebsd
+
+package serialtest
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+)
@@ -0,0 +1,29 @@
+package serial
+
+import "time"
+
+// ReadSlicesLoop reads the raw byte stream and passes whatever has arrived
+// to onSlice every interval, regardless of delimiters, for streaming
+// displays and protocols where latency matters more than framing. Intervals
+// in which nothing arrived are skipped, and bytes still pending when the
+// loop ends are passed on before it returns. The timer is the poll timeout,
+// so no extra goroutine is involved. The slice is only valid during the
+// call. The loop ends like ReadLinesLoop.
+func (s *SerialReader) ReadSlicesLoop(interval time.Duration, onSlice func([]byte), onError func(error)) {
+	if interval <= 0 {
+		interval = 10 * time.Millisecond
+	}
+	var pending []byte
+	emit := func() bool {
+		if len(pending) > 0 {
+			onSlice(pending)
+			pending = pending[:0]
+		}
+		return true
+	}
+	defer emit()
+	s.readTicking(nil, interval, emit, func(chunk []byte, _ time.Time) bool {
+		pending = append(pending, chunk...)
+		return true
+	}, onError)
+}
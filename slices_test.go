@@ -0,0 +1,50 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_ReadSlicesLoop(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	type slice struct {
+		data string
+		at   time.Time
+	}
+	slices := make(chan slice, 8)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader.ReadSlicesLoop(50*time.Millisecond, func(b []byte) {
+			slices <- slice{string(b), time.Now()}
+		}, func(error) {})
+	}()
+
+	// Bytes without any delimiter still come out, batched per interval: three
+	// writes within 2ms straddle at most one tick.
+	start := time.Now()
+	for _, b := range []string{"a", "b", "c"} {
+		_, err := master.Write([]byte(b))
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	var data string
+	n := 0
+	for data != "abc" {
+		got := <-slices
+		require.Less(t, got.at.Sub(start), 300*time.Millisecond)
+		data += got.data
+		n++
+	}
+	require.LessOrEqual(t, n, 2)
+
+	_, err := master.Write([]byte("d"))
+	require.NoError(t, err)
+	require.Equal(t, "d", (<-slices).data)
+
+	reader.Close()
+	<-done
+	require.Empty(t, slices) // idle intervals emit nothing
+}
@@ -0,0 +1,68 @@
+package serial
+
+// SLIP special characters (RFC 1055).
+const (
+	slipEnd    = 0xC0
+	slipEsc    = 0xDB
+	slipEscEnd = 0xDC
+	slipEscEsc = 0xDD
+)
+
+// SLIP is a Framer for RFC 1055 Serial Line IP framing: frames end with END
+// (0xC0), and END and ESC bytes inside them are escaped. Empty frames, such
+// as those produced by a leading END used to flush line noise, are skipped.
+// Following the RFC, an ESC followed by anything other than ESC_END or
+// ESC_ESC is passed through unchanged.
+type SLIP struct{}
+
+// Frame implements Framer.
+func (SLIP) Frame(data []byte) (int, []byte, error) {
+	end := -1
+	for i, b := range data {
+		if b == slipEnd {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return 0, nil, nil
+	}
+	if end == 0 {
+		return 1, nil, nil
+	}
+	frame := make([]byte, 0, end)
+	for i := 0; i < end; i++ {
+		b := data[i]
+		if b == slipEsc && i+1 < end {
+			i++
+			switch data[i] {
+			case slipEscEnd:
+				b = slipEnd
+			case slipEscEsc:
+				b = slipEsc
+			default:
+				b = data[i]
+			}
+		}
+		frame = append(frame, b)
+	}
+	return end + 1, frame, nil
+}
+
+// Encode returns payload as a SLIP frame, with a leading END to flush any
+// noise the receiver has accumulated.
+func (SLIP) Encode(payload []byte) []byte {
+	out := make([]byte, 0, len(payload)+2)
+	out = append(out, slipEnd)
+	for _, b := range payload {
+		switch b {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, b)
+		}
+	}
+	return append(out, slipEnd)
+}
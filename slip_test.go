@@ -0,0 +1,54 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSLIP_RoundTrip(t *testing.T) {
+	payload := []byte{0x01, slipEnd, 0x02, slipEsc, 0x03}
+	enc := SLIP{}.Encode(payload)
+	require.Equal(t, []byte{slipEnd, 0x01, slipEsc, slipEscEnd, 0x02, slipEsc, slipEscEsc, 0x03, slipEnd}, enc)
+
+	// The leading END yields an empty frame, which is skipped.
+	advance, frame, err := SLIP{}.Frame(enc)
+	require.NoError(t, err)
+	require.Equal(t, 1, advance)
+	require.Nil(t, frame)
+
+	advance, frame, err = SLIP{}.Frame(enc[1:])
+	require.NoError(t, err)
+	require.Equal(t, len(enc)-1, advance)
+	require.Equal(t, payload, frame)
+
+	advance, frame, _ = SLIP{}.Frame([]byte{0x01, 0x02})
+	require.Zero(t, advance)
+	require.Nil(t, frame)
+}
+
+func TestSerialReader_ReadFramesLoop(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	frames := make(chan []byte, 4)
+	go reader.ReadFramesLoop(SLIP{}, func(f []byte) {
+		frames <- append([]byte(nil), f...)
+	}, func(err error) {})
+
+	enc := append(SLIP{}.Encode([]byte("hello\n")), SLIP{}.Encode([]byte{slipEnd, 0x00})...)
+	// Split mid-escape to exercise buffering across reads.
+	_, err := master.Write(enc[:10])
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = master.Write(enc[10:])
+	require.NoError(t, err)
+
+	for _, want := range [][]byte{[]byte("hello\n"), {slipEnd, 0x00}} {
+		select {
+		case got := <-frames:
+			require.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting for frame")
+		}
+	}
+}
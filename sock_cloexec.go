@@ -0,0 +1,21 @@
+//go:build linux || freebsd
+
+package serial
+
+import "golang.org/x/sys/unix"
+
+// socketpair returns a connected pair of close-on-exec AF_UNIX sockets.
+func socketpair(typ int) ([2]int, error) {
+	return unix.Socketpair(unix.AF_UNIX, typ|unix.SOCK_CLOEXEC, 0)
+}
+
+// pipe returns a close-on-exec pipe, read end first.
+func pipe() ([2]int, error) {
+	var fds [2]int
+	err := unix.Pipe2(fds[:], unix.O_CLOEXEC)
+	return fds, err
+}
+
+// datagramPair is the socket type that carries UDP datagrams to the poll
+// engine with their boundaries intact.
+const datagramPair = unix.SOCK_SEQPACKET
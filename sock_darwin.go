@@ -0,0 +1,39 @@
+package serial
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketpair returns a connected pair of close-on-exec AF_UNIX sockets.
+// macOS has no SOCK_CLOEXEC, so the flag is set under the fork lock instead.
+func socketpair(typ int) ([2]int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	fds, err := unix.Socketpair(unix.AF_UNIX, typ, 0)
+	if err != nil {
+		return fds, err
+	}
+	unix.CloseOnExec(fds[0])
+	unix.CloseOnExec(fds[1])
+	return fds, nil
+}
+
+// pipe returns a close-on-exec pipe, read end first. macOS has no pipe2, so
+// the flag is set under the fork lock instead.
+func pipe() ([2]int, error) {
+	syscall.ForkLock.RLock()
+	defer syscall.ForkLock.RUnlock()
+	var fds [2]int
+	if err := unix.Pipe(fds[:]); err != nil {
+		return fds, err
+	}
+	unix.CloseOnExec(fds[0])
+	unix.CloseOnExec(fds[1])
+	return fds, nil
+}
+
+// datagramPair is the socket type that carries UDP datagrams to the poll
+// engine with their boundaries intact. macOS lacks AF_UNIX SOCK_SEQPACKET.
+const datagramPair = unix.SOCK_DGRAM
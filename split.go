@@ -0,0 +1,32 @@
+package serial
+
+// SplitLines splits data into lines exactly as ReadLine and ReadLinesLoop do
+// with c: at every Delimiter, or Terminator match, with the line ends
+// removed. rest is the unterminated tail, which a caller feeding a stream
+// prepends to the next data. SplitLines only reads data and c, so it can be
+// fuzzed or property-tested, for instance against delimiters split across
+// reads, and used to frame captured streams the same way the reader would.
+// Config.MaxLineLength and Checksum are not applied.
+func (c Config) SplitLines(data []byte) (lines []string, rest []byte) {
+	n := splitLines(data, 0, c.lineEnd(), func(line []byte) {
+		lines = append(lines, string(line))
+	})
+	return lines, data[n:]
+}
+
+// splitLines passes every line in data, as delimited by lineEnd, to emit
+// and returns how many bytes the complete lines and their line ends took.
+// data[:scanned] is known to hold no line end, so lineEnd can skip it. The
+// line passed to emit aliases data.
+func splitLines(data []byte, scanned int, lineEnd func(b []byte, from int) (end, next int), emit func(line []byte)) int {
+	start := 0
+	for {
+		end, next := lineEnd(data[start:], scanned)
+		if end < 0 {
+			return start
+		}
+		emit(data[start : start+end])
+		start += next
+		scanned = 0
+	}
+}
@@ -0,0 +1,50 @@
+package serial
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_SplitLines(t *testing.T) {
+	lines, rest := Config{}.SplitLines([]byte("a\r\nb\nc\r\n\r\nd\r"))
+	require.Equal(t, []string{"a", "b\nc", ""}, lines)
+	require.Equal(t, "d\r", string(rest))
+
+	lines, rest = Config{Terminator: regexp.MustCompile(`\r?\n`)}.SplitLines([]byte("a\r\nb\nc"))
+	require.Equal(t, []string{"a", "b"}, lines)
+	require.Equal(t, "c", string(rest))
+
+	lines, rest = Config{Delimiter: "\n"}.SplitLines(nil)
+	require.Empty(t, lines)
+	require.Empty(t, rest)
+}
+
+// FuzzSplitLines checks that splitting a stream in two reads, with the
+// remainder of the first carried over, gives the lines of splitting it in
+// one, and that the lines and remainder put back together are the stream.
+func FuzzSplitLines(f *testing.F) {
+	f.Add([]byte("a\r\nb\r\n"), "\r\n", 2)
+	f.Add([]byte("$GP\xff\x00,1\xff\x00"), "\xff\x00", 4)
+	f.Add([]byte("abab"), "aba", 1)
+	f.Fuzz(func(t *testing.T, data []byte, delim string, cut int) {
+		if delim == "" || cut < 0 || cut > len(data) {
+			t.Skip()
+		}
+		cfg := Config{Delimiter: delim}
+		want, wantRest := cfg.SplitLines(data)
+
+		got, rest := cfg.SplitLines(data[:cut])
+		more, rest := cfg.SplitLines(append(bytes.Clone(rest), data[cut:]...))
+		require.Equal(t, want, append(got, more...))
+		require.Equal(t, wantRest, rest)
+
+		var joined []byte
+		for _, l := range want {
+			joined = append(append(joined, l...), delim...)
+		}
+		require.Equal(t, data, append(joined, rest...))
+	})
+}
@@ -0,0 +1,136 @@
+// Package sse serves the live line stream of a serial port as HTTP
+// Server-Sent Events, a zero-dependency way to tail a port from curl or a
+// browser's EventSource:
+//
+//	http.Handle("/lines", sse.NewHandler(port))
+//	curl -N 'http://gateway:8080/lines?prefix=$GPGGA'
+//
+// Each line is sent as one event. Query parameters filter the stream: prefix
+// (repeatable; any may match), contains, and match (a regular expression).
+package sse
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// Handler is an http.Handler streaming lines as Server-Sent Events.
+type Handler struct {
+	// Heartbeat is the interval of comment events that keep idle
+	// connections and proxies alive; default 15s, negative disables.
+	Heartbeat time.Duration
+	// Buffer is the number of lines queued per client; when a client falls
+	// behind, the oldest lines are dropped. Default 256.
+	Buffer int
+
+	src serial.LineSource
+}
+
+// NewHandler returns a Handler streaming the lines of src.
+func NewHandler(src serial.LineSource) *Handler {
+	return &Handler{src: src, Heartbeat: 15 * time.Second, Buffer: 256}
+}
+
+// ServeHTTP streams events until the client disconnects or the port is
+// closed.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	if rc.Flush() != nil {
+		return
+	}
+
+	sub := h.src.Subscribe(h.Buffer)
+	sub.SetPolicy(serial.DropOldest)
+	defer sub.Unsubscribe()
+	var heartbeat <-chan time.Time
+	if h.Heartbeat > 0 {
+		t := time.NewTicker(h.Heartbeat)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+	var buf []byte
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat:
+			buf = append(buf[:0], ": heartbeat\n\n"...)
+		case line, ok := <-sub.C():
+			if !ok {
+				return
+			}
+			if !filter(line) {
+				continue
+			}
+			buf = appendEvent(buf[:0], line)
+		}
+		if _, err := w.Write(buf); err != nil {
+			return
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// appendEvent encodes line as an event; a stray CR or LF in line would end
+// the data field, so it starts a new one instead.
+func appendEvent(b []byte, line string) []byte {
+	for {
+		b = append(b, "data: "...)
+		i := strings.IndexAny(line, "\r\n")
+		if i < 0 {
+			b = append(b, line...)
+			break
+		}
+		b = append(b, line[:i]...)
+		b = append(b, '\n')
+		line = line[i+1:]
+	}
+	return append(b, '\n', '\n')
+}
+
+// parseFilter builds the line filter from the request's query parameters.
+func parseFilter(r *http.Request) (func(string) bool, error) {
+	q := r.URL.Query()
+	prefixes := q["prefix"]
+	contains := q.Get("contains")
+	var re *regexp.Regexp
+	if m := q.Get("match"); m != "" {
+		var err error
+		if re, err = regexp.Compile(m); err != nil {
+			return nil, err
+		}
+	}
+	return func(line string) bool {
+		if len(prefixes) > 0 {
+			ok := false
+			for _, p := range prefixes {
+				if strings.HasPrefix(line, p) {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+		if contains != "" && !strings.Contains(line, contains) {
+			return false
+		}
+		return re == nil || re.MatchString(line)
+	}, nil
+}
@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	sim, err := serialtest.NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	port, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\r\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+	go port.ReadLinesLoop(nil, func(error) {})
+
+	h := NewHandler(port)
+	h.Heartbeat = 30 * time.Millisecond
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?prefix=$GPGGA&prefix=$GPRMC&match=,A,")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	time.Sleep(20 * time.Millisecond) // let the handler subscribe
+	for _, l := range []string{"$GPGSV,1", "$GPRMC,V,", "$GPRMC,A,1"} {
+		require.NoError(t, sim.Send(l))
+	}
+
+	br := bufio.NewReader(resp.Body)
+	sawHeartbeat := false
+	for {
+		l, err := br.ReadString('\n')
+		require.NoError(t, err)
+		if l == ": heartbeat\n" {
+			sawHeartbeat = true
+			continue
+		}
+		if l == "\n" {
+			continue
+		}
+		require.Equal(t, "data: $GPRMC,A,1\n", l)
+		break
+	}
+	for !sawHeartbeat {
+		l, err := br.ReadString('\n')
+		require.NoError(t, err)
+		sawHeartbeat = l == ": heartbeat\n"
+	}
+
+	resp2, err := http.Get(srv.URL + "?match=(")
+	require.NoError(t, err)
+	resp2.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp2.StatusCode)
+}
+
+func TestAppendEvent(t *testing.T) {
+	require.Equal(t, "data: a\ndata: b\n\n", string(appendEvent(nil, "a\rb")))
+	require.Equal(t, "data: \n\n", string(appendEvent(nil, "")))
+}
@@ -0,0 +1,87 @@
+package serial
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of a SerialReader's counters since Open or the last
+// ResetStats. They carry over Reopen.
+type Stats struct {
+	BytesRead    uint64
+	BytesWritten uint64
+	Lines        uint64 // lines framed by ReadLine and ReadLinesLoop
+	LinesWritten uint64 // lines sent by WriteLine
+	WriteErrors  uint64 // failed Write and WriteLine calls, once the port was written to
+	Dropped      uint64 // lines dropped by a full Config.Async queue
+	BadLines     uint64 // lines rejected by Config.Checksum
+	ParseErrors  uint64 // lines rejected by the parse function of Decode
+	BadChars     uint64 // characters marked corrupted; see Config.MarkErrors
+	Breaks       uint64 // break conditions; see Config.DetectBreaks
+	Overruns     uint64 // characters the driver lost; see Config.OnOverrun
+	MaxBacklog   uint64 // most bytes seen queued in the kernel; see Config.BacklogInterval
+
+	// Latency is the read latency histogram; nil unless
+	// Config.MeasureLatency is set.
+	Latency *Histogram
+}
+
+// Stats returns the current counters.
+func (s *SerialReader) Stats() Stats {
+	st := Stats{
+		BytesRead:    s.bytesRead.Load(),
+		BytesWritten: s.bytesWritten.Load(),
+		Lines:        s.linesRead.Load(),
+		LinesWritten: s.linesWritten.Load(),
+		WriteErrors:  s.writeErrors.Load(),
+		Dropped:      s.dropped.Load(),
+		BadLines:     s.badLines.Load(),
+		ParseErrors:  s.parseErrors.Load(),
+		BadChars:     s.badChars.Load(),
+		Breaks:       s.breaks.Load(),
+		Overruns:     s.overruns.Load(),
+		MaxBacklog:   s.maxBacklog.Load(),
+	}
+	if s.latency != nil {
+		st.Latency = s.latency.snapshot()
+	}
+	return st
+}
+
+// ResetStats sets all counters, and the latency histogram, back to zero, for
+// callers that report per interval. Each counter is cleared on its own, so
+// a snapshot taken concurrently may mix values from before and after.
+func (s *SerialReader) ResetStats() {
+	for _, c := range []*atomic.Uint64{
+		&s.bytesRead, &s.bytesWritten, &s.linesRead, &s.linesWritten,
+		&s.writeErrors, &s.dropped, &s.badLines, &s.parseErrors, &s.badChars, &s.breaks,
+		&s.overruns, &s.maxBacklog,
+	} {
+		c.Store(0)
+	}
+	if s.latency != nil {
+		s.latency.reset()
+	}
+}
+
+// sampleBacklog records the input queue length of p in the MaxBacklog
+// high-water mark, if Config.BacklogInterval has passed since the last
+// sample.
+func (s *SerialReader) sampleBacklog(p *port) {
+	d := s.config.BacklogInterval
+	if d <= 0 {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := s.backlogAt.Load()
+	if now-last < int64(d) || !s.backlogAt.CompareAndSwap(last, now) {
+		return // sampled recently, possibly by another loop
+	}
+	n := uint64(queueLen(p.fd, ioctlInQueue))
+	for {
+		high := s.maxBacklog.Load()
+		if n <= high || s.maxBacklog.CompareAndSwap(high, n) {
+			return
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package serial
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_Stats(t *testing.T) {
+	reader, master := newTestReader(t, Config{MeasureLatency: true})
+	require.NoError(t, reader.WriteLine("a", "\n"))
+	require.NoError(t, reader.WriteLine("bc", "\n"))
+	_, err := master.Write([]byte("xyz\n"))
+	require.NoError(t, err)
+	_, err = reader.ReadLine()
+	require.NoError(t, err)
+
+	st := reader.Stats()
+	require.EqualValues(t, 5, st.BytesWritten)
+	require.EqualValues(t, 2, st.LinesWritten)
+	require.EqualValues(t, 4, st.BytesRead)
+	require.EqualValues(t, 1, st.Lines)
+	require.Zero(t, st.WriteErrors)
+
+	// Writing to a PTY whose master has gone fails with EIO.
+	master.Close()
+	require.Error(t, reader.WriteLine("d", "\n"))
+	require.EqualValues(t, 1, reader.Stats().WriteErrors)
+	require.EqualValues(t, 2, reader.Stats().LinesWritten)
+
+	reader.ResetStats()
+	st = reader.Stats()
+	require.Zero(t, st.BytesRead+st.BytesWritten+st.Lines+st.LinesWritten+st.WriteErrors)
+	require.Zero(t, st.Latency.Count())
+}
+
+func TestSerialReader_MaxBacklog(t *testing.T) {
+	reader, master := newTestReader(t, Config{BacklogInterval: time.Nanosecond})
+	// Queue the input before the loop starts, so the first sample sees it all.
+	_, err := master.Write([]byte(strings.Repeat("sample\n", 100)))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return queueLen(reader.port().fd, ioctlInQueue) == 700
+	}, time.Second, time.Millisecond)
+
+	lines := make(chan string, 100)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	for range 100 {
+		<-lines
+	}
+	require.EqualValues(t, 700, reader.Stats().MaxBacklog)
+	reader.ResetStats()
+	require.Zero(t, reader.Stats().MaxBacklog)
+}
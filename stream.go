@@ -0,0 +1,62 @@
+package serial
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+	"syscall"
+)
+
+// OpenStream returns a SerialReader on a byte stream that is not a serial
+// port, such as standard input, a pipe from another process, a FIFO or a
+// socket, so that it is split, verified and delivered exactly as a port
+// would be: Delimiter or Terminator, MaxLineLength, Checksum, Middleware,
+// subscriptions and the read loops all apply. cfg.Device, if empty, is set
+// to the file name of src or to "stream", for error messages; the serial
+// line settings in cfg are ignored. The end of the stream is reported as
+// ErrDeviceRemoved.
+//
+// If src has a file descriptor (an *os.File or a net.Conn), the poll engine
+// reads a duplicate of it directly, put in blocking mode, and Reopen takes
+// a fresh duplicate; src stays open after Close. Any other io.Reader is
+// copied into the engine by a goroutine, which also copies writes to src if
+// it is an io.Writer; that goroutine ends at the end of src or at the first
+// read of src after Close, and Reopen fails with errors.ErrUnsupported.
+func OpenStream(src io.Reader, cfg Config) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = "stream"
+		if f, ok := src.(*os.File); ok {
+			cfg.Device = f.Name()
+		}
+	}
+	if sc, ok := src.(syscall.Conn); ok {
+		return openWith(cfg, func(cfg Config) (*port, error) {
+			return adoptFD(sc, "open", cfg)
+		})
+	}
+	var opened atomic.Bool
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		if opened.Swap(true) {
+			return nil, &SerialError{Device: cfg.Device, Op: "open", Err: errors.ErrUnsupported}
+		}
+		return bridgeStream(src, cfg)
+	})
+}
+
+// bridgeStream connects src to a new port through a stream socketpair.
+func bridgeStream(src io.Reader, cfg Config) (*port, error) {
+	p, local, err := bridgePort(false, "stream", cfg)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		// The port sees the end of the stream when local is closed.
+		defer local.Close()
+		io.Copy(local, src)
+	}()
+	if w, ok := src.(io.Writer); ok {
+		go io.Copy(w, local)
+	}
+	return p, nil
+}
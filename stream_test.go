@@ -0,0 +1,91 @@
+package serial
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenStream_File(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	reader, err := OpenStream(r, Config{Delimiter: "\n", MaxLineLength: 8, ContinueOnError: true})
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, r.Name(), reader.config.Device)
+
+	_, err = w.Write([]byte("one\nno line end in sight"))
+	require.NoError(t, err)
+	w.Close()
+	var lines []string
+	var errs []error
+	reader.ReadLinesLoop(func(l string) { lines = append(lines, l) }, func(err error) { errs = append(errs, err) })
+	require.Equal(t, []string{"one"}, lines)
+	require.Len(t, errs, 2)
+	require.ErrorIs(t, errs[0], ErrLineTooLong)
+	require.ErrorIs(t, errs[1], ErrDeviceRemoved)
+
+	// Reopen takes a fresh duplicate of the pipe, which is still open.
+	require.NoError(t, reader.Reopen())
+}
+
+func TestOpenStream_Reader(t *testing.T) {
+	reader, err := OpenStream(strings.NewReader("a\r\nb\r\npartial"), Config{})
+	require.NoError(t, err)
+	defer reader.Close()
+	require.Equal(t, "stream", reader.config.Device)
+
+	for _, want := range []string{"a", "b"} {
+		line, err := reader.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, want, line)
+	}
+	_, err = reader.ReadLine()
+	require.ErrorIs(t, err, ErrDeviceRemoved)
+	require.ErrorIs(t, reader.Reopen(), errors.ErrUnsupported)
+}
+
+// pipeRW joins the two ends of in-memory pipes into an io.ReadWriter
+// without a file descriptor.
+type pipeRW struct {
+	io.Reader
+	io.Writer
+}
+
+func TestOpenStream_ReadWriter(t *testing.T) {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	reader, err := OpenStream(pipeRW{inR, outW}, Config{Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.NoError(t, reader.WriteLine("ping", "\n"))
+	buf := make([]byte, 16)
+	n, err := outR.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping\n", string(buf[:n]))
+
+	go inW.Write([]byte("pong\n"))
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "pong", line)
+
+	// The end of the source ends the loop, even with the writer still open.
+	inW.Close()
+	done := make(chan error, 1)
+	go reader.ReadLinesLoop(func(string) {}, func(err error) { done <- err })
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrDeviceRemoved)
+	case <-time.After(time.Second):
+		t.Fatal("no error at the end of the stream")
+	}
+}
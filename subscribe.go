@@ -0,0 +1,166 @@
+package serial
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Backpressure selects what happens when a subscription's buffer is full.
+type Backpressure int32
+
+const (
+	// Block waits for the consumer, stalling the read loop (the default).
+	Block Backpressure = iota
+	// DropOldest discards the oldest buffered line to make room for the new one.
+	DropOldest
+	// DropNewest discards the incoming line.
+	DropNewest
+	// Coalesce discards everything buffered when full, so the consumer skips
+	// straight to the latest lines.
+	Coalesce
+)
+
+// Subscription receives a copy of every line delivered by ReadLinesLoop.
+// Each subscription has its own buffer, so consumers drain independently.
+// What happens when the buffer is full is governed by its Backpressure policy.
+type Subscription struct {
+	r       *SerialReader
+	ch      chan string
+	done    chan struct{}
+	once    sync.Once
+	policy  atomic.Int32
+	dropped atomic.Uint64
+}
+
+// Subscribe registers a new subscription with the given channel buffer size.
+// Lines are available on C until Unsubscribe or Close is called.
+func (s *SerialReader) Subscribe(buffer int) *Subscription {
+	sub := &Subscription{
+		r:    s,
+		ch:   make(chan string, buffer),
+		done: make(chan struct{}),
+	}
+	s.subMu.Lock()
+	s.subs = append(s.subs, sub)
+	s.subMu.Unlock()
+	return sub
+}
+
+// SubscribeFunc registers a subscription whose lines are passed to fn on a
+// dedicated goroutine, so a slow fn never delays other consumers' callbacks.
+func (s *SerialReader) SubscribeFunc(buffer int, fn func(string)) *Subscription {
+	sub := s.Subscribe(buffer)
+	go func() {
+		for line := range sub.ch {
+			fn(line)
+		}
+	}()
+	return sub
+}
+
+// SetPolicy changes the subscription's Backpressure policy. The drop policies
+// need a buffer of at least one line to be meaningful; use them so a stalled
+// consumer cannot delay reads and cause UART overruns.
+func (sub *Subscription) SetPolicy(p Backpressure) {
+	sub.policy.Store(int32(p))
+}
+
+// Dropped returns the number of lines discarded by the Backpressure policy.
+func (sub *Subscription) Dropped() uint64 {
+	return sub.dropped.Load()
+}
+
+// C returns the channel on which lines are delivered. It is closed when the
+// subscription ends.
+func (sub *Subscription) C() <-chan string {
+	return sub.ch
+}
+
+// Unsubscribe stops delivery and closes the channel. Safe to call multiple times.
+func (sub *Subscription) Unsubscribe() {
+	sub.once.Do(func() {
+		close(sub.done) // unblock a publisher waiting on a full buffer
+		s := sub.r
+		s.subMu.Lock()
+		for i, other := range s.subs {
+			if other == sub {
+				s.subs = append(s.subs[:i], s.subs[i+1:]...)
+				break
+			}
+		}
+		s.subMu.Unlock()
+		close(sub.ch)
+	})
+}
+
+// deliverFunc returns a handler that calls onLine (if non-nil) and then
+// publishes the line to all subscriptions.
+func (s *SerialReader) deliverFunc(onLine func(string)) func(string) {
+	return func(line string) {
+		if onLine != nil {
+			onLine(line)
+		}
+		s.publish(line)
+	}
+}
+
+func (s *SerialReader) publish(line string) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	for _, sub := range s.subs {
+		sub.send(line)
+	}
+}
+
+func (sub *Subscription) send(line string) {
+	switch Backpressure(sub.policy.Load()) {
+	case DropNewest:
+		select {
+		case sub.ch <- line:
+		default:
+			sub.dropped.Add(1)
+		}
+	case DropOldest, Coalesce:
+		for {
+			select {
+			case sub.ch <- line:
+				return
+			default:
+			}
+			if cap(sub.ch) == 0 {
+				sub.dropped.Add(1) // nothing buffered to evict
+				return
+			}
+			sub.evict(Backpressure(sub.policy.Load()) == Coalesce)
+		}
+	default:
+		select {
+		case sub.ch <- line:
+		case <-sub.done:
+		}
+	}
+}
+
+// evict discards the oldest buffered line, or every buffered line if all is set.
+func (sub *Subscription) evict(all bool) {
+	for {
+		select {
+		case <-sub.ch:
+			sub.dropped.Add(1)
+			if !all {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *SerialReader) closeSubscriptions() {
+	s.subMu.RLock()
+	subs := append([]*Subscription(nil), s.subs...)
+	s.subMu.RUnlock()
+	for _, sub := range subs {
+		sub.Unsubscribe()
+	}
+}
@@ -0,0 +1,99 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_Subscribe(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	logger := reader.Subscribe(4)
+	plotted := make(chan string, 4)
+	plotter := reader.SubscribeFunc(4, func(l string) { plotted <- l })
+	defer plotter.Unsubscribe()
+
+	go reader.ReadLinesLoop(nil, func(err error) {})
+
+	_, err := master.Write([]byte("a\nb\n"))
+	require.NoError(t, err)
+
+	for _, want := range []string{"a", "b"} {
+		select {
+		case l := <-logger.C():
+			require.Equal(t, want, l)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("timeout waiting for subscription line")
+		}
+		select {
+		case l := <-plotted:
+			require.Equal(t, want, l)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("timeout waiting for subscription callback")
+		}
+	}
+
+	logger.Unsubscribe()
+	logger.Unsubscribe() // no-op
+	_, ok := <-logger.C()
+	require.False(t, ok)
+}
+
+func TestSerialReader_UnsubscribeUnblocksFullBuffer(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+
+	stalled := reader.Subscribe(0)
+	lines := make(chan string, 2)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) {})
+
+	_, err := master.Write([]byte("one\ntwo\n"))
+	require.NoError(t, err)
+
+	<-lines // loop is now blocked publishing "one" to the stalled subscriber
+	stalled.Unsubscribe()
+
+	select {
+	case l := <-lines:
+		require.Equal(t, "two", l)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("read loop still blocked after Unsubscribe")
+	}
+}
+
+func TestSerialReader_CloseEndsSubscriptions(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+	sub := reader.Subscribe(1)
+	require.NoError(t, reader.Close())
+	_, ok := <-sub.C()
+	require.False(t, ok)
+}
+
+func TestSubscription_Backpressure(t *testing.T) {
+	cases := []struct {
+		policy  Backpressure
+		want    []string
+		dropped uint64
+	}{
+		{DropNewest, []string{"1", "2"}, 2},
+		{DropOldest, []string{"3", "4"}, 2},
+		{Coalesce, []string{"3", "4"}, 2},
+	}
+	for _, tc := range cases {
+		reader, _ := newTestReader(t, Config{})
+		sub := reader.Subscribe(2)
+		sub.SetPolicy(tc.policy)
+		for _, l := range []string{"1", "2", "3", "4"} {
+			reader.publish(l) // must never block
+		}
+		reader.Close()
+
+		var got []string
+		for l := range sub.C() {
+			got = append(got, l)
+		}
+		require.Equal(t, tc.want, got, "policy %d", tc.policy)
+		require.Equal(t, tc.dropped, sub.Dropped(), "policy %d", tc.policy)
+	}
+}
@@ -0,0 +1,221 @@
+package serial
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// EventKind identifies a Supervisor lifecycle event.
+type EventKind int
+
+const (
+	EventStarted   EventKind = iota // the read loop is running
+	EventError                      // the read loop or a reopen failed
+	EventRestarted                  // the port was reopened and the loop restarted
+	EventGaveUp                     // the restart policy is exhausted; Err is the last error
+	EventStopped                    // Stop or Close ended the loop
+)
+
+var eventKindNames = [...]string{"started", "error", "restarted", "gave-up", "stopped"}
+
+// String returns "started", "error", "restarted", "gave-up" or "stopped".
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(eventKindNames) {
+		return "EventKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return eventKindNames[k]
+}
+
+// SupervisorEvent is published by a Supervisor as its read loop changes
+// state.
+type SupervisorEvent struct {
+	Kind    EventKind
+	Time    time.Time
+	Attempt int   // consecutive restart attempt, from 1; 0 for the first start
+	Err     error // for EventError and EventGaveUp
+}
+
+// RestartPolicy governs how a Supervisor restarts its read loop.
+type RestartPolicy struct {
+	// MaxRestarts bounds consecutive restart attempts; zero means no
+	// limit. A run lasting Healthy starts the count afresh.
+	MaxRestarts int
+	// Backoff is the wait before the first restart attempt, doubled for
+	// each further one up to MaxBackoff. Zero means one second, and a
+	// zero MaxBackoff means 30 seconds.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Healthy is how long the loop must run for its next failure to count
+	// as the first again. Zero means one minute.
+	Healthy time.Duration
+	// Retry, if set, decides whether the loop is restarted after err;
+	// returning false gives up at once. By default every error is retried.
+	Retry func(err error) bool
+}
+
+// Supervisor runs ReadLinesLoop on a reader in the background and, when
+// the loop ends with an error, reopens the port and restarts it as its
+// RestartPolicy allows, in place of the goroutine and onError wiring around
+// ReadLinesWithReconnect. It publishes its lifecycle on Events and ends
+// when stopped, when the reader is closed or when it gives up. Create one
+// with Supervise.
+type Supervisor struct {
+	s      *SerialReader
+	onLine func(string)
+	policy RestartPolicy
+	stop   *waker
+	events chan SupervisorEvent
+	done   chan struct{}
+
+	mu      sync.Mutex
+	err     error // the error given up on
+	dropped uint64
+}
+
+// Supervise starts a Supervisor running onLine over s's lines under policy.
+// Events has room for 64 events; events published while it is full are
+// dropped rather than stalling the reader, and counted by Dropped.
+func (s *SerialReader) Supervise(onLine func(string), policy RestartPolicy) (*Supervisor, error) {
+	stop, err := newWaker()
+	if err != nil {
+		return nil, s.opErr("supervise", err)
+	}
+	sv := &Supervisor{
+		s:      s,
+		onLine: onLine,
+		policy: policy,
+		stop:   stop,
+		events: make(chan SupervisorEvent, 64),
+		done:   make(chan struct{}),
+	}
+	go sv.run()
+	return sv, nil
+}
+
+// Events returns the channel events are published on. It is closed after
+// EventGaveUp or EventStopped.
+func (sv *Supervisor) Events() <-chan SupervisorEvent { return sv.events }
+
+// Done is closed when the Supervisor has stopped.
+func (sv *Supervisor) Done() <-chan struct{} { return sv.done }
+
+// Err returns the error the Supervisor gave up on, once Done is closed; nil
+// if it was stopped.
+func (sv *Supervisor) Err() error {
+	<-sv.done
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	return sv.err
+}
+
+// Dropped returns the number of events lost to a full Events channel.
+func (sv *Supervisor) Dropped() uint64 {
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	return sv.dropped
+}
+
+// Stop ends the read loop, leaving the reader open, and waits for the
+// Supervisor to finish.
+func (sv *Supervisor) Stop() {
+	sv.stop.close()
+	<-sv.done
+}
+
+func (sv *Supervisor) run() {
+	defer close(sv.done)
+	defer close(sv.events)
+	defer sv.stop.close() // release the pipe however the loop ends
+	p := sv.policy
+	backoff, maxBackoff, healthy := p.Backoff, p.MaxBackoff, p.Healthy
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 30 * time.Second
+	}
+	if healthy <= 0 {
+		healthy = time.Minute
+	}
+	attempt := 0
+	sv.publish(SupervisorEvent{Kind: EventStarted})
+	for {
+		if !sv.stop.enter() {
+			sv.publish(SupervisorEvent{Kind: EventStopped})
+			return
+		}
+		start := time.Now()
+		var loopErr error
+		sv.s.readLinesLoop(sv.stop, sv.onLine, func(err error) {
+			loopErr = err
+			sv.publish(SupervisorEvent{Kind: EventError, Attempt: attempt, Err: err})
+		})
+		sv.stop.exit()
+		if sv.stopped() {
+			return
+		}
+		if loopErr == nil {
+			continue // the port was reopened elsewhere
+		}
+		if time.Since(start) >= healthy {
+			attempt = 0
+		}
+		for {
+			if p.Retry != nil && !p.Retry(loopErr) || p.MaxRestarts > 0 && attempt >= p.MaxRestarts {
+				sv.giveUp(loopErr)
+				return
+			}
+			attempt++
+			wait := backoff << min(attempt-1, 30)
+			if wait <= 0 || wait > maxBackoff {
+				wait = maxBackoff
+			}
+			t := time.NewTimer(wait)
+			select {
+			case <-t.C:
+			case <-sv.stop.done:
+				t.Stop()
+				sv.publish(SupervisorEvent{Kind: EventStopped})
+				return
+			}
+			if loopErr = sv.s.Reopen(); loopErr == nil {
+				break
+			}
+			if sv.stopped() {
+				return
+			}
+			sv.publish(SupervisorEvent{Kind: EventError, Attempt: attempt, Err: loopErr})
+		}
+		sv.publish(SupervisorEvent{Kind: EventRestarted, Attempt: attempt})
+	}
+}
+
+// stopped reports whether Stop or Close ended the loop, publishing
+// EventStopped if so.
+func (sv *Supervisor) stopped() bool {
+	if !sv.stop.fired() && !sv.s.closed.Load() {
+		return false
+	}
+	sv.publish(SupervisorEvent{Kind: EventStopped})
+	return true
+}
+
+func (sv *Supervisor) giveUp(err error) {
+	sv.mu.Lock()
+	sv.err = err
+	sv.mu.Unlock()
+	sv.publish(SupervisorEvent{Kind: EventGaveUp, Err: err})
+}
+
+// publish sends ev without blocking.
+func (sv *Supervisor) publish(ev SupervisorEvent) {
+	ev.Time = time.Now()
+	select {
+	case sv.events <- ev:
+	default:
+		sv.mu.Lock()
+		sv.dropped++
+		sv.mu.Unlock()
+	}
+}
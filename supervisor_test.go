@@ -0,0 +1,125 @@
+package serial
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+)
+
+// nextEvent returns the next Supervisor event, failing after a second.
+func nextEvent(t *testing.T, sv *Supervisor) SupervisorEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-sv.Events():
+		require.True(t, ok, "events closed")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event")
+	}
+	return SupervisorEvent{}
+}
+
+func TestSupervisor(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	lines := make(chan string, 4)
+	sv, err := reader.Supervise(func(l string) { lines <- l }, RestartPolicy{})
+	require.NoError(t, err)
+	require.Equal(t, EventStarted, nextEvent(t, sv).Kind)
+
+	_, err = master.Write([]byte("a\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a", <-lines)
+
+	sv.Stop()
+	ev := nextEvent(t, sv)
+	require.Equal(t, EventStopped, ev.Kind)
+	require.Equal(t, "stopped", ev.Kind.String())
+	_, ok := <-sv.Events()
+	require.False(t, ok)
+	require.NoError(t, sv.Err())
+
+	// The reader stays open after Stop.
+	_, err = master.Write([]byte("b\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "b", line)
+}
+
+func TestSupervisor_Restart(t *testing.T) {
+	master, slave, err := pty.Open()
+	require.NoError(t, err)
+	defer slave.Close()
+	reader, err := Open(Config{Device: slave.Name(), Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	sv, err := reader.Supervise(func(string) {}, RestartPolicy{MaxRestarts: 2, Backoff: time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, EventStarted, nextEvent(t, sv).Kind)
+
+	master.Close() // the device goes away
+	ev := nextEvent(t, sv)
+	require.Equal(t, EventError, ev.Kind)
+	require.Error(t, ev.Err)
+	// The pty slave reopens, but reads on it keep failing.
+	for ev.Kind != EventGaveUp {
+		ev = nextEvent(t, sv)
+		require.LessOrEqual(t, ev.Attempt, 2)
+	}
+	require.Error(t, ev.Err)
+	<-sv.Done()
+	require.Equal(t, ev.Err, sv.Err())
+}
+
+func TestSupervisor_Retry(t *testing.T) {
+	reader, _ := newTestReader(t, Config{Access: WriteOnly})
+	var seen error
+	sv, err := reader.Supervise(func(string) {}, RestartPolicy{Retry: func(err error) bool {
+		seen = err
+		return false
+	}})
+	require.NoError(t, err)
+	var kinds []EventKind
+	for ev := range sv.Events() {
+		kinds = append(kinds, ev.Kind)
+	}
+	require.Equal(t, []EventKind{EventStarted, EventError, EventGaveUp}, kinds)
+	require.ErrorIs(t, sv.Err(), ErrAccessMode)
+	require.True(t, errors.Is(seen, ErrAccessMode))
+}
+
+func TestSupervisor_GaveUpReleasesFDs(t *testing.T) {
+	reader, _ := newTestReader(t, Config{Access: WriteOnly})
+	openFDs := func() int {
+		fds, err := os.ReadDir("/dev/fd")
+		if err != nil {
+			t.Skip("no /dev/fd:", err)
+		}
+		return len(fds)
+	}
+	before := openFDs()
+	for range 10 {
+		sv, err := reader.Supervise(func(string) {}, RestartPolicy{Retry: func(error) bool { return false }})
+		require.NoError(t, err)
+		for range sv.Events() {
+		}
+		require.ErrorIs(t, sv.Err(), ErrAccessMode) // gave up
+	}
+	require.Equal(t, before, openFDs())
+}
+
+func TestSupervisor_Close(t *testing.T) {
+	reader, _ := newTestReader(t, Config{})
+	sv, err := reader.Supervise(func(string) {}, RestartPolicy{})
+	require.NoError(t, err)
+	require.Equal(t, EventStarted, nextEvent(t, sv).Kind)
+	require.NoError(t, reader.Close())
+	require.Equal(t, EventStopped, nextEvent(t, sv).Kind)
+	<-sv.Done()
+	require.NoError(t, sv.Err())
+}
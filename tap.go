@@ -0,0 +1,47 @@
+package serial
+
+import "sync"
+
+// rawTap is one callback registered with TapRaw.
+type rawTap struct {
+	fn func([]byte)
+}
+
+// TapRaw registers fn to receive every chunk of bytes read from the port,
+// before framing, alongside the line callbacks: the same read syscall feeds
+// both, so the exact byte stream can be archived while it is parsed without
+// opening the device twice. Chunks come from ReadLinesLoop, ReadFramesLoop,
+// ReadLine and Read alike.
+//
+// fn runs on the reading goroutine, so it must be quick (an archive file
+// write is fine), and the chunk is only valid during the call; copy it to
+// retain it. The returned function removes the tap; it is safe to call more
+// than once.
+func (s *SerialReader) TapRaw(fn func(chunk []byte)) (remove func()) {
+	t := &rawTap{fn: fn}
+	s.subMu.Lock()
+	s.taps = append(s.taps, t)
+	s.subMu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.subMu.Lock()
+			defer s.subMu.Unlock()
+			for i, other := range s.taps {
+				if other == t {
+					s.taps = append(s.taps[:i], s.taps[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// tapRaw passes b to every tap.
+func (s *SerialReader) tapRaw(b []byte) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	for _, t := range s.taps {
+		t.fn(b)
+	}
+}
@@ -0,0 +1,41 @@
+package serial
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_TapRaw(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	var (
+		mu      sync.Mutex
+		archive bytes.Buffer
+	)
+	remove := reader.TapRaw(func(b []byte) {
+		mu.Lock()
+		archive.Write(b)
+		mu.Unlock()
+	})
+
+	lines := make(chan string, 4)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	_, err := master.Write([]byte("a\n\x00b\n"))
+	require.NoError(t, err)
+	require.Equal(t, "a", <-lines)
+	require.Equal(t, "\x00b", <-lines)
+	mu.Lock()
+	require.Equal(t, "a\n\x00b\n", archive.String())
+	mu.Unlock()
+
+	remove()
+	remove()
+	_, err = master.Write([]byte("c\n"))
+	require.NoError(t, err)
+	require.Equal(t, "c", <-lines) // taps run before framing
+	mu.Lock()
+	require.Equal(t, "a\n\x00b\n", archive.String())
+	mu.Unlock()
+}
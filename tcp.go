@@ -0,0 +1,58 @@
+package serial
+
+import (
+	"net"
+	"syscall"
+	"time"
+)
+
+// dialTimeout bounds connecting to a network serial server.
+const dialTimeout = 10 * time.Second
+
+// DialTCP connects to a networked serial server (ser2net, Moxa NPort and
+// similar device servers in raw TCP mode) at addr ("host:port") and returns
+// a SerialReader with the same API as a local port: ReadLinesLoop, WriteLine,
+// Reopen (which reconnects), subscriptions and so on. cfg.Device, if empty,
+// is set to addr for error messages; the serial line settings in cfg are
+// those of the server and are ignored. A connection closed by the server is
+// reported as ErrDeviceRemoved.
+func DialTCP(addr string, cfg Config) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = addr
+	}
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		return dialPort("tcp", addr, cfg)
+	})
+}
+
+// dialPort connects to addr and adopts the socket as a port, so the poll
+// engine serves it exactly like a tty.
+func dialPort(network, addr string, cfg Config) (*port, error) {
+	conn, err := net.DialTimeout(network, addr, dialTimeout)
+	if err != nil {
+		return nil, &SerialError{Device: cfg.Device, Op: "dial", Err: err}
+	}
+	return adoptConn(conn, cfg)
+}
+
+// adoptConn takes over the socket behind conn as a port and closes conn.
+func adoptConn(conn net.Conn, cfg Config) (*port, error) {
+	defer conn.Close()
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil, &SerialError{Device: cfg.Device, Op: "dial", Err: syscall.ENOTSUP}
+	}
+	return adoptFD(sc, "dial", cfg)
+}
+
+// DialUnix connects to a stream socket at path, such as a co-located device
+// simulator or a privilege-separated port broker, and returns a SerialReader
+// that behaves exactly like DialTCP's.
+func DialUnix(path string, cfg Config) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = path
+	}
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		return dialPort("unix", path, cfg)
+	})
+}
@@ -0,0 +1,94 @@
+package serial
+
+import (
+	"bufio"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	conns := make(chan net.Conn, 2)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+		}
+	}()
+
+	reader, err := DialTCP(l.Addr().String(), Config{Delimiter: "\r\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+	server := <-conns
+	defer server.Close()
+
+	lines := make(chan string, 4)
+	errs := make(chan error, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(err error) { errs <- err })
+
+	_, err = server.Write([]byte("$GPZDA,1\r\n$GP"))
+	require.NoError(t, err)
+	require.Equal(t, "$GPZDA,1", <-lines)
+
+	require.NoError(t, reader.WriteLine("PING", "\r\n"))
+	got, err := bufio.NewReader(server).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "PING\r\n", got)
+
+	// The server going away looks like an unplugged device.
+	server.Close()
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, ErrDeviceRemoved)
+	case <-time.After(time.Second):
+		t.Fatal("no error after server closed the connection")
+	}
+
+	// Reopen reconnects, and the partial "$GP" from before is continued.
+	require.NoError(t, reader.Reopen())
+	server = <-conns
+	defer server.Close()
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	_, err = server.Write([]byte("ZDA,2\r\n"))
+	require.NoError(t, err)
+	require.Equal(t, "$GPZDA,2", <-lines)
+}
+
+func TestDialUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sim.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	defer l.Close()
+	conns := make(chan net.Conn, 1)
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			conns <- c
+		}
+	}()
+
+	reader, err := DialUnix(path, Config{Delimiter: "\n"})
+	require.NoError(t, err)
+	defer reader.Close()
+	server := <-conns
+	defer server.Close()
+
+	_, err = server.Write([]byte("sim\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "sim", line)
+
+	require.NoError(t, reader.WriteLine("cmd", "\n"))
+	got, err := bufio.NewReader(server).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "cmd\n", got)
+}
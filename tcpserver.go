@@ -0,0 +1,286 @@
+package serial
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+)
+
+// ServerMode selects the protocol a TCPServer speaks to its clients.
+type ServerMode int
+
+const (
+	// ServeRaw passes bytes through unchanged, like ser2net's raw mode.
+	ServeRaw ServerMode = iota
+	// ServeRFC2217 speaks Telnet COM-Port-Control, so clients can change the
+	// line settings and modem lines of the local port.
+	ServeRFC2217
+)
+
+// AccessPolicy decides how a TCPServer handles concurrent clients.
+type AccessPolicy int
+
+const (
+	// Exclusive serves one client at a time; others are turned away.
+	Exclusive AccessPolicy = iota
+	// Shared lets every client receive the port's data and write to it.
+	Shared
+)
+
+// ErrServerClosed is returned by TCPServer.Serve after Close.
+var ErrServerClosed = errors.New("serial: server closed")
+
+// RFC 2217 server-side commands beyond those the client sends.
+const (
+	cpcSignature = 0
+	cpcPurgeData = 12
+	cpcServerOff = 100 // responses carry the command code plus 100
+)
+
+// TCPServer exposes a SerialReader over TCP, giving a headless gateway
+// ser2net-like functionality. It reads the port through the raw Read path,
+// so no other reader may run on it while the server does.
+type TCPServer struct {
+	mode   ServerMode
+	access AccessPolicy
+	sr     *SerialReader
+
+	pumpOnce sync.Once
+
+	mu        sync.Mutex
+	clients   map[*tcpClient]struct{}
+	listeners map[net.Listener]struct{}
+	closed    bool
+}
+
+// NewTCPServer returns a server for sr.
+func NewTCPServer(sr *SerialReader, mode ServerMode, access AccessPolicy) *TCPServer {
+	return &TCPServer{
+		mode:      mode,
+		access:    access,
+		sr:        sr,
+		clients:   make(map[*tcpClient]struct{}),
+		listeners: make(map[net.Listener]struct{}),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and calls Serve.
+func (s *TCPServer) ListenAndServe(addr string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts clients on l until Close is called or the port is closed.
+func (s *TCPServer) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+	s.pumpOnce.Do(func() { go s.pump() })
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+		c := &tcpClient{srv: s, conn: conn, out: make(chan []byte, 64)}
+		s.mu.Lock()
+		if s.access == Exclusive && len(s.clients) > 0 {
+			s.mu.Unlock()
+			conn.Write([]byte("port busy\r\n"))
+			conn.Close()
+			continue
+		}
+		s.clients[c] = struct{}{}
+		s.mu.Unlock()
+		go c.serve()
+	}
+}
+
+// Close stops the listeners and disconnects all clients. The port stays open.
+func (s *TCPServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for l := range s.listeners {
+		l.Close()
+	}
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	return nil
+}
+
+// pump copies everything read from the port to the connected clients until
+// the port is closed. Clients that cannot keep up are disconnected.
+func (s *TCPServer) pump() {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.sr.Read(buf)
+		if err != nil {
+			if errors.Is(err, ErrTimeout) {
+				continue
+			}
+			s.Close()
+			return
+		}
+		chunk := append([]byte(nil), buf[:n]...)
+		s.mu.Lock()
+		for c := range s.clients {
+			select {
+			case c.out <- chunk:
+			default:
+				c.conn.Close()
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// tcpClient is one connection to a TCPServer.
+type tcpClient struct {
+	srv  *TCPServer
+	conn net.Conn
+	out  chan []byte
+	wmu  sync.Mutex
+}
+
+func (c *tcpClient) serve() {
+	done := make(chan struct{})
+	defer func() {
+		c.srv.mu.Lock()
+		delete(c.srv.clients, c)
+		c.srv.mu.Unlock()
+		c.conn.Close()
+		close(done)
+	}()
+	rfc := c.srv.mode == ServeRFC2217
+	go func() {
+		for {
+			select {
+			case chunk := <-c.out:
+				if rfc {
+					chunk = escapeIAC(chunk)
+				}
+				if c.write(chunk) != nil {
+					c.conn.Close()
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	if rfc {
+		c.write([]byte{
+			telnetIAC, telnetWILL, telnetBinary,
+			telnetIAC, telnetDO, telnetBinary,
+			telnetIAC, telnetWILL, telnetSGA,
+			telnetIAC, telnetDO, telnetComPort,
+		})
+	}
+	var dec telnetDecoder
+	buf := make([]byte, 4096)
+	for {
+		n, err := c.conn.Read(buf)
+		if err != nil {
+			return
+		}
+		data := buf[:n]
+		if rfc {
+			data = dec.decode(data, c.answer, c.comPort)
+		}
+		if len(data) > 0 {
+			if _, err := c.srv.sr.Write(data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *tcpClient) write(p []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_, err := c.conn.Write(p)
+	return err
+}
+
+// answer refuses options other than those RFC 2217 needs.
+func (c *tcpClient) answer(verb, opt byte) {
+	switch verb {
+	case telnetDO:
+		if opt != telnetBinary && opt != telnetSGA {
+			c.write([]byte{telnetIAC, telnetWONT, opt})
+		}
+	case telnetWILL:
+		if opt != telnetBinary && opt != telnetSGA && opt != telnetComPort {
+			c.write([]byte{telnetIAC, telnetDONT, opt})
+		}
+	}
+}
+
+// comPort executes a COM-PORT-OPTION command and acknowledges it with the
+// resulting value, as RFC 2217 requires.
+func (c *tcpClient) comPort(sb []byte) {
+	if len(sb) < 2 || sb[0] != telnetComPort {
+		return
+	}
+	sr := c.srv.sr
+	cur := sr.Config()
+	code, val := sb[1], sb[2:]
+	reply := append([]byte(nil), val...)
+	switch code {
+	case cpcSignature:
+		reply = []byte("go-linux-serial")
+	case cpcSetBaudRate:
+		if len(val) == 4 {
+			if baud := binary.BigEndian.Uint32(val); baud != 0 {
+				if sr.SetLineSettings(int(baud), 0, cur.Parity, 0) == nil {
+					cur.BaudRate = int(baud)
+				}
+			}
+			reply = binary.BigEndian.AppendUint32(nil, uint32(cur.BaudRate))
+		}
+	case cpcSetDataSize:
+		if len(val) == 1 && val[0] != 0 {
+			sr.SetLineSettings(0, int(val[0]), cur.Parity, 0)
+		}
+	case cpcSetParity:
+		if len(val) == 1 && val[0] != 0 {
+			sr.SetLineSettings(0, 0, Parity(val[0]-1), 0)
+		}
+	case cpcSetStopSize:
+		if len(val) == 1 && val[0] != 0 {
+			sr.SetLineSettings(0, 0, cur.Parity, int(val[0]))
+		}
+	case cpcSetControl:
+		if len(val) == 1 {
+			switch val[0] {
+			case cpcDTROn, cpcDTROff:
+				sr.SetDTR(val[0] == cpcDTROn)
+			case cpcRTSOn, cpcRTSOff:
+				sr.SetRTS(val[0] == cpcRTSOn)
+			}
+		}
+	case cpcPurgeData:
+		if len(val) == 1 && val[0] != 2 {
+			sr.Drain() // 1 or 3: purge receive buffer
+		}
+	}
+	msg := []byte{telnetIAC, telnetSB, telnetComPort, code + cpcServerOff}
+	msg = append(msg, escapeIAC(reply)...)
+	c.write(append(msg, telnetIAC, telnetSE))
+}
@@ -0,0 +1,89 @@
+package serial
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func startTCPServer(t *testing.T, sr *SerialReader, mode ServerMode, access AccessPolicy) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := NewTCPServer(sr, mode, access)
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return l.Addr().String()
+}
+
+func TestTCPServer_RFC2217(t *testing.T) {
+	local, master := newTestReader(t, Config{BaudRate: 9600})
+	addr := startTCPServer(t, local, ServeRFC2217, Exclusive)
+
+	remote, err := DialRFC2217(addr, Config{BaudRate: 19200, Delimiter: "\n"})
+	require.NoError(t, err)
+	defer remote.Close()
+
+	// Line settings from the client reach the local port.
+	require.Eventually(t, func() bool {
+		local.mu.Lock()
+		defer local.mu.Unlock()
+		return local.config.BaudRate == 19200
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, remote.WriteLine("A\xffB", "\n"))
+	buf := make([]byte, 16)
+	master.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := io.ReadAtLeast(master, buf, 4)
+	require.NoError(t, err)
+	require.Equal(t, "A\xffB\n", string(buf[:n]))
+
+	_, err = master.Write([]byte("x\xffy\n"))
+	require.NoError(t, err)
+	line, err := remote.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "x\xffy", line)
+
+	// A second client is turned away while the first is connected.
+	c, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer c.Close()
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	msg, err := io.ReadAll(c)
+	require.NoError(t, err)
+	require.Equal(t, "port busy\r\n", string(msg))
+}
+
+func TestTCPServer_RawShared(t *testing.T) {
+	local, master := newTestReader(t, Config{})
+	addr := startTCPServer(t, local, ServeRaw, Shared)
+
+	var clients []net.Conn
+	for i := 0; i < 2; i++ {
+		c, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer c.Close()
+		clients = append(clients, c)
+	}
+	time.Sleep(20 * time.Millisecond) // let the server register both clients
+
+	_, err := master.Write([]byte("sample\n"))
+	require.NoError(t, err)
+	for _, c := range clients {
+		buf := make([]byte, 7)
+		c.SetReadDeadline(time.Now().Add(time.Second))
+		_, err := io.ReadFull(c, buf)
+		require.NoError(t, err)
+		require.Equal(t, "sample\n", string(buf))
+	}
+
+	_, err = clients[1].Write([]byte("cmd\n"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(master, buf)
+	require.NoError(t, err)
+	require.Equal(t, "cmd\n", string(buf))
+}
@@ -0,0 +1,42 @@
+//go:build darwin || freebsd
+
+package serial
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlGetTermios = unix.TIOCGETA
+	ioctlSetTermios = unix.TIOCSETA
+	ioctlInQueue    = 0x4004667f // FIONREAD
+	ioctlOutQueue   = unix.TIOCOUTQ
+
+	cmspar = 0 // BSD termios has no mark/space parity
+
+	fread = 0x1 // FREAD, the TIOCFLUSH input queue selector
+)
+
+// setSpeed sets the input and output baud rate of t.
+func setSpeed(t *unix.Termios, baud int) {
+	setSpeeds(&t.Ispeed, &t.Ospeed, baudToUnix(baud))
+}
+
+// setSpeeds covers the speed fields being 32 bits on FreeBSD and 64 on macOS.
+func setSpeeds[T uint32 | uint64](in, out *T, speed uint32) {
+	*in, *out = T(speed), T(speed)
+}
+
+// drainOutput waits until everything written has been transmitted
+// (tcdrain).
+func drainOutput(fd int) error {
+	return unix.IoctlSetInt(fd, unix.TIOCDRAIN, 0)
+}
+
+// flushInput discards data received but not yet read.
+func flushInput(fd int) error {
+	return unix.IoctlSetPointerInt(fd, unix.TIOCFLUSH, fread)
+}
+
+// readOverruns fails: the BSD tty layer has no overrun counters.
+func readOverruns(fd int) (overrun, bufOverrun uint64, err error) {
+	return 0, 0, unix.ENOTTY
+}
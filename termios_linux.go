@@ -0,0 +1,53 @@
+package serial
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+	ioctlInQueue    = unix.TIOCINQ
+	ioctlOutQueue   = unix.TIOCOUTQ
+
+	// cmspar selects mark/space ("stick") parity; zero where unsupported.
+	cmspar = unix.CMSPAR
+)
+
+// setSpeed sets the input and output baud rate of t.
+func setSpeed(t *unix.Termios, baud int) {
+	t.Cflag &^= unix.CBAUD
+	t.Cflag |= baudToUnix(baud)
+}
+
+// drainOutput waits until everything written has been transmitted
+// (tcdrain).
+func drainOutput(fd int) error {
+	return unix.IoctlSetInt(fd, unix.TCSBRK, 1)
+}
+
+// flushInput discards data received but not yet read.
+func flushInput(fd int) error {
+	return unix.IoctlSetInt(fd, unix.TCFLSH, unix.TCIFLUSH)
+}
+
+// serialICounter is struct serial_icounter_struct, filled by TIOCGICOUNT.
+type serialICounter struct {
+	cts, dsr, rng, dcd, rx, tx  int32
+	frame, overrun, parity, brk int32
+	bufOverrun                  int32
+	_                           [9]int32
+}
+
+// readOverruns returns the driver's counts of characters lost to UART FIFO
+// and tty buffer overruns. Drivers without counters, PTYs among them, fail.
+func readOverruns(fd int) (overrun, bufOverrun uint64, err error) {
+	var ic serialICounter
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.TIOCGICOUNT, uintptr(unsafe.Pointer(&ic)))
+	if errno != 0 {
+		return 0, 0, errno
+	}
+	return uint64(uint32(ic.overrun)), uint64(uint32(ic.bufOverrun)), nil
+}
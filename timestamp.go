@@ -0,0 +1,125 @@
+package serial
+
+import (
+	"strconv"
+	"time"
+)
+
+// ClockSource selects the clocks read to timestamp a line.
+type ClockSource int
+
+const (
+	ClockRealtime  ClockSource = iota // CLOCK_REALTIME: UTC, stepped when NTP corrects it
+	ClockMonotonic                    // CLOCK_MONOTONIC: time since boot, never stepped
+	ClockBoth                         // both, read back to back
+)
+
+var clockSourceNames = [...]string{"realtime", "monotonic", "both"}
+
+// String returns "realtime", "monotonic" or "both".
+func (c ClockSource) String() string {
+	if c < 0 || int(c) >= len(clockSourceNames) {
+		return "ClockSource(" + strconv.Itoa(int(c)) + ")"
+	}
+	return clockSourceNames[c]
+}
+
+// Timestamp is the capture time of a line on the selected clocks.
+type Timestamp struct {
+	Wall time.Time     // CLOCK_REALTIME; zero with ClockMonotonic
+	Mono time.Duration // CLOCK_MONOTONIC; zero with ClockRealtime
+}
+
+// Stamp reads the clocks selected by clock.
+func Stamp(clock ClockSource) Timestamp {
+	var t Timestamp
+	if clock != ClockMonotonic {
+		t.Wall = wallClock()
+	}
+	if clock != ClockRealtime {
+		t.Mono = monoClock()
+	}
+	return t
+}
+
+// Offset returns the wall-clock time at which the monotonic clock read
+// zero, as seen at capture; ok is false unless both clocks were read.
+// Offsets taken before and after NTP steps the clock differ by the step,
+// so an archive can map every Mono reading to UTC with a single offset of
+// its choosing:
+//
+//	boot, _ := first.Time.Offset()
+//	utc := boot.Add(line.Time.Mono)
+func (t Timestamp) Offset() (boot time.Time, ok bool) {
+	if t.Wall.IsZero() || t.Mono == 0 {
+		return time.Time{}, false
+	}
+	return t.Wall.Add(-t.Mono), true
+}
+
+// StampedLine is a line with the time it was captured.
+type StampedLine struct {
+	Line string
+	Time Timestamp
+}
+
+// ReadStampedLinesLoop is ReadLinesLoop that timestamps each line on the
+// clocks selected by clock, read as soon as the read carrying its delimiter
+// returns. With Config.SampleRate set, lines that arrived in one read are
+// back-interpolated from that time. Checksum verification and
+// subscriptions apply as in ReadLinesLoop; Middleware, Workers and Async,
+// which would separate lines from their stamps, do not.
+func (s *SerialReader) ReadStampedLinesLoop(clock ClockSource, onLine func(StampedLine), onError func(error)) {
+	var stamp Timestamp
+	deliver := s.validateFunc(s.deliverFunc(func(line string) {
+		onLine(StampedLine{Line: line, Time: stamp})
+	}))
+	var period time.Duration
+	if s.config.SampleRate > 0 {
+		period = time.Duration(float64(time.Second) / s.config.SampleRate)
+	}
+	p := s.port()
+	line := s.partial.takeLine(p)
+	defer func() { s.partial.keepLine(p, line) }()
+	scanned := 0 // line[:scanned] holds no line end, as in readLinesLoop
+	var batch [][]byte
+	split := func() bool {
+		batch = batch[:0]
+		n := splitLines(line, scanned, s.lineEnd, func(b []byte) { batch = append(batch, b) })
+		var now Timestamp
+		if len(batch) > 0 {
+			now = Stamp(clock)
+		}
+		for i, b := range batch {
+			stamp = now
+			if back := time.Duration(len(batch)-1-i) * period; back > 0 {
+				if !stamp.Wall.IsZero() {
+					stamp.Wall = stamp.Wall.Add(-back)
+				}
+				if stamp.Mono != 0 {
+					stamp.Mono -= back
+				}
+			}
+			s.linesRead.Add(1)
+			l := string(b)
+			s.transcribe('<', l)
+			deliver(l)
+		}
+		line = line[:copy(line, line[n:])]
+		scanned = len(line)
+		if s.config.MaxLineLength > 0 && len(line) > s.config.MaxLineLength {
+			line, scanned = line[:0], 0
+			err := s.opErr("read", ErrLineTooLong)
+			onError(err)
+			return s.keepGoing(err)
+		}
+		return true
+	}
+	if len(line) > 0 && !split() {
+		return
+	}
+	s.readChunks(nil, func(chunk []byte, _ time.Time) bool {
+		line = append(line, chunk...)
+		return split()
+	}, onError)
+}
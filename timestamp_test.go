@@ -0,0 +1,57 @@
+package serial
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStamp(t *testing.T) {
+	ts := Stamp(ClockRealtime)
+	require.WithinDuration(t, time.Now(), ts.Wall, time.Second)
+	require.Zero(t, ts.Mono)
+	_, ok := ts.Offset()
+	require.False(t, ok)
+
+	ts = Stamp(ClockMonotonic)
+	require.True(t, ts.Wall.IsZero())
+	require.Positive(t, ts.Mono)
+
+	a := Stamp(ClockBoth)
+	b := Stamp(ClockBoth)
+	bootA, ok := a.Offset()
+	require.True(t, ok)
+	bootB, _ := b.Offset()
+	require.WithinDuration(t, bootA, bootB, 10*time.Millisecond)
+	require.Equal(t, a.Wall, bootA.Add(a.Mono))
+	require.Equal(t, "both", ClockBoth.String())
+}
+
+func TestSerialReader_ReadStampedLinesLoop(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	lines := make(chan StampedLine, 1)
+	go reader.ReadStampedLinesLoop(ClockBoth, func(l StampedLine) { lines <- l }, func(error) {})
+	before := Stamp(ClockMonotonic)
+	_, err := master.Write([]byte("T=21.5\n"))
+	require.NoError(t, err)
+	l := <-lines
+	require.Equal(t, "T=21.5", l.Line)
+	require.GreaterOrEqual(t, l.Time.Mono, before.Mono)
+	require.WithinDuration(t, time.Now(), l.Time.Wall, time.Second)
+}
+
+func TestSerialReader_ReadStampedLinesLoop_SampleRate(t *testing.T) {
+	reader, master := newTestReader(t, Config{SampleRate: 100})
+	lines := make(chan StampedLine, 3)
+	go reader.ReadStampedLinesLoop(ClockBoth, func(l StampedLine) { lines <- l }, func(error) {})
+	// Three samples batched into one transfer.
+	_, err := master.Write([]byte("1\n2\n3\n"))
+	require.NoError(t, err)
+	a, b, c := <-lines, <-lines, <-lines
+	require.Equal(t, []string{"1", "2", "3"}, []string{a.Line, b.Line, c.Line})
+	require.Equal(t, 10*time.Millisecond, b.Time.Mono-a.Time.Mono)
+	require.Equal(t, 10*time.Millisecond, c.Time.Mono-b.Time.Mono)
+	require.Equal(t, 10*time.Millisecond, c.Time.Wall.Sub(b.Time.Wall))
+	require.EqualValues(t, 3, reader.Stats().Lines)
+}
@@ -0,0 +1,27 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// wallClock reads CLOCK_REALTIME, or returns the zero time if it cannot.
+func wallClock() time.Time {
+	var ts unix.Timespec
+	if unix.ClockGettime(unix.CLOCK_REALTIME, &ts) != nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Unix())
+}
+
+// monoClock reads CLOCK_MONOTONIC, or returns 0 if it cannot.
+func monoClock() time.Duration {
+	var ts unix.Timespec
+	if unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts) != nil {
+		return 0
+	}
+	return time.Duration(ts.Nano())
+}
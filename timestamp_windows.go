@@ -0,0 +1,15 @@
+package serial
+
+import "time"
+
+// start anchors monoClock: Windows has no CLOCK_MONOTONIC, so Mono counts
+// from process start on the runtime's monotonic clock instead of from boot.
+var start = time.Now()
+
+func wallClock() time.Time {
+	return time.Now().Round(0)
+}
+
+func monoClock() time.Duration {
+	return time.Since(start)
+}
@@ -0,0 +1,219 @@
+package serial
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Transcript is a text log of the lines exchanged with a device, both
+// directions interleaved in the order they happened, so support can see
+// exactly what the application and the device said to each other. Each
+// entry is one line: a UTC timestamp, ">" for a line sent to the device or
+// "<" for one received, and the line Go-quoted, so control characters and
+// binary bytes survive:
+//
+//	2026-10-16T09:30:01.250113Z > "AT+CSQ"
+//	2026-10-16T09:30:01.263870Z < "+CSQ: 21,99"
+//
+// The file is rotated by size; see OpenTranscript. Attach it to a reader
+// with Transcribe, or record lines yourself with Sent and Received. A
+// Transcript is safe for concurrent use.
+type Transcript struct {
+	path    string
+	maxSize int64
+	keep    int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+	err  error
+	buf  []byte
+}
+
+// OpenTranscript opens path for appending. Once the file would grow past
+// maxSize bytes (10MB if zero or less) it is renamed to path.1, path.1 to
+// path.2 and so on, keeping keep old files (none if zero or less), and a
+// new path is started.
+func OpenTranscript(path string, maxSize int64, keep int) (*Transcript, error) {
+	if maxSize <= 0 {
+		maxSize = 10 << 20
+	}
+	t := &Transcript{path: path, maxSize: maxSize, keep: max(keep, 0)}
+	if err := t.open(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Sent records line as sent to the device.
+func (t *Transcript) Sent(line string) {
+	t.record('>', line, time.Now())
+}
+
+// Received records line as received from the device.
+func (t *Transcript) Received(line string) {
+	t.record('<', line, time.Now())
+}
+
+// Err returns the first error writing or rotating the file; entries after
+// it are lost.
+func (t *Transcript) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Close closes the file. It returns Err if that is set.
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.f == nil {
+		return t.err
+	}
+	err := t.f.Close()
+	t.f = nil
+	if t.err == nil && err != nil {
+		t.err = err
+	}
+	return t.err
+}
+
+func (t *Transcript) record(dir byte, line string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil || t.f == nil {
+		return
+	}
+	b := now.UTC().AppendFormat(t.buf[:0], transcriptTime)
+	b = append(b, ' ', dir, ' ')
+	b = strconv.AppendQuote(b, line)
+	b = append(b, '\n')
+	t.buf = b
+	if t.size > 0 && t.size+int64(len(b)) > t.maxSize {
+		if t.err = t.rotate(); t.err != nil {
+			return
+		}
+	}
+	n, err := t.f.Write(b)
+	t.size += int64(n)
+	t.err = err
+}
+
+// transcriptTime is the timestamp layout of transcript entries.
+const transcriptTime = "2006-01-02T15:04:05.000000Z07:00"
+
+func (t *Transcript) open() error {
+	f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("serial: transcript: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("serial: transcript: %w", err)
+	}
+	t.f, t.size = f, st.Size()
+	return nil
+}
+
+// rotate shifts the old files up by one and starts a new one. Called with
+// t.mu held.
+func (t *Transcript) rotate() error {
+	if err := t.f.Close(); err != nil {
+		return fmt.Errorf("serial: transcript: %w", err)
+	}
+	t.f = nil
+	old := func(i int) string { return t.path + "." + strconv.Itoa(i) }
+	var err error
+	if t.keep == 0 {
+		err = os.Remove(t.path)
+	} else {
+		for i := t.keep - 1; i > 0; i-- {
+			if err := os.Rename(old(i), old(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("serial: transcript: %w", err)
+			}
+		}
+		err = os.Rename(t.path, old(1))
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("serial: transcript: %w", err)
+	}
+	return t.open()
+}
+
+// TranscriptEntry is one line of a transcript.
+type TranscriptEntry struct {
+	Time time.Time
+	Sent bool // sent to the device; false if received from it
+	Line string
+}
+
+// ReadTranscript parses a transcript written by Transcript, such as one
+// attached to a support ticket, for analysis or to act it out again with
+// serialtest.Simulator.Replay. Blank lines are skipped.
+func ReadTranscript(r io.Reader) ([]TranscriptEntry, error) {
+	var entries []TranscriptEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		text := sc.Text()
+		if text == "" {
+			continue
+		}
+		stamp, rest, _ := strings.Cut(text, " ")
+		dir, quoted, _ := strings.Cut(rest, " ")
+		ts, err := time.Parse(transcriptTime, stamp)
+		if err != nil || (dir != ">" && dir != "<") {
+			return entries, fmt.Errorf("serial: transcript line %d: malformed entry %q", n, text)
+		}
+		line, err := strconv.Unquote(quoted)
+		if err != nil {
+			return entries, fmt.Errorf("serial: transcript line %d: %w", n, err)
+		}
+		entries = append(entries, TranscriptEntry{Time: ts, Sent: dir == ">", Line: line})
+	}
+	return entries, sc.Err()
+}
+
+// Transcribe records every line the reader sends with WriteLine and every
+// line it receives through ReadLine or ReadLinesLoop in t. Received lines
+// are recorded as framed, before checksum verification and middleware, and
+// sent ones with their checksum. The returned function stops recording; it
+// does not close t.
+func (s *SerialReader) Transcribe(t *Transcript) (stop func()) {
+	s.subMu.Lock()
+	s.transcripts = append(s.transcripts, t)
+	s.subMu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.subMu.Lock()
+			defer s.subMu.Unlock()
+			for i, other := range s.transcripts {
+				if other == t {
+					s.transcripts = append(s.transcripts[:i], s.transcripts[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// transcribe records line in every transcript.
+func (s *SerialReader) transcribe(dir byte, line string) {
+	s.subMu.RLock()
+	defer s.subMu.RUnlock()
+	if len(s.transcripts) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, t := range s.transcripts {
+		t.record(dir, line, now)
+	}
+}
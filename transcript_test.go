@@ -0,0 +1,77 @@
+package serial
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialReader_Transcribe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	tr, err := OpenTranscript(path, 0, 0)
+	require.NoError(t, err)
+	reader, master := newTestReader(t, Config{})
+	stop := reader.Transcribe(tr)
+
+	require.NoError(t, reader.WriteLine("AT+CSQ", "\r\n"))
+	_, err = master.Write([]byte("+CSQ: 21,99\nbin\x00\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "+CSQ: 21,99", line)
+	lines := make(chan string, 1)
+	go reader.ReadLinesLoop(func(l string) { lines <- l }, func(error) {})
+	require.Equal(t, "bin\x00", <-lines)
+
+	stop()
+	require.NoError(t, reader.WriteLine("ATH", "\r\n"))
+	require.NoError(t, tr.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	entries := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	require.Len(t, entries, 3)
+	stamp := `^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}Z `
+	require.Regexp(t, regexp.MustCompile(stamp+`> "AT\+CSQ"$`), entries[0])
+	require.Regexp(t, regexp.MustCompile(stamp+`< "\+CSQ: 21,99"$`), entries[1])
+	require.Regexp(t, regexp.MustCompile(stamp+`< "bin\\x00"$`), entries[2])
+
+	parsed, err := ReadTranscript(strings.NewReader(string(b)))
+	require.NoError(t, err)
+	require.Len(t, parsed, 3)
+	require.Equal(t, []bool{true, false, false}, []bool{parsed[0].Sent, parsed[1].Sent, parsed[2].Sent})
+	require.Equal(t, "bin\x00", parsed[2].Line)
+	require.False(t, parsed[1].Time.Before(parsed[0].Time))
+
+	_, err = ReadTranscript(strings.NewReader("2026-10-16T09:30:01.250113Z ? \"x\"\n"))
+	require.Error(t, err)
+}
+
+func TestTranscript_Rotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.log")
+	tr, err := OpenTranscript(path, 100, 2)
+	require.NoError(t, err)
+	for range 10 {
+		tr.Sent("0123456789") // 43 bytes an entry, two per file
+	}
+	require.NoError(t, tr.Close())
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		b, err := os.ReadFile(name)
+		require.NoError(t, err)
+		require.Equal(t, 2, strings.Count(string(b), "\n"), name)
+	}
+	require.NoFileExists(t, path+".3")
+
+	// Reopening appends to the current file.
+	tr, err = OpenTranscript(path, 200, 2)
+	require.NoError(t, err)
+	tr.Received("x")
+	require.NoError(t, tr.Close())
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, 3, strings.Count(string(b), "\n"))
+}
@@ -0,0 +1,168 @@
+package serial
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+)
+
+// UDPOptions configures a UDP port.
+type UDPOptions struct {
+	// LockRemote makes ListenUDP accept datagrams only from the first peer it
+	// hears from. DialUDP always accepts datagrams from its remote only.
+	LockRemote bool
+	// RawDatagrams passes received datagrams through unchanged. By default a
+	// datagram that does not end with Config.Delimiter has it appended, so
+	// that every datagram reads as one line.
+	RawDatagrams bool
+}
+
+// DialUDP returns a SerialReader that exchanges datagrams with addr
+// ("host:port"), for telemetry links and serial-to-UDP radio bridges. Each
+// WriteLine or Write call is sent as one datagram. Datagrams longer than 4096
+// bytes are truncated.
+func DialUDP(addr string, cfg Config, opts UDPOptions) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = addr
+	}
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		conn, err := net.Dial("udp", addr)
+		if err != nil {
+			return nil, &SerialError{Device: cfg.Device, Op: "dial", Err: err}
+		}
+		return bridgeUDP(conn.(*net.UDPConn), conn.RemoteAddr(), cfg, opts)
+	})
+}
+
+// ListenUDP returns a SerialReader that receives datagrams on the local
+// address addr and sends each write to the peer it last heard from (with
+// LockRemote, the first one). Writes before any datagram has arrived are
+// discarded. Otherwise it behaves like DialUDP.
+func ListenUDP(addr string, cfg Config, opts UDPOptions) (*SerialReader, error) {
+	if cfg.Device == "" {
+		cfg.Device = addr
+	}
+	// Reopen binds the replacement socket while the old one is still open.
+	lc := net.ListenConfig{Control: func(_, _ string, c syscall.RawConn) error {
+		return reusePort(c)
+	}}
+	return openWith(cfg, func(cfg Config) (*port, error) {
+		conn, err := lc.ListenPacket(context.Background(), "udp", addr)
+		if err != nil {
+			return nil, &SerialError{Device: cfg.Device, Op: "listen", Err: err}
+		}
+		return bridgeUDP(conn.(*net.UDPConn), nil, cfg, opts)
+	})
+}
+
+// udpBridge relays between a UDP socket and a datagram socketpair whose
+// other end is the port's fd; the socketpair keeps datagram boundaries in
+// both directions.
+type udpBridge struct {
+	conn      *net.UDPConn
+	connected bool // conn was dialed; the kernel filters peers
+	local     *os.File
+	delim     []byte // appended to unterminated datagrams; nil for raw
+	lock      bool
+
+	mu   sync.Mutex
+	peer net.Addr
+}
+
+func bridgeUDP(conn *net.UDPConn, remote net.Addr, cfg Config, opts UDPOptions) (*port, error) {
+	p, local, err := bridgePort(true, "udp", cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	b := &udpBridge{
+		conn:      conn,
+		connected: remote != nil,
+		local:     local,
+		lock:      opts.LockRemote,
+		peer:      remote,
+	}
+	if !opts.RawDatagrams && cfg.Delimiter != "" {
+		b.delim = []byte(cfg.Delimiter)
+	}
+	go b.fromNet()
+	go b.toNet()
+	return p, nil
+}
+
+func (b *udpBridge) close() {
+	b.conn.Close()
+	b.local.Close()
+}
+
+// fromNet forwards received datagrams to the port.
+func (b *udpBridge) fromNet() {
+	defer b.close()
+	buf := make([]byte, 65536)
+	for {
+		n, from, err := b.conn.ReadFrom(buf)
+		if err != nil {
+			if isTransientUDP(err) {
+				continue
+			}
+			return // the port was closed
+		}
+		if !b.connected && !b.accept(from) {
+			continue
+		}
+		msg := buf[:n]
+		if b.delim != nil && !bytes.HasSuffix(msg, b.delim) {
+			msg = append(msg, b.delim...)
+		}
+		if _, err := b.local.Write(msg); err != nil {
+			return
+		}
+	}
+}
+
+// accept records the sender of a datagram as the peer and reports whether the
+// datagram should be delivered.
+func (b *udpBridge) accept(from net.Addr) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.lock && b.peer != nil && b.peer.String() != from.String() {
+		return false
+	}
+	b.peer = from
+	return true
+}
+
+// toNet sends each write on the port as one datagram.
+func (b *udpBridge) toNet() {
+	defer b.close()
+	buf := make([]byte, 65536)
+	for {
+		n, err := b.local.Read(buf)
+		if err != nil || n == 0 {
+			return // the port was closed
+		}
+		if b.connected {
+			_, err = b.conn.Write(buf[:n])
+		} else {
+			b.mu.Lock()
+			peer := b.peer
+			b.mu.Unlock()
+			if peer != nil {
+				_, err = b.conn.WriteTo(buf[:n], peer)
+			}
+		}
+		if err != nil && !isTransientUDP(err) {
+			return
+		}
+	}
+}
+
+// isTransientUDP reports errors that affect a single datagram only, such as
+// an ICMP port unreachable from an earlier send.
+func isTransientUDP(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EMSGSIZE)
+}
@@ -0,0 +1,81 @@
+package serial
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialUDP(t *testing.T) {
+	peer, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer peer.Close()
+
+	reader, err := DialUDP(peer.LocalAddr().String(), Config{Delimiter: "\n"}, UDPOptions{})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.NoError(t, reader.WriteLine("one", "\n"))
+	require.NoError(t, reader.WriteLine("two", "\n"))
+	buf := make([]byte, 64)
+	var from net.Addr
+	for _, want := range []string{"one\n", "two\n"} {
+		var n int
+		n, from, err = peer.ReadFrom(buf)
+		require.NoError(t, err)
+		require.Equal(t, want, string(buf[:n]))
+	}
+
+	// Unterminated datagrams still read as one line each.
+	_, err = peer.WriteTo([]byte("a,1"), from)
+	require.NoError(t, err)
+	_, err = peer.WriteTo([]byte("b,2\n"), from)
+	require.NoError(t, err)
+	for _, want := range []string{"a,1", "b,2"} {
+		line, err := reader.ReadLine()
+		require.NoError(t, err)
+		require.Equal(t, want, line)
+	}
+}
+
+func TestListenUDPLockRemote(t *testing.T) {
+	probe, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := probe.LocalAddr().String()
+	probe.Close()
+
+	reader, err := ListenUDP(addr, Config{Delimiter: "\n"}, UDPOptions{LockRemote: true})
+	require.NoError(t, err)
+	defer reader.Close()
+
+	first, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer first.Close()
+	other, err := net.Dial("udp", addr)
+	require.NoError(t, err)
+	defer other.Close()
+
+	_, err = first.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	line, err := reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "hello", line)
+
+	// Once locked, other senders are ignored and replies go to the first peer.
+	_, err = other.Write([]byte("intruder\n"))
+	require.NoError(t, err)
+	_, err = first.Write([]byte("again\n"))
+	require.NoError(t, err)
+	line, err = reader.ReadLine()
+	require.NoError(t, err)
+	require.Equal(t, "again", line)
+
+	require.NoError(t, reader.WriteLine("reply", "\n"))
+	buf := make([]byte, 16)
+	first.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := first.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "reply\n", string(buf[:n]))
+}
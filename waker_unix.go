@@ -0,0 +1,30 @@
+//go:build linux || darwin || freebsd
+
+package serial
+
+import "golang.org/x/sys/unix"
+
+// wakePipe is the self-pipe behind a waker: poll watches r, and wake writes
+// to w.
+type wakePipe struct {
+	r, w int
+}
+
+func newWakePipe() (wakePipe, error) {
+	fds, err := pipe()
+	if err != nil {
+		return wakePipe{}, err
+	}
+	return wakePipe{r: fds[0], w: fds[1]}, nil
+}
+
+// wake makes r readable and closes the write end.
+func (p wakePipe) wake() {
+	unix.Write(p.w, []byte{1})
+	unix.Close(p.w)
+}
+
+// release closes the read end.
+func (p wakePipe) release() {
+	unix.Close(p.r)
+}
@@ -0,0 +1,13 @@
+package serial
+
+// wakePipe is empty on Windows: reads there return every readWait and
+// check the waker's done channel, so nothing has to be woken.
+type wakePipe struct{}
+
+func newWakePipe() (wakePipe, error) {
+	return wakePipe{}, nil
+}
+
+func (wakePipe) wake() {}
+
+func (wakePipe) release() {}
@@ -0,0 +1,178 @@
+package serial
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Priority selects the lane of a WriteQueue.
+type Priority int
+
+const (
+	PriorityNormal Priority = iota // bulk traffic, written in order
+	PriorityUrgent                 // commands such as STOP that overtake queued normal writes
+)
+
+var priorityNames = [...]string{"normal", "urgent"}
+
+// String returns "normal" or "urgent".
+func (p Priority) String() string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return "Priority(" + strconv.Itoa(int(p)) + ")"
+	}
+	return priorityNames[p]
+}
+
+// WriteQueue writes to a SerialReader from a background goroutine, so
+// senders do not wait for the port. It has two lanes: urgent writes go out
+// before any queued normal ones, though not before a write already in
+// progress. So that a stream of urgent writes cannot starve the normal lane,
+// a waiting normal write goes out after every burst of urgent ones. Create
+// one with NewWriteQueue; it is safe for concurrent use.
+//
+// As with BufferedWriter, a failed write is sticky: queued writes are
+// dropped and the error is returned by every later Send and by Close.
+type WriteQueue struct {
+	s     *SerialReader
+	size  int
+	burst int
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	lanes  [2][][]byte
+	urgent int  // urgent writes made while a normal one waited
+	busy   bool // a write is in progress
+	closed bool
+	err    error
+	done   chan struct{}
+}
+
+// NewWriteQueue starts a WriteQueue holding up to size writes (default 64)
+// in each lane, where a waiting normal write goes out after burst urgent
+// ones (default 8).
+func (s *SerialReader) NewWriteQueue(size, burst int) *WriteQueue {
+	if size <= 0 {
+		size = 64
+	}
+	if burst <= 0 {
+		burst = 8
+	}
+	q := &WriteQueue{s: s, size: size, burst: burst, done: make(chan struct{})}
+	q.cond = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// Send queues a copy of b in the lane for prio, waiting while that lane is
+// full. It returns ErrClosed after Close, or the error of an earlier failed
+// write.
+func (q *WriteQueue) Send(b []byte, prio Priority) error {
+	lane := 0
+	if prio == PriorityUrgent {
+		lane = 1
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.err == nil && !q.closed && len(q.lanes[lane]) >= q.size {
+		q.cond.Wait()
+	}
+	switch {
+	case q.err != nil:
+		return q.err
+	case q.closed:
+		return ErrClosed
+	}
+	q.lanes[lane] = append(q.lanes[lane], append([]byte(nil), b...))
+	q.cond.Broadcast()
+	return nil
+}
+
+// SendLine queues line followed by newline, like Send.
+func (q *WriteQueue) SendLine(line, newline string, prio Priority) error {
+	return q.Send([]byte(line+newline), prio)
+}
+
+// Len returns the number of writes queued in the lane for prio.
+func (q *WriteQueue) Len(prio Priority) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if prio == PriorityUrgent {
+		return len(q.lanes[1])
+	}
+	return len(q.lanes[0])
+}
+
+// Flush waits until every queued write has been made, and returns the
+// error of a failed one.
+func (q *WriteQueue) Flush() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.err == nil && (q.busy || len(q.lanes[0])+len(q.lanes[1]) > 0) {
+		q.cond.Wait()
+	}
+	return q.err
+}
+
+// Close stops accepting writes, waits for the queued ones to be made and
+// returns the error of a failed one. Safe to call multiple times.
+func (q *WriteQueue) Close() error {
+	q.mu.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.mu.Unlock()
+	<-q.done
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err
+}
+
+func (q *WriteQueue) run() {
+	defer close(q.done)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for q.err == nil && !q.closed && len(q.lanes[0])+len(q.lanes[1]) == 0 {
+			q.cond.Wait()
+		}
+		b := q.next()
+		if b == nil {
+			return // closed and drained, or failed
+		}
+		q.busy = true
+		q.cond.Broadcast() // a lane has room
+		q.mu.Unlock()
+		_, err := q.s.Write(b)
+		q.mu.Lock()
+		q.busy = false
+		if err != nil {
+			q.err = err
+			q.lanes = [2][][]byte{}
+		}
+		q.cond.Broadcast()
+	}
+}
+
+// next takes the write to make next, or returns nil if there is none.
+func (q *WriteQueue) next() []byte {
+	if q.err != nil {
+		return nil
+	}
+	lane := 0
+	if len(q.lanes[1]) > 0 && (len(q.lanes[0]) == 0 || q.urgent < q.burst) {
+		lane = 1
+	}
+	if len(q.lanes[lane]) == 0 {
+		return nil
+	}
+	if lane == 1 {
+		if len(q.lanes[0]) > 0 {
+			q.urgent++ // a normal write is kept waiting
+		}
+	} else {
+		q.urgent = 0
+	}
+	b := q.lanes[lane][0]
+	q.lanes[lane][0] = nil
+	q.lanes[lane] = q.lanes[lane][1:]
+	return b
+}
@@ -0,0 +1,49 @@
+package serial
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteQueue(t *testing.T) {
+	reader, master := newTestReader(t, Config{})
+	q := reader.NewWriteQueue(0, 0)
+	require.NoError(t, q.SendLine("bulk", "\n", PriorityNormal))
+	require.NoError(t, q.SendLine("STOP", "\n", PriorityUrgent))
+	require.NoError(t, q.Flush())
+	require.Zero(t, q.Len(PriorityNormal))
+	buf := make([]byte, 10)
+	_, err := io.ReadFull(master, buf)
+	require.NoError(t, err)
+	// The worker may already have taken the bulk line when STOP arrived.
+	require.Contains(t, []string{"bulk\nSTOP\n", "STOP\nbulk\n"}, string(buf))
+	require.NoError(t, q.Close())
+	require.ErrorIs(t, q.Send([]byte("x"), PriorityNormal), ErrClosed)
+
+	// Write errors are sticky.
+	q = reader.NewWriteQueue(0, 0)
+	reader.Close()
+	require.NoError(t, q.SendLine("late", "\n", PriorityNormal))
+	require.ErrorIs(t, q.Flush(), ErrClosed)
+	require.ErrorIs(t, q.SendLine("later", "\n", PriorityUrgent), ErrClosed)
+	require.ErrorIs(t, q.Close(), ErrClosed)
+}
+
+func TestWriteQueue_Order(t *testing.T) {
+	q := &WriteQueue{burst: 2}
+	for _, s := range []string{"n1", "n2"} {
+		q.lanes[0] = append(q.lanes[0], []byte(s))
+	}
+	for _, s := range []string{"u1", "u2", "u3", "u4", "u5"} {
+		q.lanes[1] = append(q.lanes[1], []byte(s))
+	}
+	var got []string
+	for b := q.next(); b != nil; b = q.next() {
+		got = append(got, string(b))
+	}
+	// Urgent writes overtake, but a waiting normal one goes out every
+	// second urgent write.
+	require.Equal(t, []string{"u1", "u2", "n1", "u3", "u4", "n2", "u5"}, got)
+}
@@ -0,0 +1,137 @@
+package wsbridge
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// WebSocket opcodes (RFC 6455 section 5.2).
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close status codes (RFC 6455 section 7.4.1).
+const (
+	closeGoingAway     = 1001
+	closeInternalError = 1011
+)
+
+// maxMessage bounds a client message; dashboards only send short commands.
+const maxMessage = 64 << 10
+
+var errMessageTooBig = errors.New("wsbridge: message too big")
+
+// acceptKey computes Sec-WebSocket-Accept for a client's Sec-WebSocket-Key.
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// appendFrame appends an unmasked, unfragmented server frame to b.
+func appendFrame(b []byte, op byte, payload []byte) []byte {
+	b = append(b, 0x80|op)
+	switch n := len(payload); {
+	case n < 126:
+		b = append(b, byte(n))
+	case n <= 0xFFFF:
+		b = append(b, 126)
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b = append(b, 127)
+		b = binary.BigEndian.AppendUint64(b, uint64(n))
+	}
+	return append(b, payload...)
+}
+
+// closePayload returns the body of a close frame, with reason cut to fit
+// the 125 bytes of a control frame.
+func closePayload(code uint16, reason string) []byte {
+	b := binary.BigEndian.AppendUint16(nil, code)
+	return append(b, reason[:min(len(reason), 123)]...)
+}
+
+// readFrame reads one frame from a client and unmasks its payload.
+func readFrame(r *bufio.Reader) (fin bool, op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(r, hdr[:]); err != nil {
+		return
+	}
+	fin, op = hdr[0]&0x80 != 0, hdr[0]&0x0F
+	n := uint64(hdr[1] & 0x7F)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > maxMessage {
+		err = errMessageTooBig
+		return
+	}
+	var mask [4]byte
+	masked := hdr[1]&0x80 != 0
+	if masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return
+}
+
+// readMessage reads a complete data message, answering pings and
+// reassembling fragments on the way. It returns io.EOF after a close frame.
+func readMessage(r *bufio.Reader, control func(op byte, payload []byte) error) (op byte, msg []byte, err error) {
+	for {
+		fin, fop, payload, err := readFrame(r)
+		if err != nil {
+			return 0, nil, err
+		}
+		switch fop {
+		case opClose, opPing, opPong:
+			if err := control(fop, payload); err != nil {
+				return 0, nil, err
+			}
+			if fop == opClose {
+				return 0, nil, io.EOF
+			}
+			continue
+		case opText, opBinary:
+			op, msg = fop, payload
+		case opContinuation:
+			if len(msg)+len(payload) > maxMessage {
+				return 0, nil, errMessageTooBig
+			}
+			msg = append(msg, payload...)
+		}
+		if fin {
+			return op, msg, nil
+		}
+	}
+}
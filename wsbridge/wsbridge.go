@@ -0,0 +1,157 @@
+// Package wsbridge serves the live line stream of a serial port over
+// WebSocket, so browser dashboards can watch instruments without a custom
+// backend. Each line is sent as a JSON Message; clients may optionally write
+// commands back to the port.
+//
+// The WebSocket protocol (RFC 6455) is implemented with the standard library
+// only; compression and subprotocols are not supported.
+package wsbridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// Message is the JSON envelope of each line sent to clients.
+type Message struct {
+	Time time.Time `json:"time"`
+	Port string    `json:"port"`
+	Line string    `json:"line"`
+}
+
+// Bridge is an http.Handler that upgrades requests to WebSocket connections
+// and streams lines to them.
+type Bridge struct {
+	// Writer, if set, receives each text message from a client as a line.
+	// Without it the bridge is read-only and client messages are ignored.
+	// A failed write closes the connection with status 1011 and the error
+	// as the reason.
+	Writer serial.LineWriter
+	// CheckOrigin, if set, decides whether to accept a handshake. By
+	// default browsers may only connect from a page served by this host,
+	// so that no other site can write to the port through a visitor's
+	// browser; requests without an Origin header, from programs rather than
+	// browsers, are accepted.
+	CheckOrigin func(r *http.Request) bool
+	// Newline terminates lines written through Writer; default "\r\n".
+	Newline string
+	// Buffer is the number of lines queued per client; when a client falls
+	// behind, the oldest lines are dropped. Default 256.
+	Buffer int
+
+	id  string
+	src serial.LineSource
+}
+
+// New returns a Bridge streaming lines from src, labelled with the port id.
+func New(id string, src serial.LineSource) *Bridge {
+	return &Bridge{id: id, src: src, Newline: "\r\n", Buffer: 256}
+}
+
+// ServeHTTP performs the WebSocket handshake and streams lines until the
+// client disconnects or the port is closed.
+func (b *Bridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !headerHas(r.Header, "Connection", "upgrade") || !headerHas(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return
+	}
+	check := b.CheckOrigin
+	if check == nil {
+		check = sameOrigin
+	}
+	if !check(r) {
+		http.Error(w, "websocket origin not allowed", http.StatusForbidden)
+		return
+	}
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n")
+	if rw.Flush() != nil {
+		return
+	}
+
+	sub := b.src.Subscribe(b.Buffer)
+	sub.SetPolicy(serial.DropOldest)
+	defer sub.Unsubscribe()
+
+	var wmu sync.Mutex
+	send := func(op byte, payload []byte) error {
+		wmu.Lock()
+		defer wmu.Unlock()
+		_, err := conn.Write(appendFrame(nil, op, payload))
+		return err
+	}
+
+	go func() {
+		defer conn.Close() // unblocks the reader below
+		for line := range sub.C() {
+			msg, _ := json.Marshal(Message{Time: time.Now().UTC(), Port: b.id, Line: line})
+			if send(opText, msg) != nil {
+				return
+			}
+		}
+		send(opClose, closePayload(closeGoingAway, ""))
+	}()
+
+	br := bufio.NewReader(rw)
+	for {
+		op, msg, err := readMessage(br, func(op byte, payload []byte) error {
+			switch op {
+			case opPing:
+				return send(opPong, payload)
+			case opClose:
+				return send(opClose, payload)
+			}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+		if op == opText && b.Writer != nil {
+			if err := b.Writer.WriteLine(string(msg), b.Newline); err != nil {
+				send(opClose, closePayload(closeInternalError, err.Error()))
+				return
+			}
+		}
+	}
+}
+
+// sameOrigin reports whether r carries no Origin header or one whose host
+// is the host r was sent to.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// headerHas reports whether the comma-separated header key contains token.
+func headerHas(h http.Header, key, token string) bool {
+	for _, v := range h.Values(key) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
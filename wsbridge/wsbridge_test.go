@@ -0,0 +1,155 @@
+package wsbridge
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+	"github.com/luhtfiimanal/go-linux-serial/serialtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// Example from RFC 6455 section 1.3.
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// handshake sends a client handshake for host x, with the extra header
+// lines, to srv.
+func handshake(t *testing.T, srv *httptest.Server, extra string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" + extra + "\r\n"))
+	require.NoError(t, err)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	return conn, br, resp
+}
+
+// dial performs a client handshake against srv.
+func dial(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, br, resp := handshake(t, srv, "")
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+	return conn, br
+}
+
+// writeMasked sends a masked client frame.
+func writeMasked(t *testing.T, conn net.Conn, op byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | op, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, c := range payload {
+		frame = append(frame, c^mask[i%4])
+	}
+	_, err := conn.Write(frame)
+	require.NoError(t, err)
+}
+
+// newPort returns a simulated instrument and the port attached to it, with
+// a read loop running.
+func newPort(t *testing.T) (*serialtest.Simulator, *serial.SerialReader) {
+	t.Helper()
+	sim, err := serialtest.NewSimulator("\r\n")
+	require.NoError(t, err)
+	t.Cleanup(func() { sim.Close() })
+	port, err := serial.Open(serial.Config{Device: sim.Path(), BaudRate: 115200, Delimiter: "\r\n"})
+	require.NoError(t, err)
+	t.Cleanup(func() { port.Close() })
+	go port.ReadLinesLoop(nil, func(error) {})
+	return sim, port
+}
+
+func TestBridge(t *testing.T) {
+	sim, port := newPort(t)
+
+	b := New("seis1", port)
+	b.Writer = port
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	conn, br := dial(t, srv)
+	time.Sleep(20 * time.Millisecond) // let the handler subscribe
+	require.NoError(t, sim.Send("1,2,3"))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	fin, op, payload, err := readFrame(br)
+	require.NoError(t, err)
+	require.True(t, fin)
+	require.EqualValues(t, opText, op)
+	var msg Message
+	require.NoError(t, json.Unmarshal(payload, &msg))
+	require.Equal(t, "seis1", msg.Port)
+	require.Equal(t, "1,2,3", msg.Line)
+	require.WithinDuration(t, time.Now(), msg.Time, time.Second)
+
+	writeMasked(t, conn, opPing, []byte("hi"))
+	_, op, payload, err = readFrame(br)
+	require.NoError(t, err)
+	require.EqualValues(t, opPong, op)
+	require.Equal(t, "hi", string(payload))
+
+	writeMasked(t, conn, opText, []byte("C,START"))
+	cmd, err := sim.Expect(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, "C,START", cmd)
+}
+
+func TestBridgeRejectsPlainHTTP(t *testing.T) {
+	srv := httptest.NewServer(New("p", nil))
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUpgradeRequired, resp.StatusCode)
+}
+
+func TestBridgeOrigin(t *testing.T) {
+	_, port := newPort(t)
+	b := New("p", port)
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	_, _, resp := handshake(t, srv, "Origin: http://evil.example\r\n")
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	_, _, resp = handshake(t, srv, "Origin: http://x\r\n")
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	b.CheckOrigin = func(r *http.Request) bool { return r.Header.Get("Origin") == "http://evil.example" }
+	_, _, resp = handshake(t, srv, "Origin: http://evil.example\r\n")
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+}
+
+// failWriter is a LineWriter whose writes fail.
+type failWriter struct{}
+
+func (failWriter) WriteLine(string, string) error { return serial.ErrClosed }
+func (failWriter) Close() error                   { return nil }
+
+func TestBridgeWriteError(t *testing.T) {
+	_, port := newPort(t)
+	b := New("p", port)
+	b.Writer = failWriter{}
+	srv := httptest.NewServer(b)
+	defer srv.Close()
+
+	conn, br := dial(t, srv)
+	writeMasked(t, conn, opText, []byte("C,START"))
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, op, payload, err := readFrame(br)
+	require.NoError(t, err)
+	require.EqualValues(t, opClose, op)
+	require.Equal(t, closePayload(closeInternalError, serial.ErrClosed.Error()), payload)
+}
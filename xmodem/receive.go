@@ -0,0 +1,203 @@
+package xmodem
+
+import (
+	"fmt"
+	"io"
+)
+
+// Receive receives one file with XMODEM and writes it to w, returning the
+// number of bytes written. It asks for CRC mode unless Checksum is set, and
+// falls back to checksum mode if the sender does not respond to CRC requests.
+// XMODEM does not transmit the file size, so the 0x1A padding of the last
+// block is written too.
+func (c *Conn) Receive(w io.Writer) (int64, error) {
+	return c.receiveData(w, -1, false)
+}
+
+// ReceiveFiles receives a YMODEM batch. For each file, create is called with
+// the name and size from the sender (size is -1 if unknown) and returns where
+// to write it; the writer is not closed. An error from create cancels the
+// transfer.
+func (c *Conn) ReceiveFiles(create func(name string, size int64) (io.Writer, error)) error {
+	for {
+		name, size, err := c.receiveHeader()
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return nil
+		}
+		w, err := create(name, size)
+		if err != nil {
+			c.cancel()
+			return err
+		}
+		if _, err := c.receiveData(w, size, true); err != nil {
+			return err
+		}
+	}
+}
+
+// receiveHeader receives YMODEM block 0.
+func (c *Conn) receiveHeader() (name string, size int64, err error) {
+	for tries := 0; tries <= c.retries(); tries++ {
+		if err := c.send(crcC); err != nil {
+			return "", 0, err
+		}
+		b, err := c.readByte()
+		if err != nil {
+			if isTimeout(err) {
+				continue
+			}
+			return "", 0, err
+		}
+		num, data, err := c.readBlock(b, true)
+		if err != nil {
+			return "", 0, err
+		}
+		if data == nil || num != 0 {
+			continue
+		}
+		if err := c.send(ack); err != nil {
+			return "", 0, err
+		}
+		return parseHeader(data)
+	}
+	c.cancel()
+	return "", 0, ErrTooManyRetries
+}
+
+// receiveData receives data blocks numbered from 1 into w until EOT. With
+// limit >= 0 at most limit bytes are written. In YMODEM mode the first EOT
+// is NAKed, as the protocol requires.
+func (c *Conn) receiveData(w io.Writer, limit int64, ymodem bool) (int64, error) {
+	useCRC := ymodem || !c.Checksum
+	start := byte(nak)
+	if useCRC {
+		start = crcC
+	}
+	var total int64
+	expected := byte(1)
+	started, eotSeen := false, false
+	failures := 0
+	if err := c.send(start); err != nil {
+		return 0, err
+	}
+	for {
+		if failures > c.retries() {
+			c.cancel()
+			return total, ErrTooManyRetries
+		}
+		b, err := c.readByte()
+		if err != nil {
+			if !isTimeout(err) {
+				return total, err
+			}
+			failures++
+			if !started {
+				if !ymodem && useCRC && failures >= 3 {
+					useCRC, start = false, nak // sender may not support CRC
+				}
+				err = c.send(start)
+			} else {
+				err = c.send(nak)
+			}
+			if err != nil {
+				return total, err
+			}
+			continue
+		}
+		switch b {
+		case soh, stx:
+			num, data, err := c.readBlock(b, useCRC)
+			if err != nil {
+				return total, err
+			}
+			if data == nil {
+				failures++
+				if err := c.purge(); err != nil {
+					c.cancel()
+					return total, err
+				}
+				if err := c.send(nak); err != nil {
+					return total, err
+				}
+				continue
+			}
+			switch num {
+			case expected:
+			case expected - 1:
+				// Our ACK was lost; acknowledge the duplicate again.
+				if err := c.send(ack); err != nil {
+					return total, err
+				}
+				continue
+			default:
+				c.cancel()
+				return total, fmt.Errorf("%w: got block %d, want %d", ErrSync, num, expected)
+			}
+			if limit >= 0 && int64(len(data)) > limit-total {
+				data = data[:limit-total]
+			}
+			n, err := w.Write(data)
+			total += int64(n)
+			if err != nil {
+				c.cancel()
+				return total, err
+			}
+			if err := c.send(ack); err != nil {
+				return total, err
+			}
+			expected++
+			started = true
+			failures = 0
+		case eot:
+			if ymodem && !eotSeen {
+				eotSeen = true
+				if err := c.send(nak); err != nil {
+					return total, err
+				}
+				continue
+			}
+			return total, c.send(ack)
+		case can:
+			if c.canceled() {
+				return total, ErrCanceled
+			}
+		}
+	}
+}
+
+// readBlock reads the rest of a block whose header byte hdr has been read.
+// data is nil if the block is corrupt or truncated.
+func (c *Conn) readBlock(hdr byte, useCRC bool) (num byte, data []byte, err error) {
+	size := 128
+	if hdr == stx {
+		size = 1024
+	} else if hdr != soh {
+		return 0, nil, nil
+	}
+	trailer := 1
+	if useCRC {
+		trailer = 2
+	}
+	b, err := c.readN(2 + size + trailer)
+	if err != nil {
+		if isTimeout(err) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+	if b[0] != ^b[1] {
+		return 0, nil, nil
+	}
+	data = b[2 : 2+size]
+	if useCRC {
+		if crc16(data) != uint16(b[2+size])<<8|uint16(b[3+size]) {
+			return 0, nil, nil
+		}
+	} else if checksum(data) != b[2+size] {
+		return 0, nil, nil
+	}
+	return b[0], data, nil
+}
@@ -0,0 +1,196 @@
+package xmodem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Send transmits the contents of r with XMODEM, in checksum or CRC mode as
+// requested by the receiver, and with 1K blocks if Block1K is set and the
+// receiver uses CRC. The last block is padded with 0x1A.
+func (c *Conn) Send(r io.Reader) error {
+	useCRC, err := c.waitStart()
+	if err != nil {
+		return err
+	}
+	size := 128
+	if c.Block1K && useCRC {
+		size = 1024
+	}
+	if err := c.sendData(r, size, useCRC); err != nil {
+		return err
+	}
+	return c.sendEOT()
+}
+
+// SendFiles transmits files as one YMODEM batch using 1K blocks.
+func (c *Conn) SendFiles(files ...File) error {
+	for i := range files {
+		if err := c.sendHeader(&files[i]); err != nil {
+			return err
+		}
+		if err := c.sendData(files[i].Data, 1024, true); err != nil {
+			return err
+		}
+		if err := c.sendEOT(); err != nil {
+			return err
+		}
+	}
+	return c.sendHeader(nil)
+}
+
+// sendHeader sends YMODEM block 0 for f (nil ends the batch).
+func (c *Conn) sendHeader(f *File) error {
+	useCRC, err := c.waitStart()
+	if err != nil {
+		return err
+	}
+	if !useCRC {
+		c.cancel()
+		return errors.New("xmodem: receiver does not support YMODEM (CRC mode)")
+	}
+	return c.sendBlock(0, header(f), true)
+}
+
+// waitStart waits for the receiver's start character and reports whether it
+// asked for CRC mode. Timeouts and stray bytes both count toward Retries, so
+// a noisy line cannot keep it waiting forever.
+func (c *Conn) waitStart() (useCRC bool, err error) {
+	for tries := 0; tries <= c.retries(); tries++ {
+		b, err := c.readByte()
+		if err != nil {
+			if !isTimeout(err) {
+				return false, err
+			}
+			continue
+		}
+		switch b {
+		case crcC:
+			return true, nil
+		case nak:
+			return false, nil
+		case can:
+			if c.canceled() {
+				return false, ErrCanceled
+			}
+		}
+	}
+	return false, ErrTooManyRetries
+}
+
+// sendData sends r in blocks of size bytes, numbered from 1. A final chunk
+// that fits is sent as a 128-byte block even in 1K mode.
+func (c *Conn) sendData(r io.Reader, size int, useCRC bool) error {
+	num := byte(1)
+	chunk := make([]byte, size)
+	for {
+		n, err := io.ReadFull(r, chunk)
+		if n == 0 {
+			if err == io.EOF {
+				return nil
+			}
+			c.cancel()
+			return fmt.Errorf("xmodem: reading data: %w", err)
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			c.cancel()
+			return fmt.Errorf("xmodem: reading data: %w", err)
+		}
+		blockLen := size
+		if n <= 128 {
+			blockLen = 128
+		}
+		block := make([]byte, blockLen)
+		copy(block, chunk[:n])
+		for i := n; i < blockLen; i++ {
+			block[i] = pad
+		}
+		if err := c.sendBlock(num, block, useCRC); err != nil {
+			return err
+		}
+		num++
+		if n < size {
+			return nil
+		}
+	}
+}
+
+// sendBlock sends one block until it is acknowledged.
+func (c *Conn) sendBlock(num byte, data []byte, useCRC bool) error {
+	frame := make([]byte, 0, len(data)+5)
+	if len(data) == 1024 {
+		frame = append(frame, stx)
+	} else {
+		frame = append(frame, soh)
+	}
+	frame = append(frame, num, ^num)
+	frame = append(frame, data...)
+	if useCRC {
+		crc := crc16(data)
+		frame = append(frame, byte(crc>>8), byte(crc))
+	} else {
+		frame = append(frame, checksum(data))
+	}
+	for attempt := 0; attempt <= c.retries(); attempt++ {
+		if err := c.send(frame...); err != nil {
+			return err
+		}
+		if done, err := c.awaitAck(); done || err != nil {
+			if err == ErrTooManyRetries {
+				c.cancel()
+			}
+			return err
+		}
+	}
+	c.cancel()
+	return ErrTooManyRetries
+}
+
+// sendEOT ends the file, repeating EOT until it is acknowledged (YMODEM
+// receivers NAK the first one).
+func (c *Conn) sendEOT() error {
+	for attempt := 0; attempt <= c.retries(); attempt++ {
+		if err := c.send(eot); err != nil {
+			return err
+		}
+		if done, err := c.awaitAck(); done || err != nil {
+			return err
+		}
+	}
+	return ErrTooManyRetries
+}
+
+// awaitAck waits for the response to a block or EOT: done is true on ACK and
+// false when it should be resent. Stray bytes, such as start characters sent
+// before the receiver saw the first block, are skipped, but more than
+// Retries of them give up with ErrTooManyRetries.
+func (c *Conn) awaitAck() (done bool, err error) {
+	for stray := 0; stray <= c.retries(); stray++ {
+		b, err := c.readByte()
+		if err != nil {
+			if isTimeout(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		switch b {
+		case ack:
+			return true, nil
+		case nak:
+			return false, nil
+		case can:
+			if c.canceled() {
+				return false, ErrCanceled
+			}
+		}
+	}
+	return false, ErrTooManyRetries
+}
+
+// canceled reports whether a CAN just read is followed by a second one, as
+// the protocol requires to rule out line noise.
+func (c *Conn) canceled() bool {
+	b, err := c.readByte()
+	return err == nil && b == can
+}
@@ -0,0 +1,222 @@
+// Package xmodem transfers files with the XMODEM and YMODEM protocols over a
+// serial byte stream, typically a *serial.SerialReader used through its raw
+// Read/Write path.
+//
+// Supported are 128-byte blocks with the original arithmetic checksum,
+// XMODEM-CRC, XMODEM-1K and YMODEM batch transfers (file name and size in
+// block 0, several files per session).
+package xmodem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// Protocol control characters.
+const (
+	soh  = 0x01
+	stx  = 0x02
+	eot  = 0x04
+	ack  = 0x06
+	nak  = 0x15
+	can  = 0x18
+	crcC = 'C'
+	pad  = 0x1A
+)
+
+var (
+	// ErrCanceled reports that the other side aborted the transfer with CAN.
+	ErrCanceled = errors.New("xmodem: transfer canceled by remote")
+	// ErrTooManyRetries reports a transfer abandoned after Conn.Retries failed attempts.
+	ErrTooManyRetries = errors.New("xmodem: too many retries")
+	// ErrSync reports an out-of-sequence block.
+	ErrSync = errors.New("xmodem: block sequence error")
+)
+
+// Transport is the byte stream the transfer runs over. *serial.SerialReader
+// implements it, as does a pollable *os.File.
+type Transport interface {
+	io.ReadWriter
+	SetReadDeadline(t time.Time) error
+}
+
+// Conn runs transfers over a Transport. A Conn runs one transfer at a time.
+type Conn struct {
+	// Timeout bounds each wait for the other side. Zero means 10 seconds.
+	Timeout time.Duration
+	// Retries bounds consecutive failed attempts per block, and the stray
+	// bytes skipped while waiting for a response. Zero means 10.
+	Retries int
+	// Block1K makes Send use 1024-byte blocks (XMODEM-1K) when the receiver
+	// asks for CRC mode. SendFiles always uses them.
+	Block1K bool
+	// Checksum makes Receive ask for the original arithmetic checksum
+	// instead of CRC-16, for senders that do not support XMODEM-CRC.
+	Checksum bool
+
+	t   Transport
+	buf []byte // bytes read but not yet consumed
+}
+
+// New returns a Conn using t.
+func New(t Transport) *Conn {
+	return &Conn{t: t}
+}
+
+// File is one file of a YMODEM batch.
+type File struct {
+	Name string
+	Size int64
+	Data io.Reader
+}
+
+func (c *Conn) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return 10 * time.Second
+}
+
+func (c *Conn) retries() int {
+	if c.Retries > 0 {
+		return c.Retries
+	}
+	return 10
+}
+
+func isTimeout(err error) bool {
+	return errors.Is(err, serial.ErrTimeout) || errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// fill reads at least n bytes into c.buf before the deadline.
+func (c *Conn) fill(n int, deadline time.Time) error {
+	if err := c.t.SetReadDeadline(deadline); err != nil {
+		return err
+	}
+	defer c.t.SetReadDeadline(time.Time{})
+	tmp := make([]byte, 1100)
+	for len(c.buf) < n {
+		m, err := c.t.Read(tmp)
+		c.buf = append(c.buf, tmp[:m]...)
+		if err != nil && len(c.buf) < n {
+			return err
+		}
+	}
+	return nil
+}
+
+// readByte reads one byte, waiting at most Timeout.
+func (c *Conn) readByte() (byte, error) {
+	if err := c.fill(1, time.Now().Add(c.timeout())); err != nil {
+		return 0, err
+	}
+	b := c.buf[0]
+	c.buf = c.buf[1:]
+	return b, nil
+}
+
+// readN reads exactly n bytes, waiting at most Timeout in total.
+func (c *Conn) readN(n int) ([]byte, error) {
+	if err := c.fill(n, time.Now().Add(c.timeout())); err != nil {
+		return nil, err
+	}
+	b := c.buf[:n:n]
+	c.buf = c.buf[n:]
+	return b, nil
+}
+
+// purge discards input until the line has been quiet for a second (or
+// Timeout, if shorter), so that a retransmission starts clean. A line that
+// does not go quiet within Retries+1 timeouts fails with ErrTooManyRetries.
+func (c *Conn) purge() error {
+	quiet := min(time.Second, c.timeout())
+	deadline := time.Now().Add(c.timeout() * time.Duration(c.retries()+1))
+	for {
+		c.buf = nil
+		if time.Until(deadline) < quiet {
+			return fmt.Errorf("%w: line did not go quiet", ErrTooManyRetries)
+		}
+		if err := c.fill(1, time.Now().Add(quiet)); err != nil {
+			if isTimeout(err) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (c *Conn) send(b ...byte) error {
+	_, err := c.t.Write(b)
+	return err
+}
+
+// cancel aborts the transfer on the remote side.
+func (c *Conn) cancel() {
+	c.send(can, can, can)
+}
+
+// crc16 computes the XMODEM CRC-16 (CCITT polynomial 0x1021, initial value 0).
+func crc16(b []byte) uint16 {
+	var crc uint16
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func checksum(b []byte) byte {
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return sum
+}
+
+// header encodes the YMODEM block 0 payload for f; a nil f ends the batch.
+func header(f *File) []byte {
+	b := make([]byte, 128)
+	if f != nil {
+		s := f.Name + "\x00" + strconv.FormatInt(f.Size, 10)
+		if len(s) > 1024 {
+			s = s[:1024]
+		}
+		if len(s) >= 128 {
+			b = make([]byte, 1024)
+		}
+		copy(b, s)
+	}
+	return b
+}
+
+// parseHeader decodes a YMODEM block 0 payload; an empty name ends the batch.
+// The size is -1 if the sender did not give one.
+func parseHeader(b []byte) (name string, size int64, err error) {
+	name, rest, _ := strings.Cut(string(b), "\x00")
+	if name == "" {
+		return "", 0, nil
+	}
+	rest, _, _ = strings.Cut(rest, "\x00")
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return name, -1, nil
+	}
+	size, err = strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("xmodem: bad file size in header: %w", err)
+	}
+	return name, size, nil
+}
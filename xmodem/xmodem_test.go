@@ -0,0 +1,191 @@
+//go:build linux || darwin || freebsd
+
+package xmodem
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/creack/pty"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	serial "github.com/luhtfiimanal/go-linux-serial"
+)
+
+// newTestLink returns both ends of a fresh PTY: a SerialReader on the slave
+// side and the non-blocking master, so that read deadlines work on both.
+func newTestLink(t *testing.T) (*serial.SerialReader, *os.File) {
+	t.Helper()
+	ptmx, slave, err := pty.Open()
+	require.NoError(t, err)
+	fd, err := unix.Dup(int(ptmx.Fd()))
+	ptmx.Close()
+	require.NoError(t, err)
+	require.NoError(t, unix.SetNonblock(fd, true))
+	master := os.NewFile(uintptr(fd), "/dev/ptmx")
+	reader, err := serial.Open(serial.Config{Device: slave.Name(), BaudRate: 115200})
+	require.NoError(t, err)
+	slave.Close()
+	t.Cleanup(func() { reader.Close(); master.Close() })
+	return reader, master
+}
+
+func randomData(n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(int64(n))).Read(b)
+	return b
+}
+
+func TestXMODEM(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		block1K  bool
+		checksum bool
+		size     int
+		want     int
+	}{
+		{"crc", false, false, 300, 384},
+		{"checksum", false, true, 256, 256},
+		{"1k", true, false, 2100, 2048 + 128},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := newTestLink(t)
+			data := randomData(tc.size)
+			sender := New(a)
+			sender.Timeout = 500 * time.Millisecond
+			sender.Block1K = tc.block1K
+			sent := make(chan error, 1)
+			go func() { sent <- sender.Send(bytes.NewReader(data)) }()
+
+			receiver := New(b)
+			receiver.Timeout = 500 * time.Millisecond
+			receiver.Checksum = tc.checksum
+			var out bytes.Buffer
+			n, err := receiver.Receive(&out)
+			require.NoError(t, err)
+			require.NoError(t, <-sent)
+			require.EqualValues(t, tc.want, n)
+			require.Equal(t, data, out.Bytes()[:tc.size])
+			require.Equal(t, bytes.Repeat([]byte{pad}, tc.want-tc.size), out.Bytes()[tc.size:])
+		})
+	}
+}
+
+func TestYMODEMBatch(t *testing.T) {
+	a, b := newTestLink(t)
+	files := map[string][]byte{
+		"seis.log":  randomData(3000),
+		"empty.dat": {},
+	}
+	sender := New(a)
+	sender.Timeout = 500 * time.Millisecond
+	sent := make(chan error, 1)
+	go func() {
+		sent <- sender.SendFiles(
+			File{Name: "seis.log", Size: 3000, Data: bytes.NewReader(files["seis.log"])},
+			File{Name: "empty.dat", Size: 0, Data: bytes.NewReader(nil)},
+		)
+	}()
+
+	receiver := New(b)
+	receiver.Timeout = 500 * time.Millisecond
+	got := map[string]*bytes.Buffer{}
+	err := receiver.ReceiveFiles(func(name string, size int64) (io.Writer, error) {
+		require.EqualValues(t, len(files[name]), size)
+		got[name] = &bytes.Buffer{}
+		return got[name], nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, <-sent)
+	require.Len(t, got, 2)
+	for name, data := range files {
+		require.Equal(t, string(data), got[name].String(), name)
+	}
+}
+
+func TestYMODEMCancel(t *testing.T) {
+	a, b := newTestLink(t)
+	sender := New(a)
+	sender.Timeout = 500 * time.Millisecond
+	sent := make(chan error, 1)
+	go func() {
+		sent <- sender.SendFiles(File{Name: "x", Size: 1, Data: bytes.NewReader([]byte{1})})
+	}()
+	refuse := errors.New("disk full")
+	err := New(b).ReceiveFiles(func(string, int64) (io.Writer, error) { return nil, refuse })
+	require.ErrorIs(t, err, refuse)
+	require.ErrorIs(t, <-sent, ErrCanceled)
+}
+
+func TestSendNoisyLine(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		noise string
+	}{
+		{"before start", "xxxxxxxx"},
+		{"after block", "Cxxxxxxxx"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := newTestLink(t)
+			sender := New(a)
+			sender.Timeout = 5 * time.Second
+			sender.Retries = 3
+			_, err := b.Write([]byte(tc.noise))
+			require.NoError(t, err)
+			start := time.Now()
+			err = sender.Send(bytes.NewReader(randomData(100)))
+			require.ErrorIs(t, err, ErrTooManyRetries)
+			require.Less(t, time.Since(start), sender.Timeout) // gave up on the noise, not a timeout
+		})
+	}
+}
+
+func TestReceiveChatteringLine(t *testing.T) {
+	a, b := newTestLink(t)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		// A bad block, then noise that never lets the line go quiet.
+		b.Write([]byte{soh})
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(5 * time.Millisecond):
+				b.Write([]byte("x"))
+			}
+		}
+	}()
+
+	receiver := New(a)
+	receiver.Timeout = 100 * time.Millisecond
+	receiver.Retries = 2
+	done := make(chan error, 1)
+	go func() {
+		_, err := receiver.Receive(io.Discard)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrTooManyRetries)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Receive hung on a chattering line")
+	}
+}
+
+func TestHeader(t *testing.T) {
+	name, size, err := parseHeader(header(&File{Name: "a.bin", Size: 42}))
+	require.NoError(t, err)
+	require.Equal(t, "a.bin", name)
+	require.EqualValues(t, 42, size)
+	name, _, err = parseHeader(header(nil))
+	require.NoError(t, err)
+	require.Empty(t, name)
+	require.Equal(t, uint16(0x31C3), crc16([]byte("123456789")))
+}